@@ -8,11 +8,12 @@ import (
 )
 
 type AccessToken struct {
-	ID         uuid.UUID  `json:"id"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
-	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
-	Label      *string    `json:"label,omitempty"`
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	Label        *string    `json:"label,omitempty"`
+	AllowedCIDRs []string   `json:"allowedCidrs,omitempty"`
 }
 
 func (obj AccessToken) WithKey(key authkey.Key) AccessTokenWithKey {
@@ -25,6 +26,7 @@ type AccessTokenWithKey struct {
 }
 
 type CreateAccessTokenRequest struct {
-	ExpiresAt *time.Time `json:"expiresAt"`
-	Label     *string    `json:"label"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	Label        *string    `json:"label"`
+	AllowedCIDRs []string   `json:"allowedCidrs"`
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/distr-sh/distr/internal/types"
 	"github.com/google/uuid"
 )
@@ -40,10 +42,42 @@ type AgentDeployment struct {
 	IgnoreRevisionSkew bool           `json:"ignoreRevisionSkew"`
 }
 
+// AgentVersionWarningHeader is set on responses to agents that are older than the minimum supported version.
+const AgentVersionWarningHeader = "X-Distr-Agent-Version-Warning"
+
+// AgentVersionOutdatedResponse is returned with status 426 if an outdated agent tries to submit data while the
+// minimum version is enforced.
+type AgentVersionOutdatedResponse struct {
+	Message         string `json:"message"`
+	ReportedVersion string `json:"reportedVersion"`
+	MinVersion      string `json:"minVersion"`
+}
+
 type AgentDeploymentStatus struct {
 	RevisionID uuid.UUID                  `json:"revisionId"`
 	Type       types.DeploymentStatusType `json:"type"`
 	Message    string                     `json:"message"`
+	// IdempotencyKey is optional. The hub ignores a status if one with the same key was already submitted for the
+	// same revision, so a client can safely retry a submission.
+	IdempotencyKey *uuid.UUID `json:"idempotencyKey,omitempty"`
+	// ObservedAt is optional. It is the time at which the agent determined the status, which is earlier than the time
+	// of the submission if the submission had to be buffered. The hub stores the time of the submission instead if it is
+	// not set or lies in the future.
+	ObservedAt *time.Time `json:"observedAt,omitempty"`
+}
+
+// MaxAgentDeploymentStatusBatchSize limits the number of statuses an agent can submit with a single request.
+const MaxAgentDeploymentStatusBatchSize = 500
+
+type AgentDeploymentStatusBatchResponse struct {
+	// Failed contains all statuses of the batch that were not accepted. All other statuses were stored.
+	Failed []AgentDeploymentStatusBatchFailure `json:"failed"`
+}
+
+type AgentDeploymentStatusBatchFailure struct {
+	RevisionID     uuid.UUID  `json:"revisionId"`
+	IdempotencyKey *uuid.UUID `json:"idempotencyKey,omitempty"`
+	Error          string     `json:"error"`
 }
 
 type AgentDeploymentTargetMetrics struct {
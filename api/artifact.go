@@ -1,9 +1,14 @@
 package api
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/validation"
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type ArtifactResponse struct {
@@ -17,6 +22,7 @@ type ArtifactsResponse struct {
 }
 
 type ArtifactVersionPullResponse struct {
+	ID                       uuid.UUID             `json:"id"`
 	CreatedAt                time.Time             `json:"createdAt"`
 	RemoteAddress            *string               `json:"remoteAddress,omitempty"`
 	UserAccountName          *string               `json:"userAccountName,omitempty"`
@@ -25,3 +31,59 @@ type ArtifactVersionPullResponse struct {
 	Artifact                 types.Artifact        `json:"artifact"`
 	ArtifactVersion          types.ArtifactVersion `json:"artifactVersion"`
 }
+
+// ManifestInspection is the parsed structure of an image manifest or index. Config and Layers are set for image
+// manifests, Manifests is set for indexes.
+type ManifestInspection struct {
+	Digest      types.Digest         `json:"digest"`
+	MediaType   string               `json:"mediaType"`
+	Config      *ManifestDescriptor  `json:"config,omitempty"`
+	Layers      []ManifestDescriptor `json:"layers,omitempty"`
+	Manifests   []ManifestDescriptor `json:"manifests,omitempty"`
+	Annotations map[string]string    `json:"annotations,omitempty"`
+}
+
+type ManifestDescriptor struct {
+	MediaType   string              `json:"mediaType"`
+	Digest      types.Digest        `json:"digest"`
+	Size        int64               `json:"size"`
+	Platform    *imgspecv1.Platform `json:"platform,omitempty"`
+	Annotations map[string]string   `json:"annotations,omitempty"`
+}
+
+type CreateArtifactAccessRuleRequest struct {
+	UserAccountID *uuid.UUID               `json:"userAccountId"`
+	UserRole      *types.UserRole          `json:"userRole"`
+	Permission    types.ArtifactPermission `json:"permission"`
+}
+
+// artifactNamePattern is the repository name format defined by the OCI distribution spec, without the organization.
+var artifactNamePattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+type CreateArtifactRequest struct {
+	Name string `json:"name"`
+}
+
+func (r CreateArtifactRequest) Validate() error {
+	if !artifactNamePattern.MatchString(r.Name) {
+		return validation.NewValidationFailedError("invalid name")
+	}
+	return nil
+}
+
+// tagPattern is the tag format defined by the OCI distribution spec.
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+type CreateArtifactTagRequest struct {
+	Tag    string       `json:"tag"`
+	Digest types.Digest `json:"digest"`
+}
+
+func (r CreateArtifactTagRequest) Validate() error {
+	if !tagPattern.MatchString(r.Tag) {
+		return validation.NewValidationFailedError("invalid tag")
+	} else if err := digest.Digest(r.Digest).Validate(); err != nil {
+		return validation.NewValidationFailedError("invalid digest")
+	}
+	return nil
+}
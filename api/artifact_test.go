@@ -0,0 +1,20 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestCreateArtifactTagRequestValidate(t *testing.T) {
+	g := NewWithT(t)
+	d := types.Digest(digest.FromString("test"))
+	g.Expect(CreateArtifactTagRequest{Tag: "production", Digest: d}.Validate()).To(Succeed())
+	g.Expect(CreateArtifactTagRequest{Tag: "1.0.0-rc.1", Digest: d}.Validate()).To(Succeed())
+	g.Expect(CreateArtifactTagRequest{Tag: "", Digest: d}.Validate()).NotTo(Succeed())
+	g.Expect(CreateArtifactTagRequest{Tag: "-invalid", Digest: d}.Validate()).NotTo(Succeed())
+	g.Expect(CreateArtifactTagRequest{Tag: "production", Digest: "sha256:invalid"}.Validate()).NotTo(Succeed())
+	g.Expect(CreateArtifactTagRequest{Tag: "production"}.Validate()).NotTo(Succeed())
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"time"
+
+	"github.com/distr-sh/distr/internal/types"
 	"github.com/distr-sh/distr/internal/validation"
 	"github.com/google/uuid"
 )
@@ -14,6 +17,8 @@ type AuthLoginRequest struct {
 type AuthLoginResponse struct {
 	Token       string `json:"token,omitempty"`
 	RequiresMFA bool   `json:"requiresMfa"`
+	// RecoveryCodesLow is set after an MFA login if the user has only a few unused recovery codes left.
+	RecoveryCodesLow bool `json:"recoveryCodesLow,omitempty"`
 }
 
 type AuthRegistrationRequest struct {
@@ -45,3 +50,26 @@ func (r *AuthResetPasswordRequest) Validate() error {
 type AuthSwitchContextRequest struct {
 	OrganizationID uuid.UUID `json:"organizationId"`
 }
+
+type AuthTokenType string
+
+const (
+	AuthTokenTypeJWT         AuthTokenType = "jwt"
+	AuthTokenTypeAccessToken AuthTokenType = "accessToken"
+)
+
+// AuthIntrospectResponse describes the identity that the token of the current request resolves to.
+type AuthIntrospectResponse struct {
+	UserID                 uuid.UUID       `json:"userId"`
+	UserEmail              string          `json:"userEmail"`
+	UserEmailVerified      bool            `json:"userEmailVerified"`
+	OrganizationID         *uuid.UUID      `json:"organizationId,omitempty"`
+	UserRole               *types.UserRole `json:"userRole,omitempty"`
+	CustomerOrganizationID *uuid.UUID      `json:"customerOrganizationId,omitempty"`
+	SuperAdmin             bool            `json:"superAdmin"`
+	TokenType              AuthTokenType   `json:"tokenType"`
+	// TokenID and TokenLabel are only set for access tokens.
+	TokenID    *uuid.UUID `json:"tokenId,omitempty"`
+	TokenLabel *string    `json:"tokenLabel,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"time"
+
+	"github.com/distr-sh/distr/internal/types"
+)
+
+// CatalogExportFormatVersion is increased whenever the structure of [CatalogExport] changes incompatibly.
+const CatalogExportFormatVersion = 1
+
+type CatalogExport struct {
+	FormatVersion    int                     `json:"formatVersion"`
+	ExportedAt       time.Time               `json:"exportedAt"`
+	OrganizationSlug *string                 `json:"organizationSlug,omitempty"`
+	Artifacts        []CatalogExportArtifact `json:"artifacts"`
+}
+
+type CatalogExportArtifact struct {
+	Name      string                 `json:"name"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Versions  []CatalogExportVersion `json:"versions"`
+}
+
+type CatalogExportVersion struct {
+	Digest       types.Digest        `json:"digest"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	MediaType    string              `json:"mediaType"`
+	Tags         []string            `json:"tags"`
+	Annotations  map[string]string   `json:"annotations,omitempty"`
+	ManifestSize int64               `json:"manifestSize"`
+	Size         int64               `json:"size"`
+	Manifest     []byte              `json:"manifest"`
+	Blobs        []CatalogExportBlob `json:"blobs"`
+}
+
+type CatalogExportBlob struct {
+	Digest types.Digest `json:"digest"`
+	Size   int64        `json:"size"`
+}
+
+type CatalogImportStatus string
+
+const (
+	CatalogImportStatusImported CatalogImportStatus = "imported"
+	CatalogImportStatusSkipped  CatalogImportStatus = "skipped"
+	CatalogImportStatusRejected CatalogImportStatus = "rejected"
+)
+
+type CatalogImportEntry struct {
+	Artifact     string              `json:"artifact"`
+	Digest       types.Digest        `json:"digest"`
+	Tags         []string            `json:"tags"`
+	Status       CatalogImportStatus `json:"status"`
+	MissingBlobs []types.Digest      `json:"missingBlobs,omitempty"`
+	Errors       []string            `json:"errors,omitempty"`
+}
+
+type CatalogImportResponse struct {
+	Imported int                  `json:"imported"`
+	Skipped  int                  `json:"skipped"`
+	Rejected int                  `json:"rejected"`
+	Entries  []CatalogImportEntry `json:"entries"`
+}
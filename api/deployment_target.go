@@ -12,6 +12,12 @@ type DeploymentTargetAccessTokenResponse struct {
 	ConnectCommand string    `json:"connectCommand"`
 }
 
+type DeploymentTargetRotateSecretResponse struct {
+	DeploymentTargetAccessTokenResponse
+	// Manifest is the agent manifest with the new secret. The agent has to be re-installed with it.
+	Manifest string `json:"manifest"`
+}
+
 type DeploymentRequest struct {
 	DeploymentID         *uuid.UUID        `json:"deploymentId"`
 	DeploymentTargetID   uuid.UUID         `json:"deploymentTargetId"`
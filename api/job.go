@@ -0,0 +1,22 @@
+package api
+
+import "time"
+
+type JobRunResponse struct {
+	Job            string    `json:"job"`
+	StartedAt      time.Time `json:"startedAt"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	Success        bool      `json:"success"`
+	Error          *string   `json:"error,omitempty"`
+	RowsAffected   *int64    `json:"rowsAffected,omitempty"`
+}
+
+type CleanupJobConfigResponse struct {
+	Job            string  `json:"job"`
+	Cron           *string `json:"cron,omitempty"`
+	TimeoutSeconds float64 `json:"timeoutSeconds"`
+	MaxAgeSeconds  *int64  `json:"maxAgeSeconds,omitempty"`
+	MaxCount       *int    `json:"maxCount,omitempty"`
+	// LastRun is the most recent run by any instance, if any.
+	LastRun *JobRunResponse `json:"lastRun,omitempty"`
+}
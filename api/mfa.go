@@ -32,3 +32,11 @@ type RegenerateMFARecoveryCodesResponse struct {
 type MFARecoveryCodesStatusResponse struct {
 	RemainingCodes int `json:"remainingCodes"`
 }
+
+type VerifyMFARecoveryCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type VerifyMFARecoveryCodeResponse struct {
+	Valid bool `json:"valid"`
+}
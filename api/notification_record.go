@@ -14,6 +14,7 @@ type NotificationRecord struct {
 	PreviousDeploymentRevisionStatusID *uuid.UUID `json:"previousDeploymentStatusId,omitempty"`
 	CurrentDeploymentRevisionStatusID  *uuid.UUID `json:"currentDeploymentStatusId,omitempty"`
 	Message                            string     `json:"message"`
+	DeliveredAt                        *time.Time `json:"deliveredAt,omitempty"`
 }
 
 type NotificationRecordWithCurrentStatus struct {
@@ -24,3 +25,13 @@ type NotificationRecordWithCurrentStatus struct {
 	ApplicationVersionName          *string                   `json:"applicationVersionName,omitempty"`
 	CurrentDeploymentRevisionStatus *DeploymentRevisionStatus `json:"currentDeploymentRevisionStatus,omitempty"`
 }
+
+type TestNotificationChannelResult struct {
+	Channel string  `json:"channel"`
+	Success bool    `json:"success"`
+	Error   *string `json:"error,omitempty"`
+}
+
+type TestNotificationResponse struct {
+	Channels []TestNotificationChannelResult `json:"channels"`
+}
@@ -1,19 +1,58 @@
 package api
 
 import (
+	"time"
+
 	"github.com/distr-sh/distr/internal/types"
 )
 
 type CreateUpdateOrganizationRequest struct {
-	Name                   string  `json:"name"`
-	Slug                   *string `json:"slug"`
-	PreConnectScript       *string `json:"preConnectScript"`
-	PostConnectScript      *string `json:"postConnectScript"`
-	ConnectScriptIsSudo    bool    `json:"connectScriptIsSudo"`
-	ArtifactVersionMutable bool    `json:"artifactVersionMutable"`
+	Name                       string  `json:"name"`
+	Slug                       *string `json:"slug"`
+	PreConnectScript           *string `json:"preConnectScript"`
+	PostConnectScript          *string `json:"postConnectScript"`
+	ConnectScriptIsSudo        bool    `json:"connectScriptIsSudo"`
+	ArtifactVersionMutable     bool    `json:"artifactVersionMutable"`
+	QuotaExceededWebhookURL    *string `json:"quotaExceededWebhookUrl"`
+	QuotaExceededEmailEnabled  bool    `json:"quotaExceededEmailEnabled"`
+	RequireArtifactPrecreation bool    `json:"requireArtifactPrecreation"`
 }
 
 type OrganizationResponse struct {
 	types.Organization
 	SubscriptionLimits SubscriptionLimits `json:"subscriptionLimits"`
 }
+
+type UpdateOrganizationDomainsRequest struct {
+	AppDomain      *string `json:"appDomain"`
+	RegistryDomain *string `json:"registryDomain"`
+}
+
+type OrganizationDomainStatus struct {
+	Domain                 string     `json:"domain"`
+	VerificationRecordName string     `json:"verificationRecordName"`
+	CNAMETarget            string     `json:"cnameTarget"`
+	Verified               bool       `json:"verified"`
+	VerifiedAt             *time.Time `json:"verifiedAt,omitempty"`
+	DNSConfigured          *bool      `json:"dnsConfigured,omitempty"`
+	TLSReady               *bool      `json:"tlsReady,omitempty"`
+	WellKnownReady         *bool      `json:"wellKnownReady,omitempty"`
+	Errors                 []string   `json:"errors,omitempty"`
+}
+
+type OrganizationDomainsResponse struct {
+	VerificationToken *string                   `json:"verificationToken,omitempty"`
+	AppDomain         *OrganizationDomainStatus `json:"appDomain,omitempty"`
+	RegistryDomain    *OrganizationDomainStatus `json:"registryDomain,omitempty"`
+}
+
+// OrganizationDownloadStats aggregates the pulls of all artifacts of an organization within a period. For customer
+// users, only the pulls of their own customer organization are included.
+type OrganizationDownloadStats struct {
+	From                     time.Time                             `json:"from"`
+	To                       time.Time                             `json:"to"`
+	PullsTotal               int                                   `json:"pullsTotal"`
+	TopArtifacts             []types.ArtifactPullStats             `json:"topArtifacts"`
+	TopCustomerOrganizations []types.CustomerOrganizationPullStats `json:"topCustomerOrganizations"`
+	PullsOverTime            []types.PullCountBucket               `json:"pullsOverTime"`
+}
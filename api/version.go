@@ -0,0 +1,10 @@
+package api
+
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	Edition   string `json:"edition"`
+	// Development is true for snapshot builds, which are not built from a release tag.
+	Development bool `json:"development"`
+}
@@ -26,7 +26,8 @@ import (
 )
 
 var (
-	platformLoggingCore = &deploymenttargetlogs.Core{Encoder: zapcore.NewConsoleEncoder(func() zapcore.EncoderConfig {
+	platformLogsCollector *deploymenttargetlogs.BufferedCollector
+	platformLoggingCore   = &deploymenttargetlogs.Core{Encoder: zapcore.NewConsoleEncoder(func() zapcore.EncoderConfig {
 		cfg := zap.NewDevelopmentEncoderConfig()
 		cfg.TimeKey = ""
 		cfg.LevelKey = ""
@@ -44,7 +45,8 @@ var (
 )
 
 func init() {
-	platformLoggingCore.Collector = &deploymenttargetlogs.BufferedCollector{Delegate: client}
+	platformLogsCollector = client.BufferedDeploymentTargetLogs()
+	platformLoggingCore.Collector = platformLogsCollector
 	if agentenv.AgentVersionID == "" {
 		logger.Warn("AgentVersionID is not set. self updates will be disabled")
 	}
@@ -52,6 +54,12 @@ func init() {
 }
 
 func main() {
+	defer func() {
+		if err := platformLogsCollector.Stop(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+
 	defer func() {
 		if err := logger.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
 			fmt.Println(err)
@@ -74,6 +82,7 @@ func main() {
 		zap.Bool("release", buildconfig.IsRelease()))
 
 	go NewLogsWatcher().Watch(ctx, 30*time.Second)
+	go sendHeartbeats(ctx)
 
 	mainLoop(ctx)
 
@@ -145,6 +154,7 @@ loop:
 				continue
 			}
 
+			statuses := make([]api.AgentDeploymentStatus, 0, len(resource.Deployments))
 			for _, deployment := range resource.Deployments {
 				var agentDeployment *AgentDeployment
 				var status string
@@ -188,15 +198,28 @@ loop:
 				}
 
 				if err != nil {
-					err = client.StatusWithError(ctx, deployment.RevisionID, err)
+					statuses = append(statuses,
+						agentclient.NewStatus(deployment.RevisionID, types.DeploymentStatusTypeError, err.Error()))
 				} else {
-					err = client.Status(ctx, deployment.RevisionID, statusType, status)
-				}
-
-				if err != nil {
-					logger.Error("failed to send status", zap.Error(err))
+					statuses = append(statuses, agentclient.NewStatus(deployment.RevisionID, statusType, status))
 				}
 			}
+			sendStatuses(ctx, statuses)
+		}
+	}
+}
+
+// sendStatuses sends the statuses of all deployments with a single request.
+func sendStatuses(ctx context.Context, statuses []api.AgentDeploymentStatus) {
+	if len(statuses) == 0 {
+		return
+	}
+	if resp, err := client.StatusBatch(ctx, statuses); err != nil {
+		logger.Error("failed to send status", zap.Error(err))
+	} else {
+		for _, failure := range resp.Failed {
+			logger.Error("status was rejected",
+				zap.Stringer("deploymentRevisionId", failure.RevisionID), zap.String("error", failure.Error))
 		}
 	}
 }
@@ -222,3 +245,17 @@ func sendProgressInterval(ctx context.Context, revisionID uuid.UUID) {
 		}
 	}
 }
+
+func sendHeartbeats(ctx context.Context) {
+	tick := time.Tick(types.DeploymentTargetHeartbeatInterval)
+	for {
+		if err := client.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("failed to send heartbeat", zap.Error(err))
+		}
+		select {
+		case <-tick:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
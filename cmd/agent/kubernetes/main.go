@@ -34,7 +34,8 @@ import (
 )
 
 var (
-	platformLoggingCore = &deploymenttargetlogs.Core{Encoder: zapcore.NewConsoleEncoder(func() zapcore.EncoderConfig {
+	platformLogsCollector *deploymenttargetlogs.BufferedCollector
+	platformLoggingCore   = &deploymenttargetlogs.Core{Encoder: zapcore.NewConsoleEncoder(func() zapcore.EncoderConfig {
 		cfg := zap.NewDevelopmentEncoderConfig()
 		cfg.TimeKey = ""
 		cfg.LevelKey = ""
@@ -57,7 +58,8 @@ var (
 )
 
 func init() {
-	platformLoggingCore.Collector = &deploymenttargetlogs.BufferedCollector{Delegate: agentClient}
+	platformLogsCollector = agentClient.BufferedDeploymentTargetLogs()
+	platformLoggingCore.Collector = platformLogsCollector
 	if agentenv.AgentVersionID == "" {
 		logger.Warn("AgentVersionID is not set. self updates will be disabled")
 	}
@@ -70,6 +72,12 @@ func init() {
 }
 
 func main() {
+	defer func() {
+		if err := platformLogsCollector.Stop(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+
 	defer func() {
 		if err := logger.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
 			fmt.Println(err)
@@ -100,6 +108,8 @@ func main() {
 		}
 	}()
 
+	go sendHeartbeats(ctx)
+
 	var metricsCancelFunc context.CancelFunc
 	var logsWatcher *logsWatcher
 	var logsCancelFunc context.CancelFunc
@@ -177,6 +187,7 @@ func main() {
 			continue
 		}
 
+		var statuses statusBatch
 		for _, deployment := range res.Deployments {
 			var currentDeployment *AgentDeployment
 			for _, existing := range existingDeployments {
@@ -190,13 +201,14 @@ func main() {
 					logger.Info("current helm release does not exist")
 				} else {
 					logger.Warn("refusing to install or update", zap.Error(err))
-					pushErrorStatus(ctx, deployment, err)
+					pushErrorStatus(&statuses, deployment, err)
 					continue
 				}
 			}
 
-			runInstallOrUpgrade(ctx, res.Namespace, deployment, currentDeployment)
+			runInstallOrUpgrade(ctx, res.Namespace, deployment, currentDeployment, &statuses)
 		}
+		statuses.send(ctx)
 	}
 
 	logger.Info("shutting down")
@@ -252,15 +264,16 @@ func runInstallOrUpgrade(
 	namespace string,
 	deployment api.AgentDeployment,
 	currentDeployment *AgentDeployment,
+	statuses *statusBatch,
 ) {
 	progress := Progress(deployment)
 
 	if _, err := agentauth.EnsureAuth(ctx, agentClient.RawToken(), deployment); err != nil {
 		logger.Error("failed to ensure docker auth", zap.Error(err))
-		pushErrorStatus(ctx, deployment, fmt.Errorf("failed to ensure docker auth: %w", err))
+		pushErrorStatus(statuses, deployment, fmt.Errorf("failed to ensure docker auth: %w", err))
 	} else if err := ensureImagePullSecret(ctx, namespace, deployment); err != nil {
 		logger.Error("failed to ensure image pull secret", zap.Error(err))
-		pushErrorStatus(ctx, deployment, fmt.Errorf("failed to ensure image pull secret: %w", err))
+		pushErrorStatus(statuses, deployment, fmt.Errorf("failed to ensure image pull secret: %w", err))
 	}
 
 	if currentDeployment == nil {
@@ -274,10 +287,10 @@ func runInstallOrUpgrade(
 		})
 		if err != nil {
 			logger.Error("install error", zap.Error(err))
-			pushErrorStatus(ctx, deployment, fmt.Errorf("install error: %w", err))
+			pushErrorStatus(statuses, deployment, fmt.Errorf("install error: %w", err))
 		} else {
 			logger.Info("helm install succeeded")
-			pushRunningStatus(ctx, deployment, "helm install succeeded")
+			pushRunningStatus(statuses, deployment, "helm install succeeded")
 		}
 	} else if currentDeployment.RevisionID != deployment.RevisionID {
 		successMessage := "helm upgrade succeeded"
@@ -288,7 +301,7 @@ func runInstallOrUpgrade(
 				return fmt.Errorf("could not save latest deployment: %w", err)
 			} else if deployment.ForceRestart {
 				if err := ForceRestart(ctx, namespace, *updatedDeployment); err != nil {
-					pushErrorStatus(ctx, deployment, fmt.Errorf("%v; force restart error: %w", successMessage, err))
+					pushErrorStatus(statuses, deployment, fmt.Errorf("%v; force restart error: %w", successMessage, err))
 				} else {
 					successMessage += "; force restart succeeded"
 				}
@@ -297,10 +310,10 @@ func runInstallOrUpgrade(
 		})
 		if err != nil {
 			logger.Error("upgrade error", zap.Error(err))
-			pushErrorStatus(ctx, deployment, fmt.Errorf("upgrade error: %w", err))
+			pushErrorStatus(statuses, deployment, fmt.Errorf("upgrade error: %w", err))
 		} else {
 			logger.Info(successMessage)
-			pushRunningStatus(ctx, deployment, successMessage)
+			pushRunningStatus(statuses, deployment, successMessage)
 		}
 	} else {
 		logger.Info("no action required. running status check")
@@ -308,11 +321,11 @@ func runInstallOrUpgrade(
 			currentDeployment.LogsEnabled = deployment.LogsEnabled
 			if err := SaveDeployment(ctx, namespace, *currentDeployment); err != nil {
 				logger.Error("could not save latest deployment", zap.Error(err))
-				pushErrorStatus(ctx, deployment, fmt.Errorf("could not save latest deployment: %w", err))
+				pushErrorStatus(statuses, deployment, fmt.Errorf("could not save latest deployment: %w", err))
 			}
 		} else if resources, err := GetHelmManifest(ctx, namespace, deployment.ReleaseName); err != nil {
 			logger.Warn("could not get helm manifest", zap.Error(err))
-			pushErrorStatus(ctx, deployment, fmt.Errorf("could not get helm manifest: %w", err))
+			pushErrorStatus(statuses, deployment, fmt.Errorf("could not get helm manifest: %w", err))
 		} else {
 			var err error
 			for _, resource := range resources {
@@ -324,10 +337,10 @@ func runInstallOrUpgrade(
 
 			if err != nil {
 				logger.Warn("resource status error", zap.Error(err))
-				pushErrorStatus(ctx, deployment, fmt.Errorf("resource status error: %w", err))
+				pushErrorStatus(statuses, deployment, fmt.Errorf("resource status error: %w", err))
 			} else {
 				logger.Info("status check passed")
-				pushHealthyStatus(ctx, deployment, fmt.Sprintf("status check passed. %v resources healthy", len(resources)))
+				pushHealthyStatus(statuses, deployment, fmt.Sprintf("status check passed. %v resources healthy", len(resources)))
 			}
 		}
 	}
@@ -362,16 +375,31 @@ func (psr *progressStatusRunner) Run(ctx context.Context, f func() error) error
 	return f()
 }
 
-func pushHealthyStatus(ctx context.Context, deployment api.AgentDeployment, status string) {
-	if err := agentClient.Status(ctx, deployment.RevisionID, types.DeploymentStatusTypeHealthy, status); err != nil {
+// statusBatch collects the statuses of one iteration of the main loop, so that they are sent with a single request.
+type statusBatch []api.AgentDeploymentStatus
+
+func (b statusBatch) send(ctx context.Context) {
+	if len(b) == 0 {
+		return
+	}
+	if resp, err := agentClient.StatusBatch(ctx, b); err != nil {
 		logger.Warn("status push failed", zap.Error(err))
+	} else {
+		for _, failure := range resp.Failed {
+			logger.Warn("status was rejected",
+				zap.Stringer("deploymentRevisionId", failure.RevisionID), zap.String("error", failure.Error))
+		}
 	}
 }
 
-func pushRunningStatus(ctx context.Context, deployment api.AgentDeployment, status string) {
-	if err := agentClient.Status(ctx, deployment.RevisionID, types.DeploymentStatusTypeRunning, status); err != nil {
-		logger.Warn("status push failed", zap.Error(err))
-	}
+func pushHealthyStatus(statuses *statusBatch, deployment api.AgentDeployment, status string) {
+	*statuses = append(*statuses,
+		agentclient.NewStatus(deployment.RevisionID, types.DeploymentStatusTypeHealthy, status))
+}
+
+func pushRunningStatus(statuses *statusBatch, deployment api.AgentDeployment, status string) {
+	*statuses = append(*statuses,
+		agentclient.NewStatus(deployment.RevisionID, types.DeploymentStatusTypeRunning, status))
 }
 
 func pushProgressingStatus(ctx context.Context, deployment api.AgentDeployment) {
@@ -385,10 +413,9 @@ func pushProgressingStatus(ctx context.Context, deployment api.AgentDeployment)
 	}
 }
 
-func pushErrorStatus(ctx context.Context, deployment api.AgentDeployment, err error) {
-	if err := agentClient.Status(ctx, deployment.RevisionID, types.DeploymentStatusTypeError, err.Error()); err != nil {
-		logger.Warn("status push failed", zap.Error(err))
-	}
+func pushErrorStatus(statuses *statusBatch, deployment api.AgentDeployment, err error) {
+	*statuses = append(*statuses,
+		agentclient.NewStatus(deployment.RevisionID, types.DeploymentStatusTypeError, err.Error()))
 }
 
 func ensureImagePullSecret(ctx context.Context, namespace string, deployment api.AgentDeployment) error {
@@ -470,3 +497,17 @@ func isSameDeployment(existingDeployment AgentDeployment, resourceDeployment api
 	return (existingDeployment.ID != uuid.Nil && existingDeployment.ID == resourceDeployment.ID) ||
 		(existingDeployment.ID == uuid.Nil && resourceDeployment.ReleaseName == existingDeployment.ReleaseName)
 }
+
+func sendHeartbeats(ctx context.Context) {
+	tick := time.Tick(types.DeploymentTargetHeartbeatInterval)
+	for {
+		if err := agentClient.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("failed to send heartbeat", zap.Error(err))
+		}
+		select {
+		case <-tick:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -28,6 +28,7 @@ const (
 	deploymentLogRecord       = "DeploymentLogRecord"
 	deploymentTargetLogRecord = "DeploymentTargetLogRecord"
 	oidcState                 = "OIDCState"
+	auditEvent                = "AuditEvent"
 )
 
 type CleanupOptions struct {
@@ -40,13 +41,14 @@ func NewCleanupCommand() *cobra.Command {
 	cmd := cobra.Command{
 		Use: "cleanup <type>",
 		Long: fmt.Sprintf(
-			"type must be one of: %v, %v, %v, %v, %v, %v",
+			"type must be one of: %v, %v, %v, %v, %v, %v, %v",
 			deploymentTargetStatus,
 			deploymentRevisionStatus,
 			deploymentTargetMetrics,
 			deploymentLogRecord,
 			deploymentTargetLogRecord,
 			oidcState,
+			auditEvent,
 		),
 		Short: "delete old data",
 		Args:  cobra.ExactArgs(1),
@@ -57,6 +59,7 @@ func NewCleanupCommand() *cobra.Command {
 			deploymentLogRecord,
 			deploymentTargetLogRecord,
 			oidcState,
+			auditEvent,
 		},
 		PreRun: func(cmd *cobra.Command, args []string) { env.Initialize() },
 		Run: func(cmd *cobra.Command, args []string) {
@@ -95,6 +98,8 @@ func runCleanup(ctx context.Context, opts CleanupOptions) error {
 		cleanupFunc = cleanup.RunDeploymentTargetLogRecordCleanup
 	case oidcState:
 		cleanupFunc = cleanup.RunOIDCStateCleanup
+	case auditEvent:
+		cleanupFunc = cleanup.RunAuditEventCleanup
 	default:
 		log.Sugar().Errorf("invalid cleanup type: %v", opts.Type)
 		return errors.New("invalid cleanup type")
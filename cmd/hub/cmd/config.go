@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/envutil"
+	"github.com/spf13/cobra"
+)
+
+const redactedValue = "<redacted>"
+
+// secretKeyParts are substrings of environment variable names whose values must never be printed.
+var secretKeyParts = []string{"SECRET", "PASSWORD", "TOKEN", "API_KEY", "DSN", "DOCKER_CONFIG"}
+
+var ConfigCommand = &cobra.Command{
+	Use:   "config",
+	Short: "validate and inspect the configuration",
+}
+
+var ConfigCheckCommand = &cobra.Command{
+	Use:   "check",
+	Short: "validate the configuration and print all problems",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		report := envutil.Inspect(env.Initialize)
+		if len(report.Errors) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+			return
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "configuration is invalid (%v problems):\n", len(report.Errors))
+		for _, err := range report.Errors {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  - %v\n", err)
+		}
+		os.Exit(1)
+	},
+}
+
+var ConfigDumpCommand = &cobra.Command{
+	Use:   "dump",
+	Short: "print the effective configuration with secrets redacted",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		report := envutil.Inspect(env.Initialize)
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVALUE\tSOURCE")
+		for _, entry := range report.Entries {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", entry.Key, redactEntryValue(entry), entrySource(entry))
+		}
+		_ = w.Flush()
+		for _, err := range report.Errors {
+			fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	ConfigCommand.AddCommand(ConfigCheckCommand, ConfigDumpCommand)
+	RootCommand.AddCommand(ConfigCommand)
+}
+
+func entrySource(entry envutil.Entry) string {
+	if entry.IsSet {
+		return "env"
+	} else if entry.IsDefault {
+		return "default"
+	} else {
+		return "unset"
+	}
+}
+
+func redactEntryValue(entry envutil.Entry) string {
+	if entry.Value == "" {
+		return ""
+	}
+	for _, part := range secretKeyParts {
+		if strings.Contains(entry.Key, part) {
+			return redactedValue
+		}
+	}
+	if entry.Key == "DATABASE_URL" {
+		if u, err := url.Parse(entry.Value); err == nil && u.Scheme != "" {
+			return u.Redacted()
+		}
+		return redactedValue
+	}
+	return entry.Value
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"os"
+	// embed the timezone database for alert configuration quiet hours in case the image does not provide it
+	_ "time/tzdata"
 
 	"github.com/distr-sh/distr/cmd/hub/cmd"
 )
@@ -9,17 +9,20 @@ import {ArtifactPullsService} from '../../services/artifact-pulls.service';
 })
 export class ArtifactPullsComponent {
   protected hasMore = true;
-  private currentOldestPull?: Date;
+  private nextBefore?: string;
+  private nextId?: string;
   private readonly fetchCount = 50;
   private readonly showMore$ = new Subject<void>();
   private readonly pulls = inject(ArtifactPullsService);
 
   protected readonly pulls$ = this.showMore$.pipe(
     startWith(undefined),
-    switchMap(() => this.pulls.get({before: this.currentOldestPull, count: this.fetchCount})),
+    switchMap(() => this.pulls.get({before: this.nextBefore, beforeId: this.nextId, count: this.fetchCount})),
     tap((it) => {
       if (it.length > 0) {
-        this.currentOldestPull = new Date(it[it.length - 1].createdAt);
+        // the next page continues after the last pull, also if further pulls have the same timestamp
+        this.nextBefore = it[it.length - 1].createdAt;
+        this.nextId = it[it.length - 1].id;
       }
       if (it.length < this.fetchCount) {
         this.hasMore = false;
@@ -34,14 +37,14 @@ export class ArtifactPullsComponent {
   }
 
   protected formatRemoteAddress(addr: string): string {
-    if (addr.includes(']')) {
-      // IPv6
-      return addr.substring(0, addr.lastIndexOf(']') + 1);
-    } else if (addr.includes(':')) {
-      // IPv4
+    if (addr.startsWith('[')) {
+      // IPv6 with port (recorded before addresses were normalized)
+      return addr.substring(1, addr.lastIndexOf(']'));
+    } else if (addr.split(':').length === 2) {
+      // IPv4 with port (recorded before addresses were normalized)
       return addr.substring(0, addr.lastIndexOf(':'));
     } else {
-      // fallback for undetermined format
+      // normalized IPv4 or IPv6 address
       return addr;
     }
   }
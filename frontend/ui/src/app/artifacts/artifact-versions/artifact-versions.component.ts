@@ -16,6 +16,7 @@ import {UuidComponent} from '../../components/uuid';
 import {RequireVendorDirective} from '../../directives/required-role.directive';
 import {
   ArtifactsService,
+  ArtifactVersionPlatform,
   ArtifactWithTags,
   HasDownloads,
   TaggedArtifactVersion,
@@ -110,6 +111,10 @@ export class ArtifactVersionsComponent {
     }
   }
 
+  protected formatPlatform(platform: ArtifactVersionPlatform): string {
+    return [platform.os, platform.architecture, platform.variant].filter((it) => !!it).join('/');
+  }
+
   protected calcVersionDownloads(version: TaggedArtifactVersion): HasDownloads {
     const downloadsTotal = version.tags.reduce(
       (sum, tag) => sum + (tag.downloads.downloadsTotal ?? 0),
@@ -147,11 +152,11 @@ export class ArtifactVersionsComponent {
   public deleteArtifact(artifact: ArtifactWithTags): void {
     this.overlay
       .confirm(
-        `This will permanently delete ${artifact.name} and all its versions. Users will no longer be able to download this artifact. Are you sure?`
+        `This will delete ${artifact.name} and all its versions. Users will no longer be able to download this artifact. An admin can restore it until it is purged. Are you sure?`
       )
       .pipe(
         filter((result) => result === true),
-        switchMap(() => this.artifacts.deleteArtifact(artifact.id)),
+        switchMap(() => this.artifacts.deleteArtifact(artifact)),
         catchError((e) => {
           const msg = getFormDisplayedError(e);
           if (msg) {
@@ -4,7 +4,7 @@ import {takeUntilDestroyed} from '@angular/core/rxjs-interop';
 import {FormBuilder, ReactiveFormsModule, Validators} from '@angular/forms';
 import {ActivatedRoute, Router, RouterLink} from '@angular/router';
 import {FaIconComponent} from '@fortawesome/angular-fontawesome';
-import {faGithub, faGoogle, faMicrosoft} from '@fortawesome/free-brands-svg-icons';
+import {faGithub, faGitlab, faGoogle, faMicrosoft} from '@fortawesome/free-brands-svg-icons';
 import {faArrowRightToBracket} from '@fortawesome/free-solid-svg-icons/faArrowRightToBracket';
 import {distinctUntilChanged, filter, lastValueFrom, map, take} from 'rxjs';
 import {getFormDisplayedError} from '../../util/errors';
@@ -111,7 +111,12 @@ export class LoginComponent implements OnInit {
       const response = await lastValueFrom(this.auth.login(email, password, mfaCode));
       if (response.requiresMfa) {
         this.mfaRequired.set(true);
-      } else if (this.auth.isCustomer()) {
+        return;
+      }
+      if (response.recoveryCodesLow) {
+        this.toast.info('You are running low on MFA recovery codes. Please regenerate them in your account settings.');
+      }
+      if (this.auth.isCustomer()) {
         await this.router.navigate(['/home']);
       } else {
         await this.router.navigate(['/dashboard'], {queryParams: {from: 'login'}});
@@ -137,6 +142,7 @@ export class LoginComponent implements OnInit {
   protected readonly faGoogle = faGoogle;
   protected readonly faGithub = faGithub;
   protected readonly faMicrosoft = faMicrosoft;
+  protected readonly faGitlab = faGitlab;
   protected readonly faGeneric = faArrowRightToBracket;
   protected readonly faArrowRightToBracket = faArrowRightToBracket;
 }
@@ -44,6 +44,9 @@ export class OrganizationSettingsComponent implements OnInit {
     postConnectScript: this.fb.control<string | undefined>(undefined),
     connectScriptIsSudo: this.fb.control<boolean>(false),
     artifactVersionMutable: this.fb.control<boolean>(false),
+    quotaExceededWebhookUrl: this.fb.control<string | undefined>(undefined, [Validators.pattern(/^https?:\/\/.+/)]),
+    quotaExceededEmailEnabled: this.fb.control<boolean>(false),
+    requireArtifactPrecreation: this.fb.control<boolean>(false),
   });
   formLoading = signal(false);
 
@@ -79,6 +82,9 @@ export class OrganizationSettingsComponent implements OnInit {
             postConnectScript: this.form.value.postConnectScript?.trim(),
             connectScriptIsSudo: this.form.value.connectScriptIsSudo ?? false,
             artifactVersionMutable: this.form.value.artifactVersionMutable ?? false,
+            quotaExceededWebhookUrl: this.form.value.quotaExceededWebhookUrl?.trim() || undefined,
+            quotaExceededEmailEnabled: this.form.value.quotaExceededEmailEnabled ?? false,
+            requireArtifactPrecreation: this.form.value.requireArtifactPrecreation ?? false,
           })
         );
         this.toast.success('Settings saved successfully');
@@ -14,6 +14,8 @@ export interface ArtifactLicense extends BaseModel, Named {
   expiresAt?: Date;
   artifacts?: ArtifactLicenseSelection[];
   customerOrganizationId?: string;
+  maxPulls?: number;
+  pullLimitPeriod?: 'day' | 'week' | 'month' | 'year';
 }
 
 @Injectable({providedIn: 'root'})
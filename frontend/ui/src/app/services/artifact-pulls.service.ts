@@ -8,10 +8,17 @@ export class ArtifactPullsService {
   private readonly baseUrl = '/api/v1/artifact-pulls';
   private readonly httpClient = inject(HttpClient);
 
-  public get({before, count}: {before?: Date; count?: number} = {}): Observable<ArtifactVersionPull[]> {
+  public get({
+    before,
+    beforeId,
+    count,
+  }: {before?: string; beforeId?: string; count?: number} = {}): Observable<ArtifactVersionPull[]> {
     let params = new HttpParams();
     if (before !== undefined) {
-      params = params.set('before', before.toISOString());
+      params = params.set('before', before);
+    }
+    if (beforeId !== undefined) {
+      params = params.set('beforeId', beforeId);
     }
     if (count !== undefined) {
       params = params.set('count', count);
@@ -55,6 +55,14 @@ export interface TaggedArtifactVersion extends HasDownloads {
   lastScannedAt?: string;
   imageUrl?: string;
   inferredType: 'generic' | 'container-image' | 'helm-chart';
+  platforms?: ArtifactVersionPlatform[];
+}
+
+export interface ArtifactVersionPlatform {
+  digest: string;
+  os: string;
+  architecture: string;
+  variant?: string;
 }
 
 export interface ArtifactWithTags extends Artifact {
@@ -98,12 +106,14 @@ export class ArtifactsService {
       .pipe(tap((it) => this.cache.save(it)));
   }
 
-  public deleteArtifact(artifactId: string): Observable<void> {
-    return this.http.delete<void>(`${this.artifactsUrl}/${artifactId}`).pipe(
-      tap(() => {
-        this.cache.remove({id: artifactId} as ArtifactWithTags);
-      })
-    );
+  public deleteArtifact(artifact: ArtifactWithTags): Observable<void> {
+    return this.http
+      .delete<void>(`${this.artifactsUrl}/${artifact.id}`, {params: {confirm: artifact.name}})
+      .pipe(
+        tap(() => {
+          this.cache.remove({id: artifact.id} as ArtifactWithTags);
+        })
+      );
   }
 
   public deleteArtifactTag(artifact: ArtifactWithTags, tagName: string) {
@@ -30,6 +30,8 @@ export interface LoginConfig {
   oidcGithubEnabled?: boolean;
   oidcGoogleEnabled?: boolean;
   oidcMicrosoftEnabled?: boolean;
+  oidcOktaEnabled?: boolean;
+  oidcGitlabEnabled?: boolean;
   oidcGenericEnabled?: boolean;
 }
 
@@ -81,7 +83,11 @@ export class AuthService {
     return this.getClaims()?.is_super_admin === true;
   }
 
-  public login(email: string, password: string, mfaCode?: string): Observable<{requiresMfa: boolean}> {
+  public login(
+    email: string,
+    password: string,
+    mfaCode?: string
+  ): Observable<{requiresMfa: boolean; recoveryCodesLow: boolean}> {
     return this.httpClient.post<LoginResponse>(`${authBaseUrl}/login`, {email, password, mfaCode}).pipe(
       tap((r) => {
         if (!r.requiresMfa) {
@@ -89,7 +95,7 @@ export class AuthService {
           this.actionToken = null;
         }
       }),
-      map((r) => ({requiresMfa: r.requiresMfa}))
+      map((r) => ({requiresMfa: r.requiresMfa, recoveryCodesLow: !r.requiresMfa && !!r.recoveryCodesLow}))
     );
   }
 
@@ -1,10 +1,18 @@
 import {DeploymentTarget, UserAccount} from '@distr-sh/distr-sdk';
 
+export type AlertTransition = 'error' | 'error_recovered' | 'stale' | 'stale_recovered';
+
 export interface CreateUpdateAlertConfigurationRequest {
   name: string;
   enabled: boolean;
   deploymentTargetIds?: string[];
   userAccountIds?: string[];
+  transitions?: AlertTransition[];
+  quietHoursStart?: string;
+  quietHoursEnd?: string;
+  quietHoursTimezone?: string;
+  quietHoursAllowErrors?: boolean;
+  digest?: boolean;
 }
 
 export interface AlertConfiguration {
@@ -14,6 +22,12 @@ export interface AlertConfiguration {
   enabled: boolean;
   deploymentTargetIds?: string[];
   userAccountIds?: string[];
+  transitions?: AlertTransition[];
+  quietHoursStart?: string;
+  quietHoursEnd?: string;
+  quietHoursTimezone?: string;
+  quietHoursAllowErrors?: boolean;
+  digest?: boolean;
   userAccounts?: UserAccount[];
   deploymentTargets?: DeploymentTarget[];
 }
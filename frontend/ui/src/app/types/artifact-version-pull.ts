@@ -1,6 +1,7 @@
 import {BaseArtifact, BaseArtifactVersion} from '../services/artifacts.service';
 
 export interface ArtifactVersionPull {
+  id: string;
   createdAt: string;
   remoteAddress?: string;
   userAccountName?: string;
@@ -9,5 +9,6 @@ export interface NotificationRecord {
   applicationName?: string;
   applicationVersionName?: string;
   message: string;
+  deliveredAt?: string;
   currentDeploymentRevisionStatus?: DeploymentRevisionStatus;
 }
@@ -16,6 +16,9 @@ export interface CreateUpdateOrganizationRequest {
   postConnectScript?: string;
   connectScriptIsSudo: boolean;
   artifactVersionMutable: boolean;
+  quotaExceededWebhookUrl?: string;
+  quotaExceededEmailEnabled: boolean;
+  requireArtifactPrecreation: boolean;
 }
 
 export interface Organization extends BaseModel, Named {
@@ -33,6 +36,9 @@ export interface Organization extends BaseModel, Named {
   preConnectScript?: string;
   postConnectScript?: string;
   connectScriptIsSudo: boolean;
+  quotaExceededWebhookUrl?: string;
+  quotaExceededEmailEnabled: boolean;
+  requireArtifactPrecreation: boolean;
 }
 
 export interface OrganizationWithUserRole extends Organization {
@@ -41,3 +47,26 @@ export interface OrganizationWithUserRole extends Organization {
   customerOrganizationName?: string;
   joinedOrgAt: string;
 }
+
+export interface UpdateOrganizationDomainsRequest {
+  appDomain?: string;
+  registryDomain?: string;
+}
+
+export interface OrganizationDomainStatus {
+  domain: string;
+  verificationRecordName: string;
+  cnameTarget: string;
+  verified: boolean;
+  verifiedAt?: string;
+  dnsConfigured?: boolean;
+  tlsReady?: boolean;
+  wellKnownReady?: boolean;
+  errors?: string[];
+}
+
+export interface OrganizationDomains {
+  verificationToken?: string;
+  appDomain?: OrganizationDomainStatus;
+  registryDomain?: OrganizationDomainStatus;
+}
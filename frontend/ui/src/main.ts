@@ -9,6 +9,7 @@ import {appConfig} from './app/app.config';
 import {buildConfig} from './buildconfig';
 import {environment} from './env/env';
 import {getRemoteEnvironment} from './env/remote';
+import {setStaleSeconds} from './util/model';
 
 dayjs.extend(duration);
 dayjs.extend(relativeTime);
@@ -18,6 +19,10 @@ bootstrapApplication(AppComponent, appConfig).catch((err) => console.error(err))
 (async () => {
   const remoteEnvironment = await getRemoteEnvironment();
 
+  if (remoteEnvironment.deploymentStatusStaleSeconds) {
+    setStaleSeconds(remoteEnvironment.deploymentStatusStaleSeconds);
+  }
+
   if (remoteEnvironment.sentryDsn) {
     Sentry.init({
       enabled: environment.production,
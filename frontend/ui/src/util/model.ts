@@ -4,7 +4,17 @@ import dayjs from 'dayjs';
 import {Duration} from 'dayjs/plugin/duration';
 import {isOlderThan} from './dates';
 
-export function isStale(model: BaseModel, duration: Duration = dayjs.duration({seconds: 60})): boolean {
+/**
+ * The age after which a status is considered stale. The actual value depends on the agent interval and is provided by
+ * the hub with the remote environment.
+ */
+let staleSeconds = 60;
+
+export function setStaleSeconds(seconds: number) {
+  staleSeconds = seconds;
+}
+
+export function isStale(model: BaseModel, duration: Duration = dayjs.duration({seconds: staleSeconds})): boolean {
   return isOlderThan(model.createdAt, duration);
 }
 
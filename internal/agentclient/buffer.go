@@ -0,0 +1,134 @@
+package agentclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// submission is a status or a batch of deployment logs that could not be sent to the hub.
+type submission struct {
+	// ID identifies the submission in the buffer. It is assigned by Push.
+	ID             uuid.UUID                  `json:"id"`
+	Status         *api.AgentDeploymentStatus `json:"status,omitempty"`
+	DeploymentLogs []api.DeploymentLogRecord  `json:"deploymentLogs,omitempty"`
+}
+
+// submissionBuffer keeps failed submissions in order until they can be replayed. If path is not empty, the buffer is
+// persisted to that file after every change, so that submissions survive a restart of the agent. Once maxSize
+// submissions are buffered, the oldest one is dropped for every new one.
+type submissionBuffer struct {
+	maxSize int
+	path    string
+	logger  *zap.Logger
+	// replayMutex ensures that submissions are not replayed concurrently, which could send them twice
+	replayMutex sync.Mutex
+	mutex       sync.Mutex
+	entries     []submission
+}
+
+func newSubmissionBuffer(maxSize int, path string, logger *zap.Logger) (*submissionBuffer, error) {
+	b := &submissionBuffer{maxSize: maxSize, path: path, logger: logger}
+	if path != "" {
+		if data, err := os.ReadFile(path); errors.Is(err, os.ErrNotExist) {
+			// nothing was buffered yet
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read submission buffer: %w", err)
+		} else if err := json.Unmarshal(data, &b.entries); err != nil {
+			logger.Warn("discarding invalid submission buffer", zap.String("path", path), zap.Error(err))
+			b.entries = nil
+		}
+		if dropped := len(b.entries) - maxSize; dropped > 0 {
+			b.entries = b.entries[dropped:]
+		}
+	}
+	return b, nil
+}
+
+func (b *submissionBuffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.entries)
+}
+
+func (b *submissionBuffer) Push(s submission) {
+	if s.Status == nil && len(s.DeploymentLogs) == 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s.ID = uuid.New()
+	b.entries = append(b.entries, s)
+	if dropped := len(b.entries) - b.maxSize; dropped > 0 {
+		b.logger.Warn("submission buffer is full, dropping oldest submissions", zap.Int("dropped", dropped))
+		b.entries = b.entries[dropped:]
+	}
+	b.persist()
+}
+
+// Replay calls send for every buffered submission, oldest first, and removes it from the buffer once send returns.
+// If send returns an error that should be retried later, Replay stops and returns that error.
+func (b *submissionBuffer) Replay(send func(submission) (retry bool, err error)) error {
+	b.replayMutex.Lock()
+	defer b.replayMutex.Unlock()
+
+	for {
+		b.mutex.Lock()
+		if len(b.entries) == 0 {
+			b.mutex.Unlock()
+			return nil
+		}
+		s := b.entries[0]
+		b.mutex.Unlock()
+
+		if retry, err := send(s); retry {
+			return err
+		} else if err != nil {
+			b.logger.Warn("dropping buffered submission that was rejected by the hub", zap.Error(err))
+		}
+
+		b.mutex.Lock()
+		// Push may have dropped the oldest entries in the meantime
+		if len(b.entries) > 0 && b.entries[0].ID == s.ID {
+			b.entries = b.entries[1:]
+			b.persist()
+		}
+		b.mutex.Unlock()
+	}
+}
+
+// persist writes the buffer to its file. The caller must hold b.mutex.
+func (b *submissionBuffer) persist() {
+	if b.path == "" {
+		return
+	}
+	if err := writeFileAtomic(b.path, b.entries); err != nil {
+		b.logger.Warn("could not persist submission buffer", zap.String("path", b.path), zap.Error(err))
+	}
+}
+
+func writeFileAtomic(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	} else if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,212 @@
+package agentclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+func statusSubmission(message string) submission {
+	return submission{Status: &api.AgentDeploymentStatus{
+		RevisionID: uuid.New(),
+		Type:       types.DeploymentStatusTypeHealthy,
+		Message:    message,
+	}}
+}
+
+// replayMessages replays the buffer and returns the messages of all submissions that were sent.
+func replayMessages(g *WithT, b *submissionBuffer) []string {
+	var messages []string
+	g.Expect(b.Replay(func(s submission) (bool, error) {
+		messages = append(messages, s.Status.Message)
+		return false, nil
+	})).To(Succeed())
+	return messages
+}
+
+func TestSubmissionBufferPush(t *testing.T) {
+	g := NewWithT(t)
+	b, err := newSubmissionBuffer(2, "", zap.NewNop())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	b.Push(submission{})
+	b.Push(submission{DeploymentLogs: []api.DeploymentLogRecord{}})
+	g.Expect(b.Len()).To(Equal(0))
+
+	b.Push(statusSubmission("1"))
+	b.Push(statusSubmission("2"))
+	b.Push(statusSubmission("3"))
+	g.Expect(b.Len()).To(Equal(2))
+	g.Expect(replayMessages(g, b)).To(Equal([]string{"2", "3"}))
+	g.Expect(b.Len()).To(Equal(0))
+}
+
+func TestSubmissionBufferReplay(t *testing.T) {
+	errUnavailable := errors.New("hub unavailable")
+
+	t.Run("stops at retryable errors", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(10, "", zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		b.Push(statusSubmission("1"))
+		b.Push(statusSubmission("2"))
+
+		var sent []string
+		err = b.Replay(func(s submission) (bool, error) {
+			sent = append(sent, s.Status.Message)
+			return true, errUnavailable
+		})
+		g.Expect(err).To(MatchError(errUnavailable))
+		g.Expect(sent).To(Equal([]string{"1"}))
+		g.Expect(b.Len()).To(Equal(2))
+		g.Expect(replayMessages(g, b)).To(Equal([]string{"1", "2"}))
+	})
+
+	t.Run("drops rejected submissions", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(10, "", zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		b.Push(statusSubmission("1"))
+		b.Push(statusSubmission("2"))
+
+		var sent []string
+		g.Expect(b.Replay(func(s submission) (bool, error) {
+			sent = append(sent, s.Status.Message)
+			return false, errors.New("bad request")
+		})).To(Succeed())
+		g.Expect(sent).To(Equal([]string{"1", "2"}))
+		g.Expect(b.Len()).To(Equal(0))
+	})
+
+	t.Run("push during replay", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(10, "", zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		b.Push(statusSubmission("1"))
+
+		var sent []string
+		g.Expect(b.Replay(func(s submission) (bool, error) {
+			sent = append(sent, s.Status.Message)
+			if s.Status.Message == "1" {
+				b.Push(statusSubmission("2"))
+			}
+			return false, nil
+		})).To(Succeed())
+		g.Expect(sent).To(Equal([]string{"1", "2"}))
+		g.Expect(b.Len()).To(Equal(0))
+	})
+
+	t.Run("push during replay drops the submission being sent", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(1, "", zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		b.Push(statusSubmission("1"))
+
+		var sent []string
+		g.Expect(b.Replay(func(s submission) (bool, error) {
+			sent = append(sent, s.Status.Message)
+			if s.Status.Message == "1" {
+				b.Push(statusSubmission("2"))
+			}
+			return false, nil
+		})).To(Succeed())
+		// the new submission must not be removed when the dropped one has been sent
+		g.Expect(sent).To(Equal([]string{"1", "2"}))
+		g.Expect(b.Len()).To(Equal(0))
+	})
+
+	t.Run("concurrent pushes", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(1000, "", zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				for range 10 {
+					b.Push(statusSubmission("status"))
+				}
+			})
+		}
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		var sent int
+		for running := true; running; {
+			select {
+			case <-done:
+				running = false
+			default:
+			}
+			// the last replay starts after all pushes have finished
+			g.Expect(b.Replay(func(s submission) (bool, error) {
+				sent++
+				return false, nil
+			})).To(Succeed())
+		}
+		g.Expect(sent).To(Equal(100))
+		g.Expect(b.Len()).To(Equal(0))
+	})
+}
+
+func TestSubmissionBufferPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buffer.json")
+
+	t.Run("survives a restart", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(10, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(b.Len()).To(Equal(0))
+		b.Push(statusSubmission("1"))
+		b.Push(submission{DeploymentLogs: []api.DeploymentLogRecord{{Body: "log"}}})
+		b.Push(statusSubmission("3"))
+
+		restored, err := newSubmissionBuffer(10, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restored.entries).To(Equal(b.entries))
+	})
+
+	t.Run("keeps the newest submissions if the size was reduced", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(2, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(b.Len()).To(Equal(2))
+		g.Expect(b.entries[0].DeploymentLogs).To(HaveLen(1))
+		g.Expect(b.entries[1].Status.Message).To(Equal("3"))
+	})
+
+	t.Run("removes replayed submissions", func(t *testing.T) {
+		g := NewWithT(t)
+		b, err := newSubmissionBuffer(10, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(b.Replay(func(s submission) (bool, error) { return false, nil })).To(Succeed())
+
+		restored, err := newSubmissionBuffer(10, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(restored.Len()).To(Equal(0))
+	})
+
+	t.Run("discards an invalid file", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(os.WriteFile(path, []byte("not json"), 0o600)).To(Succeed())
+		b, err := newSubmissionBuffer(10, path, zap.NewNop())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(b.Len()).To(Equal(0))
+	})
+
+	t.Run("fails if the file can not be read", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := newSubmissionBuffer(10, t.TempDir(), zap.NewNop())
+		g.Expect(err).To(HaveOccurred())
+	})
+}
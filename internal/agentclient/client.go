@@ -2,28 +2,41 @@ package agentclient
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/agentclient/useragent"
+	"github.com/distr-sh/distr/internal/agentenv"
 	"github.com/distr-sh/distr/internal/buildconfig"
 	"github.com/distr-sh/distr/internal/deploymentlogs"
 	"github.com/distr-sh/distr/internal/deploymenttargetlogs"
 	"github.com/distr-sh/distr/internal/httpstatus"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
+const (
+	// logsCompressionThreshold is the size in bytes above which exported log records are compressed.
+	logsCompressionThreshold = 16 * 1024
+
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
 type clientData struct {
 	authTarget                   string
 	authSecret                   string
@@ -32,17 +45,25 @@ type clientData struct {
 	resourceEndpoint             string
 	statusEndpoint               string
 	metricsEndpoint              string
+	heartbeatEndpoint            string
 	deploymentLogsEndpoint       string
 	deploymentTargetLogsEndpoint string
+	tls                          tlsData
 }
 
 type Client struct {
 	clientData
 	httpClient *http.Client
-	logger     *zap.Logger
-	token      jwt.Token
-	rawToken   string
-	mutex      sync.Mutex
+	// logsHTTPClient is used for log exports, which can take considerably longer than other requests
+	logsHTTPClient *http.Client
+	logger         *zap.Logger
+	// buffer holds statuses and deployment logs that could not be submitted
+	buffer   *submissionBuffer
+	token    jwt.Token
+	rawToken string
+	mutex    sync.Mutex
+	// versionWarningOnce makes sure that a warning about an outdated agent version is only logged once
+	versionWarningOnce sync.Once
 }
 
 func (c *Client) Resource(ctx context.Context) (*api.AgentResource, error) {
@@ -51,7 +72,7 @@ func (c *Client) Resource(ctx context.Context) (*api.AgentResource, error) {
 		return nil, err
 	} else {
 		req.Header.Set("Content-Type", "application/json")
-		if resp, err := c.doAuthenticated(ctx, req, true); err != nil {
+		if resp, err := c.doAuthenticated(ctx, c.httpClient, req, true); err != nil {
 			return nil, err
 		} else if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 			return nil, err
@@ -61,81 +82,250 @@ func (c *Client) Resource(ctx context.Context) (*api.AgentResource, error) {
 	}
 }
 
+// Manifest downloads the rendered agent manifest. The manifest is requested gzip compressed, but it is also accepted
+// uncompressed in case the hub does not support compression.
 func (c *Client) Manifest(ctx context.Context) ([]byte, error) {
-	if req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.manifestEndpoint, nil); err != nil {
-		return nil, err
-	} else if resp, err := c.doAuthenticated(ctx, req, true); err != nil {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.manifestEndpoint, nil)
+	if err != nil {
 		return nil, err
-	} else if data, err := io.ReadAll(resp.Body); err != nil {
+	}
+	// Setting the header explicitly disables the transparent decompression of the transport, so that the response
+	// can be decompressed below regardless of the transport configuration.
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := c.doAuthenticated(ctx, c.httpClient, req, true)
+	if err != nil {
 		return nil, err
-	} else {
-		return data, nil
 	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip manifest: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	return io.ReadAll(body)
 }
 
 func (c *Client) StatusWithError(ctx context.Context, revisionID uuid.UUID, err error) error {
 	return c.Status(ctx, revisionID, types.DeploymentStatusTypeError, err.Error())
 }
 
+// Status sends the status of the given revision to the hub. If the hub is unreachable, the status is buffered and
+// replayed together with later submissions, so that no status is lost during transient outages.
 func (c *Client) Status(
 	ctx context.Context,
 	revisionID uuid.UUID,
 	statusType types.DeploymentStatusType,
 	message string,
 ) error {
-	deploymentStatus := api.AgentDeploymentStatus{
-		RevisionID: revisionID,
-		Message:    message,
-		Type:       statusType,
+	status := NewStatus(revisionID, statusType, message)
+	return c.submit(ctx, submission{Status: &status})
+}
+
+// NewStatus returns a status of the given revision that was observed now, e.g. to send it later with StatusBatch.
+func NewStatus(revisionID uuid.UUID, statusType types.DeploymentStatusType, message string) api.AgentDeploymentStatus {
+	return api.AgentDeploymentStatus{
+		RevisionID:     revisionID,
+		Message:        message,
+		Type:           statusType,
+		IdempotencyKey: util.PtrTo(uuid.New()),
+		ObservedAt:     util.PtrTo(time.Now()),
+	}
+}
+
+// StatusBatch sends multiple statuses with as few requests as possible. Statuses without an idempotency key or
+// observation time are assigned one.
+// Statuses that are rejected by the hub are listed in the response. If the hub is unreachable, all statuses are
+// buffered like in Status.
+func (c *Client) StatusBatch(
+	ctx context.Context,
+	statuses []api.AgentDeploymentStatus,
+) (*api.AgentDeploymentStatusBatchResponse, error) {
+	for i := range statuses {
+		if statuses[i].IdempotencyKey == nil {
+			statuses[i].IdempotencyKey = util.PtrTo(uuid.New())
+		}
+		if statuses[i].ObservedAt == nil {
+			statuses[i].ObservedAt = util.PtrTo(time.Now())
+		}
+	}
+
+	result := api.AgentDeploymentStatusBatchResponse{Failed: []api.AgentDeploymentStatusBatchFailure{}}
+	var errs error
+	for chunk := range slices.Chunk(statuses, api.MaxAgentDeploymentStatusBatchSize) {
+		if resp, err := c.statusBatch(ctx, chunk); err != nil {
+			errs = multierr.Append(errs, err)
+		} else {
+			result.Failed = append(result.Failed, resp.Failed...)
+		}
 	}
+	return &result, errs
+}
+
+func (c *Client) statusBatch(
+	ctx context.Context,
+	statuses []api.AgentDeploymentStatus,
+) (*api.AgentDeploymentStatusBatchResponse, error) {
+	bufferAll := func(err error) error {
+		for _, status := range statuses {
+			c.buffer.Push(submission{Status: &status})
+		}
+		return fmt.Errorf("submissions buffered for replay: %w", err)
+	}
+
+	if c.buffer.Len() > 0 {
+		if err := c.ReplayBuffered(ctx); err != nil {
+			return nil, bufferAll(err)
+		}
+	}
+
 	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(deploymentStatus); err != nil {
-		return err
-	} else if req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.statusEndpoint, &buf); err != nil {
-		return err
+	var result api.AgentDeploymentStatusBatchResponse
+	if err := json.NewEncoder(&buf).Encode(statuses); err != nil {
+		return nil, err
+	} else if req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.statusEndpoint+"/batch", &buf,
+	); err != nil {
+		return nil, err
 	} else {
 		req.Header.Set("Content-Type", "application/json")
-		if _, err := c.doAuthenticated(ctx, req, true); err != nil {
-			return err
+		if resp, err := c.doAuthenticated(ctx, c.httpClient, req, true); err != nil {
+			if isRetryable(resp, err) {
+				return nil, bufferAll(err)
+			}
+			return nil, err
+		} else if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, err
 		} else {
-			return nil
+			return &result, nil
 		}
 	}
 }
 
+// ExportDeploymentLogs sends the given records to the hub. Like statuses, they are buffered if the hub is unreachable.
 func (c *Client) ExportDeploymentLogs(ctx context.Context, records []api.DeploymentLogRecord) error {
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(records); err != nil {
-		return err
-	} else if req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.deploymentLogsEndpoint, &buf); err != nil {
+	return c.submit(ctx, submission{DeploymentLogs: records})
+}
+
+// ReplayBuffered sends all buffered submissions to the hub. Submissions that are rejected by the hub are dropped.
+func (c *Client) ReplayBuffered(ctx context.Context) error {
+	return c.buffer.Replay(func(s submission) (bool, error) {
+		resp, err := c.send(ctx, s)
+		return isRetryable(resp, err), err
+	})
+}
+
+// submit sends the submission after all buffered submissions, so that the hub receives statuses in order. If that is
+// not possible because of a transient error, the submission is buffered as well.
+func (c *Client) submit(ctx context.Context, s submission) error {
+	if c.buffer.Len() > 0 {
+		if err := c.ReplayBuffered(ctx); err != nil {
+			c.buffer.Push(s)
+			return fmt.Errorf("submission buffered for replay: %w", err)
+		}
+	}
+	if resp, err := c.send(ctx, s); err != nil {
+		if isRetryable(resp, err) {
+			c.buffer.Push(s)
+			return fmt.Errorf("submission buffered for replay: %w", err)
+		}
 		return err
+	}
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, s submission) (*http.Response, error) {
+	if s.Status != nil {
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(s.Status); err != nil {
+			return nil, err
+		} else if req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.statusEndpoint, &buf); err != nil {
+			return nil, err
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+			return c.doAuthenticated(ctx, c.httpClient, req, true)
+		}
+	} else if req, err := newLogsRequest(ctx, c.deploymentLogsEndpoint, s.DeploymentLogs); err != nil {
+		return nil, err
 	} else {
-		req.Header.Set("Content-Type", "application/json")
-		_, err := c.doAuthenticated(ctx, req, true)
-		return err
+		return c.doAuthenticated(ctx, c.logsHTTPClient, req, true)
+	}
+}
+
+// isRetryable reports whether a request failed because of a transient problem, i.e. if the hub could not be reached
+// or responded with a server error.
+func isRetryable(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	} else if resp == nil {
+		return true
+	} else {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
 	}
 }
 
 func (c *Client) ExportDeploymentTargetLogs(records ...api.DeploymentTargetLogRecord) error {
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(records); err != nil {
-		return err
-	} else if req, err := http.NewRequest(http.MethodPut, c.deploymentTargetLogsEndpoint, &buf); err != nil {
+	if req, err := newLogsRequest(context.TODO(), c.deploymentTargetLogsEndpoint, records); err != nil {
 		return err
 	} else {
-		req.Header.Set("Content-Type", "application/json")
-		_, err := c.doAuthenticated(context.TODO(), req, false)
+		_, err := c.doAuthenticated(context.TODO(), c.logsHTTPClient, req, false)
 		return err
 	}
 }
 
+// BufferedDeploymentTargetLogs returns an exporter that batches deployment target log records before exporting them
+// with this client. Batches are sent once DISTR_AGENT_LOGS_BATCH_SIZE records are buffered or the oldest record is
+// older than DISTR_AGENT_LOGS_MAX_AGE. Callers must call Stop on shutdown to flush the remaining records.
+func (c *Client) BufferedDeploymentTargetLogs() *deploymenttargetlogs.BufferedCollector {
+	return &deploymenttargetlogs.BufferedCollector{
+		Size:          agentenv.AgentLogsBatchSize,
+		FlushInterval: agentenv.AgentLogsMaxAge,
+		Delegate:      c,
+	}
+}
+
+// newLogsRequest creates a PUT request with the given records encoded as JSON. Bodies larger than
+// logsCompressionThreshold are gzip compressed.
+func newLogsRequest(ctx context.Context, url string, records any) (*http.Request, error) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := len(data) > logsCompressionThreshold
+	if compressed {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return nil, err
+		} else if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req, nil
+}
+
 func (c *Client) Login(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.loginEndpoint, nil)
 	if err != nil {
 		return err
 	}
 	req.SetBasicAuth(c.authTarget, c.authSecret)
-	if resp, err := c.do(req); err != nil {
+	if resp, err := c.do(c.httpClient, req); err != nil {
 		return err
 	} else {
 		var loginResponse api.AuthLoginResponse
@@ -197,7 +387,7 @@ func (c *Client) ReportMetrics(ctx context.Context, metrics api.AgentDeploymentT
 		return err
 	} else {
 		req.Header.Set("Content-Type", "application/json")
-		if _, err := c.doAuthenticated(ctx, req, true); err != nil {
+		if _, err := c.doAuthenticated(ctx, c.httpClient, req, true); err != nil {
 			return err
 		} else {
 			return nil
@@ -205,15 +395,38 @@ func (c *Client) ReportMetrics(ctx context.Context, metrics api.AgentDeploymentT
 	}
 }
 
-func (c *Client) doAuthenticated(ctx context.Context, r *http.Request, loggingEnabled bool) (*http.Response, error) {
-	if resp, err := c.doAuthenticatedNoRetry(ctx, r, loggingEnabled); resp == nil || resp.StatusCode != 401 {
+// Heartbeat tells the hub that the agent is alive, independent of any deployments.
+func (c *Client) Heartbeat(ctx context.Context) error {
+	if req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.heartbeatEndpoint, nil); err != nil {
+		return err
+	} else {
+		if _, err := c.doAuthenticated(ctx, c.httpClient, req, false); err != nil {
+			return err
+		}
+		// the hub is reachable again, so this is a good time to replay failed submissions
+		if c.buffer.Len() > 0 {
+			if err := c.ReplayBuffered(ctx); err != nil {
+				c.logger.Warn("failed to replay buffered submissions", zap.Error(err))
+			}
+		}
+		return nil
+	}
+}
+
+func (c *Client) doAuthenticated(
+	ctx context.Context,
+	client *http.Client,
+	r *http.Request,
+	loggingEnabled bool,
+) (*http.Response, error) {
+	if resp, err := c.doAuthenticatedNoRetry(ctx, client, r, loggingEnabled); resp == nil || resp.StatusCode != 401 {
 		return resp, err
 	} else {
 		if loggingEnabled {
 			c.logger.Warn("got 401 response, try to regenerate token")
 		}
 		c.ClearToken()
-		resp, err1 := c.doAuthenticatedNoRetry(ctx, r, loggingEnabled)
+		resp, err1 := c.doAuthenticatedNoRetry(ctx, client, r, loggingEnabled)
 		if err1 != nil {
 			return resp, multierr.Append(err, err1)
 		} else {
@@ -224,6 +437,7 @@ func (c *Client) doAuthenticated(ctx context.Context, r *http.Request, loggingEn
 
 func (c *Client) doAuthenticatedNoRetry(
 	ctx context.Context,
+	client *http.Client,
 	r *http.Request,
 	loggingEnabled bool,
 ) (*http.Response, error) {
@@ -231,13 +445,19 @@ func (c *Client) doAuthenticatedNoRetry(
 		return nil, err
 	} else {
 		r.Header.Set("Authorization", "Bearer "+c.rawToken)
-		return c.do(r)
+		return c.do(client, r)
 	}
 }
 
-func (c *Client) do(r *http.Request) (*http.Response, error) {
+func (c *Client) do(client *http.Client, r *http.Request) (*http.Response, error) {
 	r.Header.Set("User-Agent", fmt.Sprintf("%v/%v", useragent.DistrAgentUserAgent, buildconfig.Version()))
-	return httpstatus.CheckStatus(c.httpClient.Do(r))
+	resp, err := client.Do(r)
+	if resp != nil {
+		if warning := resp.Header.Get(api.AgentVersionWarningHeader); warning != "" {
+			c.versionWarningOnce.Do(func() { c.logger.Warn(warning) })
+		}
+	}
+	return httpstatus.CheckStatus(resp, err)
 }
 
 func (c *Client) ReloadFromEnv() (changed bool, err error) {
@@ -260,9 +480,24 @@ func (c *Client) ReloadFromEnv() (changed bool, err error) {
 		return changed, err
 	} else if d.deploymentTargetLogsEndpoint, err = readEnvVar("DISTR_AGENT_LOGS_ENDPOINT"); err != nil {
 		return changed, err
+	} else if d.tls, err = readTLSDataFromEnv(); err != nil {
+		return changed, err
 	} else {
+		// agents deployed before the heartbeat endpoint was added do not have DISTR_HEARTBEAT_ENDPOINT set
+		d.heartbeatEndpoint = readEnvVarOrDefault(
+			"DISTR_HEARTBEAT_ENDPOINT",
+			strings.TrimSuffix(d.statusEndpoint, "/status")+"/heartbeat",
+		)
 		changed = c.clientData != d
 		if changed {
+			if c.httpClient == nil || c.tls != d.tls {
+				if transport, err := newHTTPTransport(agentenv.HTTPConnectTimeout, d.tls); err != nil {
+					return false, err
+				} else {
+					c.httpClient = &http.Client{Timeout: agentenv.HTTPTimeout, Transport: transport}
+					c.logsHTTPClient = &http.Client{Timeout: agentenv.HTTPLogsTimeout, Transport: transport}
+				}
+			}
 			c.clientData = d
 			c.ClearToken()
 		}
@@ -271,16 +506,35 @@ func (c *Client) ReloadFromEnv() (changed bool, err error) {
 }
 
 func NewFromEnv(logger *zap.Logger) (*Client, error) {
-	client := Client{
-		httpClient: &http.Client{},
-		logger:     logger,
-	}
+	client := Client{logger: logger}
 	if _, err := client.ReloadFromEnv(); err != nil {
 		return nil, err
+	} else if client.buffer, err = newSubmissionBuffer(
+		agentenv.SubmissionBufferSize,
+		util.PtrDerefOrZero(agentenv.SubmissionBufferFile),
+		logger,
+	); err != nil {
+		return nil, err
 	}
 	return &client, nil
 }
 
+// newHTTPTransport returns a transport that aborts connection attempts taking longer than the given timeout. The
+// overall duration of a request is limited by the timeout of the client using it, so that an unresponsive hub can not
+// block the agent indefinitely. If certificates are configured, they are used for mutual TLS. If server certificate
+// fingerprints are configured, the hub's certificate is pinned to them.
+func newHTTPTransport(connectTimeout time.Duration, tlsData tlsData) (*http.Transport, error) {
+	tlsConfig, err := tlsData.config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = min(tlsHandshakeTimeout, connectTimeout)
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
 func readEnvVar(key string) (string, error) {
 	if value, ok := os.LookupEnv(key); ok {
 		return value, nil
@@ -289,6 +543,14 @@ func readEnvVar(key string) (string, error) {
 	}
 }
 
+func readEnvVarOrDefault(key string, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	} else {
+		return defaultValue
+	}
+}
+
 var (
 	_ deploymenttargetlogs.Exporter = (*Client)(nil)
 	_ deploymentlogs.Exporter       = (*Client)(nil)
@@ -0,0 +1,117 @@
+package agentclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsData holds the PEM encoded certificates used for mutual TLS with the hub. The contents are stored instead of
+// the file names, so that rotated certificates are detected by [Client.ReloadFromEnv].
+//
+// serverCertFingerprints is a comma separated list of SHA-256 fingerprints. If it is set, the hub must present a
+// certificate chain that contains a certificate with one of these fingerprints, in addition to the regular
+// verification against the system trust store or caCert.
+type tlsData struct {
+	clientCert             string
+	clientKey              string
+	caCert                 string
+	serverCertFingerprints string
+}
+
+func readTLSDataFromEnv() (d tlsData, err error) {
+	if d.clientCert, err = readFileFromEnvVar("DISTR_CLIENT_CERT_FILE"); err != nil {
+		return d, err
+	} else if d.clientKey, err = readFileFromEnvVar("DISTR_CLIENT_KEY_FILE"); err != nil {
+		return d, err
+	} else if d.caCert, err = readFileFromEnvVar("DISTR_CA_FILE"); err != nil {
+		return d, err
+	} else {
+		d.serverCertFingerprints = os.Getenv("DISTR_SERVER_CERT_FINGERPRINT")
+		return d, nil
+	}
+}
+
+// config returns the TLS configuration for the agent's HTTP client or nil, if no certificates are configured.
+func (d tlsData) config() (*tls.Config, error) {
+	if d == (tlsData{}) {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if d.clientCert != "" || d.clientKey != "" {
+		if cert, err := tls.X509KeyPair([]byte(d.clientCert), []byte(d.clientKey)); err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		} else {
+			config.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	if d.caCert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(d.caCert)) {
+			return nil, errors.New("invalid CA file: no certificates found")
+		}
+		config.RootCAs = pool
+	}
+
+	if d.serverCertFingerprints != "" {
+		fingerprints, err := parseCertFingerprints(d.serverCertFingerprints)
+		if err != nil {
+			return nil, err
+		}
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				sum := sha256.Sum256(cert.Raw)
+				for _, fingerprint := range fingerprints {
+					if bytes.Equal(sum[:], fingerprint) {
+						return nil
+					}
+				}
+			}
+			return errors.New("server certificate does not match DISTR_SERVER_CERT_FINGERPRINT")
+		}
+	}
+
+	return config, nil
+}
+
+// parseCertFingerprints parses a comma separated list of hex encoded SHA-256 fingerprints. The bytes of a fingerprint
+// may be separated by colons, as in the output of "openssl x509 -fingerprint -sha256".
+func parseCertFingerprints(value string) ([][]byte, error) {
+	var result [][]byte
+	for item := range strings.SplitSeq(value, ",") {
+		item = strings.ReplaceAll(strings.TrimSpace(item), ":", "")
+		if item == "" {
+			continue
+		} else if fingerprint, err := hex.DecodeString(item); err != nil || len(fingerprint) != sha256.Size {
+			return nil, fmt.Errorf("invalid server certificate fingerprint %q: expected a hex encoded SHA-256 hash", item)
+		} else {
+			result = append(result, fingerprint)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("invalid server certificate fingerprint: no fingerprints found")
+	}
+	return result, nil
+}
+
+func readFileFromEnvVar(key string) (string, error) {
+	if path, ok := os.LookupEnv(key); !ok || path == "" {
+		return "", nil
+	} else if data, err := os.ReadFile(path); err != nil {
+		return "", fmt.Errorf("failed to read file from %v: %w", key, err)
+	} else {
+		return string(data), nil
+	}
+}
@@ -0,0 +1,80 @@
+package agentclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseCertFingerprints(t *testing.T) {
+	a := sha256.Sum256([]byte("a"))
+	b := sha256.Sum256([]byte("b"))
+	hexA := hex.EncodeToString(a[:])
+	hexB := hex.EncodeToString(b[:])
+	// colonSeparated formats a fingerprint like "openssl x509 -fingerprint -sha256" does
+	colonSeparated := func(fingerprint []byte) string {
+		parts := make([]string, len(fingerprint))
+		for i, c := range fingerprint {
+			parts[i] = strings.ToUpper(hex.EncodeToString([]byte{c}))
+		}
+		return strings.Join(parts, ":")
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected [][]byte
+		err      string
+	}{
+		{"single", hexA, [][]byte{a[:]}, ""},
+		{"upper case", strings.ToUpper(hexA), [][]byte{a[:]}, ""},
+		{"colon separated", colonSeparated(a[:]), [][]byte{a[:]}, ""},
+		{"multiple", hexA + ", " + colonSeparated(b[:]), [][]byte{a[:], b[:]}, ""},
+		{"empty items", "," + hexA + ",,", [][]byte{a[:]}, ""},
+		{"empty", " , ", nil, "no fingerprints found"},
+		{"not hex", "not-a-fingerprint", nil, `invalid server certificate fingerprint "not-a-fingerprint"`},
+		{"too short", hexA[:32], nil, "expected a hex encoded SHA-256 hash"},
+		{"one invalid", hexB + "," + hexA + "00", nil, "expected a hex encoded SHA-256 hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			result, err := parseCertFingerprints(tt.value)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.err)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(tt.expected))
+			}
+		})
+	}
+}
+
+func TestTLSDataServerCertFingerprints(t *testing.T) {
+	g := NewWithT(t)
+	leaf := &x509.Certificate{Raw: []byte("leaf")}
+	intermediate := &x509.Certificate{Raw: []byte("intermediate")}
+	sum := sha256.Sum256(intermediate.Raw)
+
+	config, err := tlsData{serverCertFingerprints: hex.EncodeToString(sum[:])}.config()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.VerifyConnection).NotTo(BeNil())
+	g.Expect(config.VerifyConnection(tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf, intermediate},
+	})).To(Succeed())
+	g.Expect(config.VerifyConnection(tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leaf},
+	})).To(MatchError(ContainSubstring("does not match")))
+
+	_, err = tlsData{serverCertFingerprints: "invalid"}.config()
+	g.Expect(err).To(HaveOccurred())
+
+	config, err = tlsData{}.config()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config).To(BeNil())
+}
@@ -109,3 +109,64 @@ func GenerateConnectCommand(
 		return "", fmt.Errorf("unsupported deployment type: %s", deploymentTarget.Type)
 	}
 }
+
+// GenerateInstallScript returns a self-contained shell script that exports the DISTR_* connection variables and
+// applies the agent manifest for the given deployment target (including the pre- and post-connect scripts of the
+// organization).
+// The script embeds targetSecret in plain text, so it must be treated as a one-time credential.
+func GenerateInstallScript(
+	deploymentTarget types.DeploymentTarget,
+	org types.Organization,
+	targetSecret string,
+) (string, error) {
+	connectURL, err := BuildConnectURL(deploymentTarget.ID, org, targetSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to build connect URL: %w", err)
+	}
+
+	var applyCommand string
+	switch deploymentTarget.Type {
+	case types.DeploymentTypeDocker:
+		applyCommand = "curl -fsSL \"$DISTR_CONNECT_URL\" | docker compose -f - up -d"
+	case types.DeploymentTypeKubernetes:
+		if deploymentTarget.Namespace == nil {
+			return "", fmt.Errorf("kubernetes deployment target must have a namespace")
+		}
+		applyCommand = generateKubernetesConnectCommand(*deploymentTarget.Namespace, "$DISTR_CONNECT_URL")
+	default:
+		return "", fmt.Errorf("unsupported deployment type: %s", deploymentTarget.Type)
+	}
+
+	prePostScripts := deploymentTarget.Type == types.DeploymentTypeDocker && org.HasFeature(types.FeaturePrePostScripts)
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "# Distr agent install script for deployment target %q\n", deploymentTarget.Name)
+	script.WriteString("#\n")
+	script.WriteString("# WARNING: This script contains a ONE-TIME secret for this deployment target.\n")
+	script.WriteString("# Generating a new install script or connect command invalidates it.\n")
+	script.WriteString("# Do not share this script or keep it around after the agent has been installed.\n")
+	script.WriteString("set -e\n\n")
+
+	fmt.Fprintf(&script, "export DISTR_TARGET_ID='%s'\n", deploymentTarget.ID)
+	fmt.Fprintf(&script, "export DISTR_TARGET_SECRET='%s'\n", targetSecret)
+	fmt.Fprintf(&script, "export DISTR_CONNECT_URL='%s'\n\n", connectURL)
+
+	if prePostScripts && org.PreConnectScript != nil && strings.TrimSpace(*org.PreConnectScript) != "" {
+		script.WriteString("# Pre-connect script\n")
+		script.WriteString(*org.PreConnectScript)
+		script.WriteString("\n\n")
+	}
+
+	script.WriteString("# Install Distr agent\n")
+	script.WriteString(applyCommand)
+	script.WriteString("\n")
+
+	if prePostScripts && org.PostConnectScript != nil && strings.TrimSpace(*org.PostConnectScript) != "" {
+		script.WriteString("\n# Post-connect script\n")
+		script.WriteString(*org.PostConnectScript)
+		script.WriteString("\n")
+	}
+
+	return script.String(), nil
+}
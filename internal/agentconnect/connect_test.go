@@ -0,0 +1,62 @@
+package agentconnect_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/agentconnect"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+func TestGenerateInstallScript(t *testing.T) {
+	org := types.Organization{
+		AppDomain:         util.PtrTo("https://distr.example.com"),
+		Features:          []types.Feature{types.FeaturePrePostScripts},
+		PreConnectScript:  util.PtrTo("echo pre"),
+		PostConnectScript: util.PtrTo("echo post"),
+	}
+
+	t.Run("docker", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := types.DeploymentTarget{ID: uuid.New(), Name: "docker", Type: types.DeploymentTypeDocker}
+		script, err := agentconnect.GenerateInstallScript(dt, org, "secret")
+		g.Expect(err).NotTo(HaveOccurred())
+		connectURL, err := agentconnect.BuildConnectURL(dt.ID, org, "secret")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(script).To(ContainSubstring("export DISTR_TARGET_ID='" + dt.ID.String() + "'\n"))
+		g.Expect(script).To(ContainSubstring("export DISTR_TARGET_SECRET='secret'\n"))
+		g.Expect(script).To(ContainSubstring("export DISTR_CONNECT_URL='" + connectURL + "'\n"))
+		g.Expect(script).To(ContainSubstring("curl -fsSL \"$DISTR_CONNECT_URL\" | docker compose -f - up -d\n"))
+		g.Expect(script).To(ContainSubstring("echo pre\n"))
+		g.Expect(script).To(ContainSubstring("echo post\n"))
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := types.DeploymentTarget{
+			ID:        uuid.New(),
+			Name:      "kubernetes",
+			Type:      types.DeploymentTypeKubernetes,
+			Namespace: util.PtrTo("distr"),
+		}
+		script, err := agentconnect.GenerateInstallScript(dt, org, "secret")
+		g.Expect(err).NotTo(HaveOccurred())
+		connectURL, err := agentconnect.BuildConnectURL(dt.ID, org, "secret")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(script).To(ContainSubstring("export DISTR_TARGET_ID='" + dt.ID.String() + "'\n"))
+		g.Expect(script).To(ContainSubstring("export DISTR_TARGET_SECRET='secret'\n"))
+		g.Expect(script).To(ContainSubstring("export DISTR_CONNECT_URL='" + connectURL + "'\n"))
+		g.Expect(script).To(ContainSubstring("kubectl apply -n distr -f \"$DISTR_CONNECT_URL\"\n"))
+		g.Expect(script).NotTo(ContainSubstring("echo pre"))
+		g.Expect(script).NotTo(ContainSubstring("echo post"))
+	})
+
+	t.Run("kubernetes without namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := types.DeploymentTarget{ID: uuid.New(), Type: types.DeploymentTypeKubernetes}
+		_, err := agentconnect.GenerateInstallScript(dt, org, "secret")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
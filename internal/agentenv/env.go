@@ -13,4 +13,22 @@ var (
 	Interval               = envutil.GetEnvParsedOrDefault("DISTR_INTERVAL", envparse.PositiveDuration, 5*time.Second)
 	DistrRegistryHost      = envutil.GetEnv("DISTR_REGISTRY_HOST")
 	DistrRegistryPlainHTTP = envutil.GetEnvParsedOrDefault("DISTR_REGISTRY_PLAIN_HTTP", strconv.ParseBool, false)
+	HTTPTimeout            = envutil.GetEnvParsedOrDefault("DISTR_HTTP_TIMEOUT", envparse.PositiveDuration, 30*time.Second)
+	AgentLogsBatchSize     = envutil.GetEnvParsedOrDefault("DISTR_AGENT_LOGS_BATCH_SIZE", envparse.PositiveNumber, 128)
+	AgentLogsMaxAge        = envutil.GetEnvParsedOrDefault(
+		"DISTR_AGENT_LOGS_MAX_AGE", envparse.PositiveDuration, 30*time.Second,
+	)
+	HTTPConnectTimeout = envutil.GetEnvParsedOrDefault(
+		"DISTR_HTTP_CONNECT_TIMEOUT", envparse.PositiveDuration, 10*time.Second,
+	)
+	// HTTPLogsTimeout applies to log exports instead of HTTPTimeout, because large batches take longer to upload.
+	HTTPLogsTimeout = envutil.GetEnvParsedOrDefault(
+		"DISTR_HTTP_LOGS_TIMEOUT", envparse.PositiveDuration, 2*time.Minute,
+	)
+	// SubmissionBufferSize is the maximum number of failed status and log submissions kept for replay.
+	SubmissionBufferSize = envutil.GetEnvParsedOrDefault(
+		"DISTR_SUBMISSION_BUFFER_SIZE", envparse.PositiveNumber, 1000,
+	)
+	// SubmissionBufferFile is where failed submissions are persisted. If it is not set, they are only kept in memory.
+	SubmissionBufferFile = envutil.GetEnvOrNil("DISTR_SUBMISSION_BUFFER_FILE")
 )
@@ -16,6 +16,9 @@ import (
 	"github.com/distr-sh/distr/internal/types"
 )
 
+// previewPlaceholder replaces credentials in manifests rendered by Preview.
+const previewPlaceholder = "<redacted>"
+
 func Get(
 	ctx context.Context,
 	deploymentTarget types.DeploymentTargetFull,
@@ -32,6 +35,27 @@ func Get(
 	}
 }
 
+// Preview renders the same manifest as Get, but the target secret and the agent docker config are replaced with a
+// placeholder, so that the manifest can be shown to users without leaking any credentials.
+func Preview(
+	ctx context.Context,
+	deploymentTarget types.DeploymentTargetFull,
+	org types.Organization,
+) (io.Reader, error) {
+	placeholder := previewPlaceholder
+	if tmpl, err := getTemplate(deploymentTarget); err != nil {
+		return nil, err
+	} else if data, err := getTemplateData(deploymentTarget, org, &placeholder); err != nil {
+		return nil, err
+	} else {
+		if len(env.AgentDockerConfig()) > 0 {
+			data["agentDockerConfig"] = placeholder
+		}
+		var buf bytes.Buffer
+		return &buf, tmpl.Execute(&buf, data)
+	}
+}
+
 func getTemplateData(
 	deploymentTarget types.DeploymentTargetFull,
 	org types.Organization,
@@ -43,6 +67,7 @@ func getTemplateData(
 		resourcesEndpoint string
 		statusEndpoint    string
 		metricsEndpoint   string
+		heartbeatEndpoint string
 		logsEndpoint      string
 		agentLogsEndpoint string
 	)
@@ -56,6 +81,7 @@ func getTemplateData(
 		resourcesEndpoint = u.JoinPath("resources").String()
 		statusEndpoint = u.JoinPath("status").String()
 		metricsEndpoint = u.JoinPath("metrics").String()
+		heartbeatEndpoint = u.JoinPath("heartbeat").String()
 		logsEndpoint = u.JoinPath("logs").String()
 		agentLogsEndpoint = u.JoinPath("deployment-target-logs").String()
 	}
@@ -68,6 +94,7 @@ func getTemplateData(
 		"loginEndpoint":     loginEndpoint,
 		"manifestEndpoint":  manifestEndpoint,
 		"metricsEndpoint":   metricsEndpoint,
+		"heartbeatEndpoint": heartbeatEndpoint,
 		"registryEnabled":   env.RegistryEnabled(),
 		"registryHost":      customdomains.RegistryDomainOrDefault(org),
 		"registryPlainHttp": buildconfig.IsDevelopment(),
@@ -84,24 +111,31 @@ func getTemplateData(
 	if deploymentTarget.Scope != nil {
 		result["targetScope"] = *deploymentTarget.Scope
 	}
-	if deploymentTarget.Resources != nil {
+	// If no resources are set, the kubernetes template uses default resources and the docker template sets none
+	if deploymentTarget.Resources != nil && *deploymentTarget.Resources != (types.DeploymentTargetResources{}) {
 		result["targetResources"] = deploymentTarget.Resources
+	} else {
+		result["targetResources"] = nil
 	}
 	return result, nil
 }
 
 func getTemplate(deploymentTarget types.DeploymentTargetFull) (*template.Template, error) {
+	return resources.GetTemplate(getTemplatePath(deploymentTarget))
+}
+
+func getTemplatePath(deploymentTarget types.DeploymentTargetFull) string {
 	if deploymentTarget.Type == types.DeploymentTypeDocker {
-		return resources.GetTemplate(path.Join(
+		return path.Join(
 			"agent/docker",
 			deploymentTarget.AgentVersion.ComposeFileRevision,
 			"docker-compose.yaml.tmpl",
-		))
+		)
 	} else {
-		return resources.GetTemplate(path.Join(
+		return path.Join(
 			"agent/kubernetes",
 			deploymentTarget.AgentVersion.ManifestFileRevision,
 			"manifest.yaml.tmpl",
-		))
+		)
 	}
 }
@@ -0,0 +1,137 @@
+package agentmanifest
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+// testTargetSecret is the secret that getManifest renders into the manifest.
+const testTargetSecret = "distr-test-target-secret"
+
+func testDeploymentTarget(deploymentType types.DeploymentType) types.DeploymentTargetFull {
+	return types.DeploymentTargetFull{
+		DeploymentTarget: types.DeploymentTarget{
+			ID:        uuid.New(),
+			Type:      deploymentType,
+			Namespace: util.PtrTo("distr"),
+			Scope:     util.PtrTo(types.DeploymentTargetScopeNamespace),
+		},
+		AgentVersion: types.AgentVersion{
+			ID:                   uuid.New(),
+			Name:                 "1.0.0",
+			ComposeFileRevision:  "v1",
+			ManifestFileRevision: "v1",
+		},
+	}
+}
+
+func getManifest(g *WithT, deploymentTarget types.DeploymentTargetFull) string {
+	manifest, err := Get(context.Background(), deploymentTarget, types.Organization{}, util.PtrTo(testTargetSecret))
+	g.Expect(err).NotTo(HaveOccurred())
+	data, err := io.ReadAll(manifest)
+	g.Expect(err).NotTo(HaveOccurred())
+	return string(data)
+}
+
+func TestGetDockerResources(t *testing.T) {
+	t.Run("without resources", func(t *testing.T) {
+		g := NewWithT(t)
+		manifest := getManifest(g, testDeploymentTarget(types.DeploymentTypeDocker))
+		g.Expect(manifest).NotTo(ContainSubstring("deploy:"))
+		g.Expect(manifest).NotTo(ContainSubstring("resources:"))
+	})
+
+	t.Run("with empty resources", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := testDeploymentTarget(types.DeploymentTypeDocker)
+		dt.Resources = &types.DeploymentTargetResources{}
+		g.Expect(getManifest(g, dt)).NotTo(ContainSubstring("deploy:"))
+	})
+
+	t.Run("with limits only", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := testDeploymentTarget(types.DeploymentTypeDocker)
+		dt.Resources = &types.DeploymentTargetResources{CPULimit: "1.5", MemoryLimit: "512m"}
+		manifest := getManifest(g, dt)
+		g.Expect(manifest).To(ContainSubstring("    deploy:\n      resources:\n        limits:\n" +
+			"          cpus: '1.5'\n          memory: '512m'\n"))
+		g.Expect(manifest).NotTo(ContainSubstring("reservations:"))
+	})
+
+	t.Run("with reservations only", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := testDeploymentTarget(types.DeploymentTypeDocker)
+		dt.Resources = &types.DeploymentTargetResources{MemoryRequest: "256m"}
+		manifest := getManifest(g, dt)
+		g.Expect(manifest).To(ContainSubstring("      resources:\n        reservations:\n          memory: '256m'\n"))
+		g.Expect(manifest).NotTo(ContainSubstring("limits:"))
+	})
+}
+
+func TestPreviewRedactsCredentials(t *testing.T) {
+	dockerConfig := `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`
+	t.Setenv("DATABASE_URL", "postgres://localhost/distr")
+	t.Setenv("JWT_SECRET", base64.StdEncoding.EncodeToString([]byte("secret")))
+	t.Setenv("DISTR_HOST", "https://distr.example.com")
+	t.Setenv("AGENT_DOCKER_CONFIG", dockerConfig)
+	env.Initialize()
+	encodedDockerConfig := base64.StdEncoding.EncodeToString([]byte(dockerConfig))
+
+	for _, deploymentType := range []types.DeploymentType{types.DeploymentTypeDocker, types.DeploymentTypeKubernetes} {
+		t.Run(string(deploymentType), func(t *testing.T) {
+			g := NewWithT(t)
+			dt := testDeploymentTarget(deploymentType)
+			g.Expect(getManifest(g, dt)).To(ContainSubstring(testTargetSecret))
+
+			preview, err := Preview(context.Background(), dt, types.Organization{})
+			g.Expect(err).NotTo(HaveOccurred())
+			data, err := io.ReadAll(preview)
+			g.Expect(err).NotTo(HaveOccurred())
+			manifest := string(data)
+			g.Expect(manifest).To(ContainSubstring(previewPlaceholder))
+			g.Expect(manifest).NotTo(ContainSubstring(testTargetSecret))
+			g.Expect(manifest).NotTo(ContainSubstring(encodedDockerConfig))
+			g.Expect(manifest).NotTo(ContainSubstring("dXNlcjpwYXNzd29yZA=="))
+		})
+	}
+
+	t.Run("kubernetes manifest contains docker config", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(getManifest(g, testDeploymentTarget(types.DeploymentTypeKubernetes))).
+			To(ContainSubstring(encodedDockerConfig))
+	})
+}
+
+func TestGetMissingTemplateData(t *testing.T) {
+	t.Run("all data available", func(t *testing.T) {
+		for _, deploymentType := range []types.DeploymentType{types.DeploymentTypeDocker, types.DeploymentTypeKubernetes} {
+			g := NewWithT(t)
+			g.Expect(getManifest(g, testDeploymentTarget(deploymentType))).NotTo(ContainSubstring("<no value>"))
+		}
+	})
+
+	t.Run("kubernetes without scope", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := testDeploymentTarget(types.DeploymentTypeKubernetes)
+		dt.Scope = nil
+		_, err := Get(context.Background(), dt, types.Organization{}, util.PtrTo(testTargetSecret))
+		g.Expect(err).To(MatchError(ContainSubstring("targetScope")))
+	})
+
+	t.Run("cluster scope without namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		dt := testDeploymentTarget(types.DeploymentTypeKubernetes)
+		dt.Scope = util.PtrTo(types.DeploymentTargetScopeCluster)
+		dt.Namespace = nil
+		_, err := Get(context.Background(), dt, types.Organization{}, util.PtrTo(testTargetSecret))
+		g.Expect(err).To(MatchError(ContainSubstring("targetNamespace")))
+	})
+}
@@ -12,6 +12,12 @@ var (
 	ErrBadRequest    = errors.New("bad request")
 	ErrForbidden     = errors.New("forbidden")
 	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	ErrTagQuotaExceeded     = fmt.Errorf("%w: tag limit reached", ErrQuotaExceeded)
+	ErrStorageQuotaExceeded = fmt.Errorf("%w: storage limit reached", ErrQuotaExceeded)
+	ErrPullLimitExceeded    = fmt.Errorf("%w: pull limit reached", ErrQuotaExceeded)
+
+	ErrManifestTooLarge = fmt.Errorf("%w: manifest too large", ErrBadRequest)
 )
 
 // NewBadRequest creates a new bad request error with the given message
@@ -93,6 +93,8 @@ func (a *Authentication[T]) handleError(w http.ResponseWriter, r *http.Request,
 
 	if errors.Is(err, ErrBadAuthentication) || errors.Is(err, ErrNoAuthentication) {
 		statusCode = http.StatusUnauthorized
+	} else if errors.Is(err, ErrForbidden) {
+		statusCode = http.StatusForbidden
 	} else if a.unknownErrorHandler != nil {
 		a.unknownErrorHandler(w, r, err)
 		return
@@ -0,0 +1,42 @@
+package authn_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/authn"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestMiddlewareAuthenticatesOncePerRequest(t *testing.T) {
+	g := NewWithT(t)
+
+	loads := 0
+	authentication := authn.New(
+		authn.AuthenticatorFunc[*http.Request, *types.Organization](
+			func(ctx context.Context, r *http.Request) (*types.Organization, error) {
+				loads++
+				return &types.Organization{Features: []types.Feature{types.FeatureLicensing}}, nil
+			},
+		),
+	)
+
+	handler := authentication.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		org := authentication.Require(r.Context())
+		for range 5 {
+			g.Expect(authentication.Require(r.Context())).To(BeIdenticalTo(org))
+			g.Expect(authentication.Require(r.Context()).HasFeature(types.FeatureLicensing)).To(BeTrue())
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for i := 1; i <= 3; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		g.Expect(rec.Code).To(Equal(http.StatusNoContent))
+		g.Expect(loads).To(Equal(i))
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/distr-sh/distr/internal/util"
 )
 
+// DbAuthInfo holds the user and organization loaded from the database during authentication. They are loaded once
+// per request, so CurrentUser and CurrentOrg (including feature checks) never hit the database again.
 type DbAuthInfo struct {
 	AuthInfo
 	user *types.UserAccount
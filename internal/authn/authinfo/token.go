@@ -8,6 +8,7 @@ import (
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/authkey"
 	"github.com/distr-sh/distr/internal/authn"
+	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
 )
 
@@ -17,6 +18,8 @@ func FromAuthKey(ctx context.Context, token authkey.Key) (AuthInfo, error) {
 			err = fmt.Errorf("%w: %w", authn.ErrBadAuthentication, err)
 		}
 		return nil, err
+	} else if !at.AllowsIPAddress(internalctx.GetRequestIPAddress(ctx)) {
+		return nil, fmt.Errorf("%w: access token must not be used from this IP address", authn.ErrForbidden)
 	} else {
 		return &SimpleAuthInfo{
 			userID:                 at.UserAccount.ID,
@@ -39,3 +39,7 @@ func (err *HttpHeaderError) Error() string {
 func (err *HttpHeaderError) Unwrap() error {
 	return err.wrapped
 }
+
+// ErrForbidden implies that the provider found valid authentication information
+// on the Request but it must not be used for this Request
+var ErrForbidden = errors.New("forbidden")
@@ -7,6 +7,8 @@ const (
 var (
 	version = snapshot
 	commit  string
+	// buildDate is the time of the build in RFC 3339 format, if it was set at build time.
+	buildDate string
 )
 
 func Version() string {
@@ -17,6 +19,10 @@ func Commit() string {
 	return commit
 }
 
+func BuildDate() string {
+	return buildDate
+}
+
 func IsRelease() bool {
 	return !IsDevelopment()
 }
@@ -0,0 +1,290 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/registry/blob"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+)
+
+// blobStatConcurrency limits the number of concurrent requests to the blob storage while checking blobs.
+const blobStatConcurrency = 8
+
+type blobStatus struct {
+	size int64
+	err  error
+}
+
+// Import recreates the artifacts, versions and tags described by the given export in the given organization. It only
+// restores metadata, so all referenced blobs must already exist in the blob storage. Versions with missing blobs or
+// otherwise invalid entries are rejected and reported, all other versions are created in a single transaction.
+func Import(
+	ctx context.Context,
+	org types.Organization,
+	userID uuid.UUID,
+	blobs blob.BlobStatHandler,
+	export api.CatalogExport,
+) (*api.CatalogImportResponse, error) {
+	if export.FormatVersion != api.CatalogExportFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %v", apierrors.ErrBadRequest, export.FormatVersion)
+	}
+
+	repoPrefix := org.ID.String()
+	if org.Slug != nil {
+		repoPrefix = *org.Slug
+	}
+
+	statuses, err := statBlobs(ctx, blobs, repoPrefix, export.Artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.CatalogImportResponse{Entries: []api.CatalogImportEntry{}}
+
+	err = db.RunTx(ctx, func(ctx context.Context) error {
+		response = api.CatalogImportResponse{Entries: []api.CatalogImportEntry{}}
+
+		for _, exportArtifact := range export.Artifacts {
+			// the artifact is only created once the first of its versions is valid
+			var artifact *types.Artifact
+
+			for _, version := range exportArtifact.Versions {
+				entry := api.CatalogImportEntry{
+					Artifact: exportArtifact.Name,
+					Digest:   version.Digest,
+					Tags:     version.Tags,
+				}
+				if strings.TrimSpace(exportArtifact.Name) == "" {
+					entry.Errors = append(entry.Errors, "artifact name must not be empty")
+				} else {
+					validateVersion(version, statuses, env.ArtifactMaxManifestDataBytes(), &entry)
+				}
+
+				if len(entry.Errors) > 0 || len(entry.MissingBlobs) > 0 {
+					entry.Status = api.CatalogImportStatusRejected
+				} else {
+					if artifact == nil {
+						var err error
+						if artifact, err = db.GetOrCreateArtifact(ctx, org.ID, exportArtifact.Name); err != nil {
+							return err
+						}
+					}
+					if err := importVersion(ctx, *artifact, userID, version, &entry); err != nil {
+						return err
+					}
+				}
+
+				switch entry.Status {
+				case api.CatalogImportStatusImported:
+					response.Imported++
+				case api.CatalogImportStatusSkipped:
+					response.Skipped++
+				case api.CatalogImportStatusRejected:
+					response.Rejected++
+				}
+				response.Entries = append(response.Entries, entry)
+			}
+		}
+
+		return db.EnsureOrganizationStorageQuota(ctx, org.ID, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// statBlobs looks up all layers and configs referenced by the given artifacts in the blob storage. Manifests
+// referenced by an index are not blobs and therefore skipped.
+func statBlobs(
+	ctx context.Context,
+	blobs blob.BlobStatHandler,
+	repoPrefix string,
+	artifacts []api.CatalogExportArtifact,
+) (map[digest.Digest]blobStatus, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, blobStatConcurrency)
+	result := make(map[digest.Digest]blobStatus)
+
+	for _, artifact := range artifacts {
+		for _, version := range artifact.Versions {
+			if manifest.MIMETypeIsMultiImage(version.MediaType) {
+				continue
+			}
+			for _, b := range version.Blobs {
+				d := digest.Digest(b.Digest)
+				if _, ok := result[d]; ok || d.Validate() != nil {
+					continue
+				}
+				result[d] = blobStatus{}
+				repo := repoPrefix + "/" + artifact.Name
+				wg.Go(func() {
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					size, err := blobs.Stat(ctx, repo, d)
+					var rerr blob.RedirectError
+					if errors.As(err, &rerr) {
+						// the blob exists, but its size is unknown
+						size, err = -1, nil
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					result[d] = blobStatus{size: size, err: err}
+				})
+			}
+		}
+	}
+	wg.Wait()
+
+	for _, status := range result {
+		if status.err != nil && !errors.Is(status.err, blob.ErrNotFound) {
+			return nil, fmt.Errorf("could not check blob: %w", status.err)
+		}
+	}
+	return result, nil
+}
+
+// validateVersion adds an error to the entry for every problem that prevents the version from being imported.
+// Manifests larger than maxManifestBytes are rejected.
+func validateVersion(
+	version api.CatalogExportVersion,
+	statuses map[digest.Digest]blobStatus,
+	maxManifestBytes int,
+	entry *api.CatalogImportEntry,
+) {
+	d := digest.Digest(version.Digest)
+	if err := d.Validate(); err != nil {
+		entry.Errors = append(entry.Errors, fmt.Sprintf("invalid digest: %v", err))
+	} else if d.Algorithm().FromBytes(version.Manifest) != d {
+		entry.Errors = append(entry.Errors, "manifest does not match digest")
+	}
+	if version.MediaType == "" {
+		entry.Errors = append(entry.Errors, "media type must not be empty")
+	}
+	if len(version.Manifest) > maxManifestBytes {
+		entry.Errors = append(entry.Errors,
+			fmt.Sprintf("manifest exceeds the maximum size of %v bytes", maxManifestBytes))
+	}
+	if _, err := types.ParseManifestPlatforms(version.MediaType, version.Manifest); err != nil {
+		entry.Errors = append(entry.Errors, fmt.Sprintf("invalid index: %v", err))
+	}
+	for _, tag := range version.Tags {
+		if tag == "" || strings.Contains(tag, ":") {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("invalid tag: %q", tag))
+		}
+	}
+	for _, b := range version.Blobs {
+		bd := digest.Digest(b.Digest)
+		if err := bd.Validate(); err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("invalid blob digest %q: %v", b.Digest, err))
+		} else if manifest.MIMETypeIsMultiImage(version.MediaType) {
+			continue
+		} else if status := statuses[bd]; errors.Is(status.err, blob.ErrNotFound) {
+			entry.MissingBlobs = append(entry.MissingBlobs, b.Digest)
+		} else if status.size >= 0 && status.size != b.Size {
+			entry.Errors = append(entry.Errors,
+				fmt.Sprintf("blob %v has size %v, expected %v", b.Digest, status.size, b.Size))
+		}
+	}
+}
+
+// importVersion creates the version identified by its digest and all of its tags, unless they already exist.
+func importVersion(
+	ctx context.Context,
+	artifact types.Artifact,
+	userID uuid.UUID,
+	version api.CatalogExportVersion,
+	entry *api.CatalogImportEntry,
+) error {
+	created := false
+
+	if _, err := db.GetArtifactVersionByTag(ctx, artifact.ID, string(version.Digest)); err == nil {
+		// version already exists, but some of its tags might be missing
+	} else if !errors.Is(err, apierrors.ErrNotFound) {
+		return err
+	} else {
+		if manifest.MIMETypeIsMultiImage(version.MediaType) {
+			for _, b := range version.Blobs {
+				_, err := db.GetArtifactVersionByTag(ctx, artifact.ID, string(b.Digest))
+				if errors.Is(err, apierrors.ErrNotFound) {
+					entry.MissingBlobs = append(entry.MissingBlobs, b.Digest)
+				} else if err != nil {
+					return err
+				}
+			}
+			if len(entry.MissingBlobs) > 0 {
+				entry.Status = api.CatalogImportStatusRejected
+				return nil
+			}
+		}
+
+		av := types.ArtifactVersion{
+			CreatedByUserAccountID: &userID,
+			Name:                   string(version.Digest),
+			ManifestBlobDigest:     version.Digest,
+			ManifestBlobSize:       version.ManifestSize,
+			ManifestContentType:    version.MediaType,
+			ManifestData:           version.Manifest,
+			ArtifactID:             artifact.ID,
+		}
+		if err := db.CreateArtifactVersion(ctx, &av); err != nil {
+			return err
+		}
+		if platforms, err := types.ParseManifestPlatforms(version.MediaType, version.Manifest); err != nil {
+			return err
+		} else if err := db.CreateArtifactVersionPlatforms(ctx, av.ID, platforms); err != nil {
+			return err
+		}
+		for _, b := range version.Blobs {
+			part := types.ArtifactVersionPart{
+				ArtifactVersionID:  av.ID,
+				ArtifactBlobDigest: b.Digest,
+				ArtifactBlobSize:   b.Size,
+			}
+			if err := db.CreateArtifactVersionPart(ctx, &part); err != nil {
+				return err
+			}
+		}
+		created = true
+	}
+
+	for _, tag := range slices.Compact(slices.Sorted(slices.Values(version.Tags))) {
+		if existing, err := db.GetArtifactVersionByTag(ctx, artifact.ID, tag); err == nil {
+			if existing.ManifestBlobDigest != version.Digest {
+				entry.Errors = append(entry.Errors, fmt.Sprintf("tag %v already exists with different content", tag))
+			}
+			continue
+		} else if !errors.Is(err, apierrors.ErrNotFound) {
+			return err
+		}
+
+		if ok, err := db.EnsureArtifactTagLimitForInsert(ctx, artifact.OrganizationID); err != nil {
+			return err
+		} else if !ok {
+			return apierrors.ErrTagQuotaExceeded
+		} else if _, err := db.CreateArtifactTagForDigest(ctx, artifact.ID, tag, version.Digest, userID); err != nil {
+			return err
+		}
+		created = true
+	}
+
+	if created {
+		entry.Status = api.CatalogImportStatusImported
+	} else {
+		entry.Status = api.CatalogImportStatusSkipped
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/registry/blob"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+)
+
+type fakeBlobStat struct {
+	mu    sync.Mutex
+	sizes map[digest.Digest]int64
+	err   map[digest.Digest]error
+	calls []string
+}
+
+func (f *fakeBlobStat) Stat(ctx context.Context, repo string, h digest.Digest) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, repo+"@"+h.String())
+	if err, ok := f.err[h]; ok {
+		return 0, err
+	} else if size, ok := f.sizes[h]; ok {
+		return size, nil
+	}
+	return 0, blob.ErrNotFound
+}
+
+const ociManifest = "application/vnd.oci.image.manifest.v1+json"
+
+func TestImportRejectsUnsupportedFormatVersion(t *testing.T) {
+	g := NewWithT(t)
+	_, err := Import(t.Context(), types.Organization{}, uuid.New(), &fakeBlobStat{}, api.CatalogExport{FormatVersion: 0})
+	g.Expect(err).To(MatchError(apierrors.ErrBadRequest))
+}
+
+func TestStatBlobs(t *testing.T) {
+	g := NewWithT(t)
+	existing, missing, redirected := digest.FromString("a"), digest.FromString("b"), digest.FromString("c")
+	stat := &fakeBlobStat{
+		sizes: map[digest.Digest]int64{existing: 10},
+		err:   map[digest.Digest]error{redirected: blob.RedirectError{Location: "https://s3", Code: 307}},
+	}
+	artifacts := []api.CatalogExportArtifact{{
+		Name: "app",
+		Versions: []api.CatalogExportVersion{
+			{MediaType: ociManifest, Blobs: []api.CatalogExportBlob{
+				{Digest: types.Digest(existing)}, {Digest: types.Digest(missing)}, {Digest: "invalid"},
+			}},
+			{MediaType: ociManifest, Blobs: []api.CatalogExportBlob{
+				{Digest: types.Digest(existing)}, {Digest: types.Digest(redirected)},
+			}},
+			{
+				MediaType: "application/vnd.oci.image.index.v1+json",
+				Blobs:     []api.CatalogExportBlob{{Digest: types.Digest(digest.FromString("child"))}},
+			},
+		},
+	}}
+
+	statuses, err := statBlobs(t.Context(), stat, "org", artifacts)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(statuses).To(HaveLen(3))
+	g.Expect(statuses[existing]).To(Equal(blobStatus{size: 10}))
+	g.Expect(statuses[missing].err).To(MatchError(blob.ErrNotFound))
+	g.Expect(statuses[redirected]).To(Equal(blobStatus{size: -1}))
+	g.Expect(stat.calls).To(HaveLen(3), "every blob is only checked once")
+	g.Expect(stat.calls).To(ContainElement("org/app@" + existing.String()))
+}
+
+func TestStatBlobsFailsOnStorageErrors(t *testing.T) {
+	g := NewWithT(t)
+	d := digest.FromString("a")
+	storageErr := errors.New("storage unavailable")
+	stat := &fakeBlobStat{err: map[digest.Digest]error{d: storageErr}}
+	artifacts := []api.CatalogExportArtifact{{
+		Name: "app",
+		Versions: []api.CatalogExportVersion{
+			{MediaType: ociManifest, Blobs: []api.CatalogExportBlob{{Digest: types.Digest(d)}}},
+		},
+	}}
+
+	_, err := statBlobs(t.Context(), stat, "org", artifacts)
+	g.Expect(err).To(MatchError(storageErr))
+}
+
+func TestValidateVersion(t *testing.T) {
+	manifestData := []byte(`{"schemaVersion":2}`)
+	layer, missing := digest.FromString("layer"), digest.FromString("missing")
+	statuses := map[digest.Digest]blobStatus{
+		layer:   {size: 100},
+		missing: {err: blob.ErrNotFound},
+	}
+	valid := func() api.CatalogExportVersion {
+		return api.CatalogExportVersion{
+			Digest:    types.Digest(digest.FromBytes(manifestData)),
+			MediaType: ociManifest,
+			Manifest:  manifestData,
+			Tags:      []string{"latest", "1.0.0"},
+			Blobs:     []api.CatalogExportBlob{{Digest: types.Digest(layer), Size: 100}},
+		}
+	}
+	validate := func(version api.CatalogExportVersion, maxManifestBytes int) api.CatalogImportEntry {
+		var entry api.CatalogImportEntry
+		validateVersion(version, statuses, maxManifestBytes, &entry)
+		return entry
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		g := NewWithT(t)
+		entry := validate(valid(), 1024)
+		g.Expect(entry.Errors).To(BeEmpty())
+		g.Expect(entry.MissingBlobs).To(BeEmpty())
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		version.Digest = types.Digest(digest.FromString("other"))
+		g.Expect(validate(version, 1024).Errors).To(ConsistOf("manifest does not match digest"))
+	})
+
+	t.Run("invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		version.Digest = "sha256:invalid"
+		g.Expect(validate(version, 1024).Errors).To(ConsistOf(HavePrefix("invalid digest")))
+	})
+
+	t.Run("manifest too large", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(validate(valid(), 4).Errors).To(ConsistOf("manifest exceeds the maximum size of 4 bytes"))
+	})
+
+	t.Run("missing media type and invalid tags", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		version.MediaType = ""
+		version.Tags = []string{"", "sha256:abc"}
+		g.Expect(validate(version, 1024).Errors).To(ConsistOf(
+			"media type must not be empty",
+			`invalid tag: ""`,
+			`invalid tag: "sha256:abc"`,
+		))
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		version.Blobs = append(version.Blobs, api.CatalogExportBlob{Digest: types.Digest(missing), Size: 5})
+		entry := validate(version, 1024)
+		g.Expect(entry.Errors).To(BeEmpty())
+		g.Expect(entry.MissingBlobs).To(ConsistOf(types.Digest(missing)))
+	})
+
+	t.Run("blob size mismatch", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		version.Blobs[0].Size = 99
+		g.Expect(validate(version, 1024).Errors).To(ConsistOf(ContainSubstring("has size 100, expected 99")))
+	})
+
+	t.Run("unknown blob size", func(t *testing.T) {
+		g := NewWithT(t)
+		version := valid()
+		var entry api.CatalogImportEntry
+		validateVersion(version, map[digest.Digest]blobStatus{layer: {size: -1}}, 1024, &entry)
+		g.Expect(entry.Errors).To(BeEmpty())
+	})
+}
@@ -0,0 +1,38 @@
+package cleanup
+
+import (
+	"context"
+	"slices"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/jobs"
+	"github.com/distr-sh/distr/internal/types"
+	"go.uber.org/zap"
+)
+
+// RunAuditEventCleanup deletes audit events older than the configured max age. Event types that are configured to be
+// preserved are kept indefinitely.
+func RunAuditEventCleanup(ctx context.Context) error {
+	log := internalctx.GetLogger(ctx)
+	maxAge := env.AuditEventsMaxAge()
+	if maxAge == nil {
+		log.Info("audit event cleanup skipped because no max age is configured")
+		return nil
+	}
+
+	for _, eventType := range types.AuditEventTypes {
+		if slices.Contains(env.AuditEventsPreservedTypes(), eventType) {
+			continue
+		}
+		if count, err := db.DeleteAuditEventsOlderThan(ctx, eventType, *maxAge); err != nil {
+			return err
+		} else {
+			jobs.AddRowsAffected(ctx, count)
+			log.Info("audit event cleanup finished",
+				zap.String("type", string(eventType)), zap.Int64("rowsDeleted", count))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/jobs"
+	"go.uber.org/zap"
+)
+
+func RunDeletedArtifactsCleanup(ctx context.Context) error {
+	log := internalctx.GetLogger(ctx)
+	count, err := db.PurgeDeletedArtifacts(ctx, deletedArtifactsMaxAge())
+	jobs.AddRowsAffected(ctx, count)
+	log.Info("deleted Artifact cleanup finished", zap.Int64("rowsDeleted", count), zap.Error(err))
+	return err
+}
+
+func deletedArtifactsMaxAge() time.Duration {
+	return time.Duration(env.DeletedArtifactsRetentionDays()) * 24 * time.Hour
+}
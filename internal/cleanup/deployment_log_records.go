@@ -5,12 +5,14 @@ import (
 
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/jobs"
 	"go.uber.org/zap"
 )
 
 func RunDeploymentLogRecordCleanup(ctx context.Context) error {
 	log := internalctx.GetLogger(ctx)
 	count, err := db.CleanupDeploymentLogRecords(ctx)
+	jobs.AddRowsAffected(ctx, count)
 	log.Info("DeploymentLogRecord cleanup finished", zap.Int64("rowsDeleted", count), zap.Error(err))
 	return err
 }
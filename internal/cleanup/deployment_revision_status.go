@@ -5,6 +5,7 @@ import (
 
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/jobs"
 	"go.uber.org/zap"
 )
 
@@ -13,6 +14,7 @@ func RunDeploymentRevisionStatusCleanup(ctx context.Context) error {
 	if count, err := db.CleanupDeploymentRevisionStatus(ctx); err != nil {
 		return err
 	} else {
+		jobs.AddRowsAffected(ctx, count)
 		log.Info("DeploymentRevisionStatus cleanup finished", zap.Int64("rowsDeleted", count))
 		return nil
 	}
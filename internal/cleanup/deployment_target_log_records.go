@@ -5,12 +5,14 @@ import (
 
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/jobs"
 	"go.uber.org/zap"
 )
 
 func RunDeploymentTargetLogRecordCleanup(ctx context.Context) error {
 	log := internalctx.GetLogger(ctx)
 	count, err := db.CleanupDeploymentTargetLogRecords(ctx)
+	jobs.AddRowsAffected(ctx, count)
 	log.Info("DeploymentTargetLogRecord cleanup finished", zap.Int64("rowsDeleted", count), zap.Error(err))
 	return err
 }
@@ -2,17 +2,36 @@ package cleanup
 
 import (
 	"context"
+	"time"
 
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/jobs"
+	"github.com/distr-sh/distr/internal/subscription"
+	"github.com/distr-sh/distr/internal/types"
 	"go.uber.org/zap"
 )
 
+// RunDeploymentTargetMetricsCleanup deletes deployment target metrics older than the configured max age. Organizations
+// with a paid subscription keep their metrics longer, see subscription.GetMetricsEntriesMaxAge.
 func RunDeploymentTargetMetricsCleanup(ctx context.Context) error {
 	log := internalctx.GetLogger(ctx)
-	if count, err := db.CleanupDeploymentTargetMetrics(ctx); err != nil {
+	maxAge := env.MetricsEntriesMaxAge()
+	if maxAge == nil {
+		log.Info("DeploymentTargetMetrics cleanup skipped because no max age is configured")
+		return nil
+	}
+
+	maxAges := make(map[types.SubscriptionType]time.Duration, len(types.AllSubscriptionTypes))
+	for _, st := range types.AllSubscriptionTypes {
+		maxAges[st] = subscription.GetMetricsEntriesMaxAge(st, *maxAge)
+	}
+
+	if count, err := db.CleanupDeploymentTargetMetrics(ctx, maxAges); err != nil {
 		return err
 	} else {
+		jobs.AddRowsAffected(ctx, count)
 		log.Info("DeploymentTargetMetrics cleanup finished", zap.Int64("rowsDeleted", count))
 		return nil
 	}
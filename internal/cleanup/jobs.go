@@ -0,0 +1,86 @@
+package cleanup
+
+import (
+	"time"
+
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/jobs"
+	"github.com/distr-sh/distr/internal/util"
+)
+
+// oidcStateMaxAge is the time a user has to complete an OIDC login.
+const oidcStateMaxAge = 1 * time.Minute
+
+// JobConfig describes a cleanup job and the retention settings it enforces.
+type JobConfig struct {
+	Name    string
+	Run     jobs.JobFunc
+	Cron    *string
+	Timeout time.Duration
+	// MaxAge is the age after which rows are deleted or nil if the job does not delete rows by age or is disabled.
+	MaxAge *time.Duration
+	// MaxCount is the number of rows that are kept or nil if the job does not delete rows by count or is disabled.
+	MaxCount *int
+}
+
+// Jobs returns the configuration of all cleanup jobs, including jobs without schedule.
+func Jobs() []JobConfig {
+	return []JobConfig{
+		{
+			Name:    "DeploymentRevisionStatusCleanup",
+			Run:     RunDeploymentRevisionStatusCleanup,
+			Cron:    env.CleanupDeploymenRevisionStatusCron(),
+			Timeout: env.CleanupDeploymenRevisionStatusTimeout(),
+			MaxAge:  env.StatusEntriesMaxAge(),
+		},
+		{
+			Name:    "DeploymentTargetStatusCleanup",
+			Run:     RunDeploymentTargetStatusCleanup,
+			Cron:    env.CleanupDeploymenTargetStatusCron(),
+			Timeout: env.CleanupDeploymenTargetStatusTimeout(),
+			MaxAge:  env.StatusEntriesMaxAge(),
+		},
+		{
+			Name:    "DeploymentTargetMetricsCleanup",
+			Run:     RunDeploymentTargetMetricsCleanup,
+			Cron:    env.CleanupDeploymentTargetMetricsCron(),
+			Timeout: env.CleanupDeploymentTargetMetricsTimeout(),
+			MaxAge:  env.MetricsEntriesMaxAge(),
+		},
+		{
+			Name:     "DeploymentTargetLogRecordCleanup",
+			Run:      RunDeploymentTargetLogRecordCleanup,
+			Cron:     env.CleanupDeploymentTargetLogRecordCron(),
+			Timeout:  env.CleanupDeploymentTargetLogRecordTimeout(),
+			MaxCount: env.LogRecordEntriesMaxCount(),
+		},
+		{
+			Name:     "DeploymentLogRecordCleanup",
+			Run:      RunDeploymentLogRecordCleanup,
+			Cron:     env.CleanupDeploymentLogRecordCron(),
+			Timeout:  env.CleanupDeploymentLogRecordTimeout(),
+			MaxCount: env.LogRecordEntriesMaxCount(),
+		},
+		{
+			Name:    "OIDCStateCleanup",
+			Run:     RunOIDCStateCleanup,
+			Cron:    env.CleanupOIDCStateCron(),
+			Timeout: env.CleanupOIDCStateCronTimeout(),
+			MaxAge:  util.PtrTo(oidcStateMaxAge),
+		},
+		{
+			Name:    "DeletedArtifactsCleanup",
+			Run:     RunDeletedArtifactsCleanup,
+			Cron:    env.CleanupDeletedArtifactsCron(),
+			Timeout: env.CleanupDeletedArtifactsTimeout(),
+			MaxAge:  util.PtrTo(deletedArtifactsMaxAge()),
+		},
+		{
+			Name:    "AuditEventCleanup",
+			Run:     RunAuditEventCleanup,
+			Cron:    env.CleanupAuditEventsCron(),
+			Timeout: env.CleanupAuditEventsTimeout(),
+			MaxAge:  env.AuditEventsMaxAge(),
+		},
+	}
+}
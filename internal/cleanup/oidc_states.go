@@ -5,14 +5,16 @@ import (
 
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/jobs"
 	"go.uber.org/zap"
 )
 
 func RunOIDCStateCleanup(ctx context.Context) error {
 	log := internalctx.GetLogger(ctx)
-	if count, err := db.CleanupOIDCStates(ctx); err != nil {
+	if count, err := db.CleanupOIDCStates(ctx, oidcStateMaxAge); err != nil {
 		return err
 	} else {
+		jobs.AddRowsAffected(ctx, count)
 		log.Info("OIDCStates cleanup finished", zap.Int64("rowsDeleted", count))
 		return nil
 	}
@@ -11,8 +11,10 @@ import (
 
 var urlSchemeRegex = regexp.MustCompile("^https?://")
 
+// AppDomainOrDefault returns the custom app domain of the organization or the default host. Custom domains are only
+// used once they have been verified.
 func AppDomainOrDefault(o types.Organization) string {
-	if o.AppDomain != nil {
+	if o.AppDomain != nil && o.AppDomainVerifiedAt != nil {
 		d := *o.AppDomain
 		if urlSchemeRegex.MatchString(d) {
 			return d
@@ -28,8 +30,10 @@ func AppDomainOrDefault(o types.Organization) string {
 	}
 }
 
+// RegistryDomainOrDefault returns the custom registry domain of the organization or the default registry host. Like
+// for [AppDomainOrDefault], only verified domains are used.
 func RegistryDomainOrDefault(o types.Organization) string {
-	if o.RegistryDomain != nil {
+	if o.RegistryDomain != nil && o.RegistryDomainVerifiedAt != nil {
 		return *o.RegistryDomain
 	} else {
 		return env.RegistryHost()
@@ -0,0 +1,79 @@
+package customdomains
+
+import (
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		valid  bool
+	}{
+		{"example.com", true},
+		{"app.example.com", true},
+		{"my-app.example-1.io", true},
+		{"xn--bcher-kva.example", true},
+		{"", false},
+		{"localhost", false},
+		{"Example.com", false},
+		{"https://example.com", false},
+		{"example.com:8443", false},
+		{"example.com/path", false},
+		{"example..com", false},
+		{".example.com", false},
+		{"-app.example.com", false},
+		{"app-.example.com", false},
+		{"app_1.example.com", false},
+		{"bücher.example", false},
+		{string(make([]byte, 64)) + ".example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			g := NewWithT(t)
+			if tt.valid {
+				g.Expect(ValidateDomain(tt.domain)).To(Succeed())
+			} else {
+				g.Expect(ValidateDomain(tt.domain)).To(MatchError(ErrInvalidDomain))
+			}
+		})
+	}
+}
+
+func TestValidateDomainLength(t *testing.T) {
+	g := NewWithT(t)
+	label := "a123456789012345678901234567890123456789012345678901234567890ab"
+	g.Expect(label).To(HaveLen(63))
+	g.Expect(ValidateDomain(label + ".example.com")).To(Succeed())
+	g.Expect(ValidateDomain(label + "c.example.com")).To(MatchError(ErrInvalidDomain))
+
+	domain := label + "." + label + "." + label + "." + label[:57] + ".com"
+	g.Expect(domain).To(HaveLen(253))
+	g.Expect(ValidateDomain(domain)).To(Succeed())
+	g.Expect(ValidateDomain("a" + domain)).To(MatchError(ErrInvalidDomain))
+}
+
+func TestDomainOrDefaultRequiresVerification(t *testing.T) {
+	g := NewWithT(t)
+	org := types.Organization{AppDomain: util.PtrTo("app.example.com"), RegistryDomain: util.PtrTo("registry.example.com")}
+	g.Expect(AppDomainOrDefault(org)).To(Equal(env.Host()))
+	g.Expect(RegistryDomainOrDefault(org)).To(Equal(env.RegistryHost()))
+
+	org.AppDomainVerifiedAt = util.PtrTo(time.Now())
+	org.RegistryDomainVerifiedAt = util.PtrTo(time.Now())
+	g.Expect(AppDomainOrDefault(org)).To(HaveSuffix("://app.example.com"))
+	g.Expect(RegistryDomainOrDefault(org)).To(Equal("registry.example.com"))
+}
+
+func TestHostname(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(Hostname("app.example.com")).To(Equal("app.example.com"))
+	g.Expect(Hostname("https://app.example.com")).To(Equal("app.example.com"))
+	g.Expect(Hostname("https://app.example.com:8443/path")).To(Equal("app.example.com"))
+	g.Expect(Hostname("registry.example.com:5000")).To(Equal("registry.example.com"))
+}
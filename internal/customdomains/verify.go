@@ -0,0 +1,187 @@
+package customdomains
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/distr-sh/distr/internal/publicnet"
+)
+
+const (
+	// VerificationRecordPrefix is prepended to a custom domain to get the name of the TXT record that must contain the
+	// verification token of the organization.
+	VerificationRecordPrefix = "_distr-verification."
+
+	// MicrosoftIdentityAssociationPath is served on every domain if a Microsoft identity association is configured.
+	MicrosoftIdentityAssociationPath = "/.well-known/microsoft-identity-association.json"
+
+	verifyTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidDomain = errors.New("invalid domain")
+
+	// verifyHTTPClient only connects to public addresses, because the domain is chosen by the user and could resolve
+	// to an address in the internal network of the hub.
+	verifyHTTPClient = func() *http.Client {
+		client := publicnet.NewHTTPClient(verifyTimeout)
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		return client
+	}()
+)
+
+// DomainCheck is the result of checking the DNS and TLS setup of a single custom domain.
+type DomainCheck struct {
+	// Verified is true if the verification TXT record contains the expected token.
+	Verified bool
+	// DNSConfigured is true if the domain is a CNAME of the default host or resolves to the same addresses.
+	DNSConfigured bool
+	// TLSReady is true if the domain serves a valid certificate.
+	TLSReady bool
+	// WellKnownReady is only set if a well-known document must be served on the domain. It is true if the document is
+	// reachable via the domain.
+	WellKnownReady *bool
+	Errors         []string
+}
+
+// ValidateDomain checks that the given value is a fully qualified host name according to RFC 1123, without scheme,
+// port or path.
+func ValidateDomain(domain string) error {
+	if len(domain) == 0 || len(domain) > 253 {
+		return fmt.Errorf("%w: must be between 1 and 253 characters long", ErrInvalidDomain)
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%w: must contain at least two labels", ErrInvalidDomain)
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("%w: each label must be between 1 and 63 characters long", ErrInvalidDomain)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("%w: labels must not start or end with a hyphen", ErrInvalidDomain)
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return fmt.Errorf("%w: labels must only contain lowercase letters, digits and hyphens", ErrInvalidDomain)
+			}
+		}
+	}
+	return nil
+}
+
+// Hostname returns the host name part of a domain as stored for an organization, which might include a scheme and
+// a port.
+func Hostname(domain string) string {
+	host, _ := splitHostPort(domain)
+	return host
+}
+
+// CheckDomain looks up the DNS records of the given domain and tries to establish a TLS connection to it.
+// If wellKnownPath is not empty, the domain is expected to serve the document at that path. Connections are only
+// made to public addresses, see [publicnet.NewDialer].
+func CheckDomain(ctx context.Context, domain, token, defaultHost, wellKnownPath string) DomainCheck {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	var result DomainCheck
+	host, port := splitHostPort(domain)
+
+	if records, err := net.DefaultResolver.LookupTXT(ctx, VerificationRecordPrefix+host); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("TXT record lookup failed: %v", err))
+	} else if token != "" && slices.Contains(records, token) {
+		result.Verified = true
+	} else {
+		result.Errors = append(result.Errors, "TXT record does not contain the verification token")
+	}
+
+	if ok, err := pointsToHost(ctx, host, Hostname(defaultHost)); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("DNS lookup failed: %v", err))
+	} else if ok {
+		result.DNSConfigured = true
+	} else {
+		result.Errors = append(result.Errors, "domain does not point to "+Hostname(defaultHost))
+	}
+
+	dialer := tls.Dialer{NetDialer: publicnet.NewDialer(verifyTimeout), Config: &tls.Config{ServerName: host}}
+	if conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port)); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("TLS handshake failed: %v", err))
+	} else {
+		_ = conn.Close()
+		result.TLSReady = true
+	}
+
+	if wellKnownPath != "" {
+		ready := false
+		if result.TLSReady {
+			u := url.URL{Scheme: "https", Host: net.JoinHostPort(host, port), Path: wellKnownPath}
+			if err := checkWellKnown(ctx, u.String()); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%v is not reachable: %v", wellKnownPath, err))
+			} else {
+				ready = true
+			}
+		}
+		result.WellKnownReady = &ready
+	}
+
+	return result
+}
+
+func pointsToHost(ctx context.Context, host, target string) (bool, error) {
+	if target == "" {
+		return false, nil
+	}
+	if cname, err := net.DefaultResolver.LookupCNAME(ctx, host); err == nil &&
+		strings.EqualFold(strings.TrimSuffix(cname, "."), target) {
+		return true, nil
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return false, err
+	}
+	targetAddrs, err := net.DefaultResolver.LookupHost(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	for _, addr := range addrs {
+		if slices.Contains(targetAddrs, addr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func checkWellKnown(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := verifyHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+	return nil
+}
+
+func splitHostPort(domain string) (string, string) {
+	domain = urlSchemeRegex.ReplaceAllString(domain, "")
+	domain, _, _ = strings.Cut(domain, "/")
+	if host, port, err := net.SplitHostPort(domain); err == nil {
+		return host, port
+	}
+	return domain, "443"
+}
@@ -15,7 +15,8 @@ import (
 
 const (
 	accessTokenOutputExpr = `
-	tok.id, tok.created_at, tok.expires_at, tok.last_used_at, tok.label, tok.key, tok.user_account_id, tok.organization_id
+	tok.id, tok.created_at, tok.expires_at, tok.last_used_at, tok.label, tok.key, tok.user_account_id, tok.organization_id,
+	tok.allowed_cidrs
 `
 	accessTokenWithUserAccountOutputExpr = accessTokenOutputExpr + `,
 	(` + userAccountOutputExpr + `) AS user_account,
@@ -26,11 +27,15 @@ const (
 
 func CreateAccessToken(ctx context.Context, token *types.AccessToken) error {
 	db := internalctx.GetDb(ctx)
+	allowedCIDRs := token.AllowedCIDRs
+	if allowedCIDRs == nil {
+		allowedCIDRs = []string{}
+	}
 	rows, err := db.Query(
 		ctx,
 		fmt.Sprintf(
-			`INSERT INTO AccessToken AS tok (label, expires_at, key, user_account_id, organization_id)
-			VALUES (@label, @expiresAt, @key, @userAccountId, @orgId)
+			`INSERT INTO AccessToken AS tok (label, expires_at, key, user_account_id, organization_id, allowed_cidrs)
+			VALUES (@label, @expiresAt, @key, @userAccountId, @orgId, @allowedCidrs)
 			RETURNING %v`,
 			accessTokenOutputExpr),
 		pgx.NamedArgs{
@@ -39,6 +44,7 @@ func CreateAccessToken(ctx context.Context, token *types.AccessToken) error {
 			"key":           token.Key[:],
 			"userAccountId": token.UserAccountID,
 			"orgId":         token.OrganizationID,
+			"allowedCidrs":  allowedCIDRs,
 		},
 	)
 	if err != nil {
@@ -120,6 +126,28 @@ func GetAccessTokenByKeyUpdatingLastUsed(
 	}
 }
 
+// GetAccessTokenByKey returns the access token with the given key. Unlike GetAccessTokenByKeyUpdatingLastUsed, it
+// does not touch last_used_at and also returns expired tokens.
+func GetAccessTokenByKey(ctx context.Context, key authkey.Key) (*types.AccessToken, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM AccessToken tok WHERE tok.key = @key`, accessTokenOutputExpr),
+		pgx.NamedArgs{"key": key[:]},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying access token: %w", err)
+	}
+	if result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.AccessToken]); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = apierrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("could not get token: %w", err)
+	} else {
+		return &result, nil
+	}
+}
+
 func DeleteAccessTokensOfUserInOrg(ctx context.Context, userID, orgID uuid.UUID) error {
 	db := internalctx.GetDb(ctx)
 	if _, err := db.Exec(
@@ -20,6 +20,12 @@ const (
 	c.customer_organization_id,
 	c.name,
 	c.enabled,
+	c.transitions,
+	c.quiet_hours_start,
+	c.quiet_hours_end,
+	c.quiet_hours_timezone,
+	c.quiet_hours_allow_errors,
+	c.digest,
 	(
 		SELECT array_agg(dt.id)
 		FROM DeploymentTarget dt
@@ -159,6 +165,10 @@ func CreateAlertConfiguration(ctx context.Context, config *types.AlertConfigurat
 	return RunTxRR(ctx, func(ctx context.Context) error {
 		db := internalctx.GetDb(ctx)
 
+		if err := checkAlertConfigReferences(ctx, config); err != nil {
+			return err
+		}
+
 		rows, err := db.Query(
 			ctx,
 			`WITH inserted AS (
@@ -166,12 +176,24 @@ func CreateAlertConfiguration(ctx context.Context, config *types.AlertConfigurat
 				organization_id,
 				customer_organization_id,
 				name,
-				enabled
+				enabled,
+				transitions,
+				quiet_hours_start,
+				quiet_hours_end,
+				quiet_hours_timezone,
+				quiet_hours_allow_errors,
+				digest
 			) VALUES (
 				@organizationID,
 				@customerOrganizationID,
 				@name,
-				@enabled
+				@enabled,
+				@transitions,
+				@quietHoursStart,
+				@quietHoursEnd,
+				@quietHoursTimezone,
+				@quietHoursAllowErrors,
+				@digest
 			)
 			RETURNING id
 		)
@@ -181,6 +203,12 @@ func CreateAlertConfiguration(ctx context.Context, config *types.AlertConfigurat
 				"customerOrganizationID": config.CustomerOrganizationID,
 				"name":                   config.Name,
 				"enabled":                config.Enabled,
+				"transitions":            alertConfigTransitions(config),
+				"quietHoursStart":        config.QuietHoursStart,
+				"quietHoursEnd":          config.QuietHoursEnd,
+				"quietHoursTimezone":     config.QuietHoursTimezone,
+				"quietHoursAllowErrors":  config.QuietHoursAllowErrors,
+				"digest":                 config.Digest,
 			},
 		)
 		if err != nil {
@@ -209,25 +237,43 @@ func UpdateAlertConfiguration(ctx context.Context, config *types.AlertConfigurat
 	return RunTxRR(ctx, func(ctx context.Context) error {
 		db := internalctx.GetDb(ctx)
 
-		_, err := db.Exec(
+		cmd, err := db.Exec(
 			ctx,
 			`UPDATE AlertConfiguration SET
 				name = @name,
-				enabled = @enabled
+				enabled = @enabled,
+				transitions = @transitions,
+				quiet_hours_start = @quietHoursStart,
+				quiet_hours_end = @quietHoursEnd,
+				quiet_hours_timezone = @quietHoursTimezone,
+				quiet_hours_allow_errors = @quietHoursAllowErrors,
+				digest = @digest
 			WHERE id = @id
 				AND organization_id = @orgID
 				AND ((@customerOrgIsNull AND customer_organization_id IS NULL) OR (customer_organization_id = @customerOrgID))`,
 			pgx.NamedArgs{
-				"id":                config.ID,
-				"name":              config.Name,
-				"enabled":           config.Enabled,
-				"orgID":             config.OrganizationID,
-				"customerOrgID":     config.CustomerOrganizationID,
-				"customerOrgIsNull": config.CustomerOrganizationID == nil,
+				"id":                    config.ID,
+				"name":                  config.Name,
+				"enabled":               config.Enabled,
+				"transitions":           alertConfigTransitions(config),
+				"quietHoursStart":       config.QuietHoursStart,
+				"quietHoursEnd":         config.QuietHoursEnd,
+				"quietHoursTimezone":    config.QuietHoursTimezone,
+				"quietHoursAllowErrors": config.QuietHoursAllowErrors,
+				"digest":                config.Digest,
+				"orgID":                 config.OrganizationID,
+				"customerOrgID":         config.CustomerOrganizationID,
+				"customerOrgIsNull":     config.CustomerOrganizationID == nil,
 			},
 		)
 		if err != nil {
 			return fmt.Errorf("failed to update AlertConfiguration: %w", err)
+		} else if cmd.RowsAffected() == 0 {
+			return fmt.Errorf("failed to update AlertConfiguration: %w", apierrors.ErrNotFound)
+		}
+
+		if err := checkAlertConfigReferences(ctx, config); err != nil {
+			return err
 		}
 
 		if err := updateAlertConfigUserAccountIDs(ctx, config); err != nil {
@@ -242,6 +288,64 @@ func UpdateAlertConfiguration(ctx context.Context, config *types.AlertConfigurat
 	})
 }
 
+// checkAlertConfigReferences makes sure that all user accounts and deployment targets referenced by the given config
+// belong to the organization (and customer organization, if set) of the config.
+func checkAlertConfigReferences(ctx context.Context, config *types.AlertConfiguration) error {
+	db := internalctx.GetDb(ctx)
+
+	rows, err := db.Query(
+		ctx,
+		`SELECT
+			(SELECT count(DISTINCT j.user_account_id) FROM Organization_UserAccount j
+				WHERE j.user_account_id = any(@userAccountIDs)
+					AND j.organization_id = @organizationID
+					AND (@customerOrgIsNull OR j.customer_organization_id = @customerOrgID)),
+			(SELECT count(dt.id) FROM DeploymentTarget dt
+				WHERE dt.id = any(@deploymentTargetIDs)
+					AND dt.organization_id = @organizationID
+					AND (@customerOrgIsNull OR dt.customer_organization_id = @customerOrgID))`,
+		pgx.NamedArgs{
+			"userAccountIDs":      config.UserAccountIDs,
+			"deploymentTargetIDs": config.DeploymentTargetIDs,
+			"organizationID":      config.OrganizationID,
+			"customerOrgID":       config.CustomerOrganizationID,
+			"customerOrgIsNull":   config.CustomerOrganizationID == nil,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query AlertConfiguration references: %w", err)
+	}
+
+	type counts struct{ UserAccounts, DeploymentTargets int }
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByPos[counts])
+	if err != nil {
+		return fmt.Errorf("failed to collect AlertConfiguration references: %w", err)
+	}
+
+	if result.UserAccounts != countDistinct(config.UserAccountIDs) {
+		return fmt.Errorf("%w: unknown user account", apierrors.ErrBadRequest)
+	} else if result.DeploymentTargets != countDistinct(config.DeploymentTargetIDs) {
+		return fmt.Errorf("%w: unknown deployment target", apierrors.ErrBadRequest)
+	}
+
+	return nil
+}
+
+func alertConfigTransitions(config *types.AlertConfiguration) []types.AlertTransition {
+	if len(config.Transitions) == 0 {
+		return types.AllAlertTransitions()
+	}
+	return config.Transitions
+}
+
+func countDistinct(ids []uuid.UUID) int {
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	return len(seen)
+}
+
 func updateAlertConfigUserAccountIDs(ctx context.Context, config *types.AlertConfiguration) error {
 	db := internalctx.GetDb(ctx)
 
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const artifactAccessRuleOutputExpr = `
+	r.id, r.created_at, r.artifact_id, r.user_account_id, r.user_role, r.permission
+`
+
+func GetArtifactAccessRules(ctx context.Context, artifactID uuid.UUID) ([]types.ArtifactAccessRule, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`SELECT `+artifactAccessRuleOutputExpr+`
+		FROM ArtifactAccessRule r
+		WHERE r.artifact_id = @artifactId
+		ORDER BY r.created_at`,
+		pgx.NamedArgs{"artifactId": artifactID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactAccessRule: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactAccessRule])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactAccessRule: %w", err)
+	}
+	return result, nil
+}
+
+// GetArtifactAccessRulesByName returns the access rules of the artifact with the given name. If no such artifact
+// exists, an empty result is returned.
+func GetArtifactAccessRulesByName(
+	ctx context.Context,
+	orgSlug, artifactName string,
+) ([]types.ArtifactAccessRule, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`SELECT `+artifactAccessRuleOutputExpr+`
+		FROM ArtifactAccessRule r
+		JOIN Artifact a ON a.id = r.artifact_id
+		JOIN Organization o ON o.id = a.organization_id
+		WHERE o.slug = @orgSlug AND a.name = @name AND a.deleted_at IS NULL`,
+		pgx.NamedArgs{"orgSlug": orgSlug, "name": artifactName},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactAccessRule: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactAccessRule])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactAccessRule: %w", err)
+	}
+	return result, nil
+}
+
+func CreateArtifactAccessRule(ctx context.Context, rule *types.ArtifactAccessRule) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`INSERT INTO ArtifactAccessRule AS r (artifact_id, user_account_id, user_role, permission)
+		VALUES (@artifactId, @userAccountId, @userRole, @permission)
+		RETURNING `+artifactAccessRuleOutputExpr,
+		pgx.NamedArgs{
+			"artifactId":    rule.ArtifactID,
+			"userAccountId": rule.UserAccountID,
+			"userRole":      rule.UserRole,
+			"permission":    rule.Permission,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert ArtifactAccessRule: %w", err)
+	}
+	if result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.ArtifactAccessRule]); err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) {
+			switch pgError.Code {
+			case pgerrcode.UniqueViolation:
+				err = fmt.Errorf("%w: %w", apierrors.ErrConflict, err)
+			case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation:
+				err = fmt.Errorf("%w: %w", apierrors.ErrBadRequest, err)
+			}
+		}
+		return fmt.Errorf("could not insert ArtifactAccessRule: %w", err)
+	} else {
+		*rule = result
+		return nil
+	}
+}
+
+func DeleteArtifactAccessRule(ctx context.Context, artifactID, id uuid.UUID) error {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(ctx,
+		`DELETE FROM ArtifactAccessRule WHERE id = @id AND artifact_id = @artifactId`,
+		pgx.NamedArgs{"id": id, "artifactId": artifactID},
+	)
+	if err != nil {
+		return fmt.Errorf("could not delete ArtifactAccessRule: %w", err)
+	} else if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("could not delete ArtifactAccessRule: %w", apierrors.ErrNotFound)
+	}
+	return nil
+}
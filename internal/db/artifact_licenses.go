@@ -16,7 +16,7 @@ import (
 
 const (
 	artifactLicenseOutExpr = `al.id, al.created_at, al.name, al.expires_at, ` +
-		`al.customer_organization_id, al.organization_id `
+		`al.customer_organization_id, al.organization_id, al.max_pulls, al.pull_limit_period `
 	artifactSelectionsOutExpor = `
 		(
 			SELECT array_agg(DISTINCT row(
@@ -56,9 +56,9 @@ func CreateArtifactLicense(ctx context.Context, license *types.ArtifactLicenseBa
 	rows, err := db.Query(ctx, `
 		WITH inserted AS (
 			INSERT INTO ArtifactLicense (
-				name, expires_at, organization_id, customer_organization_id
+				name, expires_at, organization_id, customer_organization_id, max_pulls, pull_limit_period
 			) VALUES (
-				@name, @expiresAt, @organizationId, @customerOrganizationId
+				@name, @expiresAt, @organizationId, @customerOrganizationId, @maxPulls, @pullLimitPeriod
 			) RETURNING *
 		)
 		SELECT `+artifactLicenseOutExpr+`
@@ -68,6 +68,8 @@ func CreateArtifactLicense(ctx context.Context, license *types.ArtifactLicenseBa
 			"expiresAt":              license.ExpiresAt,
 			"organizationId":         license.OrganizationID,
 			"customerOrganizationId": license.CustomerOrganizationID,
+			"maxPulls":               license.MaxPulls,
+			"pullLimitPeriod":        license.PullLimitPeriod,
 		},
 	)
 	if err != nil {
@@ -92,7 +94,9 @@ func UpdateArtifactLicense(ctx context.Context, license *types.ArtifactLicenseBa
 			UPDATE ArtifactLicense SET
 			name = @name,
             expires_at = @expiresAt,
-            customer_organization_id = @customerOrganizationId
+            customer_organization_id = @customerOrganizationId,
+			max_pulls = @maxPulls,
+			pull_limit_period = @pullLimitPeriod
 		 	WHERE id = @id RETURNING *
 		)
 		SELECT `+artifactLicenseOutExpr+`
@@ -102,6 +106,8 @@ func UpdateArtifactLicense(ctx context.Context, license *types.ArtifactLicenseBa
 			"name":                   license.Name,
 			"expiresAt":              license.ExpiresAt,
 			"customerOrganizationId": license.CustomerOrganizationID,
+			"maxPulls":               license.MaxPulls,
+			"pullLimitPeriod":        license.PullLimitPeriod,
 		},
 	)
 	if err != nil {
@@ -139,6 +145,44 @@ func RemoveAllArtifactsFromLicense(
 	}
 }
 
+// GetArtifactLicensesByArtifactID returns all licenses that grant access to the given artifact or any of its versions.
+func GetArtifactLicensesByArtifactID(ctx context.Context, artifactID uuid.UUID) ([]types.ArtifactLicenseBase, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT `+artifactLicenseOutExpr+`
+		FROM ArtifactLicense al
+		WHERE EXISTS (
+			SELECT 1 FROM ArtifactLicense_Artifact ala
+			WHERE ala.artifact_license_id = al.id AND ala.artifact_id = @artifactId
+		)
+		ORDER BY al.name`,
+		pgx.NamedArgs{"artifactId": artifactID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactLicense: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactLicenseBase])
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactLicense: %w", err)
+	}
+	return result, nil
+}
+
+// RemoveArtifactFromAllLicenses revokes access to the given artifact from all licenses.
+// The licenses themselves are kept, including any other artifacts they grant access to.
+func RemoveArtifactFromAllLicenses(ctx context.Context, artifactID uuid.UUID) error {
+	db := internalctx.GetDb(ctx)
+	_, err := db.Exec(
+		ctx,
+		`DELETE FROM ArtifactLicense_Artifact WHERE artifact_id = @artifactId`,
+		pgx.NamedArgs{"artifactId": artifactID},
+	)
+	if err != nil {
+		return fmt.Errorf("could not delete relation: %w", err)
+	}
+	return nil
+}
+
 func AddArtifactToArtifactLicense(
 	ctx context.Context,
 	licenseID uuid.UUID,
@@ -245,3 +289,55 @@ func DeleteArtifactLicensesWithOrganizationSubscriptionType(
 
 	return cmd.RowsAffected(), nil
 }
+
+// GetCustomerOrganizationsWithAccessToArtifact returns all customer organizations of the organization that can pull
+// the given artifact because of a license that has not expired, ordered by name. If the organization has no artifact
+// licenses at all, pulls are not restricted, so every customer organization is returned.
+func GetCustomerOrganizationsWithAccessToArtifact(
+	ctx context.Context,
+	orgID, artifactID uuid.UUID,
+) ([]types.ArtifactCustomerAccess, error) {
+	hasLicenses, err := HasAnyArtifactLicense(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		WITH access AS (
+			SELECT al.customer_organization_id,
+				bool_or(ala.artifact_version_id IS NULL) AS all_versions,
+				coalesce(array_agg(DISTINCT ala.artifact_version_id)
+					FILTER (WHERE ala.artifact_version_id IS NOT NULL), ARRAY[]::UUID[]) AS version_ids
+			FROM ArtifactLicense al
+			JOIN ArtifactLicense_Artifact ala ON ala.artifact_license_id = al.id
+			WHERE al.organization_id = @orgId
+			AND ala.artifact_id = @artifactId
+			AND al.customer_organization_id IS NOT NULL
+			AND (al.expires_at IS NULL OR al.expires_at > now())
+			GROUP BY al.customer_organization_id
+		)
+		SELECT `+customerOrganizationOutputExpr+`,
+			coalesce(access.all_versions, true) AS all_versions,
+			CASE WHEN coalesce(access.all_versions, true) THEN ARRAY[]::UUID[] ELSE access.version_ids END
+				AS version_ids
+		FROM CustomerOrganization co
+		LEFT JOIN access ON access.customer_organization_id = co.id
+		WHERE co.organization_id = @orgId
+		AND (NOT @hasLicenses OR access.customer_organization_id IS NOT NULL)
+		ORDER BY co.name`,
+		pgx.NamedArgs{
+			"orgId":       orgID,
+			"artifactId":  artifactID,
+			"hasLicenses": hasLicenses,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query CustomerOrganization: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactCustomerAccess])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect CustomerOrganization: %w", err)
+	}
+	return result, nil
+}
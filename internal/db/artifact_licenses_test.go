@@ -0,0 +1,100 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestGetCustomerOrganizationsWithAccessToArtifact checks which customer organizations have access to an artifact
+// before and after licenses are created.
+func TestGetCustomerOrganizationsWithAccessToArtifact(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	createArtifact := func(name string) (types.Artifact, []types.ArtifactVersion) {
+		artifact := dbtest.CreateArtifact(t, ctx, org.ID, name)
+		var versions []types.ArtifactVersion
+		for _, tag := range []string{"1.0.0", "2.0.0"} {
+			versions = append(versions, dbtest.CreateArtifactVersion(
+				t, ctx, artifact.ID, tag, digest.FromString(name+tag), imgspecv1.MediaTypeImageManifest))
+		}
+		return artifact, versions
+	}
+	artifact, versions := createArtifact("access/app")
+	otherArtifact, _ := createArtifact("access/other")
+
+	customerOrgs := map[string]types.CustomerOrganization{}
+	for _, name := range []string{"All", "Expired", "Mixed", "None", "Other", "Versions"} {
+		customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: name}
+		g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+		customerOrgs[name] = customerOrg
+	}
+
+	t.Run("without licenses", func(t *testing.T) {
+		g := NewWithT(t)
+		access, err := db.GetCustomerOrganizationsWithAccessToArtifact(ctx, org.ID, artifact.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(access).To(HaveLen(len(customerOrgs)))
+		for _, a := range access {
+			g.Expect(a.AllVersions).To(BeTrue())
+			g.Expect(a.VersionIDs).To(BeEmpty())
+		}
+	})
+
+	license := func(customerOrg string, expiresAt *time.Time, artifactID uuid.UUID, versionIDs ...uuid.UUID) {
+		l := types.ArtifactLicenseBase{
+			Name:                   fmt.Sprintf("%v %v", customerOrg, uuid.New()),
+			ExpiresAt:              expiresAt,
+			OrganizationID:         org.ID,
+			CustomerOrganizationID: util.PtrTo(customerOrgs[customerOrg].ID),
+		}
+		g.Expect(db.CreateArtifactLicense(ctx, &l)).To(Succeed())
+		if len(versionIDs) == 0 {
+			g.Expect(db.AddArtifactToArtifactLicense(ctx, l.ID, artifactID, nil)).To(Succeed())
+		}
+		for _, versionID := range versionIDs {
+			g.Expect(db.AddArtifactToArtifactLicense(ctx, l.ID, artifactID, &versionID)).To(Succeed())
+		}
+	}
+	license("All", util.PtrTo(time.Now().Add(time.Hour)), artifact.ID)
+	license("Expired", util.PtrTo(time.Now().Add(-time.Hour)), artifact.ID)
+	license("Mixed", nil, artifact.ID, versions[0].ID)
+	license("Mixed", nil, artifact.ID)
+	license("Other", nil, otherArtifact.ID)
+	license("Versions", nil, artifact.ID, versions[0].ID)
+	license("Versions", nil, artifact.ID, versions[1].ID)
+
+	t.Run("with licenses", func(t *testing.T) {
+		g := NewWithT(t)
+		access, err := db.GetCustomerOrganizationsWithAccessToArtifact(ctx, org.ID, artifact.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(access).To(HaveLen(3))
+		g.Expect(access[0].ID).To(Equal(customerOrgs["All"].ID))
+		g.Expect(access[0].AllVersions).To(BeTrue())
+		g.Expect(access[0].VersionIDs).To(BeEmpty())
+		g.Expect(access[1].ID).To(Equal(customerOrgs["Mixed"].ID))
+		g.Expect(access[1].AllVersions).To(BeTrue())
+		g.Expect(access[1].VersionIDs).To(BeEmpty())
+		g.Expect(access[2].ID).To(Equal(customerOrgs["Versions"].ID))
+		g.Expect(access[2].AllVersions).To(BeFalse())
+		g.Expect(access[2].VersionIDs).To(ConsistOf(versions[0].ID, versions[1].ID))
+	})
+
+	t.Run("other organization", func(t *testing.T) {
+		g := NewWithT(t)
+		access, err := db.GetCustomerOrganizationsWithAccessToArtifact(ctx, uuid.New(), artifact.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(access).To(BeEmpty())
+	})
+}
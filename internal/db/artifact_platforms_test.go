@@ -0,0 +1,85 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestGetArtifactVersionForPlatform checks that the manifest of an index is resolved by OS, architecture and optional
+// variant.
+func TestGetArtifactVersionForPlatform(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	artifact := dbtest.CreateArtifact(t, ctx, org.ID, "platform/app")
+
+	createVersion := func(d digest.Digest, contentType string) types.ArtifactVersion {
+		return dbtest.CreateArtifactVersion(t, ctx, artifact.ID, d.String(), d, contentType)
+	}
+	amd64 := createVersion(digest.FromString("amd64"), imgspecv1.MediaTypeImageManifest)
+	armV7 := createVersion(digest.FromString("arm-v7"), imgspecv1.MediaTypeImageManifest)
+	armV6 := createVersion(digest.FromString("arm-v6"), imgspecv1.MediaTypeImageManifest)
+	index := createVersion(digest.FromString("index"), imgspecv1.MediaTypeImageIndex)
+	g.Expect(db.CreateArtifactVersionPlatforms(ctx, index.ID, []types.ArtifactVersionPlatform{
+		{Digest: amd64.ManifestBlobDigest, OS: "linux", Architecture: "amd64"},
+		{Digest: armV7.ManifestBlobDigest, OS: "linux", Architecture: "arm", Variant: "v7"},
+		{Digest: armV6.ManifestBlobDigest, OS: "linux", Architecture: "arm", Variant: "v6"},
+		// the registry does not require the manifests of an index to be pushed
+		{Digest: types.Digest(digest.FromString("missing")), OS: "windows", Architecture: "amd64"},
+	})).To(Succeed())
+
+	t.Run("match", func(t *testing.T) {
+		g := NewWithT(t)
+		version, platform, err := db.GetArtifactVersionForPlatform(
+			ctx, artifact.ID, index.ManifestBlobDigest, "linux", "amd64", "")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(version.ID).To(Equal(amd64.ID))
+		g.Expect(*platform).To(Equal(types.ArtifactVersionPlatform{
+			Digest: amd64.ManifestBlobDigest, OS: "linux", Architecture: "amd64",
+		}))
+	})
+
+	t.Run("variant", func(t *testing.T) {
+		g := NewWithT(t)
+		version, platform, err := db.GetArtifactVersionForPlatform(
+			ctx, artifact.ID, index.ManifestBlobDigest, "linux", "arm", "v7")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(version.ID).To(Equal(armV7.ID))
+		g.Expect(platform.Variant).To(Equal("v7"))
+	})
+
+	t.Run("any variant", func(t *testing.T) {
+		g := NewWithT(t)
+		version, _, err := db.GetArtifactVersionForPlatform(
+			ctx, artifact.ID, index.ManifestBlobDigest, "linux", "arm", "")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(version.ID).To(Equal(armV6.ID))
+	})
+
+	for _, tc := range []struct{ name, os, architecture, variant string }{
+		{"unknown architecture", "linux", "s390x", ""},
+		{"unknown variant", "linux", "arm", "v5"},
+		{"manifest not pushed", "windows", "amd64", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, _, err := db.GetArtifactVersionForPlatform(
+				ctx, artifact.ID, index.ManifestBlobDigest, tc.os, tc.architecture, tc.variant)
+			g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+		})
+	}
+
+	t.Run("not an index", func(t *testing.T) {
+		g := NewWithT(t)
+		_, _, err := db.GetArtifactVersionForPlatform(ctx, artifact.ID, amd64.ManifestBlobDigest, "linux", "amd64", "")
+		g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+	})
+}
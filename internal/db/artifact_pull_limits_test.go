@@ -0,0 +1,178 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestCheckPullLimitForArtifact checks the pull limit periods, the combination of several licenses and which pulls
+// count towards a limit.
+func TestCheckPullLimitForArtifact(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, app := dbtest.CreateOrgWithArtifact(t, ctx, "limit/app")
+	createVersion := func(artifact types.Artifact, name, contentType string) types.ArtifactVersion {
+		return dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, name, digest.FromString(artifact.Name+name), contentType)
+	}
+	appVersion := createVersion(app, "1.0.0", imgspecv1.MediaTypeImageManifest)
+	other := dbtest.CreateArtifact(t, ctx, org.ID, "limit/other")
+	otherVersion := createVersion(other, "1.0.0", imgspecv1.MediaTypeImageManifest)
+
+	customerOrg := func() types.CustomerOrganization {
+		customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: uuid.NewString()}
+		g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+		return customerOrg
+	}
+	license := func(
+		customerOrg types.CustomerOrganization,
+		maxPulls *int,
+		period *types.PullLimitPeriod,
+		expiresAt *time.Time,
+		artifacts ...types.Artifact,
+	) {
+		l := types.ArtifactLicenseBase{
+			Name:                   uuid.NewString(),
+			ExpiresAt:              expiresAt,
+			OrganizationID:         org.ID,
+			CustomerOrganizationID: &customerOrg.ID,
+			MaxPulls:               maxPulls,
+			PullLimitPeriod:        period,
+		}
+		g.Expect(db.CreateArtifactLicense(ctx, &l)).To(Succeed())
+		for _, artifact := range artifacts {
+			g.Expect(db.AddArtifactToArtifactLicense(ctx, l.ID, artifact.ID, nil)).To(Succeed())
+		}
+	}
+	pull := func(customerOrg types.CustomerOrganization, version types.ArtifactVersion, pulledAt time.Time, count int) {
+		for range count {
+			g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", &customerOrg.ID, pulledAt)).
+				To(Succeed())
+		}
+	}
+	check := func(customerOrg types.CustomerOrganization, artifact types.Artifact) error {
+		return db.CheckPullLimitForArtifact(ctx, *org.Slug, artifact.Name, customerOrg.ID)
+	}
+	day := util.PtrTo(types.PullLimitPeriodDay)
+	unlimited := (*types.PullLimitPeriod)(nil)
+
+	t.Run("without limit", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		g.Expect(check(c, app)).To(Succeed())
+		license(c, nil, unlimited, nil, app)
+		pull(c, appVersion, time.Now(), 5)
+		g.Expect(check(c, app)).To(Succeed())
+	})
+
+	for _, tc := range []struct {
+		period   types.PullLimitPeriod
+		previous time.Duration
+	}{
+		{types.PullLimitPeriodDay, 25 * time.Hour},
+		{types.PullLimitPeriodWeek, 8 * 24 * time.Hour},
+		{types.PullLimitPeriodMonth, 32 * 24 * time.Hour},
+		{types.PullLimitPeriodYear, 367 * 24 * time.Hour},
+	} {
+		t.Run(fmt.Sprintf("period %v", tc.period), func(t *testing.T) {
+			g := NewWithT(t)
+			c := customerOrg()
+			license(c, util.PtrTo(2), &tc.period, nil, app)
+			pull(c, appVersion, time.Now().Add(-tc.previous), 5)
+			g.Expect(check(c, app)).To(Succeed())
+			pull(c, appVersion, time.Now(), 1)
+			g.Expect(check(c, app)).To(Succeed())
+			pull(c, appVersion, time.Now(), 1)
+			g.Expect(check(c, app)).To(MatchError(apierrors.ErrPullLimitExceeded))
+		})
+	}
+
+	t.Run("limited and unlimited license", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		license(c, nil, unlimited, nil, app)
+		pull(c, appVersion, time.Now(), 2)
+		g.Expect(check(c, app)).To(Succeed())
+	})
+
+	t.Run("two limited licenses", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		license(c, util.PtrTo(3), day, nil, app)
+		pull(c, appVersion, time.Now(), 2)
+		g.Expect(check(c, app)).To(Succeed())
+		pull(c, appVersion, time.Now(), 1)
+		g.Expect(check(c, app)).To(MatchError(apierrors.ErrPullLimitExceeded))
+	})
+
+	t.Run("expired unlimited license", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		license(c, nil, unlimited, util.PtrTo(time.Now().Add(-time.Hour)), app)
+		pull(c, appVersion, time.Now(), 1)
+		g.Expect(check(c, app)).To(MatchError(apierrors.ErrPullLimitExceeded))
+	})
+
+	t.Run("only pulls of licensed artifacts count", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		license(c, nil, unlimited, nil, other)
+		pull(c, otherVersion, time.Now(), 3)
+		g.Expect(check(c, app)).To(Succeed())
+		g.Expect(check(c, other)).To(Succeed())
+		pull(c, appVersion, time.Now(), 1)
+		g.Expect(check(c, app)).To(MatchError(apierrors.ErrPullLimitExceeded))
+	})
+
+	t.Run("pulls of other customer organizations do not count", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		pull(customerOrg(), appVersion, time.Now(), 3)
+		g.Expect(check(c, app)).To(Succeed())
+	})
+
+	t.Run("platform manifests of an index do not count", func(t *testing.T) {
+		g := NewWithT(t)
+		multi := dbtest.CreateArtifact(t, ctx, org.ID, "limit/multi")
+		amd64 := createVersion(multi, "amd64", imgspecv1.MediaTypeImageManifest)
+		index := createVersion(multi, "1.0.0", imgspecv1.MediaTypeImageIndex)
+		g.Expect(db.CreateArtifactVersionPlatforms(ctx, index.ID, []types.ArtifactVersionPlatform{
+			{Digest: amd64.ManifestBlobDigest, OS: "linux", Architecture: "amd64"},
+		})).To(Succeed())
+
+		c := customerOrg()
+		license(c, util.PtrTo(2), day, nil, multi)
+		pull(c, index, time.Now(), 1)
+		pull(c, amd64, time.Now(), 3)
+		g.Expect(check(c, multi)).To(Succeed())
+		pull(c, index, time.Now(), 1)
+		g.Expect(check(c, multi)).To(MatchError(apierrors.ErrPullLimitExceeded))
+	})
+
+	t.Run("ensure for insert", func(t *testing.T) {
+		g := NewWithT(t)
+		c := customerOrg()
+		license(c, util.PtrTo(1), day, nil, app)
+		g.Expect(db.EnsurePullLimitForInsert(ctx, *org.Slug, app.Name, c.ID)).To(Succeed())
+		pull(c, appVersion, time.Now(), 1)
+		g.Expect(db.EnsurePullLimitForInsert(ctx, *org.Slug, app.Name, c.ID)).
+			To(MatchError(apierrors.ErrPullLimitExceeded))
+	})
+}
@@ -0,0 +1,214 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type pullStatsFixture struct {
+	org       types.Organization
+	customerA types.CustomerOrganization
+	customerB types.CustomerOrganization
+	artifactA types.Artifact
+	artifactB types.Artifact
+	artifactC types.Artifact
+	from, to  time.Time
+}
+
+// seedPullStatsFixture creates an organization with two customer organizations and the following pulls between from
+// and to:
+//   - stats/a: 3 pulls by customer A, 1 pull by customer B
+//   - stats/b: 2 pulls by a vendor user, 1 pull by customer A
+//   - stats/c: 1 pull by customer B
+//
+// Additionally, stats/c is pulled by customer A before from and at to, and a deleted artifact is pulled by customer B.
+func seedPullStatsFixture(t *testing.T, ctx context.Context) pullStatsFixture {
+	g := NewWithT(t)
+	f := pullStatsFixture{to: time.Now().UTC().Add(-time.Minute).Truncate(time.Second)}
+	f.from = f.to.Add(-24 * time.Hour)
+
+	f.org = dbtest.CreateOrganization(t, ctx)
+	user := dbtest.CreateUserAccount(t, ctx)
+	f.customerA = types.CustomerOrganization{OrganizationID: f.org.ID, Name: "Customer A"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &f.customerA)).To(Succeed())
+	f.customerB = types.CustomerOrganization{OrganizationID: f.org.ID, Name: "Customer B"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &f.customerB)).To(Succeed())
+
+	createVersion := func(artifact types.Artifact) types.ArtifactVersion {
+		return dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, "1.0.0", digest.FromString(artifact.Name), imgspecv1.MediaTypeImageManifest)
+	}
+	pull := func(version types.ArtifactVersion, customerOrgID *uuid.UUID, pulledAt time.Time, count int) {
+		for range count {
+			g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", customerOrgID, pulledAt)).
+				To(Succeed())
+		}
+	}
+
+	f.artifactA = dbtest.CreateArtifact(t, ctx, f.org.ID, "stats/a")
+	versionA := createVersion(f.artifactA)
+	f.artifactB = dbtest.CreateArtifact(t, ctx, f.org.ID, "stats/b")
+	versionB := createVersion(f.artifactB)
+	f.artifactC = dbtest.CreateArtifact(t, ctx, f.org.ID, "stats/c")
+	versionC := createVersion(f.artifactC)
+	deleted := dbtest.CreateArtifact(t, ctx, f.org.ID, "stats/deleted")
+	versionDeleted := createVersion(deleted)
+
+	pull(versionA, &f.customerA.ID, f.from, 2)
+	pull(versionA, &f.customerA.ID, f.to.Add(-time.Hour), 1)
+	pull(versionA, &f.customerB.ID, f.from.Add(time.Hour), 1)
+	pull(versionB, nil, f.from.Add(2*time.Hour), 2)
+	pull(versionB, &f.customerA.ID, f.from.Add(3*time.Hour), 1)
+	pull(versionC, &f.customerB.ID, f.to.Add(-time.Second), 1)
+	pull(versionC, &f.customerA.ID, f.from.Add(-time.Second), 5)
+	pull(versionC, &f.customerA.ID, f.to, 5)
+	pull(versionDeleted, &f.customerB.ID, f.from.Add(time.Hour), 5)
+	_, err := db.DeleteArtifactWithID(ctx, deleted.ID, user.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return f
+}
+
+// TestGetTopArtifactsByPulls checks the ranking, the period bounds and the customer organization filter of the top
+// artifacts.
+func TestGetTopArtifactsByPulls(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	f := seedPullStatsFixture(t, ctx)
+
+	t.Run("all pulls", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetTopArtifactsByPulls(ctx, f.org.ID, nil, f.from, f.to, 10)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(Equal([]types.ArtifactPullStats{
+			{ArtifactID: f.artifactA.ID, ArtifactName: "stats/a", PullsTotal: 4, DistinctPullers: 2},
+			{ArtifactID: f.artifactB.ID, ArtifactName: "stats/b", PullsTotal: 3, DistinctPullers: 2},
+			{ArtifactID: f.artifactC.ID, ArtifactName: "stats/c", PullsTotal: 1, DistinctPullers: 1},
+		}))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetTopArtifactsByPulls(ctx, f.org.ID, nil, f.from, f.to, 2)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(HaveLen(2))
+		g.Expect(stats[0].ArtifactID).To(Equal(f.artifactA.ID))
+		g.Expect(stats[1].ArtifactID).To(Equal(f.artifactB.ID))
+	})
+
+	t.Run("customer organization", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetTopArtifactsByPulls(ctx, f.org.ID, &f.customerA.ID, f.from, f.to, 10)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(Equal([]types.ArtifactPullStats{
+			{ArtifactID: f.artifactA.ID, ArtifactName: "stats/a", PullsTotal: 3, DistinctPullers: 1},
+			{ArtifactID: f.artifactB.ID, ArtifactName: "stats/b", PullsTotal: 1, DistinctPullers: 1},
+		}))
+	})
+
+	t.Run("other organization", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetTopArtifactsByPulls(ctx, uuid.New(), nil, f.from, f.to, 10)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(BeEmpty())
+	})
+}
+
+// TestGetPullsGroupedByCustomerOrganization checks the totals per customer organization, the period bounds, the
+// filter and the limit. Pulls of deleted artifacts are still counted.
+func TestGetPullsGroupedByCustomerOrganization(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	f := seedPullStatsFixture(t, ctx)
+
+	t.Run("all customer organizations", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetPullsGroupedByCustomerOrganization(ctx, f.org.ID, nil, f.from, f.to, 0)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(HaveLen(2))
+		g.Expect(stats[0].CustomerOrganizationID).To(Equal(f.customerB.ID))
+		g.Expect(stats[0].CustomerOrganizationName).To(Equal("Customer B"))
+		g.Expect(stats[0].PullsTotal).To(Equal(7))
+		g.Expect(stats[0].DistinctArtifacts).To(Equal(3))
+		g.Expect(stats[0].LastPulledAt).To(BeTemporally("==", f.to.Add(-time.Second)))
+		g.Expect(stats[1].CustomerOrganizationID).To(Equal(f.customerA.ID))
+		g.Expect(stats[1].PullsTotal).To(Equal(4))
+		g.Expect(stats[1].DistinctArtifacts).To(Equal(2))
+		g.Expect(stats[1].LastPulledAt).To(BeTemporally("==", f.to.Add(-time.Hour)))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetPullsGroupedByCustomerOrganization(ctx, f.org.ID, nil, f.from, f.to, 1)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(HaveLen(1))
+		g.Expect(stats[0].CustomerOrganizationID).To(Equal(f.customerB.ID))
+	})
+
+	t.Run("customer organization", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetPullsGroupedByCustomerOrganization(ctx, f.org.ID, &f.customerA.ID, f.from, f.to, 0)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(HaveLen(1))
+		g.Expect(stats[0].CustomerOrganizationID).To(Equal(f.customerA.ID))
+		g.Expect(stats[0].PullsTotal).To(Equal(4))
+	})
+
+	t.Run("period without pulls", func(t *testing.T) {
+		g := NewWithT(t)
+		stats, err := db.GetPullsGroupedByCustomerOrganization(ctx, f.org.ID, nil, f.to.Add(time.Second), time.Now(), 0)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stats).To(BeEmpty())
+	})
+}
+
+// TestGetPullCountsOverTime checks that every bucket of the period is returned, including empty ones, and that pulls
+// are counted in the bucket they fall into.
+func TestGetPullCountsOverTime(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	f := seedPullStatsFixture(t, ctx)
+
+	counts := func(buckets []types.PullCountBucket) map[int]int {
+		result := map[int]int{}
+		for i, bucket := range buckets {
+			if bucket.PullsTotal > 0 {
+				result[i] = bucket.PullsTotal
+			}
+		}
+		return result
+	}
+
+	t.Run("all pulls", func(t *testing.T) {
+		g := NewWithT(t)
+		buckets, err := db.GetPullCountsOverTime(ctx, f.org.ID, nil, f.from, f.to, time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(buckets).To(HaveLen(24))
+		for i, bucket := range buckets {
+			g.Expect(bucket.Start).To(BeTemporally("==", f.from.Add(time.Duration(i)*time.Hour)))
+		}
+		g.Expect(counts(buckets)).To(Equal(map[int]int{0: 2, 1: 6, 2: 2, 3: 1, 23: 2}))
+	})
+
+	t.Run("customer organization", func(t *testing.T) {
+		g := NewWithT(t)
+		buckets, err := db.GetPullCountsOverTime(ctx, f.org.ID, &f.customerA.ID, f.from, f.to, time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(buckets).To(HaveLen(24))
+		g.Expect(counts(buckets)).To(Equal(map[int]int{0: 2, 3: 1, 23: 1}))
+	})
+
+	t.Run("partial last bucket", func(t *testing.T) {
+		g := NewWithT(t)
+		buckets, err := db.GetPullCountsOverTime(ctx, f.org.ID, nil, f.from, f.to, 10*time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(buckets).To(HaveLen(3))
+		g.Expect(counts(buckets)).To(Equal(map[int]int{0: 11, 2: 2}))
+	})
+}
@@ -0,0 +1,57 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestGetArtifactVersionPullsClusteredTimestamps pages through pulls that mostly share the same timestamp and checks
+// that the keyset cursor returns every pull exactly once and in order.
+func TestGetArtifactVersionPullsClusteredTimestamps(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "pulls/app")
+	version := dbtest.CreateArtifactVersion(
+		t, ctx, artifact.ID, "1.0.0", digest.FromString("pulls"), imgspecv1.MediaTypeImageManifest)
+
+	ts := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for range 7 {
+		g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", nil, ts)).To(Succeed())
+	}
+	g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", nil, ts.Add(-time.Second))).To(Succeed())
+
+	all, err := db.GetArtifactVersionPulls(ctx, org.ID, 100, time.Now(), nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(all).To(HaveLen(8))
+
+	var seen []uuid.UUID
+	before := time.Now()
+	var beforeID *uuid.UUID
+	for range len(all) {
+		pulls, err := db.GetArtifactVersionPulls(ctx, org.ID, 3, before, beforeID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(pulls).To(HaveLen(min(3, len(all)-len(seen))))
+		for _, p := range pulls {
+			seen = append(seen, p.ID)
+		}
+		if len(pulls) < 3 {
+			break
+		}
+		// like the UI, continue after the last pull of the page
+		last := pulls[len(pulls)-1]
+		before, beforeID = last.CreatedAt, &last.ID
+	}
+
+	g.Expect(seen).To(HaveLen(len(all)))
+	for i, p := range all {
+		g.Expect(seen[i]).To(Equal(p.ID))
+	}
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	artifactPushWebhookOutputExpr = `w.id, w.created_at, w.organization_id, w.url, w.secret, w.artifact_name_filter`
+
+	artifactPushWebhookDeliveryOutputExpr = `
+		d.id,
+		d.created_at,
+		d.webhook_id,
+		d.payload,
+		d.attempts,
+		d.next_attempt_at,
+		d.last_attempt_at,
+		d.last_status_code,
+		d.last_error,
+		d.delivered_at `
+)
+
+func GetArtifactPushWebhooks(ctx context.Context, orgID uuid.UUID) ([]types.ArtifactPushWebhook, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT `+artifactPushWebhookOutputExpr+`
+		FROM ArtifactPushWebhook w
+		WHERE w.organization_id = @orgId
+		ORDER BY w.created_at`,
+		pgx.NamedArgs{"orgId": orgID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactPushWebhook: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactPushWebhook])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactPushWebhook: %w", err)
+	}
+	return result, nil
+}
+
+func CreateArtifactPushWebhook(ctx context.Context, webhook *types.ArtifactPushWebhook) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`INSERT INTO ArtifactPushWebhook AS w (organization_id, url, secret, artifact_name_filter)
+		VALUES (@orgId, @url, @secret, @artifactNameFilter)
+		RETURNING `+artifactPushWebhookOutputExpr,
+		pgx.NamedArgs{
+			"orgId":              webhook.OrganizationID,
+			"url":                webhook.URL,
+			"secret":             webhook.Secret,
+			"artifactNameFilter": webhook.ArtifactNameFilter,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert ArtifactPushWebhook: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.ArtifactPushWebhook])
+	if err != nil {
+		return fmt.Errorf("could not insert ArtifactPushWebhook: %w", err)
+	}
+	*webhook = result
+	return nil
+}
+
+// UpdateArtifactPushWebhook updates the URL and filter of the given webhook. The secret is only replaced if it is not
+// empty. It returns an error wrapping apierrors.ErrNotFound if the organization has no such webhook.
+func UpdateArtifactPushWebhook(ctx context.Context, webhook *types.ArtifactPushWebhook) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`UPDATE ArtifactPushWebhook AS w SET
+			url = @url,
+			secret = coalesce(nullif(@secret, ''), w.secret),
+			artifact_name_filter = @artifactNameFilter
+		WHERE w.id = @id AND w.organization_id = @orgId
+		RETURNING `+artifactPushWebhookOutputExpr,
+		pgx.NamedArgs{
+			"id":                 webhook.ID,
+			"orgId":              webhook.OrganizationID,
+			"url":                webhook.URL,
+			"secret":             webhook.Secret,
+			"artifactNameFilter": webhook.ArtifactNameFilter,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not update ArtifactPushWebhook: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.ArtifactPushWebhook])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apierrors.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("could not update ArtifactPushWebhook: %w", err)
+	}
+	*webhook = result
+	return nil
+}
+
+func DeleteArtifactPushWebhook(ctx context.Context, id, orgID uuid.UUID) error {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(
+		ctx,
+		`DELETE FROM ArtifactPushWebhook WHERE id = @id AND organization_id = @orgId`,
+		pgx.NamedArgs{"id": id, "orgId": orgID},
+	)
+	if err == nil && cmd.RowsAffected() == 0 {
+		err = apierrors.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete ArtifactPushWebhook: %w", err)
+	}
+	return nil
+}
+
+// CreateArtifactPushWebhookDelivery queues the delivery of the given payload to the webhook. It is due immediately.
+func CreateArtifactPushWebhookDelivery(ctx context.Context, webhookID uuid.UUID, payload []byte) error {
+	db := internalctx.GetDb(ctx)
+	_, err := db.Exec(
+		ctx,
+		`INSERT INTO ArtifactPushWebhookDelivery (webhook_id, payload) VALUES (@webhookId, @payload)`,
+		pgx.NamedArgs{"webhookId": webhookID, "payload": payload},
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert ArtifactPushWebhookDelivery: %w", err)
+	}
+	return nil
+}
+
+// GetArtifactPushWebhookDeliveries returns the most recent deliveries of the given webhook, newest first.
+func GetArtifactPushWebhookDeliveries(
+	ctx context.Context,
+	webhookID, orgID uuid.UUID,
+	limit int,
+) ([]types.ArtifactPushWebhookDelivery, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT `+artifactPushWebhookDeliveryOutputExpr+`
+		FROM ArtifactPushWebhookDelivery d
+		JOIN ArtifactPushWebhook w ON w.id = d.webhook_id
+		WHERE d.webhook_id = @webhookId AND w.organization_id = @orgId
+		ORDER BY d.created_at DESC
+		LIMIT @limit`,
+		pgx.NamedArgs{"webhookId": webhookID, "orgId": orgID, "limit": limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactPushWebhookDelivery: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactPushWebhookDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactPushWebhookDelivery: %w", err)
+	}
+	return result, nil
+}
+
+// ClaimDueArtifactPushWebhookDeliveries returns up to limit deliveries that are due and postpones their next attempt
+// by lease. This way, every delivery is only attempted by one replica at a time, and deliveries that are claimed by a
+// replica that crashes before recording the attempt are retried once the lease has expired.
+func ClaimDueArtifactPushWebhookDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]types.ArtifactPushWebhookDeliveryWithTarget, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`WITH claimed AS (
+			UPDATE ArtifactPushWebhookDelivery
+			SET next_attempt_at = now() + make_interval(secs => @lease)
+			WHERE id IN (
+				SELECT id FROM ArtifactPushWebhookDelivery
+				WHERE next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT @limit
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+artifactPushWebhookDeliveryOutputExpr+`, w.url, w.secret
+		FROM claimed d
+		JOIN ArtifactPushWebhook w ON w.id = d.webhook_id
+		ORDER BY d.created_at`,
+		pgx.NamedArgs{"limit": limit, "lease": lease.Seconds()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not claim ArtifactPushWebhookDelivery: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactPushWebhookDeliveryWithTarget])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactPushWebhookDelivery: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateArtifactPushWebhookDeliveryAttempt records the result of an attempt to deliver the given delivery. If
+// nextAttemptAt is nil, no further attempts are made.
+func UpdateArtifactPushWebhookDeliveryAttempt(
+	ctx context.Context,
+	id uuid.UUID,
+	statusCode *int,
+	attemptErr error,
+	nextAttemptAt *time.Time,
+) error {
+	var lastError *string
+	if attemptErr != nil {
+		lastError = util.PtrTo(attemptErr.Error())
+	}
+	db := internalctx.GetDb(ctx)
+	_, err := db.Exec(
+		ctx,
+		`UPDATE ArtifactPushWebhookDelivery SET
+			attempts = attempts + 1,
+			last_attempt_at = now(),
+			last_status_code = @statusCode,
+			last_error = @lastError,
+			next_attempt_at = @nextAttemptAt,
+			delivered_at = CASE WHEN @delivered THEN now() END
+		WHERE id = @id`,
+		pgx.NamedArgs{
+			"id":            id,
+			"statusCode":    statusCode,
+			"lastError":     lastError,
+			"nextAttemptAt": nextAttemptAt,
+			"delivered":     attemptErr == nil,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not update ArtifactPushWebhookDelivery: %w", err)
+	}
+	return nil
+}
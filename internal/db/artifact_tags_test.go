@@ -0,0 +1,210 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestDeleteArtifactVersionsByPattern checks that only tags matching the glob are deleted and that nothing is deleted
+// if the pattern would remove the last tag of the artifact.
+func TestDeleteArtifactVersionsByPattern(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	_, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "tags/app")
+	for _, name := range []string{"1.0.0", "1.0.1", "1.1.0", "2.0.0", "latest"} {
+		dbtest.CreateArtifactVersion(t, ctx, artifact.ID, name, digest.FromString(name), imgspecv1.MediaTypeImageManifest)
+	}
+
+	tags := func() []string {
+		rows, err := internalctx.GetDb(ctx).Query(ctx,
+			`SELECT name FROM ArtifactVersion WHERE artifact_id = @artifactId AND name NOT LIKE '%:%' ORDER BY name`,
+			pgx.NamedArgs{"artifactId": artifact.ID},
+		)
+		g.Expect(err).NotTo(HaveOccurred())
+		names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		g.Expect(err).NotTo(HaveOccurred())
+		return names
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := db.DeleteArtifactVersionsByPattern(ctx, artifact.ID, "[", user.ID)
+		g.Expect(err).To(MatchError(apierrors.ErrBadRequest))
+	})
+
+	t.Run("matching tags", func(t *testing.T) {
+		g := NewWithT(t)
+		deleted, err := db.DeleteArtifactVersionsByPattern(ctx, artifact.ID, "1.0.*", user.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(deleted).To(Equal([]string{"1.0.0", "1.0.1"}))
+		g.Expect(tags()).To(Equal([]string{"1.1.0", "2.0.0", "latest"}))
+	})
+
+	t.Run("no matching tags", func(t *testing.T) {
+		g := NewWithT(t)
+		deleted, err := db.DeleteArtifactVersionsByPattern(ctx, artifact.ID, "3.*", user.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(deleted).To(BeEmpty())
+		g.Expect(tags()).To(Equal([]string{"1.1.0", "2.0.0", "latest"}))
+	})
+
+	t.Run("last tag", func(t *testing.T) {
+		g := NewWithT(t)
+		err := db.RunTx(ctx, func(ctx context.Context) error {
+			_, err := db.DeleteArtifactVersionsByPattern(ctx, artifact.ID, "*", user.ID)
+			return err
+		})
+		g.Expect(err).To(MatchError(apierrors.ErrConflict))
+		g.Expect(tags()).To(Equal([]string{"1.1.0", "2.0.0", "latest"}))
+	})
+}
+
+// TestCreateArtifactTagForDigest checks that a new tag references the manifest, parts and subject of the existing
+// version and that unknown digests and existing tags are rejected.
+func TestCreateArtifactTagForDigest(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	_, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "retag/app")
+	d := types.Digest(digest.FromString("staging"))
+	layer := types.Digest(digest.FromString("layer"))
+	subject := types.Digest(digest.FromString("subject"))
+	for _, name := range []string{string(d), "staging"} {
+		version := dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, name, digest.Digest(d), imgspecv1.MediaTypeImageManifest)
+		g.Expect(db.CreateArtifactVersionPart(ctx, &types.ArtifactVersionPart{
+			ArtifactVersionID:  version.ID,
+			ArtifactBlobDigest: layer,
+			ArtifactBlobSize:   4096,
+		})).To(Succeed())
+		g.Expect(db.SetArtifactVersionSubject(ctx, version.ID, subject)).To(Succeed())
+	}
+
+	t.Run("new tag", func(t *testing.T) {
+		g := NewWithT(t)
+		version, err := db.CreateArtifactTagForDigest(ctx, artifact.ID, "production", d, user.ID)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(version.Name).To(Equal("production"))
+		g.Expect(version.ManifestBlobDigest).To(Equal(d))
+		g.Expect(version.ManifestBlobSize).To(BeEquivalentTo(1024))
+		g.Expect(version.CreatedByUserAccountID).To(HaveValue(Equal(user.ID)))
+
+		rows, err := internalctx.GetDb(ctx).Query(ctx,
+			`SELECT artifact_blob_digest FROM ArtifactVersionPart WHERE artifact_version_id = @id`,
+			pgx.NamedArgs{"id": version.ID},
+		)
+		g.Expect(err).NotTo(HaveOccurred())
+		parts, err := pgx.CollectRows(rows, pgx.RowTo[types.Digest])
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(parts).To(Equal([]types.Digest{layer}))
+
+		var subjectDigest *types.Digest
+		g.Expect(internalctx.GetDb(ctx).QueryRow(ctx,
+			`SELECT subject_digest FROM ArtifactVersion WHERE id = @id`,
+			pgx.NamedArgs{"id": version.ID},
+		).Scan(&subjectDigest)).To(Succeed())
+		g.Expect(subjectDigest).To(HaveValue(Equal(subject)))
+	})
+
+	t.Run("unknown digest", func(t *testing.T) {
+		g := NewWithT(t)
+		err := db.RunTx(ctx, func(ctx context.Context) error {
+			_, err := db.CreateArtifactTagForDigest(ctx, artifact.ID, "unknown", types.Digest(digest.FromString("x")), user.ID)
+			return err
+		})
+		g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+	})
+
+	t.Run("existing tag", func(t *testing.T) {
+		g := NewWithT(t)
+		err := db.RunTx(ctx, func(ctx context.Context) error {
+			_, err := db.CreateArtifactTagForDigest(ctx, artifact.ID, "staging", d, user.ID)
+			return err
+		})
+		g.Expect(err).To(MatchError(apierrors.ErrConflict))
+	})
+}
+
+// TestArtifactDeletionsArePreserved checks that tag and artifact deletions are recorded and that the records keep the
+// artifact name after the artifact has been purged.
+func TestArtifactDeletionsArePreserved(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "deletion/app")
+	for _, name := range []string{"1.0.0", "latest"} {
+		dbtest.CreateArtifactVersion(t, ctx, artifact.ID, name, digest.FromString(name), imgspecv1.MediaTypeImageManifest)
+	}
+
+	g.Expect(db.DeleteArtifactVersion(ctx, artifact.ID, "1.0.0", user.ID)).To(Succeed())
+	_, err := db.DeleteArtifactWithID(ctx, artifact.ID, user.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	deletions, err := db.GetArtifactDeletions(ctx, artifact.ID, 10)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deletions).To(HaveLen(2))
+	for _, deletion := range deletions {
+		g.Expect(deletion.ArtifactID).To(HaveValue(Equal(artifact.ID)))
+		g.Expect(deletion.ArtifactName).To(Equal(artifact.Name))
+		g.Expect(deletion.DeletedByUserAccountEmail).To(HaveValue(Equal(user.Email)))
+	}
+
+	_, err = internalctx.GetDb(ctx).Exec(ctx, `DELETE FROM Artifact WHERE id = @id`, pgx.NamedArgs{"id": artifact.ID})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	rows, err := internalctx.GetDb(ctx).Query(ctx,
+		`SELECT artifact_name, tag_name FROM ArtifactDeletion WHERE organization_id = @orgId AND artifact_id IS NULL
+		ORDER BY tag_name NULLS LAST`,
+		pgx.NamedArgs{"orgId": org.ID},
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+	records, err := pgx.CollectRows(rows, pgx.RowToMap)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(records).To(Equal([]map[string]any{
+		{"artifact_name": artifact.Name, "tag_name": "1.0.0"},
+		{"artifact_name": artifact.Name, "tag_name": nil},
+	}))
+}
+
+// TestGetTagNamesForArtifactPaging checks that tags are listed in order after last and limited to the page size, which
+// the registry relies on to find out whether there is a next page.
+func TestGetTagNamesForArtifactPaging(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	artifact := dbtest.CreateArtifact(t, ctx, org.ID, "paging/app")
+	d := digest.FromString("paging")
+	for _, name := range []string{d.String(), "a", "b", "c", "d", "e"} {
+		dbtest.CreateArtifactVersion(t, ctx, artifact.ID, name, d, imgspecv1.MediaTypeImageManifest)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		last     string
+		limit    int
+		expected []string
+	}{
+		{"unlimited", "", 0, []string{"a", "b", "c", "d", "e"}},
+		{"first page", "", 3, []string{"a", "b", "c"}},
+		{"next page", "c", 3, []string{"d", "e"}},
+		{"after the last tag", "e", 3, []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			tags, err := db.GetTagNamesForArtifact(ctx, artifact.ID, nil, tt.last, tt.limit)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tags).To(Equal(tt.expected))
+		})
+	}
+}
@@ -2,22 +2,25 @@ package db
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math"
-	"slices"
+	"path"
 	"strings"
 	"time"
 
-	"github.com/containers/image/v5/manifest"
 	"github.com/distr-sh/distr/internal/apierrors"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -41,6 +44,26 @@ const (
 		", o.slug AS organization_slug," +
 		artifactDownloadsOutExpr
 
+	// artifactHasTagExpr checks that the artifact a has at least one tag. Versions that are only referenced by their
+	// digest are named after it and therefore contain a colon.
+	artifactHasTagExpr = `EXISTS (
+				SELECT avt.id
+				FROM ArtifactVersion avt
+				WHERE avt.artifact_id = a.id AND avt.name NOT LIKE '%:%'
+			)`
+
+	// artifactReadableExpr is the SQL equivalent of types.ArtifactAccessAllowed for read access.
+	artifactReadableExpr = `(
+				@accessUserId::UUID IS NULL
+				OR NOT EXISTS (SELECT r.id FROM ArtifactAccessRule r WHERE r.artifact_id = a.id)
+				OR EXISTS (
+					SELECT r.id
+					FROM ArtifactAccessRule r
+					WHERE r.artifact_id = a.id
+						AND (r.user_account_id = @accessUserId OR r.user_role = @accessUserRole)
+				)
+			)`
+
 	artifactVersionOutputExpr = `
 		v.id,
 		v.created_at,
@@ -52,10 +75,59 @@ const (
 		v.manifest_blob_size,
 		v.manifest_content_type,
 		v.manifest_data,
-		v.artifact_id `
+		v.artifact_id,
+		v.inferred_type `
+
+	// artifactVersionLicensedExpr checks that the artifact version av is visible for the user. Vendor users can see all
+	// versions, customer users only those that are licensed to their customer organization, if there are any licenses.
+	// It requires the named args isVendorUser, artifactId and customerOrgId.
+	artifactVersionLicensedExpr = `(
+			@isVendorUser
+			-- only check license if there is at least one license in this organization
+			OR NOT EXISTS (
+				SELECT al.id
+				FROM artifact a
+				JOIN ArtifactLicense al ON a.organization_id = al.organization_id
+				WHERE a.id = @artifactId
+			)
+			-- license check
+			OR EXISTS (
+				-- license for all versions of the artifact
+				SELECT *
+				FROM ArtifactLicense_Artifact ala
+				INNER JOIN ArtifactLicense al ON ala.artifact_license_id = al.id
+				WHERE ala.artifact_id = @artifactId AND ala.artifact_version_id IS NULL
+				AND al.customer_organization_id = @customerOrgId AND (al.expires_at IS NULL OR al.expires_at > now())
+			)
+			OR EXISTS (
+				-- or license only for specific versions or their parent versions
+				WITH RECURSIVE ArtifactVersionAggregate (id, manifest_blob_digest) AS (
+					SELECT avx.id, avx.manifest_blob_digest
+					FROM ArtifactVersion avx
+					WHERE avx.manifest_blob_digest = av.manifest_blob_digest AND avx.artifact_id = @artifactId
+
+					UNION ALL
+
+					SELECT DISTINCT avx.id, avx.manifest_blob_digest
+					FROM ArtifactVersion avx
+					JOIN ArtifactVersionPart avp ON avx.id = avp.artifact_version_id
+					JOIN ArtifactVersionAggregate agg ON avp.artifact_blob_digest = agg.manifest_blob_digest
+				)
+				SELECT *
+				FROM ArtifactVersionAggregate avagg
+				INNER JOIN ArtifactLicense_Artifact ala ON ala.artifact_version_id = avagg.id
+				INNER JOIN ArtifactLicense al ON ala.artifact_license_id = al.id
+				WHERE al.customer_organization_id = @customerOrgId AND (al.expires_at IS NULL OR al.expires_at > now())
+				AND ala.artifact_id = @artifactId
+			)
+		)`
 )
 
-func GetArtifactsByOrgID(ctx context.Context, orgID uuid.UUID) ([]types.ArtifactWithDownloads, error) {
+func GetArtifactsByOrgID(
+	ctx context.Context,
+	orgID uuid.UUID,
+	filter types.ArtifactListFilter,
+) ([]types.ArtifactWithDownloads, error) {
 	db := internalctx.GetDb(ctx)
 	if artifactRows, err := db.Query(ctx, `
 			SELECT `+artifactWithDownloadsOutputExpr+`
@@ -66,11 +138,27 @@ func GetArtifactsByOrgID(ctx context.Context, orgID uuid.UUID) ([]types.Artifact
 				ON a.id = av.artifact_id
 			LEFT JOIN ArtifactVersionPull avpl
 				ON avpl.artifact_version_id = av.id
-			WHERE a.organization_id = @orgId
+			WHERE a.organization_id = @orgId AND a.deleted_at IS NULL
+			AND (@nameQuery = '' OR a.name ILIKE '%' || @nameQuery || '%')
+			AND (@type = '' OR EXISTS (
+				SELECT avt.id
+				FROM ArtifactVersion avt
+				WHERE avt.artifact_id = a.id AND avt.inferred_type = @type
+			))
+			AND (NOT @taggedOnly OR `+artifactHasTagExpr+`)
+			AND `+artifactReadableExpr+`
 			GROUP BY a.id, a.created_at, a.organization_id, a.name, o.slug
-			ORDER BY max(av.created_at) DESC`,
+			`+artifactListOrderExpr(filter)+`
+			LIMIT @limit OFFSET @offset`,
 		pgx.NamedArgs{
-			"orgId": orgID,
+			"orgId":          orgID,
+			"nameQuery":      escapeLikePattern(filter.NameQuery),
+			"type":           filter.Type,
+			"taggedOnly":     filter.TaggedOnly,
+			"accessUserId":   filter.AccessUserID,
+			"accessUserRole": filter.AccessUserRole,
+			"limit":          artifactListLimit(filter),
+			"offset":         filter.Offset,
 		}); err != nil {
 		return nil, fmt.Errorf("failed to query artifacts: %w", err)
 	} else if artifacts, err := pgx.CollectRows(
@@ -82,9 +170,43 @@ func GetArtifactsByOrgID(ctx context.Context, orgID uuid.UUID) ([]types.Artifact
 	}
 }
 
-func GetArtifactsByLicenseOwnerID(ctx context.Context, orgID uuid.UUID, ownerID uuid.UUID) (
-	[]types.ArtifactWithDownloads, error,
-) {
+func artifactListOrderExpr(filter types.ArtifactListFilter) string {
+	dir := "DESC"
+	if filter.SortDir == types.SortDirectionAsc {
+		dir = "ASC"
+	}
+	switch filter.SortBy {
+	case types.ArtifactSortByName:
+		return "ORDER BY a.name " + dir + ", a.id"
+	case types.ArtifactSortByCreated:
+		return "ORDER BY a.created_at " + dir + ", a.id"
+	case types.ArtifactSortByDownloads:
+		return "ORDER BY downloads_total " + dir + ", a.name"
+	default:
+		return "ORDER BY max(av.created_at) " + dir + ", a.id"
+	}
+}
+
+// artifactListLimit returns nil if no limit was requested, which PostgreSQL treats as LIMIT ALL.
+func artifactListLimit(filter types.ArtifactListFilter) *int {
+	if filter.Limit > 0 {
+		return &filter.Limit
+	}
+	return nil
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// GetArtifactsByLicenseOwnerID returns the artifacts that are licensed to the given customer organization, restricted
+// and ordered like [GetArtifactsByOrgID].
+func GetArtifactsByLicenseOwnerID(
+	ctx context.Context,
+	orgID uuid.UUID,
+	ownerID uuid.UUID,
+	filter types.ArtifactListFilter,
+) ([]types.ArtifactWithDownloads, error) {
 	db := internalctx.GetDb(ctx)
 	if artifactRows, err := db.Query(ctx, `
 			SELECT `+artifactWithDownloadsOutputExpr+`
@@ -97,7 +219,7 @@ func GetArtifactsByLicenseOwnerID(ctx context.Context, orgID uuid.UUID, ownerID
 				ON a.id = av.artifact_id
 			LEFT JOIN ArtifactVersionPull avpl
 				ON avpl.artifact_version_id = av.id AND avpl.useraccount_id = oua.user_account_id
-			WHERE a.organization_id = @orgId
+			WHERE a.organization_id = @orgId AND a.deleted_at IS NULL
 			AND EXISTS(
 				SELECT ala.id
 				FROM ArtifactLicense_Artifact ala
@@ -105,11 +227,24 @@ func GetArtifactsByLicenseOwnerID(ctx context.Context, orgID uuid.UUID, ownerID
 				WHERE al.customer_organization_id = @ownerId AND (al.expires_at IS NULL OR al.expires_at > now())
 				AND ala.artifact_id = a.id
 			)
+			AND (@nameQuery = '' OR a.name ILIKE '%' || @nameQuery || '%')
+			AND (@type = '' OR EXISTS (
+				SELECT avt.id
+				FROM ArtifactVersion avt
+				WHERE avt.artifact_id = a.id AND avt.inferred_type = @type
+			))
+			AND (NOT @taggedOnly OR `+artifactHasTagExpr+`)
 			GROUP BY a.id, a.created_at, a.organization_id, a.name, o.slug
-			ORDER BY max(av.created_at) DESC`,
+			`+artifactListOrderExpr(filter)+`
+			LIMIT @limit OFFSET @offset`,
 		pgx.NamedArgs{
-			"orgId":   orgID,
-			"ownerId": ownerID,
+			"orgId":      orgID,
+			"ownerId":    ownerID,
+			"nameQuery":  escapeLikePattern(filter.NameQuery),
+			"type":       filter.Type,
+			"taggedOnly": filter.TaggedOnly,
+			"limit":      artifactListLimit(filter),
+			"offset":     filter.Offset,
 		}); err != nil {
 		return nil, fmt.Errorf("failed to query artifacts: %w", err)
 	} else if artifacts, err := pgx.CollectRows(
@@ -146,7 +281,7 @@ func GetArtifactByID(
 			LEFT JOIN Organization_UserAccount oua_dl
 				ON oua_dl.organization_id = a.organization_id
 					AND oua_dl.user_account_id = avpl.useraccount_id
-			WHERE a.id = @id AND a.organization_id = @orgId
+			WHERE a.id = @id AND a.organization_id = @orgId AND a.deleted_at IS NULL
 			GROUP BY a.id, a.created_at, a.organization_id, a.name, o.slug`,
 		pgx.NamedArgs{
 			"id":           artifactID,
@@ -180,7 +315,7 @@ func GetArtifactByName(ctx context.Context, orgSlug, name string) (*types.Artifa
 		`SELECT`+artifactOutputExpr+`
 			FROM Artifact a
 			JOIN Organization o on o.id = a.organization_id
-			WHERE o.slug = @orgSlug AND a.name = @name
+			WHERE o.slug = @orgSlug AND a.name = @name AND a.deleted_at IS NULL
 			ORDER BY a.name`,
 		pgx.NamedArgs{
 			"orgSlug": orgSlug,
@@ -201,11 +336,20 @@ func GetArtifactByName(ctx context.Context, orgSlug, name string) (*types.Artifa
 }
 
 func GetVersionsForArtifact(ctx context.Context, artifactID uuid.UUID, customerOrgID *uuid.UUID) (
-	[]types.TaggedArtifactVersion,
-	error,
+	result []types.TaggedArtifactVersion,
+	finalErr error,
 ) {
 	isVendorUser := customerOrgID == nil
 
+	ctx, span := startSpan(ctx, "GetVersionsForArtifact",
+		attribute.String("artifact.id", artifactID.String()),
+		attribute.Bool("license.check", !isVendorUser),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("db.rows", len(result)))
+		endSpan(span, finalErr)
+	}()
+
 	db := internalctx.GetDb(ctx)
 	if rows, err := db.Query(ctx, `
 			SELECT
@@ -213,7 +357,7 @@ func GetVersionsForArtifact(ctx context.Context, artifactID uuid.UUID, customerO
 				av.created_at,
 				av.manifest_blob_digest,
 				av.manifest_content_type,
-				av.manifest_data,
+				av.inferred_type,
 				coalesce((
 					SELECT array_agg(row (avt.id, avt.name, (
 						SELECT ROW(
@@ -236,6 +380,12 @@ func GetVersionsForArtifact(ctx context.Context, artifactID uuid.UUID, customerO
 					AND avt.artifact_id = av.artifact_id
 					AND avt.name NOT LIKE '%:%'
 				), ARRAY []::RECORD[]) AS tags,
+				coalesce((
+					SELECT array_agg(row (avpf.manifest_blob_digest, avpf.os, avpf.architecture, avpf.variant)
+						ORDER BY avpf.os, avpf.architecture, avpf.variant)
+					FROM ArtifactVersionPlatform avpf
+					WHERE avpf.artifact_version_id = av.id
+				), ARRAY []::RECORD[]) AS platforms,
 				av.manifest_blob_size + coalesce(sum(avp.artifact_blob_size), 0) AS size,
 				`+artifactDownloadsOutExpr+`
 			FROM ArtifactVersion av
@@ -262,46 +412,7 @@ func GetVersionsForArtifact(ctx context.Context, artifactID uuid.UUID, customerO
 					AND oua_dl.user_account_id = avpl.useraccount_id
 			WHERE av.artifact_id = @artifactId
 			AND av.name LIKE '%:%'
-			AND (
-				@isVendorUser
-				-- only check license if there is at least one license in this organization
-				OR NOT EXISTS (
-					SELECT al.id
-					FROM artifact a
-					JOIN ArtifactLicense al ON a.organization_id = al.organization_id
-					WHERE a.id = @artifactId
-				)
-				-- license check
-				OR EXISTS (
-					-- license for all versions of the artifact
-					SELECT *
-					FROM ArtifactLicense_Artifact ala
-					INNER JOIN ArtifactLicense al ON ala.artifact_license_id = al.id
-					WHERE ala.artifact_id = @artifactId AND ala.artifact_version_id IS NULL
-					AND al.customer_organization_id = @customerOrgId AND (al.expires_at IS NULL OR al.expires_at > now())
-				)
-				OR EXISTS (
-					-- or license only for specific versions or their parent versions
-					WITH RECURSIVE ArtifactVersionAggregate (id, manifest_blob_digest) AS (
-						SELECT avx.id, avx.manifest_blob_digest
-						FROM ArtifactVersion avx
-						WHERE avx.manifest_blob_digest = av.manifest_blob_digest AND avx.artifact_id = @artifactId
-
-						UNION ALL
-
-						SELECT DISTINCT avx.id, avx.manifest_blob_digest
-						FROM ArtifactVersion avx
-						JOIN ArtifactVersionPart avp ON avx.id = avp.artifact_version_id
-						JOIN ArtifactVersionAggregate agg ON avp.artifact_blob_digest = agg.manifest_blob_digest
-					)
-					SELECT *
-					FROM ArtifactVersionAggregate avagg
-					INNER JOIN ArtifactLicense_Artifact ala ON ala.artifact_version_id = avagg.id
-					INNER JOIN ArtifactLicense al ON ala.artifact_license_id = al.id
-					WHERE al.customer_organization_id = @customerOrgId AND (al.expires_at IS NULL OR al.expires_at > now())
-					AND ala.artifact_id = @artifactId
-				)
-			)
+			AND `+artifactVersionLicensedExpr+`
 			AND EXISTS (
 				-- only versions that have a tag
 				SELECT avt.id
@@ -322,36 +433,94 @@ func GetVersionsForArtifact(ctx context.Context, artifactID uuid.UUID, customerO
 	} else if versions, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.TaggedArtifactVersion]); err != nil {
 		return nil, err
 	} else {
-		for i, version := range versions {
-			version.InferredType = types.ManifestTypeGeneric
-			if strings.HasPrefix(version.ManifestContentType, "application/vnd.docker") {
-				version.InferredType = types.ManifestTypeContainerImage
-			} else if !manifest.MIMETypeIsMultiImage(version.ManifestContentType) && len(version.ManifestData) > 0 {
-				parsedManifest, err := manifest.FromBlob(version.ManifestData, version.ManifestContentType)
-				if err != nil {
-					return nil, err
-				}
-
-				if strings.HasPrefix(parsedManifest.ConfigInfo().MediaType, "application/vnd.cncf.helm") ||
-					slices.ContainsFunc(parsedManifest.LayerInfos(), func(layer manifest.LayerInfo) bool {
-						return strings.HasPrefix(layer.MediaType, "application/vnd.cncf.helm")
-					}) {
-					version.InferredType = types.ManifestTypeHelmChart
-				}
-			}
-			versions[i] = version
-		}
 		return versions, nil
 	}
 }
 
+// GetVersionsForArtifactBasic returns the same versions as GetVersionsForArtifact for a vendor user, but without
+// computing tags, sizes and download metrics. It does not check licenses, so it must not be used for customer users.
+func GetVersionsForArtifactBasic(ctx context.Context, artifactID uuid.UUID) ([]types.BasicArtifactVersion, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT av.id, av.name, av.manifest_blob_digest, av.manifest_content_type
+		FROM ArtifactVersion av
+		WHERE av.artifact_id = @artifactId
+		AND av.name LIKE '%:%'
+		AND EXISTS (
+			-- only versions that have a tag
+			SELECT avt.id
+			FROM ArtifactVersion avt
+			WHERE avt.manifest_blob_digest = av.manifest_blob_digest
+			AND avt.artifact_id = av.artifact_id
+			AND avt.name NOT LIKE '%:%'
+		)
+		ORDER BY av.created_at DESC`,
+		pgx.NamedArgs{"artifactId": artifactID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersion: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.BasicArtifactVersion])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactVersion: %w", err)
+	}
+	return result, nil
+}
+
+// GetTagNamesForArtifact returns the tag names of the given artifact in lexical order, starting after last. If limit
+// is positive, at most limit names are returned. If customerOrgID is not nil, only tags of versions licensed to that
+// customer organization are returned.
+func GetTagNamesForArtifact(
+	ctx context.Context,
+	artifactID uuid.UUID,
+	customerOrgID *uuid.UUID,
+	last string,
+	limit int,
+) ([]string, error) {
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT avt.name
+		FROM ArtifactVersion avt
+		JOIN ArtifactVersion av
+			ON av.artifact_id = avt.artifact_id
+				AND av.manifest_blob_digest = avt.manifest_blob_digest
+				AND av.name LIKE '%:%'
+		WHERE avt.artifact_id = @artifactId
+		AND avt.name NOT LIKE '%:%'
+		AND avt.name COLLATE "C" > @last
+		AND `+artifactVersionLicensedExpr+`
+		ORDER BY avt.name COLLATE "C"
+		LIMIT @limit`,
+		pgx.NamedArgs{
+			"artifactId":    artifactID,
+			"customerOrgId": customerOrgID,
+			"isVendorUser":  customerOrgID == nil,
+			"last":          last,
+			"limit":         limitArg,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersion: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactVersion: %w", err)
+	}
+	return result, nil
+}
+
 func GetOrCreateArtifact(ctx context.Context, orgID uuid.UUID, artifactName string) (*types.Artifact, error) {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
 		ctx,
 		`SELECT `+artifactOutputExpr+`
 			FROM Artifact a
-			WHERE a.name = @name AND a.organization_id = @orgId`,
+			WHERE a.name = @name AND a.organization_id = @orgId AND a.deleted_at IS NULL`,
 		pgx.NamedArgs{
 			"name":  artifactName,
 			"orgId": orgID,
@@ -419,13 +588,32 @@ func CheckLicenseForArtifact(
 	orgName, name, reference string,
 	customerOrganizationID uuid.UUID,
 	orgID uuid.UUID,
-) error {
+) (finalErr error) {
+	ctx, span := startSpan(ctx, "CheckLicenseForArtifact",
+		attribute.String("artifact.name", orgName+"/"+name),
+		attribute.String("artifact.reference", reference),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Bool("license.granted", finalErr == nil))
+		if errors.Is(finalErr, apierrors.ErrForbidden) {
+			// a missing license is an expected result, not an error of the query
+			endSpan(span, nil)
+		} else {
+			endSpan(span, finalErr)
+		}
+	}()
+
 	hasLicenses, err := HasAnyArtifactLicense(ctx, orgID)
 	if err != nil {
 		return err
 	} else if !hasLicenses {
+		span.SetAttributes(attribute.Bool("license.check", false))
 		return nil
 	}
+	span.SetAttributes(attribute.Bool("license.check", true))
+
+	ctx, cancel := context.WithTimeout(ctx, env.ArtifactLicenseCheckTimeout())
+	defer cancel()
 
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
@@ -438,6 +626,7 @@ func CheckLicenseForArtifact(
 				JOIN Organization o ON o.id = a.organization_id
 				WHERE o.slug = @orgName
 				AND a.name = @name
+				AND a.deleted_at IS NULL
 				AND (avx.name = @reference OR avx.manifest_blob_digest = @reference)
 			UNION ALL
 			SELECT DISTINCT av.id, av.artifact_id, av.manifest_blob_digest
@@ -463,17 +652,49 @@ func CheckLicenseForArtifact(
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("could not query ArtifactVersion: %w", err)
+		return licenseCheckError(ctx, err)
 	}
 	exists, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
 	if err != nil {
-		return fmt.Errorf("could not query ArtifactVersion: %w", err)
+		return licenseCheckError(ctx, err)
 	} else if !exists {
 		return apierrors.ErrForbidden
 	}
 	return nil
 }
 
+// licenseCheckError wraps an error of a license check query. If the check was aborted because it exceeded its
+// timeout, this is made explicit, because the error returned by the driver is not always descriptive.
+func licenseCheckError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("license check exceeded timeout of %v: %w", env.ArtifactLicenseCheckTimeout(), ctx.Err())
+	}
+	return fmt.Errorf("could not query ArtifactVersion: %w", err)
+}
+
+// GetArtifactBlobDigestsInOrganization returns those of the given digests that are part of at least one artifact
+// version of the organization.
+func GetArtifactBlobDigestsInOrganization(ctx context.Context, orgID uuid.UUID, digests []string) ([]string, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT DISTINCT avp.artifact_blob_digest
+		FROM ArtifactVersionPart avp
+		JOIN ArtifactVersion av ON av.id = avp.artifact_version_id
+		JOIN Artifact a ON a.id = av.artifact_id
+		WHERE a.organization_id = @orgId AND avp.artifact_blob_digest = ANY(@digests)`,
+		pgx.NamedArgs{"orgId": orgID, "digests": digests},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersionPart: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactVersionPart: %w", err)
+	}
+	return result, nil
+}
+
 func CheckLicenseForArtifactBlob(ctx context.Context, digest string,
 	customerOrganizationID uuid.UUID,
 	orgID uuid.UUID,
@@ -524,6 +745,100 @@ func CheckLicenseForArtifactBlob(ctx context.Context, digest string,
 	return nil
 }
 
+// CheckPullLimitForArtifact checks that the customer organization has not exhausted the pull limits of its licenses for
+// the given artifact. Pulls are allowed if any of the licenses granting access to the artifact has no limit or pulls
+// remaining in its current period. Periods are calendar periods starting at date_trunc(pull_limit_period, now()).
+// It returns apierrors.ErrPullLimitExceeded if all licenses are exhausted.
+//
+// Every recorded pull of an artifact covered by a license counts towards its limit, except for pulls of the platform
+// manifests of an index in the same artifact. Pulling a multi-platform image therefore counts once, for the tag or the
+// digest of the index, and not once more for the platform that the client downloads afterwards.
+func CheckPullLimitForArtifact(
+	ctx context.Context,
+	orgName, name string,
+	customerOrganizationID uuid.UUID,
+) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`WITH License AS (
+			SELECT DISTINCT al.id, al.max_pulls, al.pull_limit_period
+				FROM Artifact a
+				JOIN Organization o ON o.id = a.organization_id
+				JOIN ArtifactLicense_Artifact ala ON ala.artifact_id = a.id
+				JOIN ArtifactLicense al ON al.id = ala.artifact_license_id
+				WHERE o.slug = @orgName
+					AND a.name = @name
+					AND a.deleted_at IS NULL
+					AND al.customer_organization_id = @customerOrganizationId
+					AND (al.expires_at IS NULL OR al.expires_at > now())
+		)
+		SELECT NOT EXISTS (SELECT 1 FROM License WHERE max_pulls IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM License WHERE max_pulls IS NULL)
+			OR EXISTS (
+				SELECT 1 FROM License l
+				WHERE l.max_pulls IS NOT NULL
+					AND l.max_pulls > (
+						SELECT count(*) FROM (
+							SELECT 1
+								FROM ArtifactVersionPull avpl
+								JOIN ArtifactVersion av ON av.id = avpl.artifact_version_id
+								WHERE avpl.customer_organization_id = @customerOrganizationId
+									AND avpl.created_at >= date_trunc(l.pull_limit_period, now())
+									AND av.artifact_id IN (
+										SELECT ala.artifact_id FROM ArtifactLicense_Artifact ala
+										WHERE ala.artifact_license_id = l.id
+									)
+									AND NOT EXISTS (
+										SELECT 1
+											FROM ArtifactVersionPlatform avpf
+											JOIN ArtifactVersion iv ON iv.id = avpf.artifact_version_id
+											WHERE iv.artifact_id = av.artifact_id
+												AND avpf.manifest_blob_digest = av.manifest_blob_digest
+									)
+								LIMIT l.max_pulls
+						) pulls
+					)
+			)`,
+		pgx.NamedArgs{
+			"orgName":                orgName,
+			"name":                   name,
+			"customerOrganizationId": customerOrganizationID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not query ArtifactVersionPull: %w", err)
+	}
+	allowed, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
+	if err != nil {
+		return fmt.Errorf("could not query ArtifactVersionPull: %w", err)
+	} else if !allowed {
+		return apierrors.ErrPullLimitExceeded
+	}
+	return nil
+}
+
+// EnsurePullLimitForInsert checks the pull limit like CheckPullLimitForArtifact.
+//
+// It must be called in the same transaction that records the pull. The customer organization row is locked until the
+// end of that transaction, so concurrent pulls of the same customer organization are counted one after another and
+// can not exceed the limit together. FOR NO KEY UPDATE is used instead of FOR UPDATE because it does not conflict with
+// the key share locks that inserting an ArtifactVersionPull takes on the customer organization.
+func EnsurePullLimitForInsert(
+	ctx context.Context,
+	orgName, name string,
+	customerOrganizationID uuid.UUID,
+) error {
+	db := internalctx.GetDb(ctx)
+	if _, err := db.Exec(ctx,
+		`SELECT 1 FROM CustomerOrganization WHERE id = @customerOrganizationId FOR NO KEY UPDATE`,
+		pgx.NamedArgs{"customerOrganizationId": customerOrganizationID},
+	); err != nil {
+		return fmt.Errorf("could not lock customer organization: %w", err)
+	}
+	return CheckPullLimitForArtifact(ctx, orgName, name, customerOrganizationID)
+}
+
 func GetArtifactVersion(ctx context.Context, orgName, name, reference string) (*types.ArtifactVersion, error) {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
@@ -534,6 +849,7 @@ func GetArtifactVersion(ctx context.Context, orgName, name, reference string) (*
 		LEFT JOIN ArtifactVersion v ON a.id = v.artifact_id
 		WHERE o.slug = @orgName
 			AND a.name = @name
+			AND a.deleted_at IS NULL
 			AND v.name = @reference`,
 		pgx.NamedArgs{"orgName": orgName, "name": name, "reference": reference},
 	)
@@ -550,22 +866,35 @@ func GetArtifactVersion(ctx context.Context, orgName, name, reference string) (*
 	return &result, nil
 }
 
+// CreateArtifactVersion inserts the given artifact version. If av.InferredType is empty, it is inferred from the
+// manifest. It returns an error wrapping apierrors.ErrManifestTooLarge if the manifest exceeds the configured size.
 func CreateArtifactVersion(ctx context.Context, av *types.ArtifactVersion) error {
+	if maxBytes := env.ArtifactMaxManifestDataBytes(); len(av.ManifestData) > maxBytes {
+		return fmt.Errorf("%w: %v bytes exceed the maximum of %v bytes",
+			apierrors.ErrManifestTooLarge, len(av.ManifestData), maxBytes)
+	}
+
+	inferredType := av.InferredType
+	if inferredType == "" {
+		inferredType = types.InferManifestType(av.ManifestContentType, av.ManifestData)
+	}
+
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
 		ctx,
-		`INSERT INTO ArtifactVersion AS av (
+		`INSERT INTO ArtifactVersion AS v (
             name,
 			created_by_useraccount_id,
 			manifest_blob_digest,
 			manifest_blob_size,
 			manifest_content_type,
 			manifest_data,
-			artifact_id
+			artifact_id,
+			inferred_type
         ) VALUES (
         	@name, @createdById, @manifestBlobDigest, @manifestBlobSize, @manifestContentType, @manifestData,
-			@artifactId
-        ) RETURNING *`,
+			@artifactId, @inferredType
+        ) RETURNING `+artifactVersionOutputExpr,
 		pgx.NamedArgs{
 			"name":                av.Name,
 			"createdById":         av.CreatedByUserAccountID,
@@ -574,6 +903,7 @@ func CreateArtifactVersion(ctx context.Context, av *types.ArtifactVersion) error
 			"manifestContentType": av.ManifestContentType,
 			"manifestData":        av.ManifestData,
 			"artifactId":          av.ArtifactID,
+			"inferredType":        inferredType,
 		},
 	)
 	if err != nil {
@@ -591,6 +921,117 @@ func CreateArtifactVersion(ctx context.Context, av *types.ArtifactVersion) error
 	}
 }
 
+// CreateArtifactTagForDigest creates the tag newTag for the artifact version with the given digest. The new version
+// references the same manifest and parts as the existing one, so no blobs have to be copied.
+// It returns an error wrapping apierrors.ErrNotFound if the artifact has no version with this digest.
+func CreateArtifactTagForDigest(
+	ctx context.Context,
+	artifactID uuid.UUID,
+	newTag string,
+	digest types.Digest,
+	createdByID uuid.UUID,
+) (*types.ArtifactVersion, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		WITH source AS (
+			SELECT * FROM ArtifactVersion WHERE artifact_id = @artifactId AND name = @digest
+		), inserted AS (
+			INSERT INTO ArtifactVersion (
+				name,
+				created_by_useraccount_id,
+				manifest_blob_digest,
+				manifest_blob_size,
+				manifest_content_type,
+				manifest_data,
+				artifact_id,
+				inferred_type,
+				subject_digest
+			)
+			SELECT @name, @createdById, manifest_blob_digest, manifest_blob_size, manifest_content_type, manifest_data,
+				artifact_id, inferred_type, subject_digest
+			FROM source
+			RETURNING *
+		), parts AS (
+			INSERT INTO ArtifactVersionPart (artifact_version_id, artifact_blob_digest, artifact_blob_size)
+			SELECT inserted.id, avp.artifact_blob_digest, avp.artifact_blob_size
+			FROM inserted, source
+			JOIN ArtifactVersionPart avp ON avp.artifact_version_id = source.id
+		)
+		SELECT `+artifactVersionOutputExpr+` FROM inserted v`,
+		pgx.NamedArgs{
+			"artifactId":  artifactID,
+			"digest":      digest,
+			"name":        newTag,
+			"createdById": createdByID,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not insert ArtifactVersion: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[types.ArtifactVersion])
+	if err != nil {
+		if pgerr := (*pgconn.PgError)(nil); errors.As(err, &pgerr) && pgerr.Code == pgerrcode.UniqueViolation {
+			err = fmt.Errorf("%w: %w", apierrors.ErrConflict, err)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = apierrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("could not insert ArtifactVersion: %w", err)
+	}
+	return result, nil
+}
+
+// SetArtifactVersionSubject stores the digest of the subject of the manifest of the given version, so that the version
+// is returned by GetReferrersForDigest.
+func SetArtifactVersionSubject(ctx context.Context, versionID uuid.UUID, subject types.Digest) error {
+	db := internalctx.GetDb(ctx)
+	if _, err := db.Exec(ctx,
+		`UPDATE ArtifactVersion SET subject_digest = @subject WHERE id = @id`,
+		pgx.NamedArgs{"id": versionID, "subject": subject},
+	); err != nil {
+		return fmt.Errorf("could not update ArtifactVersion: %w", err)
+	}
+	return nil
+}
+
+// GetReferrersForDigest returns all versions of the artifact whose manifest has the given digest as subject and that
+// are visible for the given customer organization, or all of them if customerOrgID is nil. Only versions named by their
+// digest are returned, so every manifest is contained at most once.
+func GetReferrersForDigest(
+	ctx context.Context,
+	artifactID uuid.UUID,
+	customerOrgID *uuid.UUID,
+	subject types.Digest,
+) ([]types.ArtifactVersion, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT `+artifactVersionOutputExpr+`
+		FROM ArtifactVersion v
+		WHERE v.id IN (
+			SELECT av.id
+			FROM ArtifactVersion av
+			WHERE av.artifact_id = @artifactId
+			AND av.subject_digest = @subject
+			AND av.name LIKE '%:%'
+			AND `+artifactVersionLicensedExpr+`
+		)
+		ORDER BY v.created_at, v.name`,
+		pgx.NamedArgs{
+			"artifactId":    artifactID,
+			"customerOrgId": customerOrgID,
+			"isVendorUser":  customerOrgID == nil,
+			"subject":       subject,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersion: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactVersion])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactVersion: %w", err)
+	}
+	return result, nil
+}
+
 func CreateArtifactVersionPart(ctx context.Context, avp *types.ArtifactVersionPart) error {
 	db := internalctx.GetDb(ctx)
 	if rows, err := db.Query(
@@ -619,14 +1060,118 @@ func CreateArtifactVersionPart(ctx context.Context, avp *types.ArtifactVersionPa
 	}
 }
 
+// CreateArtifactVersionPlatforms stores the platforms of the manifests referenced by the index with the given version
+// ID.
+func CreateArtifactVersionPlatforms(
+	ctx context.Context,
+	versionID uuid.UUID,
+	platforms []types.ArtifactVersionPlatform,
+) error {
+	db := internalctx.GetDb(ctx)
+	_, err := db.CopyFrom(
+		ctx,
+		pgx.Identifier{"artifactversionplatform"},
+		[]string{"artifact_version_id", "manifest_blob_digest", "os", "architecture", "variant"},
+		pgx.CopyFromSlice(len(platforms), func(i int) ([]any, error) {
+			return []any{
+				versionID,
+				platforms[i].Digest,
+				platforms[i].OS,
+				platforms[i].Architecture,
+				platforms[i].Variant,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert ArtifactVersionPlatform: %w", err)
+	}
+	return nil
+}
+
+// GetArtifactVersionForPlatform returns the manifest for the given platform referenced by the index with the given
+// digest, together with the matching platform. If variant is empty, manifests of any variant match.
+// It returns an error wrapping apierrors.ErrNotFound if the index does not reference a matching manifest.
+func GetArtifactVersionForPlatform(
+	ctx context.Context,
+	artifactID uuid.UUID,
+	indexDigest types.Digest,
+	os, architecture, variant string,
+) (*types.ArtifactVersion, *types.ArtifactVersionPlatform, error) {
+	type versionWithPlatform struct {
+		types.ArtifactVersion
+		PlatformOS           string `db:"platform_os"`
+		PlatformArchitecture string `db:"platform_architecture"`
+		PlatformVariant      string `db:"platform_variant"`
+	}
+
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT `+artifactVersionOutputExpr+`,
+			p.os AS platform_os,
+			p.architecture AS platform_architecture,
+			p.variant AS platform_variant
+		FROM ArtifactVersion iv
+		JOIN ArtifactVersionPlatform p ON p.artifact_version_id = iv.id
+		JOIN ArtifactVersion v ON v.artifact_id = iv.artifact_id AND v.name = p.manifest_blob_digest
+		WHERE iv.artifact_id = @artifactId
+			AND iv.name = @indexDigest
+			AND p.os = @os
+			AND p.architecture = @architecture
+			AND (@variant = '' OR p.variant = @variant)
+		ORDER BY p.variant
+		LIMIT 1`,
+		pgx.NamedArgs{
+			"artifactId":   artifactID,
+			"indexDigest":  indexDigest,
+			"os":           os,
+			"architecture": architecture,
+			"variant":      variant,
+		},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not query ArtifactVersionPlatform: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[versionWithPlatform])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("could not collect ArtifactVersionPlatform: %w", err)
+	}
+	return &result.ArtifactVersion, &types.ArtifactVersionPlatform{
+		Digest:       result.ManifestBlobDigest,
+		OS:           result.PlatformOS,
+		Architecture: result.PlatformArchitecture,
+		Variant:      result.PlatformVariant,
+	}, nil
+}
+
+// anonymizeRemoteAddress applies the configured anonymization to the given normalized address. Hashes use a key that
+// is derived from the JWT secret and rotates daily, so pulls from the same address can only be correlated within
+// the same day.
+func anonymizeRemoteAddress(address string, now time.Time) string {
+	switch env.RemoteAddressAnonymization() {
+	case env.RemoteAddressAnonymizationTruncate:
+		return util.TruncateIPAddress(address)
+	case env.RemoteAddressAnonymizationHash:
+		mac := hmac.New(sha256.New, env.JWTSecret())
+		mac.Write([]byte("remote-address-" + now.UTC().Format(time.DateOnly)))
+		return util.HashIPAddress(address, mac.Sum(nil))
+	default:
+		return address
+	}
+}
+
 func CreateArtifactPullLogEntry(
 	ctx context.Context,
 	versionID,
 	userID uuid.UUID,
 	remoteAddress string,
 	customerOrgID *uuid.UUID,
+	pulledAt time.Time,
 ) error {
 	db := internalctx.GetDb(ctx)
+	remoteAddress = anonymizeRemoteAddress(util.NormalizeIPAddress(remoteAddress), pulledAt)
 	remoteAddressPtr := &remoteAddress
 	if remoteAddress == "" {
 		remoteAddressPtr = nil
@@ -634,18 +1179,21 @@ func CreateArtifactPullLogEntry(
 	_, err := db.Exec(
 		ctx,
 		`INSERT INTO ArtifactVersionPull (
+			created_at,
 			artifact_version_id,
 			useraccount_id,
 			remote_address,
 			customer_organization_id
 		)
 		VALUES (
+			@createdAt,
 			@versionId,
 			@userId,
 			@remoteAddress,
 			@customerOrgId
 		)`,
 		pgx.NamedArgs{
+			"createdAt":     pulledAt,
 			"versionId":     versionID,
 			"userId":        userID,
 			"remoteAddress": remoteAddressPtr,
@@ -658,17 +1206,35 @@ func CreateArtifactPullLogEntry(
 	return nil
 }
 
+// pullCustomerOrganizationFilterExpr restricts the pulls p to the customer organization given as named arg
+// customerOrgId, unless it is NULL.
+const pullCustomerOrganizationFilterExpr = `
+	(@customerOrgId::UUID IS NULL OR p.customer_organization_id = @customerOrgId)`
+
+// artifactTagLimitExpr evaluates to the tag limit of organization o or NULL if there is no limit.
+// It requires the named argument "defaultLimit".
+const artifactTagLimitExpr = `coalesce(o.artifact_tag_limit, CASE WHEN @defaultLimit > 0 THEN @defaultLimit END)`
+
+// EnsureArtifactTagLimitForInsert reports whether the organization may create another tag.
+//
+// It must be called in the same transaction that inserts the tag. The organization row is locked until the end of that
+// transaction, so concurrent pushes to the same organization are counted one after another and can not exceed the
+// limit together. FOR NO KEY UPDATE is used instead of FOR UPDATE because it does not conflict with the key share locks
+// that inserting an Artifact takes on the organization.
 func EnsureArtifactTagLimitForInsert(ctx context.Context, orgID uuid.UUID) (bool, error) {
 	db := internalctx.GetDb(ctx)
+	if _, err := db.Exec(ctx,
+		`SELECT 1 FROM Organization WHERE id = @orgId FOR NO KEY UPDATE`,
+		pgx.NamedArgs{"orgId": orgID},
+	); err != nil {
+		return false, fmt.Errorf("could not lock organization: %w", err)
+	}
 	rows, err := db.Query(ctx, `
-		SELECT count(av.name) + 1 < coalesce(
-			o.artifact_tag_limit,
-			CASE WHEN @defaultLimit > 0 THEN @defaultLimit ELSE @maxLimit END
-		)
+		SELECT count(av.name) + 1 <= coalesce(`+artifactTagLimitExpr+`, @maxLimit)
 		FROM ArtifactVersion av
 		JOIN Artifact a on av.artifact_id = a.id
 		JOIN Organization o ON a.organization_id = o.id
-		WHERE o.id = @orgId AND av.name NOT LIKE '%:%'
+		WHERE o.id = @orgId AND a.deleted_at IS NULL AND av.name NOT LIKE '%:%'
 		GROUP BY o.id;`,
 		pgx.NamedArgs{
 			"orgId":        orgID,
@@ -690,16 +1256,126 @@ func EnsureArtifactTagLimitForInsert(ctx context.Context, orgID uuid.UUID) (bool
 	}
 }
 
+// organizationStorageUsageExpr evaluates to the number of bytes stored by organization o.
+// Storage is counted once per distinct blob digest, including manifests. Deleted artifacts are not counted, even before
+// they are purged. Index manifests reference their children
+// through ArtifactVersionPart, and the children are artifact versions themselves, so they are included as well.
+const organizationStorageUsageExpr = `(
+	SELECT coalesce(sum(blobs.size), 0)::BIGINT
+	FROM (
+		SELECT DISTINCT ON (digest) digest, size
+		FROM (
+			SELECT av.manifest_blob_digest AS digest, av.manifest_blob_size AS size
+			FROM ArtifactVersion av
+			JOIN Artifact a ON av.artifact_id = a.id
+			WHERE a.organization_id = o.id AND a.deleted_at IS NULL
+			UNION ALL
+			SELECT avp.artifact_blob_digest, avp.artifact_blob_size
+			FROM ArtifactVersionPart avp
+			JOIN ArtifactVersion av ON avp.artifact_version_id = av.id
+			JOIN Artifact a ON av.artifact_id = a.id
+			WHERE a.organization_id = o.id AND a.deleted_at IS NULL
+		) all_blobs
+	) blobs
+)`
+
+// organizationStorageLimitExpr evaluates to the storage limit of organization o in bytes or NULL if there is no limit.
+// It requires the named argument "defaultStorageLimit".
+const organizationStorageLimitExpr = `coalesce(
+	o.storage_quota_bytes,
+	CASE WHEN @defaultStorageLimit > 0 THEN @defaultStorageLimit END
+)::BIGINT`
+
+// GetOrganizationStorageQuota returns the storage usage and limit of the organization. Unlike [GetRegistryQuota], the
+// tag usage is not counted.
+func GetOrganizationStorageQuota(ctx context.Context, orgID uuid.UUID) (*types.RegistryQuota, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`SELECT
+			`+organizationStorageUsageExpr+` AS storage_used_bytes,
+			`+organizationStorageLimitExpr+` AS storage_limit_bytes
+		FROM Organization o
+		WHERE o.id = @orgId`,
+		pgx.NamedArgs{"orgId": orgID, "defaultStorageLimit": env.ArtifactStorageDefaultLimitBytesPerOrg()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query storage quota: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByNameLax[types.RegistryQuota])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not collect storage quota: %w", err)
+	}
+	return result, nil
+}
+
+// EnsureOrganizationStorageQuota returns an error wrapping apierrors.ErrStorageQuotaExceeded if storing additional
+// bytes would exceed the storage quota of the organization. Organizations without a quota are not limited.
+func EnsureOrganizationStorageQuota(ctx context.Context, orgID uuid.UUID, additional int64) error {
+	if quota, err := GetOrganizationStorageQuota(ctx, orgID); err != nil {
+		return fmt.Errorf("could not check storage quota: %w", err)
+	} else {
+		return CheckStorageQuota(*quota, additional)
+	}
+}
+
+// CheckStorageQuota returns an error wrapping apierrors.ErrStorageQuotaExceeded if storing additional bytes would
+// exceed the given storage quota.
+func CheckStorageQuota(quota types.RegistryQuota, additional int64) error {
+	if !quota.StorageAvailable(additional) {
+		return fmt.Errorf("%w: %v of %v bytes used",
+			apierrors.ErrStorageQuotaExceeded, quota.StorageUsedBytes, *quota.StorageLimitBytes)
+	}
+	return nil
+}
+
+// GetRegistryQuota returns the current registry usage and limits of the organization.
+func GetRegistryQuota(ctx context.Context, orgID uuid.UUID) (*types.RegistryQuota, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT
+			(
+				SELECT count(av.name)
+				FROM ArtifactVersion av
+				JOIN Artifact a ON av.artifact_id = a.id
+				WHERE a.organization_id = o.id AND a.deleted_at IS NULL AND av.name NOT LIKE '%:%'
+			) AS tag_count,
+			`+artifactTagLimitExpr+`::BIGINT AS tag_limit,
+			`+organizationStorageUsageExpr+` AS storage_used_bytes,
+			`+organizationStorageLimitExpr+` AS storage_limit_bytes
+		FROM Organization o
+		WHERE o.id = @orgId`,
+		pgx.NamedArgs{
+			"orgId":               orgID,
+			"defaultLimit":        env.ArtifactTagsDefaultLimitPerOrg(),
+			"defaultStorageLimit": env.ArtifactStorageDefaultLimitBytesPerOrg(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query registry quota: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[types.RegistryQuota])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not collect registry quota: %w", err)
+	}
+	return result, nil
+}
+
 func GetArtifactVersionPulls(
 	ctx context.Context,
 	orgID uuid.UUID,
 	count int,
 	before time.Time,
+	beforeID *uuid.UUID,
 ) ([]types.ArtifactVersionPull, error) {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
 		ctx,
 		`SELECT
+			p.id,
 			p.created_at,
 			p.remote_address,
 			CASE WHEN u.id IS NOT NULL THEN (`+userAccountOutputExpr+`) ELSE NULL END,
@@ -712,13 +1388,14 @@ func GetArtifactVersionPulls(
 			JOIN ArtifactVersion v ON v.id = p.artifact_version_id
 			JOIN Artifact A on a.id = v.artifact_id
 		WHERE a.organization_id = @orgId
-			AND p.created_at < @before
-		ORDER BY p.created_at DESC
+			AND (p.created_at < @before OR (p.created_at = @before AND p.id < @beforeId))
+		ORDER BY p.created_at DESC, p.id DESC
 		LIMIT @count`,
 		pgx.NamedArgs{
-			"orgId":  orgID,
-			"count":  count,
-			"before": before,
+			"orgId":    orgID,
+			"count":    count,
+			"before":   before,
+			"beforeId": beforeID,
 		},
 	)
 	if err != nil {
@@ -731,6 +1408,69 @@ func GetArtifactVersionPulls(
 	return result, nil
 }
 
+// GetArtifactVersionPullsForExport retrieves artifact version pulls for export
+// ordered by created_at DESC with a subscription-based limit.
+// Zero values for before and after as well as a nil artifactID are ignored.
+func GetArtifactVersionPullsForExport(
+	ctx context.Context,
+	orgID uuid.UUID,
+	artifactID *uuid.UUID,
+	before time.Time,
+	after time.Time,
+	limit int,
+	callback func(types.ArtifactVersionPull) error,
+) error {
+	if before.IsZero() {
+		before = time.Now()
+	}
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT
+			p.id,
+			p.created_at,
+			p.remote_address,
+			CASE WHEN u.id IS NOT NULL THEN (`+userAccountOutputExpr+`) ELSE NULL END,
+			CASE WHEN co.id IS NOT NULL THEN (`+customerOrganizationOutputExpr+`) ELSE NULL END,
+			(`+artifactOutputExpr+`),
+			(`+artifactVersionOutputExpr+`)
+		FROM ArtifactVersionPull p
+			LEFT JOIN UserAccount u ON u.id = p.useraccount_id
+			LEFT JOIN CustomerOrganization co ON co.id = p.customer_organization_id
+			JOIN ArtifactVersion v ON v.id = p.artifact_version_id
+			JOIN Artifact A on a.id = v.artifact_id
+		WHERE a.organization_id = @orgId
+			AND (@artifactId::UUID IS NULL OR a.id = @artifactId)
+			AND p.created_at BETWEEN @after AND @before
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT @limit`,
+		pgx.NamedArgs{
+			"orgId":      orgID,
+			"artifactId": artifactID,
+			"before":     before,
+			"after":      after,
+			"limit":      limit,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not query ArtifactVersionPulls: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pull, err := pgx.RowToStructByPos[types.ArtifactVersionPull](rows)
+		if err != nil {
+			return fmt.Errorf("could not scan ArtifactVersionPull: %w", err)
+		} else if err := callback(pull); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not iterate ArtifactVersionPulls: %w", err)
+	}
+	return nil
+}
+
 func UpdateArtifactImage(ctx context.Context, artifact *types.ArtifactWithTaggedVersion, imageID uuid.UUID) error {
 	db := internalctx.GetDb(ctx)
 	row := db.QueryRow(ctx,
@@ -743,24 +1483,6 @@ func UpdateArtifactImage(ctx context.Context, artifact *types.ArtifactWithTagged
 	return nil
 }
 
-func ArtifactIsReferencedInLicenses(ctx context.Context, artifactID uuid.UUID) (bool, error) {
-	db := internalctx.GetDb(ctx)
-	rows, err := db.Query(ctx, `
-		SELECT count(ala.id) > 0
-		FROM ArtifactLicense_Artifact ala
-		WHERE ala.artifact_id = @artifactId`,
-		pgx.NamedArgs{"artifactId": artifactID},
-	)
-	if err != nil {
-		return false, err
-	}
-	exists, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
-	if err != nil {
-		return false, err
-	}
-	return exists, nil
-}
-
 // GetArtifactVersionByTag retrieves an artifact version by its tag name
 func GetArtifactVersionByTag(
 	ctx context.Context,
@@ -915,22 +1637,93 @@ func isDigestName(name string) bool {
 	return len(name) > 0 && strings.Contains(name, ":")
 }
 
-func DeleteArtifactWithID(ctx context.Context, id uuid.UUID) error {
+// DeleteArtifactWithID marks the artifact as deleted and returns the number of its versions. A deleted artifact is
+// hidden everywhere but can be restored with [RestoreArtifact] until it is purged by [PurgeDeletedArtifacts].
+// The deletion is recorded as an [types.ArtifactDeletion].
+func DeleteArtifactWithID(ctx context.Context, id uuid.UUID, deletedByID uuid.UUID) (int64, error) {
 	db := internalctx.GetDb(ctx)
-	cmd, err := db.Exec(ctx, `DELETE FROM Artifact WHERE id = @id`, pgx.NamedArgs{"id": id})
+	rows, err := db.Query(ctx, `
+		WITH deleted AS (
+			UPDATE Artifact SET deleted_at = now() WHERE id = @id AND deleted_at IS NULL
+			RETURNING id, organization_id, name
+		), audit AS (
+			INSERT INTO ArtifactDeletion (artifact_id, organization_id, artifact_name, deleted_by_useraccount_id)
+			SELECT deleted.id, deleted.organization_id, deleted.name, @deletedById FROM deleted
+		)
+		SELECT (SELECT count(*) FROM ArtifactVersion WHERE artifact_id = deleted.id) FROM deleted`,
+		pgx.NamedArgs{"id": id, "deletedById": deletedByID},
+	)
+	var count int64
+	if err == nil {
+		count, err = pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+	}
 	if err != nil {
-		if pgerr := (*pgconn.PgError)(nil); errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation {
-			err = fmt.Errorf("%w: %w", apierrors.ErrConflict, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = apierrors.ErrNotFound
 		}
-	} else if cmd.RowsAffected() == 0 {
-		err = apierrors.ErrNotFound
+		return 0, fmt.Errorf("could not delete Artifact: %w", err)
 	}
 
+	return count, nil
+}
+
+// GetDeletedArtifactsByOrgID returns all deleted artifacts of the organization that have not been purged yet.
+func GetDeletedArtifactsByOrgID(ctx context.Context, orgID uuid.UUID) ([]types.DeletedArtifact, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT `+artifactOutputExpr+`, a.deleted_at
+		FROM Artifact a
+		WHERE a.organization_id = @orgId AND a.deleted_at IS NOT NULL
+		ORDER BY a.deleted_at DESC`,
+		pgx.NamedArgs{"orgId": orgID},
+	)
 	if err != nil {
-		return fmt.Errorf("could not delete Artifact: %w", err)
+		return nil, fmt.Errorf("failed to query deleted artifacts: %w", err)
 	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.DeletedArtifact])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deleted artifacts: %w", err)
+	}
+	return result, nil
+}
 
-	return nil
+// RestoreArtifact reverts the deletion of an artifact. It returns an error wrapping apierrors.ErrConflict if another
+// artifact with the same name was created in the meantime.
+func RestoreArtifact(ctx context.Context, orgID, id uuid.UUID) (*types.Artifact, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		UPDATE Artifact AS a SET deleted_at = NULL
+		WHERE a.id = @id AND a.organization_id = @orgId AND a.deleted_at IS NOT NULL
+		RETURNING `+artifactOutputExpr,
+		pgx.NamedArgs{"id": id, "orgId": orgID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not restore Artifact: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[types.Artifact])
+	if err != nil {
+		if pgerr := (*pgconn.PgError)(nil); errors.As(err, &pgerr) && pgerr.Code == pgerrcode.UniqueViolation {
+			err = fmt.Errorf("%w: an artifact with this name already exists", apierrors.ErrConflict)
+		} else if errors.Is(err, pgx.ErrNoRows) {
+			err = apierrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("could not restore Artifact: %w", err)
+	}
+	return result, nil
+}
+
+// PurgeDeletedArtifacts permanently removes artifacts that were deleted more than maxAge ago, together with all their
+// versions and parts. Blobs that are no longer referenced by any version can be garbage collected afterwards.
+func PurgeDeletedArtifacts(ctx context.Context, maxAge time.Duration) (int64, error) {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(ctx,
+		`DELETE FROM Artifact WHERE deleted_at IS NOT NULL AND current_timestamp - deleted_at > @maxAge`,
+		pgx.NamedArgs{"maxAge": maxAge},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not purge deleted Artifacts: %w", err)
+	}
+	return cmd.RowsAffected(), nil
 }
 
 func IsLastTagOfArtifact(ctx context.Context, artifactID uuid.UUID, tagName string) (bool, error) {
@@ -955,19 +1748,31 @@ func IsLastTagOfArtifact(ctx context.Context, artifactID uuid.UUID, tagName stri
 	return tagCount == 1, nil
 }
 
-func DeleteArtifactVersion(ctx context.Context, artifactID uuid.UUID, tagName string) error {
+// DeleteArtifactVersion deletes the given tag of the artifact and records the deletion as an
+// [types.ArtifactDeletion].
+func DeleteArtifactVersion(ctx context.Context, artifactID uuid.UUID, tagName string, deletedByID uuid.UUID) error {
 	db := internalctx.GetDb(ctx)
 
 	// Delete only the tag, not the version SHA
 	// Tags are ArtifactVersion records where name does NOT contain a colon
 	cmd, err := db.Exec(ctx, `
-		DELETE FROM ArtifactVersion
-		WHERE artifact_id = @artifactId
-		AND name = @tagName
-		AND name NOT LIKE '%:%'`,
+		WITH deleted AS (
+			DELETE FROM ArtifactVersion
+			WHERE artifact_id = @artifactId
+			AND name = @tagName
+			AND name NOT LIKE '%:%'
+			RETURNING artifact_id, name, manifest_blob_digest
+		)
+		INSERT INTO ArtifactDeletion (
+			artifact_id, organization_id, artifact_name, tag_name, manifest_blob_digest, deleted_by_useraccount_id
+		)
+		SELECT deleted.artifact_id, a.organization_id, a.name, deleted.name, deleted.manifest_blob_digest, @deletedById
+		FROM deleted
+		JOIN Artifact a ON a.id = deleted.artifact_id`,
 		pgx.NamedArgs{
-			"artifactId": artifactID,
-			"tagName":    tagName,
+			"artifactId":  artifactID,
+			"tagName":     tagName,
+			"deletedById": deletedByID,
 		})
 	if err != nil {
 		if pgerr := (*pgconn.PgError)(nil); errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation {
@@ -983,3 +1788,280 @@ func DeleteArtifactVersion(ctx context.Context, artifactID uuid.UUID, tagName st
 
 	return nil
 }
+
+// DeleteArtifactVersionsByPattern deletes all non-SHA tags of the artifact whose name matches the given glob pattern
+// (see [path.Match]) and returns the names of the deleted tags.
+// Every tag is subject to the same checks as a single tag deletion, so the last tag of an artifact is never deleted
+// and tags required by licenses are kept. It should be called inside a transaction, so that nothing is deleted if any
+// of the checks fail.
+func DeleteArtifactVersionsByPattern(
+	ctx context.Context,
+	artifactID uuid.UUID,
+	glob string,
+	deletedByID uuid.UUID,
+) ([]string, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid tag pattern: %v", err))
+	}
+
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT name
+		FROM ArtifactVersion
+		WHERE artifact_id = @artifactId
+		AND name NOT LIKE '%:%'
+		ORDER BY name`,
+		pgx.NamedArgs{"artifactId": artifactID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query tags: %w", err)
+	}
+	tagNames, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect tags: %w", err)
+	}
+
+	deleted := []string{}
+	for _, tagName := range tagNames {
+		if matches, _ := path.Match(glob, tagName); !matches {
+			continue
+		}
+
+		version, err := GetArtifactVersionByTag(ctx, artifactID, tagName)
+		if err != nil {
+			return nil, err
+		}
+		versionsWithSameDigest, err := GetArtifactVersionsByDigest(ctx, artifactID, string(version.ManifestBlobDigest))
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckArtifactVersionDeletionForLicenses(ctx, artifactID, version, versionsWithSameDigest); err != nil {
+			return nil, fmt.Errorf("tag %v: %w", tagName, err)
+		}
+		if isLast, err := IsLastTagOfArtifact(ctx, artifactID, tagName); err != nil {
+			return nil, err
+		} else if isLast {
+			return nil, apierrors.NewConflict(fmt.Sprintf(
+				"Cannot delete tag %v: it is the last tag of the artifact. At least one tag must remain for the artifact.",
+				tagName,
+			))
+		}
+		if err := DeleteArtifactVersion(ctx, artifactID, tagName, deletedByID); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, tagName)
+	}
+
+	return deleted, nil
+}
+
+// GetArtifactDeletions returns the most recent tag and artifact deletions of the given artifact.
+func GetArtifactDeletions(ctx context.Context, artifactID uuid.UUID, limit int) ([]types.ArtifactDeletion, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT ad.id, ad.created_at, ad.artifact_id, ad.artifact_name, ad.tag_name, ad.manifest_blob_digest,
+			ad.deleted_by_useraccount_id, u.email AS deleted_by_useraccount_email
+		FROM ArtifactDeletion ad
+		LEFT JOIN UserAccount u ON u.id = ad.deleted_by_useraccount_id
+		WHERE ad.artifact_id = @artifactId
+		ORDER BY ad.created_at DESC
+		LIMIT @limit`,
+		pgx.NamedArgs{"artifactId": artifactID, "limit": limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactDeletion: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactDeletion])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactDeletion: %w", err)
+	}
+	return result, nil
+}
+
+// GetTopArtifactsByPulls returns the artifacts of the given organization with the most pulls between from
+// (inclusive) and to (exclusive), ordered by the number of pulls. Artifacts without pulls in this period are omitted.
+func GetTopArtifactsByPulls(
+	ctx context.Context,
+	orgID uuid.UUID,
+	customerOrgID *uuid.UUID,
+	from, to time.Time,
+	limit int,
+) ([]types.ArtifactPullStats, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT a.id AS artifact_id,
+			a.name AS artifact_name,
+			count(p.id) AS pulls_total,
+			count(DISTINCT coalesce(p.customer_organization_id, p.useraccount_id)) AS distinct_pullers
+		FROM ArtifactVersionPull p
+		JOIN ArtifactVersion v ON v.id = p.artifact_version_id
+		JOIN Artifact a ON a.id = v.artifact_id
+		WHERE a.organization_id = @orgId
+			AND a.deleted_at IS NULL
+			AND p.created_at >= @from
+			AND p.created_at < @to
+			AND `+pullCustomerOrganizationFilterExpr+`
+		GROUP BY a.id, a.name
+		ORDER BY pulls_total DESC, distinct_pullers DESC, a.name
+		LIMIT @limit`,
+		pgx.NamedArgs{"orgId": orgID, "customerOrgId": customerOrgID, "from": from, "to": to, "limit": limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersionPull: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.ArtifactPullStats])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect ArtifactPullStats: %w", err)
+	}
+	return result, nil
+}
+
+// GetPullsGroupedByCustomerOrganization returns the pull totals of every customer organization of the given
+// organization that pulled between from (inclusive) and to (exclusive), ordered by the number of pulls.
+// Pulls by vendor users are not included. If customerOrgID is not nil, only this customer organization is included.
+// If limit is positive, at most limit customer organizations are returned.
+func GetPullsGroupedByCustomerOrganization(
+	ctx context.Context,
+	orgID uuid.UUID,
+	customerOrgID *uuid.UUID,
+	from, to time.Time,
+	limit int,
+) ([]types.CustomerOrganizationPullStats, error) {
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		SELECT co.id AS customer_organization_id,
+			co.name AS customer_organization_name,
+			count(p.id) AS pulls_total,
+			count(DISTINCT v.artifact_id) AS distinct_artifacts,
+			max(p.created_at) AS last_pulled_at
+		FROM ArtifactVersionPull p
+		JOIN CustomerOrganization co ON co.id = p.customer_organization_id
+		JOIN ArtifactVersion v ON v.id = p.artifact_version_id
+		JOIN Artifact a ON a.id = v.artifact_id
+		WHERE a.organization_id = @orgId
+			AND p.created_at >= @from
+			AND p.created_at < @to
+			AND `+pullCustomerOrganizationFilterExpr+`
+		GROUP BY co.id, co.name
+		ORDER BY pulls_total DESC, co.name
+		LIMIT @limit`,
+		pgx.NamedArgs{"orgId": orgID, "customerOrgId": customerOrgID, "from": from, "to": to, "limit": limitArg},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersionPull: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.CustomerOrganizationPullStats])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect CustomerOrganizationPullStats: %w", err)
+	}
+	return result, nil
+}
+
+// GetPullCountsOverTime returns the number of pulls of all artifacts of the given organization between from
+// (inclusive) and to (exclusive) in buckets of the given size, starting at from. Buckets without pulls are included.
+// If customerOrgID is not nil, only pulls of this customer organization are counted.
+func GetPullCountsOverTime(
+	ctx context.Context,
+	orgID uuid.UUID,
+	customerOrgID *uuid.UUID,
+	from, to time.Time,
+	bucketSize time.Duration,
+) ([]types.PullCountBucket, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx, `
+		WITH pulls AS (
+			SELECT date_bin(@bucketSize::INTERVAL, p.created_at, @from::TIMESTAMP) AS start,
+				count(p.id) AS pulls_total
+			FROM ArtifactVersionPull p
+			JOIN ArtifactVersion v ON v.id = p.artifact_version_id
+			JOIN Artifact a ON a.id = v.artifact_id
+			WHERE a.organization_id = @orgId
+				AND p.created_at >= @from
+				AND p.created_at < @to
+				AND `+pullCustomerOrganizationFilterExpr+`
+			GROUP BY 1
+		)
+		SELECT b.start, coalesce(pulls.pulls_total, 0) AS pulls_total
+		FROM generate_series(@from::TIMESTAMP, @to::TIMESTAMP, @bucketSize::INTERVAL) AS b (start)
+		LEFT JOIN pulls ON pulls.start = b.start
+		WHERE b.start < @to
+		ORDER BY b.start`,
+		pgx.NamedArgs{
+			"orgId":         orgID,
+			"customerOrgId": customerOrgID,
+			"from":          from,
+			"to":            to,
+			"bucketSize":    bucketSize,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query ArtifactVersionPull: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.PullCountBucket])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect PullCountBucket: %w", err)
+	}
+	return result, nil
+}
+
+// GetCatalogForExport calls the callback for every manifest of all artifacts of the given organization, ordered by
+// artifact name and manifest creation date. Deleted artifacts are skipped.
+func GetCatalogForExport(
+	ctx context.Context,
+	orgID uuid.UUID,
+	callback func(types.CatalogExportVersion) error,
+) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`SELECT
+			a.id AS artifact_id,
+			a.name AS artifact_name,
+			a.created_at AS artifact_created_at,
+			av.created_at,
+			av.manifest_blob_digest,
+			av.manifest_blob_size,
+			av.manifest_content_type,
+			av.manifest_data,
+			coalesce((
+				SELECT array_agg(avt.name ORDER BY avt.name)
+				FROM ArtifactVersion avt
+				WHERE avt.artifact_id = av.artifact_id
+					AND avt.manifest_blob_digest = av.manifest_blob_digest
+					AND avt.name NOT LIKE '%:%'
+			), ARRAY[]::TEXT[]) AS tags,
+			coalesce((
+				SELECT array_agg(row(avp.artifact_blob_digest, avp.artifact_blob_size) ORDER BY avp.artifact_blob_digest)
+				FROM ArtifactVersionPart avp
+				WHERE avp.artifact_version_id = av.id
+			), ARRAY[]::RECORD[]) AS blobs
+		FROM Artifact a
+		JOIN ArtifactVersion av ON av.artifact_id = a.id
+		WHERE a.organization_id = @orgId
+			AND a.deleted_at IS NULL
+			AND av.name LIKE '%:%'
+		ORDER BY a.name, av.created_at, av.id`,
+		pgx.NamedArgs{"orgId": orgID},
+	)
+	if err != nil {
+		return fmt.Errorf("could not query ArtifactVersions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version, err := pgx.RowToStructByName[types.CatalogExportVersion](rows)
+		if err != nil {
+			return fmt.Errorf("could not scan ArtifactVersion: %w", err)
+		} else if err := callback(version); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not iterate ArtifactVersions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestEnsureArtifactTagLimitForInsertConcurrent pushes many tags to the same organization concurrently, each in its
+// own transaction like the registry does, and checks that exactly as many tags as the limit allows are created. Unlike
+// the benchmarks, the transactions have to be committed, so the test data is deleted afterwards.
+func TestEnsureArtifactTagLimitForInsertConcurrent(t *testing.T) {
+	const (
+		tagLimit = 5
+		pushes   = 20
+	)
+
+	g := NewWithT(t)
+	ctx := internalctx.WithDb(context.Background(), dbtest.Connect(t, nil))
+
+	org := dbtest.CreateOrganization(t, ctx)
+	t.Cleanup(func() {
+		for _, statement := range []string{
+			`DELETE FROM ArtifactVersion
+				WHERE artifact_id IN (SELECT id FROM Artifact WHERE organization_id = @orgId)`,
+			`DELETE FROM Artifact WHERE organization_id = @orgId`,
+			`DELETE FROM Organization WHERE id = @orgId`,
+		} {
+			_, err := internalctx.GetDb(ctx).Exec(context.Background(), statement, pgx.NamedArgs{"orgId": org.ID})
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	_, err := internalctx.GetDb(ctx).Exec(ctx,
+		`UPDATE Organization SET artifact_tag_limit = @limit WHERE id = @orgId`,
+		pgx.NamedArgs{"limit": tagLimit, "orgId": org.ID},
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	artifact := dbtest.CreateArtifact(t, ctx, org.ID, "quota/app")
+
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	errs := make([]error, pushes)
+	for i := range pushes {
+		wg.Go(func() {
+			errs[i] = db.RunTx(ctx, func(ctx context.Context) error {
+				if ok, err := db.EnsureArtifactTagLimitForInsert(ctx, org.ID); err != nil || !ok {
+					return err
+				}
+				d := digest.FromString(fmt.Sprintf("manifest-%v", i))
+				version := types.ArtifactVersion{
+					Name:                fmt.Sprintf("1.0.%v", i),
+					ManifestBlobDigest:  types.Digest(d),
+					ManifestBlobSize:    1024,
+					ManifestContentType: "application/vnd.oci.image.manifest.v1+json",
+					ManifestData:        []byte(d),
+					ArtifactID:          artifact.ID,
+				}
+				if err := db.CreateArtifactVersion(ctx, &version); err != nil {
+					return err
+				}
+				created.Add(1)
+				return nil
+			})
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	var tags int
+	g.Expect(internalctx.GetDb(ctx).QueryRow(ctx,
+		`SELECT count(*) FROM ArtifactVersion WHERE artifact_id = @artifactId AND name NOT LIKE '%:%'`,
+		pgx.NamedArgs{"artifactId": artifact.ID},
+	).Scan(&tags)).To(Succeed())
+	g.Expect(tags).To(BeEquivalentTo(created.Load()))
+	g.Expect(tags).To(Equal(tagLimit))
+}
+
+// TestEnsureOrganizationStorageQuota checks that blobs referenced by the versions of an organization count towards its
+// storage quota, and that shared blobs are only counted once.
+func TestEnsureOrganizationStorageQuota(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	artifact := dbtest.CreateArtifact(t, ctx, org.ID, "storage/app")
+
+	quota, err := db.GetOrganizationStorageQuota(ctx, org.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(quota.StorageUsedBytes).To(BeZero())
+	g.Expect(quota.StorageLimitBytes).To(BeNil())
+	g.Expect(db.EnsureOrganizationStorageQuota(ctx, org.ID, 1<<40)).To(Succeed())
+
+	layer := digest.FromString("layer")
+	for _, tag := range []string{"1.0.0", "1.0.1"} {
+		version := dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, tag, digest.FromString(tag), imgspecv1.MediaTypeImageManifest)
+		g.Expect(db.CreateArtifactVersionPart(ctx, &types.ArtifactVersionPart{
+			ArtifactVersionID:  version.ID,
+			ArtifactBlobDigest: types.Digest(layer),
+			ArtifactBlobSize:   1000,
+		})).To(Succeed())
+	}
+
+	_, err = internalctx.GetDb(ctx).Exec(ctx,
+		`UPDATE Organization SET storage_quota_bytes = 3500 WHERE id = @orgId`,
+		pgx.NamedArgs{"orgId": org.ID},
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	quota, err = db.GetOrganizationStorageQuota(ctx, org.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(quota.StorageUsedBytes).To(BeEquivalentTo(3048))
+	g.Expect(quota.StorageLimitBytes).To(HaveValue(BeEquivalentTo(3500)))
+	g.Expect(db.EnsureOrganizationStorageQuota(ctx, org.ID, 452)).To(Succeed())
+	g.Expect(db.EnsureOrganizationStorageQuota(ctx, org.ID, 453)).To(MatchError(apierrors.ErrStorageQuotaExceeded))
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeleteAuditEventsOlderThan deletes all audit records of the given type that are older than maxAge and returns the
+// number of deleted records.
+func DeleteAuditEventsOlderThan(
+	ctx context.Context,
+	eventType types.AuditEventType,
+	maxAge time.Duration,
+) (int64, error) {
+	var query string
+	switch eventType {
+	case types.AuditEventTypeArtifactDeletion:
+		query = `DELETE FROM ArtifactDeletion WHERE tag_name IS NULL AND current_timestamp - created_at > @maxAge`
+	case types.AuditEventTypeArtifactTagDeletion:
+		query = `DELETE FROM ArtifactDeletion WHERE tag_name IS NOT NULL AND current_timestamp - created_at > @maxAge`
+	default:
+		return 0, fmt.Errorf("unsupported AuditEventType: %v", eventType)
+	}
+
+	db := internalctx.GetDb(ctx)
+	if cmd, err := db.Exec(ctx, query, pgx.NamedArgs{"maxAge": maxAge}); err != nil {
+		return 0, fmt.Errorf("could not delete %v audit events: %w", eventType, err)
+	} else {
+		return cmd.RowsAffected(), nil
+	}
+}
@@ -43,12 +43,12 @@ func DeleteOIDCState(ctx context.Context, id uuid.UUID) (string, time.Time, erro
 	return r.PKCECodeVerifier, r.CreatedAt, nil
 }
 
-func CleanupOIDCStates(ctx context.Context) (int64, error) {
+func CleanupOIDCStates(ctx context.Context, maxAge time.Duration) (int64, error) {
 	db := internalctx.GetDb(ctx)
 	cmd, err := db.Exec(
 		ctx,
 		`DELETE FROM OIDCState WHERE current_timestamp - created_at > @maxAge`,
-		pgx.NamedArgs{"maxAge": 1 * time.Minute},
+		pgx.NamedArgs{"maxAge": maxAge},
 	)
 	if err != nil {
 		return 0, fmt.Errorf("error cleaning up OIDCState: %w", err)
@@ -0,0 +1,50 @@
+// Package dbtest provides access to the database for tests that run queries against a real database.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "github.com/onsi/gomega"
+)
+
+var initEnvOnce sync.Once
+
+// Connect creates a connection pool for the fully migrated database at DISTR_TEST_DATABASE_URL that is closed when
+// the test is done. The test is skipped if DISTR_TEST_DATABASE_URL is not set. Otherwise, the environment is
+// initialized like for the hub, so that the queries use the configured limits.
+func Connect(tb testing.TB, tracer pgx.QueryTracer) *pgxpool.Pool {
+	databaseUrl := os.Getenv("DISTR_TEST_DATABASE_URL")
+	if databaseUrl == "" {
+		tb.Skip("DISTR_TEST_DATABASE_URL is not set")
+	}
+	initEnvOnce.Do(env.Initialize)
+
+	g := NewWithT(tb)
+	config, err := pgxpool.ParseConfig(databaseUrl)
+	g.Expect(err).NotTo(HaveOccurred())
+	config.ConnConfig.Tracer = tracer
+	config.AfterConnect = db.RegisterTypes
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	g.Expect(err).NotTo(HaveOccurred())
+	tb.Cleanup(pool.Close)
+	return pool
+}
+
+// TxContext connects to the test database like Connect and returns a context with a transaction that is rolled back
+// when the test is done, so that the database is left untouched.
+func TxContext(tb testing.TB, tracer pgx.QueryTracer) context.Context {
+	pool := Connect(tb, tracer)
+	tx, err := pool.Begin(context.Background())
+	NewWithT(tb).Expect(err).NotTo(HaveOccurred())
+	tb.Cleanup(func() { _ = tx.Rollback(context.Background()) })
+	return internalctx.WithDb(context.Background(), tx)
+}
@@ -0,0 +1,72 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+)
+
+// CreateOrganization creates an organization with a unique slug and the given features.
+func CreateOrganization(tb testing.TB, ctx context.Context, features ...types.Feature) types.Organization {
+	org := types.Organization{
+		Name:     "Test",
+		Slug:     util.PtrTo(fmt.Sprintf("test-%v", uuid.New())),
+		Features: features,
+	}
+	NewWithT(tb).Expect(db.CreateOrganization(ctx, &org)).To(Succeed())
+	return org
+}
+
+// CreateUserAccount creates a user account with a unique email address.
+func CreateUserAccount(tb testing.TB, ctx context.Context) types.UserAccount {
+	user := types.UserAccount{Email: fmt.Sprintf("test-%v@example.com", uuid.New())}
+	NewWithT(tb).Expect(db.CreateUserAccount(ctx, &user)).To(Succeed())
+	return user
+}
+
+// CreateArtifact creates an artifact with the given name in the organization.
+func CreateArtifact(tb testing.TB, ctx context.Context, orgID uuid.UUID, name string) types.Artifact {
+	artifact := types.Artifact{OrganizationID: orgID, Name: name}
+	NewWithT(tb).Expect(db.CreateArtifact(ctx, &artifact)).To(Succeed())
+	return artifact
+}
+
+// CreateArtifactVersion creates a version of the artifact for a manifest with the given digest and content type. The
+// digest is also used as manifest data, so that different digests result in different manifests.
+func CreateArtifactVersion(
+	tb testing.TB,
+	ctx context.Context,
+	artifactID uuid.UUID,
+	name string,
+	d digest.Digest,
+	contentType string,
+) types.ArtifactVersion {
+	version := types.ArtifactVersion{
+		Name:                name,
+		ManifestBlobDigest:  types.Digest(d),
+		ManifestBlobSize:    1024,
+		ManifestContentType: contentType,
+		ManifestData:        []byte(d),
+		ArtifactID:          artifactID,
+	}
+	NewWithT(tb).Expect(db.CreateArtifactVersion(ctx, &version)).To(Succeed())
+	return version
+}
+
+// CreateOrgWithArtifact creates an organization, a user account and an artifact with the given name in that
+// organization. The user account is not assigned to the organization.
+func CreateOrgWithArtifact(
+	tb testing.TB,
+	ctx context.Context,
+	artifactName string,
+) (types.Organization, types.UserAccount, types.Artifact) {
+	org := CreateOrganization(tb, ctx)
+	return org, CreateUserAccount(tb, ctx), CreateArtifact(tb, ctx, org.ID, artifactName)
+}
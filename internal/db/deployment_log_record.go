@@ -12,6 +12,7 @@ import (
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -228,14 +229,41 @@ func BulkCreateDeploymentLogRecordWithCreatedAt(
 	return err
 }
 
+// TrimDeploymentLogRecords deletes all but the newest [limit] log records for each (deployment_id, resource) group of
+// the given deployments.
+func TrimDeploymentLogRecords(ctx context.Context, deploymentIDs []uuid.UUID, limit int) (int64, error) {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(
+		ctx,
+		`DELETE FROM DeploymentLogRecord
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id,
+					row_number() OVER (PARTITION BY (deployment_id, resource) ORDER BY timestamp DESC) AS rnk
+				FROM DeploymentLogRecord
+				WHERE deployment_id = ANY(@deploymentIds)
+			) lr
+			WHERE rnk > @limit
+		)`,
+		pgx.NamedArgs{"deploymentIds": deploymentIDs, "limit": limit},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not trim DeploymentLogRecord: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
+
 // CleanupDeploymentLogRecords deletes logrecords for all deployments but keeps the
-// last [env.LogRecordEntriesMaxCount] records for each (deployment_id, resource) group.
+// last [env.LogRecordEntriesMaxCount] records (or the override of the deployment target)
+// for each (deployment_id, resource) group.
 //
-// If [env.LogRecordEntriesMaxCount] is nil, no cleanup is performed.
+// If [env.LogRecordEntriesMaxCount] is nil, only the log records of deployment targets with an override are cleaned up.
 func CleanupDeploymentLogRecords(ctx context.Context) (int64, error) {
-	limit := env.LogRecordEntriesMaxCount()
-	if limit == nil {
-		return 0, nil
+	var limit *int
+	if maxCount := env.LogRecordEntriesMaxCount(); maxCount != nil {
+		// "limit + 1" because we want to get the newest record that should be deleted.
+		// This is an optimization to avoid unnecessary queries for resources that have exactly [limit] entries.
+		limit = util.PtrTo(*maxCount + 1)
 	}
 
 	db := internalctx.GetDb(ctx)
@@ -244,14 +272,16 @@ func CleanupDeploymentLogRecords(ctx context.Context) (int64, error) {
 	rows, err := db.Query(
 		ctx,
 		`SELECT `+deploymentLogRecordOutputExpr+` FROM (
-			SELECT *,
-				row_number() OVER (PARTITION BY (deployment_id, resource) ORDER BY timestamp DESC) AS rnk
-				FROM DeploymentLogRecord
+			SELECT lr.*,
+				coalesce(dt.log_record_entries_max_count + 1, @limit::INTEGER) AS max_rnk,
+				row_number() OVER (PARTITION BY (lr.deployment_id, lr.resource) ORDER BY lr.timestamp DESC) AS rnk
+				FROM DeploymentLogRecord lr
+				JOIN Deployment d ON d.id = lr.deployment_id
+				JOIN DeploymentTarget dt ON dt.id = d.deployment_target_id
+				WHERE @limit::INTEGER IS NOT NULL OR dt.log_record_entries_max_count IS NOT NULL
 		) lr
-		WHERE rnk = @limit`,
-		// "limit + 1" because we want to get the newest record that should be deleted.
-		// This is an optimization to avoid unnecessary queries for resources that have exactly [limit] entries.
-		pgx.NamedArgs{"limit": *limit + 1},
+		WHERE rnk = max_rnk`,
+		pgx.NamedArgs{"limit": limit},
 	)
 	if err != nil {
 		return 0, fmt.Errorf("error querying DeploymentLogRecords: %w", err)
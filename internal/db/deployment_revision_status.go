@@ -6,23 +6,42 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/apierrors"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
 )
 
-func CreateDeploymentRevisionStatus(ctx context.Context, status *types.DeploymentRevisionStatus) error {
+// CreateDeploymentRevisionStatus inserts a new status for a deployment revision. If status.CreatedAt is set, it is used
+// as the creation time unless it lies in the future.
+// If idempotencyKey is not nil and a status with the same key already exists for the revision, nothing is inserted,
+// status is set to the existing row and an error wrapping [apierrors.ErrAlreadyExists] is returned.
+func CreateDeploymentRevisionStatus(
+	ctx context.Context,
+	status *types.DeploymentRevisionStatus,
+	idempotencyKey *uuid.UUID,
+) error {
+	status.Message = truncateStatusMessage(ctx, status.Message)
+	var createdAt *time.Time
+	if !status.CreatedAt.IsZero() {
+		createdAt = &status.CreatedAt
+	}
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
 		ctx,
 		`WITH inserted AS (
-			INSERT INTO DeploymentRevisionStatus (deployment_revision_id, message, type)
-			VALUES (@deploymentRevisionId, @message, @type)
+			INSERT INTO DeploymentRevisionStatus (created_at, deployment_revision_id, message, type, idempotency_key)
+			VALUES (
+				least(coalesce(@createdAt, now()), now()), @deploymentRevisionId, @message, @type, @idempotencyKey
+			)
+			ON CONFLICT (deployment_revision_id, idempotency_key) DO NOTHING
 			RETURNING *
 		)
 		SELECT id, created_at, deployment_revision_id, type, message FROM inserted
@@ -31,6 +50,8 @@ func CreateDeploymentRevisionStatus(ctx context.Context, status *types.Deploymen
 			"deploymentRevisionId": status.DeploymentRevisionID,
 			"message":              status.Message,
 			"type":                 status.Type,
+			"idempotencyKey":       idempotencyKey,
+			"createdAt":            createdAt,
 		},
 	)
 	if err != nil {
@@ -40,6 +61,15 @@ func CreateDeploymentRevisionStatus(ctx context.Context, status *types.Deploymen
 	if res, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.DeploymentRevisionStatus]); err != nil {
 		if pgErr := new(pgconn.PgError); errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
 			err = fmt.Errorf("%w: %w", apierrors.ErrConflict, err)
+		} else if errors.Is(err, pgx.ErrNoRows) && idempotencyKey != nil {
+			if existing, err1 := getDeploymentRevisionStatusByIdempotencyKey(
+				ctx, status.DeploymentRevisionID, *idempotencyKey,
+			); err1 != nil {
+				return err1
+			} else {
+				*status = *existing
+				err = fmt.Errorf("%w: deployment revision status with this idempotency key", apierrors.ErrAlreadyExists)
+			}
 		}
 		return err
 	} else {
@@ -49,6 +79,32 @@ func CreateDeploymentRevisionStatus(ctx context.Context, status *types.Deploymen
 	return nil
 }
 
+func getDeploymentRevisionStatusByIdempotencyKey(
+	ctx context.Context,
+	deploymentRevisionID uuid.UUID,
+	idempotencyKey uuid.UUID,
+) (*types.DeploymentRevisionStatus, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT id, created_at, deployment_revision_id, type, message
+		FROM DeploymentRevisionStatus
+		WHERE deployment_revision_id = @deploymentRevisionId AND idempotency_key = @idempotencyKey`,
+		pgx.NamedArgs{
+			"deploymentRevisionId": deploymentRevisionID,
+			"idempotencyKey":       idempotencyKey,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	res, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[types.DeploymentRevisionStatus])
+	if err != nil {
+		return nil, fmt.Errorf("could not get deployment revision status by idempotency key: %w", err)
+	}
+	return res, nil
+}
+
 func BulkCreateDeploymentRevisionStatusWithCreatedAt(
 	ctx context.Context,
 	deploymentRevisionID uuid.UUID,
@@ -71,6 +127,100 @@ func BulkCreateDeploymentRevisionStatusWithCreatedAt(
 	return err
 }
 
+// BulkCreateDeploymentRevisionStatus inserts the given statuses and returns the inserted statuses in the order they
+// were given. The statuses are copied into a temporary staging table first, so that statuses with an idempotency key
+// that was already submitted for the same revision, also by a concurrent request, are skipped instead of failing the
+// whole batch. Statuses are created at the time they were observed, if it is given and not in the future. All other
+// statuses get consecutive creation times, so that their order is preserved.
+func BulkCreateDeploymentRevisionStatus(
+	ctx context.Context,
+	statuses []api.AgentDeploymentStatus,
+) ([]types.DeploymentRevisionStatus, error) {
+	now := time.Now()
+	candidates := make([]types.DeploymentRevisionStatus, len(statuses))
+	for i, s := range statuses {
+		createdAt := now.Add(time.Duration(i) * time.Microsecond)
+		if s.ObservedAt != nil && s.ObservedAt.Before(createdAt) {
+			createdAt = *s.ObservedAt
+		}
+		candidates[i] = types.DeploymentRevisionStatus{
+			ID:                   uuid.New(),
+			CreatedAt:            createdAt,
+			DeploymentRevisionID: s.RevisionID,
+			Type:                 s.Type,
+			Message:              truncateStatusMessage(ctx, s.Message),
+		}
+	}
+
+	inserted := make(map[uuid.UUID]struct{}, len(candidates))
+	err := RunTx(ctx, func(ctx context.Context) error {
+		db := internalctx.GetDb(ctx)
+		if _, err := db.Exec(
+			ctx,
+			`CREATE TEMP TABLE DeploymentRevisionStatusStaging
+			(LIKE DeploymentRevisionStatus INCLUDING DEFAULTS)
+			ON COMMIT DROP`,
+		); err != nil {
+			return fmt.Errorf("failed to create DeploymentRevisionStatus staging table: %w", err)
+		}
+		if _, err := db.CopyFrom(
+			ctx,
+			pgx.Identifier{"deploymentrevisionstatusstaging"},
+			[]string{"id", "created_at", "deployment_revision_id", "type", "message", "idempotency_key"},
+			pgx.CopyFromSlice(len(candidates), func(i int) ([]any, error) {
+				return []any{
+					candidates[i].ID,
+					candidates[i].CreatedAt,
+					candidates[i].DeploymentRevisionID,
+					candidates[i].Type,
+					candidates[i].Message,
+					statuses[i].IdempotencyKey,
+				}, nil
+			}),
+		); err != nil {
+			return fmt.Errorf("failed to copy DeploymentRevisionStatus: %w", err)
+		}
+		rows, err := db.Query(
+			ctx,
+			`INSERT INTO DeploymentRevisionStatus
+				(id, created_at, deployment_revision_id, type, message, idempotency_key)
+			SELECT id, created_at, deployment_revision_id, type, message, idempotency_key
+			FROM DeploymentRevisionStatusStaging
+			ON CONFLICT (deployment_revision_id, idempotency_key) DO NOTHING
+			RETURNING id`,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert DeploymentRevisionStatus: %w", err)
+		}
+		var id uuid.UUID
+		if _, err := pgx.ForEachRow(rows, []any{&id}, func() error {
+			inserted[id] = struct{}{}
+			return nil
+		}); err != nil {
+			if pgErr := new(pgconn.PgError); errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return fmt.Errorf("%w: %w", apierrors.ErrConflict, err)
+			}
+			return fmt.Errorf("failed to insert DeploymentRevisionStatus: %w", err)
+		}
+		// the table is only dropped on commit of the outermost transaction
+		if _, err := db.Exec(ctx, "DROP TABLE DeploymentRevisionStatusStaging"); err != nil {
+			return fmt.Errorf("failed to drop DeploymentRevisionStatus staging table: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.DeploymentRevisionStatus, 0, len(inserted))
+	for _, s := range candidates {
+		if _, ok := inserted[s.ID]; ok {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
 func GetDeploymentRevisionStatus(
 	ctx context.Context,
 	deploymentID uuid.UUID,
@@ -244,3 +394,14 @@ func CleanupDeploymentRevisionStatus(ctx context.Context) (int64, error) {
 		return cmd.RowsAffected(), nil
 	}
 }
+
+// truncateStatusMessage shortens message to the length configured with STATUS_MESSAGE_MAX_LENGTH.
+func truncateStatusMessage(ctx context.Context, message string) string {
+	if truncated, ok := util.Truncate(message, env.StatusMessageMaxLength()); ok {
+		internalctx.GetLogger(ctx).Warn("status message was truncated",
+			zap.Int("originalLength", len(message)),
+			zap.Int("maxLength", env.StatusMessageMaxLength()))
+		return truncated
+	}
+	return message
+}
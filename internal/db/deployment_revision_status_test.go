@@ -0,0 +1,168 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+// createTestDeploymentRevision creates an organization with a docker deployment target and a deployment of an
+// application to it, and returns the revision of that deployment.
+func createTestDeploymentRevision(t *testing.T, ctx context.Context) *types.DeploymentRevision {
+	g := NewWithT(t)
+	org := dbtest.CreateOrganization(t, ctx)
+	user := dbtest.CreateUserAccount(t, ctx)
+	agentVersions, err := db.GetAgentVersions(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(agentVersions).NotTo(BeEmpty())
+	target := types.DeploymentTargetFull{DeploymentTarget: types.DeploymentTarget{
+		Name:           "Status Test",
+		Type:           types.DeploymentTypeDocker,
+		AgentVersionID: &agentVersions[0].ID,
+	}}
+	g.Expect(db.CreateDeploymentTarget(ctx, &target, org.ID, user.ID, nil)).To(Succeed())
+	application := types.Application{Name: "Status Test", Type: types.DeploymentTypeDocker}
+	g.Expect(db.CreateApplication(ctx, &application, org.ID)).To(Succeed())
+	version := types.ApplicationVersion{
+		Name:            "1.0.0",
+		ApplicationID:   application.ID,
+		ComposeFileData: []byte("services: {}"),
+	}
+	g.Expect(db.CreateApplicationVersion(ctx, &version)).To(Succeed())
+	request := api.DeploymentRequest{
+		DeploymentTargetID:   target.ID,
+		ApplicationVersionID: version.ID,
+		DockerType:           util.PtrTo(types.DockerTypeCompose),
+	}
+	g.Expect(db.CreateDeployment(ctx, &request)).To(Succeed())
+	revision, err := db.CreateDeploymentRevision(ctx, &request)
+	g.Expect(err).NotTo(HaveOccurred())
+	return revision
+}
+
+// TestCreateDeploymentRevisionStatusCreatedAt checks that a status is stored with the time it was observed, unless
+// that time lies in the future.
+func TestCreateDeploymentRevisionStatusCreatedAt(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	revision := createTestDeploymentRevision(t, ctx)
+
+	create := func(g *WithT, createdAt time.Time) types.DeploymentRevisionStatus {
+		status := types.DeploymentRevisionStatus{
+			CreatedAt:            createdAt,
+			DeploymentRevisionID: revision.ID,
+			Type:                 types.DeploymentStatusTypeHealthy,
+			Message:              "status",
+		}
+		g.Expect(db.CreateDeploymentRevisionStatus(ctx, &status, util.PtrTo(uuid.New()))).To(Succeed())
+		return status
+	}
+
+	t.Run("observed in the past", func(t *testing.T) {
+		g := NewWithT(t)
+		observedAt := time.Now().Add(-time.Hour)
+		g.Expect(create(g, observedAt).CreatedAt).To(BeTemporally("~", observedAt, time.Millisecond))
+	})
+
+	t.Run("observed in the future", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(create(g, time.Now().Add(time.Hour)).CreatedAt).To(BeTemporally("<=", time.Now()))
+	})
+
+	t.Run("not observed", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(create(g, time.Time{}).CreatedAt).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+}
+
+// TestBulkCreateDeploymentRevisionStatusObservedAt checks that statuses are stored with the time they were observed,
+// unless that time lies in the future.
+func TestBulkCreateDeploymentRevisionStatusObservedAt(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+	revision := createTestDeploymentRevision(t, ctx)
+
+	observedAt := time.Now().Add(-time.Hour)
+	status := func(observedAt *time.Time) api.AgentDeploymentStatus {
+		return api.AgentDeploymentStatus{
+			RevisionID:     revision.ID,
+			Type:           types.DeploymentStatusTypeHealthy,
+			Message:        "status",
+			IdempotencyKey: util.PtrTo(uuid.New()),
+			ObservedAt:     observedAt,
+		}
+	}
+	created, err := db.BulkCreateDeploymentRevisionStatus(ctx, []api.AgentDeploymentStatus{
+		status(&observedAt),
+		status(util.PtrTo(time.Now().Add(time.Hour))),
+		status(nil),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(created).To(HaveLen(3))
+	g.Expect(created[0].CreatedAt).To(BeTemporally("==", observedAt))
+	g.Expect(created[1].CreatedAt).To(BeTemporally("<=", time.Now()))
+	g.Expect(created[2].CreatedAt).To(BeTemporally(">", created[1].CreatedAt))
+
+	stored, err := db.GetDeploymentRevisionStatus(ctx, revision.DeploymentID, 10, time.Time{}, time.Time{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stored).To(HaveLen(3))
+	g.Expect(stored[len(stored)-1].CreatedAt).To(BeTemporally("~", observedAt, time.Millisecond))
+}
+
+// TestBulkCreateDeploymentRevisionStatusIdempotencyKey checks that statuses with an idempotency key that was already
+// submitted, in the same or an earlier batch, are skipped.
+func TestBulkCreateDeploymentRevisionStatusIdempotencyKey(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+	revision := createTestDeploymentRevision(t, ctx)
+
+	status := func(message string, idempotencyKey *uuid.UUID) api.AgentDeploymentStatus {
+		return api.AgentDeploymentStatus{
+			RevisionID:     revision.ID,
+			Type:           types.DeploymentStatusTypeHealthy,
+			Message:        message,
+			IdempotencyKey: idempotencyKey,
+		}
+	}
+	existingKey, duplicateKey := uuid.New(), uuid.New()
+	created, err := db.BulkCreateDeploymentRevisionStatus(ctx,
+		[]api.AgentDeploymentStatus{status("existing", &existingKey)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(created).To(HaveLen(1))
+
+	created, err = db.BulkCreateDeploymentRevisionStatus(ctx, []api.AgentDeploymentStatus{
+		status("retried", &existingKey),
+		status("first", &duplicateKey),
+		status("duplicate", &duplicateKey),
+		status("without key", nil),
+		status("new", util.PtrTo(uuid.New())),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	var messages []string
+	for _, s := range created {
+		messages = append(messages, s.Message)
+	}
+	g.Expect(messages).To(Equal([]string{"first", "without key", "new"}))
+
+	created, err = db.BulkCreateDeploymentRevisionStatus(ctx,
+		[]api.AgentDeploymentStatus{status("retried", &duplicateKey)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(created).To(BeEmpty())
+
+	stored, err := db.GetDeploymentRevisionStatus(ctx, revision.DeploymentID, 10, time.Time{}, time.Time{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stored).To(HaveLen(4))
+
+	_, err = db.BulkCreateDeploymentRevisionStatus(ctx, []api.AgentDeploymentStatus{
+		{RevisionID: uuid.New(), Type: types.DeploymentStatusTypeHealthy, Message: "unknown revision"},
+	})
+	g.Expect(err).To(MatchError(apierrors.ErrConflict))
+}
@@ -10,6 +10,7 @@ import (
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
@@ -100,10 +101,37 @@ func SaveDeploymentTargetLogRecords(
 	return err
 }
 
+// TrimDeploymentTargetLogRecords deletes all but the newest [limit] log records of the given deployment target.
+func TrimDeploymentTargetLogRecords(ctx context.Context, deploymentTargetID uuid.UUID, limit int) (int64, error) {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(
+		ctx,
+		`DELETE FROM DeploymentTargetLogRecord
+		WHERE id IN (
+			SELECT id
+			FROM DeploymentTargetLogRecord
+			WHERE deployment_target_id = @deploymentTargetId
+			ORDER BY timestamp DESC
+			OFFSET @limit
+		)`,
+		pgx.NamedArgs{"deploymentTargetId": deploymentTargetID, "limit": limit},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not trim DeploymentTargetLogRecord: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
+
+// CleanupDeploymentTargetLogRecords deletes log records for all deployment targets but keeps the last
+// [env.LogRecordEntriesMaxCount] records (or the override of the deployment target) for each deployment target.
+//
+// If [env.LogRecordEntriesMaxCount] is nil, only the log records of deployment targets with an override are cleaned up.
 func CleanupDeploymentTargetLogRecords(ctx context.Context) (int64, error) {
-	limit := env.LogRecordEntriesMaxCount()
-	if limit == nil {
-		return 0, nil
+	var limit *int
+	if maxCount := env.LogRecordEntriesMaxCount(); maxCount != nil {
+		// "limit + 1" because we want to get the newest record that should be deleted.
+		// This is an optimization to avoid unnecessary queries for resources that have exactly [limit] entries.
+		limit = util.PtrTo(*maxCount + 1)
 	}
 
 	db := internalctx.GetDb(ctx)
@@ -112,14 +140,15 @@ func CleanupDeploymentTargetLogRecords(ctx context.Context) (int64, error) {
 	rows, err := db.Query(
 		ctx,
 		`SELECT `+deploymentTargetLogRecordOutputExpr+` FROM (
-			SELECT *,
-				row_number() OVER (PARTITION BY (deployment_target_id) ORDER BY timestamp DESC) AS rnk
-				FROM DeploymentTargetLogRecord
+			SELECT lr.*,
+				coalesce(dt.log_record_entries_max_count + 1, @limit::INTEGER) AS max_rnk,
+				row_number() OVER (PARTITION BY (lr.deployment_target_id) ORDER BY lr.timestamp DESC) AS rnk
+				FROM DeploymentTargetLogRecord lr
+				JOIN DeploymentTarget dt ON dt.id = lr.deployment_target_id
+				WHERE @limit::INTEGER IS NOT NULL OR dt.log_record_entries_max_count IS NOT NULL
 		) lr
-		WHERE rnk = @limit`,
-		// "limit + 1" because we want to get the newest record that should be deleted.
-		// This is an optimization to avoid unnecessary queries for resources that have exactly [limit] entries.
-		pgx.NamedArgs{"limit": *limit + 1},
+		WHERE rnk = max_rnk`,
+		pgx.NamedArgs{"limit": limit},
 	)
 	if err != nil {
 		return 0, fmt.Errorf("error querying DeploymentTargetLogRecord: %w", err)
@@ -3,10 +3,10 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/distr-sh/distr/api"
 	internalctx "github.com/distr-sh/distr/internal/context"
-	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -86,10 +86,20 @@ func CreateDeploymentTargetMetrics(
 	}
 }
 
-func CleanupDeploymentTargetMetrics(ctx context.Context) (int64, error) {
-	if env.MetricsEntriesMaxAge() == nil {
-		return 0, nil
+// CleanupDeploymentTargetMetrics deletes all metrics except the latest of each deployment target that are older than
+// the max age for the subscription type of its organization. Organizations with a subscription type that is not in
+// maxAges are skipped.
+func CleanupDeploymentTargetMetrics(
+	ctx context.Context,
+	maxAges map[types.SubscriptionType]time.Duration,
+) (int64, error) {
+	subscriptionTypes := make([]string, 0, len(maxAges))
+	maxAgeSeconds := make([]float64, 0, len(maxAges))
+	for st, maxAge := range maxAges {
+		subscriptionTypes = append(subscriptionTypes, string(st))
+		maxAgeSeconds = append(maxAgeSeconds, maxAge.Seconds())
 	}
+
 	db := internalctx.GetDb(ctx)
 	if cmd, err := db.Exec(
 		ctx,
@@ -98,13 +108,17 @@ func CleanupDeploymentTargetMetrics(ctx context.Context) (int64, error) {
 			SELECT
 				dt.id AS deployment_target_id,
 				(SELECT max(created_at) FROM DeploymentTargetMetrics WHERE deployment_target_id = dt.id)
-					AS max_created_at
+					AS max_created_at,
+				make_interval(secs => ma.max_age_seconds) AS max_age
 			FROM DeploymentTarget dt
+			JOIN Organization o ON o.id = dt.organization_id
+			JOIN unnest(@subscriptionTypes::TEXT[], @maxAgeSeconds::FLOAT8[]) AS ma(subscription_type, max_age_seconds)
+				ON ma.subscription_type = o.subscription_type::TEXT
 		) max_created_at
 		WHERE dtm.deployment_target_id = max_created_at.deployment_target_id
 			AND dtm.created_at < max_created_at.max_created_at
-			AND current_timestamp - dtm.created_at > @metricsEntriesMaxAge`,
-		pgx.NamedArgs{"metricsEntriesMaxAge": env.MetricsEntriesMaxAge()},
+			AND current_timestamp - dtm.created_at > max_created_at.max_age`,
+		pgx.NamedArgs{"subscriptionTypes": subscriptionTypes, "maxAgeSeconds": maxAgeSeconds},
 	); err != nil {
 		return 0, err
 	} else {
@@ -9,6 +9,7 @@ import (
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
@@ -33,7 +34,9 @@ const (
 			dt.resources_memory_request,
 			dt.resources_cpu_limit,
 			dt.resources_memory_limit
-		) END
+		) END,
+		dt.log_record_entries_max_count,
+		dt.access_key_rotated_at
 	`
 	deploymentTargetOutputExpr = deploymentTargetOutputExprBase +
 		", CASE WHEN co.id IS NOT NULL THEN (" + customerOrganizationOutputExpr + ") END AS customer_organization"
@@ -43,7 +46,11 @@ const (
 			AS current_status,
 		CASE WHEN agv.id IS NOT NULL
 			THEN (agv.id, agv.created_at, agv.name, agv.manifest_file_revision, agv.compose_file_revision) END
-			AS agent_version
+			AS agent_version,
+		dt.last_seen_at,
+		CASE WHEN ragv.id IS NOT NULL
+			THEN (ragv.id, ragv.created_at, ragv.name, ragv.manifest_file_revision, ragv.compose_file_revision) END
+			AS reported_agent_version
 	`
 	deploymentTargetJoinExpr = `
 		LEFT JOIN (
@@ -61,6 +68,8 @@ const (
 			AND status.created_at = status_max.max_created_at
 		LEFT JOIN AgentVersion agv
 			ON dt.agent_version_id = agv.id
+		LEFT JOIN AgentVersion ragv
+			ON dt.reported_agent_version_id = ragv.id
 		LEFT JOIN CustomerOrganization co
 			ON dt.customer_organization_id = co.id
 		LEFT JOIN Organization o
@@ -93,6 +102,7 @@ func GetDeploymentTargets(
 		return nil, fmt.Errorf("failed to get DeploymentTargets: %w", err)
 	} else {
 		for i := range result {
+			setComputedFields(&result[i])
 			if err := addDeploymentsToTarget(ctx, &result[i]); err != nil {
 				return nil, err
 			}
@@ -150,6 +160,7 @@ func GetDeploymentTarget(
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get DeploymentTarget: %w", err)
 	} else {
+		setComputedFields(&result)
 		return &result, addDeploymentsToTarget(ctx, &result)
 	}
 }
@@ -174,6 +185,7 @@ func GetDeploymentTargetForDeploymentID(
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get DeploymentTarget: %w", err)
 	} else {
+		setComputedFields(&result)
 		return &result, addDeploymentsToTarget(ctx, &result)
 	}
 }
@@ -196,6 +208,7 @@ func CreateDeploymentTarget(
 		"agentVersionId": dt.AgentVersionID,
 		"metricsEnabled": dt.MetricsEnabled,
 		"customerOrgId":  customerOrgID,
+		"logMaxCount":    dt.LogRecordEntriesMaxCount,
 	}
 
 	if dt.Resources != nil {
@@ -211,9 +224,9 @@ func CreateDeploymentTarget(
 			INSERT INTO DeploymentTarget
 			(name, type, organization_id, namespace, scope, agent_version_id, metrics_enabled,
 				customer_organization_id, resources_cpu_request, resources_memory_request, resources_cpu_limit,
-				resources_memory_limit)
+				resources_memory_limit, log_record_entries_max_count)
 			VALUES (@name, @type, @orgId, @namespace, @scope, @agentVersionId, @metricsEnabled, @customerOrgId,
-				@resourcesCpuRequest, @resourcesMemoryRequest, @resourcesCpuLimit, @resourcesMemoryLimit)
+				@resourcesCpuRequest, @resourcesMemoryRequest, @resourcesCpuLimit, @resourcesMemoryLimit, @logMaxCount)
 			RETURNING *
 		)
 		SELECT `+deploymentTargetFullOutputExpr+` FROM inserted dt`+deploymentTargetJoinExpr,
@@ -227,6 +240,7 @@ func CreateDeploymentTarget(
 		return fmt.Errorf("could not save DeploymentTarget: %w", err)
 	} else {
 		*dt = result
+		setComputedFields(dt)
 		return addDeploymentsToTarget(ctx, dt)
 	}
 }
@@ -239,6 +253,7 @@ func UpdateDeploymentTarget(ctx context.Context, dt *types.DeploymentTargetFull,
 		"name":           dt.Name,
 		"orgId":          orgID,
 		"metricsEnabled": dt.MetricsEnabled,
+		"logMaxCount":    dt.LogRecordEntriesMaxCount,
 	}
 	if dt.AgentVersionID != nil {
 		args["agentVersionId"] = dt.AgentVersionID
@@ -258,7 +273,8 @@ func UpdateDeploymentTarget(ctx context.Context, dt *types.DeploymentTargetFull,
 				resources_cpu_request = @cpuRequest,
 				resources_cpu_limit = @cpuLimit,
 				resources_memory_request = @memoryRequest,
-				resources_memory_limit = @memoryLimit `+agentUpdateStr+`
+				resources_memory_limit = @memoryLimit,
+				log_record_entries_max_count = @logMaxCount `+agentUpdateStr+`
 			WHERE id = @id AND organization_id = @orgId RETURNING *
 		)
 		SELECT `+deploymentTargetFullOutputExpr+` FROM updated dt`+deploymentTargetJoinExpr,
@@ -271,6 +287,7 @@ func UpdateDeploymentTarget(ctx context.Context, dt *types.DeploymentTargetFull,
 		return fmt.Errorf("could not get updated DeploymentTarget: %w", err)
 	} else {
 		*dt = updated
+		setComputedFields(dt)
 		return addDeploymentsToTarget(ctx, dt)
 	}
 }
@@ -286,12 +303,15 @@ func DeleteDeploymentTargetWithID(ctx context.Context, id uuid.UUID) error {
 	}
 }
 
+// UpdateDeploymentTargetAccess replaces the access key of the deployment target and records the time of the change,
+// so that agent tokens issued with the previous access key are rejected.
 func UpdateDeploymentTargetAccess(ctx context.Context, dt *types.DeploymentTarget, orgID uuid.UUID) error {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(ctx,
-		"UPDATE DeploymentTarget AS dt SET access_key_salt = @accessKeySalt, access_key_hash = @accessKeyHash "+
-			"WHERE id = @id AND organization_id = @orgId RETURNING "+
-			deploymentTargetOutputExprBase,
+		`UPDATE DeploymentTarget AS dt
+		SET access_key_salt = @accessKeySalt, access_key_hash = @accessKeyHash, access_key_rotated_at = now()
+		WHERE id = @id AND organization_id = @orgId
+		RETURNING `+deploymentTargetOutputExprBase,
 		pgx.NamedArgs{"accessKeySalt": dt.AccessKeySalt, "accessKeyHash": dt.AccessKeyHash, "id": dt.ID, "orgId": orgID})
 	if err != nil {
 		return fmt.Errorf("could not update DeploymentTarget: %w", err)
@@ -333,6 +353,19 @@ func UpdateDeploymentTargetReportedAgentVersionID(
 	}
 }
 
+func UpdateDeploymentTargetLastSeenAt(ctx context.Context, id uuid.UUID) error {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(ctx,
+		"UPDATE DeploymentTarget SET last_seen_at = now() WHERE id = @id",
+		pgx.NamedArgs{"id": id})
+	if err != nil {
+		return fmt.Errorf("could not update DeploymentTarget last seen: %w", err)
+	} else if cmd.RowsAffected() == 0 {
+		return apierrors.ErrNotFound
+	}
+	return nil
+}
+
 func CreateDeploymentTargetStatus(ctx context.Context, dt *types.DeploymentTarget, message string) error {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(ctx,
@@ -372,6 +405,18 @@ func CleanupDeploymentTargetStatus(ctx context.Context) (int64, error) {
 	}
 }
 
+// setComputedFields sets the fields of dt that are not stored in the database but derived from the stored fields and
+// the instance configuration.
+func setComputedFields(dt *types.DeploymentTargetFull) {
+	dt.EffectiveLogRecordEntriesMaxCount = dt.GetEffectiveLogRecordEntriesMaxCount(env.LogRecordEntriesMaxCount())
+	dt.Online = dt.IsOnline()
+	if dt.ReportedAgentVersion != nil {
+		if err := dt.ReportedAgentVersion.CheckMinVersion(env.AgentMinVersion()); err != nil {
+			dt.AgentVersionWarning = util.PtrTo(err.Error())
+		}
+	}
+}
+
 func addDeploymentsToTarget(ctx context.Context, dt *types.DeploymentTargetFull) error {
 	if d, err := GetDeploymentsForDeploymentTarget(ctx, dt.ID); errors.Is(err, apierrors.ErrNotFound) {
 		return nil
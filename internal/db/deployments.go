@@ -11,6 +11,7 @@ import (
 	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/apierrors"
 	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
@@ -139,6 +140,80 @@ func GetDeploymentsForDeploymentTarget(
 	return result, nil
 }
 
+// GetDeploymentsForOrganization returns the deployments of all deployment targets of the organization that the user
+// has access to, ordered by deployment target name. Like [GetLatestDeploymentRevisionStatus], the latest status is
+// the newest status of any revision of the deployment, so that an update does not hide the status of the previous
+// revision until the agent reports the new one.
+func GetDeploymentsForOrganization(
+	ctx context.Context,
+	orgID uuid.UUID,
+	customerOrganizationID *uuid.UUID,
+	filter types.DeploymentListFilter,
+) ([]types.DeploymentOverview, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT`+deploymentOutputExpr+`,
+				dt.name AS deployment_target_name,
+				dt.customer_organization_id,
+				dt.last_seen_at,
+				a.name AS application_name,
+				av.name AS application_version_name,
+				CASE WHEN drs.id IS NOT NULL THEN (
+					drs.id,
+					drs.created_at,
+					drs.deployment_revision_id,
+					drs.type, drs.message
+				) END AS latest_status
+			FROM Deployment d
+				JOIN DeploymentTarget dt ON d.deployment_target_id = dt.id
+				JOIN LATERAL (
+					SELECT dr1.application_version_id
+					FROM DeploymentRevision dr1
+					WHERE dr1.deployment_id = d.id
+					ORDER BY dr1.created_at DESC
+					LIMIT 1
+				) dr ON true
+				JOIN ApplicationVersion av ON dr.application_version_id = av.id
+				JOIN Application a ON av.application_id = a.id
+				LEFT JOIN LATERAL (
+					SELECT s.id, s.created_at, s.deployment_revision_id, s.type, s.message
+					FROM DeploymentRevisionStatus s
+						JOIN DeploymentRevision dr1 ON s.deployment_revision_id = dr1.id
+					WHERE dr1.deployment_id = d.id
+					ORDER BY s.created_at DESC
+					LIMIT 1
+				) drs ON true
+			WHERE dt.organization_id = @orgId
+				AND (@isVendor OR dt.customer_organization_id = @customerOrganizationId)
+				AND (@deploymentTargetId::UUID IS NULL OR dt.id = @deploymentTargetId)
+				AND (@statusType = '' OR drs.type::TEXT = @statusType)
+			ORDER BY dt.name, d.created_at, d.id
+			LIMIT @limit OFFSET @offset`,
+		pgx.NamedArgs{
+			"orgId":                  orgID,
+			"isVendor":               customerOrganizationID == nil,
+			"customerOrganizationId": customerOrganizationID,
+			"deploymentTargetId":     filter.DeploymentTargetID,
+			"statusType":             string(filter.StatusType),
+			"limit":                  filter.Limit,
+			"offset":                 filter.Offset,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Deployments: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.DeploymentOverview])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan Deployments: %w", err)
+	}
+	for i := range result {
+		result[i].Stale = result[i].LatestStatus.IsStale(env.DeploymentStatusStaleDuration())
+		result[i].AgentOnline = result[i].IsAgentOnline()
+	}
+	return result, nil
+}
+
 func TemplateApplicationLink(link string, envFileData []byte, valuesYaml []byte) (string, error) {
 	if link == "" {
 		return "", nil
@@ -376,6 +451,31 @@ func GetDeploymentIDForRevisionID(ctx context.Context, revisionID uuid.UUID) (uu
 	return deploymentID, nil
 }
 
+// GetDeploymentIDsForRevisionIDs returns a map from revision ID to deployment ID. Revisions that do not exist are
+// missing from the map.
+func GetDeploymentIDsForRevisionIDs(ctx context.Context, revisionIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		"SELECT id, deployment_id from DeploymentRevision WHERE id = ANY(@revisionIds)",
+		pgx.NamedArgs{"revisionIds": revisionIDs},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Deployment IDs: %w", err)
+	}
+
+	result := make(map[uuid.UUID]uuid.UUID, len(revisionIDs))
+	var revisionID, deploymentID uuid.UUID
+	if _, err := pgx.ForEachRow(rows, []any{&revisionID, &deploymentID}, func() error {
+		result[revisionID] = deploymentID
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to scan Deployment IDs: %w", err)
+	}
+
+	return result, nil
+}
+
 func GetDeploymentRevisionIDs(ctx context.Context, deploymentID uuid.UUID) ([]uuid.UUID, error) {
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	jobExecutionOutputExpr = `e.id, e.job_name, e.started_at, e.finished_at, e.error, e.rows_affected`
+
+	// jobExecutionHistoryLimit is the number of executions that are kept per job.
+	jobExecutionHistoryLimit = 100
+)
+
+// CreateJobExecution records an execution of a job. Only the most recent executions of each job are kept, older ones
+// are deleted.
+func CreateJobExecution(ctx context.Context, execution *types.JobExecution) error {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`INSERT INTO JobExecution AS e (job_name, started_at, finished_at, error, rows_affected)
+		VALUES (@jobName, @startedAt, @finishedAt, @error, @rowsAffected)
+		RETURNING `+jobExecutionOutputExpr,
+		pgx.NamedArgs{
+			"jobName":      execution.JobName,
+			"startedAt":    execution.StartedAt,
+			"finishedAt":   execution.FinishedAt,
+			"error":        execution.Error,
+			"rowsAffected": execution.RowsAffected,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert JobExecution: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.JobExecution])
+	if err != nil {
+		return fmt.Errorf("could not collect JobExecution: %w", err)
+	}
+	*execution = result
+
+	_, err = db.Exec(
+		ctx,
+		`DELETE FROM JobExecution
+		WHERE job_name = @jobName
+			AND id NOT IN (
+				SELECT id FROM JobExecution WHERE job_name = @jobName ORDER BY started_at DESC LIMIT @limit
+			)`,
+		pgx.NamedArgs{"jobName": execution.JobName, "limit": jobExecutionHistoryLimit},
+	)
+	if err != nil {
+		return fmt.Errorf("could not delete old JobExecution: %w", err)
+	}
+	return nil
+}
+
+// GetLatestJobExecutions returns the most recent execution of every job that has been executed.
+func GetLatestJobExecutions(ctx context.Context) ([]types.JobExecution, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(
+		ctx,
+		`SELECT DISTINCT ON (e.job_name) `+jobExecutionOutputExpr+`
+		FROM JobExecution e
+		ORDER BY e.job_name, e.started_at DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query JobExecution: %w", err)
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.JobExecution])
+	if err != nil {
+		return nil, fmt.Errorf("could not collect JobExecution: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+// TestJobExecutions checks that the latest execution of every job is returned and that old executions are deleted.
+func TestJobExecutions(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+	suffix := time.Now().UnixNano()
+	jobA := fmt.Sprintf("job-a-%v", suffix)
+	jobB := fmt.Sprintf("job-b-%v", suffix)
+	startedAt := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+
+	for i := range 105 {
+		execution := types.JobExecution{
+			JobName:    jobA,
+			StartedAt:  startedAt.Add(time.Duration(i) * time.Second),
+			FinishedAt: startedAt.Add(time.Duration(i)*time.Second + 500*time.Millisecond),
+		}
+		g.Expect(db.CreateJobExecution(ctx, &execution)).To(Succeed())
+		g.Expect(execution.ID).NotTo(BeZero())
+	}
+	failed := types.JobExecution{
+		JobName:      jobB,
+		StartedAt:    startedAt,
+		FinishedAt:   startedAt.Add(time.Minute),
+		Error:        util.PtrTo("boom"),
+		RowsAffected: util.PtrTo(int64(3)),
+	}
+	g.Expect(db.CreateJobExecution(ctx, &failed)).To(Succeed())
+
+	executions, err := db.GetLatestJobExecutions(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	latest := map[string]types.JobExecution{}
+	for _, execution := range executions {
+		latest[execution.JobName] = execution
+	}
+	g.Expect(latest).To(HaveKey(jobA))
+	g.Expect(latest[jobA].StartedAt).To(BeTemporally("==", startedAt.Add(104*time.Second)))
+	g.Expect(latest[jobA].FinishedAt).To(BeTemporally("==", startedAt.Add(104*time.Second+500*time.Millisecond)))
+	g.Expect(latest[jobA].Error).To(BeNil())
+	g.Expect(latest[jobA].RowsAffected).To(BeNil())
+	g.Expect(latest).To(HaveKey(jobB))
+	g.Expect(latest[jobB].ID).To(Equal(failed.ID))
+	g.Expect(latest[jobB].Error).To(Equal(util.PtrTo("boom")))
+	g.Expect(latest[jobB].RowsAffected).To(Equal(util.PtrTo(int64(3))))
+
+	var count int
+	g.Expect(internalctx.GetDb(ctx).QueryRow(ctx, "SELECT count(*) FROM JobExecution WHERE job_name = $1", jobA).
+		Scan(&count)).To(Succeed())
+	g.Expect(count).To(Equal(100))
+}
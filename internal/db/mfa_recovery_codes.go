@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/security"
 	"github.com/distr-sh/distr/internal/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -55,6 +56,28 @@ func GetUnusedMFARecoveryCodes(ctx context.Context, userID uuid.UUID) ([]types.M
 	return codes, nil
 }
 
+// FindMatchingUnusedRecoveryCode returns the unused recovery code of the given user that matches code or nil if there is
+// no such code. The matched code is not marked as used.
+func FindMatchingUnusedRecoveryCode(
+	ctx context.Context,
+	userID uuid.UUID,
+	code string,
+) (*types.MFARecoveryCode, error) {
+	codes, err := GetUnusedMFARecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := security.NormalizeRecoveryCode(code)
+	for _, c := range codes {
+		if security.VerifyRecoveryCode(normalized, c.CodeSalt, c.CodeHash) {
+			return &c, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func CountUnusedMFARecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
 	db := internalctx.GetDb(ctx)
 	var count int
@@ -21,9 +21,38 @@ const notificationRecordOutputExpr = `
 	r.alert_configuration_id,
 	r.previous_deployment_revision_status_id,
 	r.current_deployment_revision_status_id,
-	r.message `
+	r.message,
+	r.delivered_at `
+
+const notificationRecordWithCurrentStatusOutputExpr = notificationRecordOutputExpr + `,
+	dt.name AS deployment_target_name,
+	co.name AS customer_organization_name,
+	a.name AS application_name,
+	av.name AS application_version_name,
+	CASE WHEN s.id IS NOT NULL THEN (
+		s.id, s.created_at, s.deployment_revision_id, s.type, s.message
+	) END current_deployment_revision_status `
+
+const notificationRecordWithCurrentStatusFromExpr = `
+	NotificationRecord r
+	LEFT JOIN DeploymentTarget dt
+		ON r.deployment_target_id = dt.id
+	LEFT JOIN CustomerOrganization co
+		ON dt.customer_organization_id = co.id
+	LEFT JOIN DeploymentRevisionStatus s
+		ON r.current_deployment_revision_status_id = s.id
+	LEFT JOIN DeploymentRevisionStatus s_prev
+		ON r.previous_deployment_revision_status_id = s_prev.id
+	LEFT JOIN DeploymentRevision dr
+		ON s.deployment_revision_id = dr.id
+			OR (s.id IS NULL AND s_prev.deployment_revision_id = dr.id)
+	LEFT JOIN ApplicationVersion av
+		ON dr.application_version_id = av.id
+	LEFT JOIN Application a
+		ON av.application_id = a.id `
 
 func SaveNotificationRecord(ctx context.Context, record *types.NotificationRecord) error {
+	record.Message = truncateStatusMessage(ctx, record.Message)
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(
 		ctx,
@@ -35,7 +64,8 @@ func SaveNotificationRecord(ctx context.Context, record *types.NotificationRecor
 				alert_configuration_id,
 				previous_deployment_revision_status_id,
 				current_deployment_revision_status_id,
-				message
+				message,
+				delivered_at
 			)
 			VALUES (
 				@organizationID,
@@ -44,7 +74,8 @@ func SaveNotificationRecord(ctx context.Context, record *types.NotificationRecor
 				@alertConfigurationID,
 				@previousDeploymentStatusID,
 				@currentDeploymentStatusID,
-				@message
+				@message,
+				@deliveredAt
 			)
 			RETURNING *
 		)
@@ -57,6 +88,7 @@ func SaveNotificationRecord(ctx context.Context, record *types.NotificationRecor
 			"previousDeploymentStatusID": record.PreviousDeploymentRevisionStatusID,
 			"currentDeploymentStatusID":  record.CurrentDeploymentRevisionStatusID,
 			"message":                    record.Message,
+			"deliveredAt":                record.DeliveredAt,
 		},
 	)
 	if err != nil {
@@ -111,30 +143,8 @@ func GetNotificationRecords(
 
 	rows, err := db.Query(
 		ctx,
-		`SELECT`+notificationRecordOutputExpr+`,
-			dt.name AS deployment_target_name,
-			co.name AS customer_organization_name,
-			a.name AS application_name,
-			av.name AS application_version_name,
-			CASE WHEN s.id IS NOT NULL THEN (
-				s.id, s.created_at, s.deployment_revision_id, s.type, s.message
-			) END current_deployment_revision_status
-		FROM NotificationRecord r
-		LEFT JOIN DeploymentTarget dt
-			ON r.deployment_target_id = dt.id
-		LEFT JOIN CustomerOrganization co
-			ON dt.customer_organization_id = co.id
-		LEFT JOIN DeploymentRevisionStatus s
-			ON r.current_deployment_revision_status_id = s.id
-		LEFT JOIN DeploymentRevisionStatus s_prev
-			ON r.previous_deployment_revision_status_id = s_prev.id
-		LEFT JOIN DeploymentRevision dr
-			ON s.deployment_revision_id = dr.id
-				OR (s.id IS NULL AND s_prev.deployment_revision_id = dr.id)
-		LEFT JOIN ApplicationVersion av
-			ON dr.application_version_id = av.id
-		LEFT JOIN Application a
-			ON av.application_id = a.id
+		`SELECT`+notificationRecordWithCurrentStatusOutputExpr+`
+		FROM`+notificationRecordWithCurrentStatusFromExpr+`
 		WHERE r.organization_id = @organizationID
 			AND ((@isVendor AND r.customer_organization_id IS NULL)
 				OR r.customer_organization_id = @customerOrganizationID)
@@ -156,3 +166,49 @@ func GetNotificationRecords(
 
 	return records, nil
 }
+
+// GetPendingNotificationRecords returns all records of the given alert configuration that have not been delivered yet,
+// oldest first.
+func GetPendingNotificationRecords(
+	ctx context.Context,
+	alertConfigurationID uuid.UUID,
+) ([]types.NotificationRecordWithCurrentStatus, error) {
+	db := internalctx.GetDb(ctx)
+
+	rows, err := db.Query(
+		ctx,
+		`SELECT`+notificationRecordWithCurrentStatusOutputExpr+`
+		FROM`+notificationRecordWithCurrentStatusFromExpr+`
+		WHERE r.alert_configuration_id = @alertConfigurationID
+			AND r.delivered_at IS NULL
+		ORDER BY r.created_at`,
+		pgx.NamedArgs{"alertConfigurationID": alertConfigurationID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending NotificationRecord: %w", err)
+	}
+
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[types.NotificationRecordWithCurrentStatus])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pending NotificationRecord: %w", err)
+	}
+
+	return records, nil
+}
+
+// MarkNotificationRecordsDelivered sets the delivery time of the given records. If message is not empty, it replaces
+// the message of the records.
+func MarkNotificationRecordsDelivered(ctx context.Context, ids []uuid.UUID, message string) error {
+	db := internalctx.GetDb(ctx)
+	_, err := db.Exec(
+		ctx,
+		`UPDATE NotificationRecord
+		SET delivered_at = now(), message = CASE WHEN @message = '' THEN message ELSE @message END
+		WHERE id = any(@ids)`,
+		pgx.NamedArgs{"ids": ids, "message": truncateStatusMessage(ctx, message)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark NotificationRecord as delivered: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/buildconfig"
@@ -24,6 +25,8 @@ const (
 		o.features,
 		o.app_domain,
 		o.registry_domain,
+		o.app_domain_verified_at,
+		o.registry_domain_verified_at,
 		o.email_from_address,
 		o.subscription_type,
 		o.subscription_period,
@@ -34,7 +37,10 @@ const (
 		o.subscription_user_account_quantity,
 		o.pre_connect_script,
 		o.post_connect_script,
-		o.connect_script_is_sudo
+		o.connect_script_is_sudo,
+		o.quota_exceeded_webhook_url,
+		o.quota_exceeded_email_enabled,
+		o.require_artifact_precreation
 	`
 	organizationWithUserRoleOutputExpr = organizationOutputExpr + `,
 		j.user_role,
@@ -54,14 +60,15 @@ func CreateOrganization(ctx context.Context, org *types.Organization) error {
 
 	db := internalctx.GetDb(ctx)
 	rows, err := db.Query(ctx,
-		`INSERT INTO Organization AS o (name, slug, subscription_type, features)
-		VALUES (@name, @slug, @subscription_type, @features)
+		`INSERT INTO Organization AS o (name, slug, subscription_type, features, require_artifact_precreation)
+		VALUES (@name, @slug, @subscription_type, @features, @require_artifact_precreation)
 		RETURNING `+organizationOutputExpr,
 		pgx.NamedArgs{
-			"name":              org.Name,
-			"slug":              org.Slug,
-			"subscription_type": org.SubscriptionType,
-			"features":          org.Features,
+			"name":                         org.Name,
+			"slug":                         org.Slug,
+			"subscription_type":            org.SubscriptionType,
+			"features":                     org.Features,
+			"require_artifact_precreation": org.RequireArtifactPrecreation,
 		},
 	)
 	if err != nil {
@@ -93,7 +100,10 @@ func UpdateOrganization(ctx context.Context, org *types.Organization) error {
 			subscription_user_account_quantity = @subscription_user_account_quantity,
 			pre_connect_script = @pre_connect_script,
 			post_connect_script = @post_connect_script,
-			connect_script_is_sudo = @connect_script_is_sudo
+			connect_script_is_sudo = @connect_script_is_sudo,
+			quota_exceeded_webhook_url = @quota_exceeded_webhook_url,
+			quota_exceeded_email_enabled = @quota_exceeded_email_enabled,
+			require_artifact_precreation = @require_artifact_precreation
 		WHERE id = @id
 		RETURNING `+organizationOutputExpr,
 		pgx.NamedArgs{
@@ -111,6 +121,9 @@ func UpdateOrganization(ctx context.Context, org *types.Organization) error {
 			"pre_connect_script":                          org.PreConnectScript,
 			"post_connect_script":                         org.PostConnectScript,
 			"connect_script_is_sudo":                      org.ConnectScriptIsSudo,
+			"quota_exceeded_webhook_url":                  org.QuotaExceededWebhookURL,
+			"quota_exceeded_email_enabled":                org.QuotaExceededEmailEnabled,
+			"require_artifact_precreation":                org.RequireArtifactPrecreation,
 		},
 	)
 	if err != nil {
@@ -266,3 +279,42 @@ func SetOrganizationDeletedAtNow(ctx context.Context, orgID uuid.UUID) error {
 	}
 	return nil
 }
+
+// ClaimQuotaExceededNotification records that a quota exceeded notification is about to be sent for the given
+// organization. It returns false if a notification has already been sent within the given interval, so that
+// concurrent pushes across all replicas result in at most one notification per interval. If the notification could
+// not be sent, the claim must be released with [ReleaseQuotaExceededNotification].
+func ClaimQuotaExceededNotification(ctx context.Context, orgID uuid.UUID, interval time.Duration) (bool, error) {
+	db := internalctx.GetDb(ctx)
+	cmd, err := db.Exec(
+		ctx,
+		`UPDATE Organization
+		SET quota_exceeded_notified_at = now()
+		WHERE id = @id
+			AND (
+				quota_exceeded_notified_at IS NULL
+				OR quota_exceeded_notified_at < now() - make_interval(secs => @interval)
+			)`,
+		pgx.NamedArgs{"id": orgID, "interval": interval.Seconds()},
+	)
+	if err != nil {
+		return false, fmt.Errorf("could not update Organization: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// ReleaseQuotaExceededNotification removes a claim made with [ClaimQuotaExceededNotification], so that the next
+// rejected push notifies the organization again. Since no other claim can succeed within the interval, the claim that
+// is removed is always the one made by the caller.
+func ReleaseQuotaExceededNotification(ctx context.Context, orgID uuid.UUID) error {
+	db := internalctx.GetDb(ctx)
+	_, err := db.Exec(
+		ctx,
+		`UPDATE Organization SET quota_exceeded_notified_at = NULL WHERE id = @id`,
+		pgx.NamedArgs{"id": orgID},
+	)
+	if err != nil {
+		return fmt.Errorf("could not update Organization: %w", err)
+	}
+	return nil
+}
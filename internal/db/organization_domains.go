@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const organizationDomainsOutputExpr = `
+	o.app_domain,
+	o.registry_domain,
+	o.domain_verification_token,
+	o.app_domain_verified_at,
+	o.registry_domain_verified_at
+`
+
+func GetOrganizationDomains(ctx context.Context, orgID uuid.UUID) (*types.OrganizationDomains, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`SELECT `+organizationDomainsOutputExpr+`
+		FROM Organization o
+		WHERE o.id = @id AND o.deleted_at IS NULL`,
+		pgx.NamedArgs{"id": orgID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not query Organization: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.OrganizationDomains])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not collect Organization: %w", err)
+	}
+	return &result, nil
+}
+
+// UpdateOrganizationDomains sets the custom domains of the given organization. The verification of a domain is reset
+// if it changed. If the organization does not have a verification token yet, the given token is stored.
+func UpdateOrganizationDomains(
+	ctx context.Context,
+	orgID uuid.UUID,
+	appDomain, registryDomain *string,
+	verificationToken string,
+) (*types.OrganizationDomains, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`UPDATE Organization AS o
+		SET app_domain = @appDomain,
+			registry_domain = @registryDomain,
+			domain_verification_token = coalesce(o.domain_verification_token, @token),
+			app_domain_verified_at = CASE
+				WHEN o.app_domain IS DISTINCT FROM @appDomain THEN NULL
+				ELSE o.app_domain_verified_at
+			END,
+			registry_domain_verified_at = CASE
+				WHEN o.registry_domain IS DISTINCT FROM @registryDomain THEN NULL
+				ELSE o.registry_domain_verified_at
+			END
+		WHERE o.id = @id AND o.deleted_at IS NULL
+		RETURNING `+organizationDomainsOutputExpr,
+		pgx.NamedArgs{
+			"id":             orgID,
+			"appDomain":      appDomain,
+			"registryDomain": registryDomain,
+			"token":          verificationToken,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not update Organization: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.OrganizationDomains])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not collect Organization: %w", err)
+	}
+	return &result, nil
+}
+
+// UpdateOrganizationDomainsVerified stores the result of a domain verification. A domain that was already verified
+// keeps its original verification timestamp, even if the check failed. The verification is only reset if the domain
+// is changed with [UpdateOrganizationDomains].
+func UpdateOrganizationDomainsVerified(
+	ctx context.Context,
+	orgID uuid.UUID,
+	appDomainVerified, registryDomainVerified bool,
+) (*types.OrganizationDomains, error) {
+	db := internalctx.GetDb(ctx)
+	rows, err := db.Query(ctx,
+		`UPDATE Organization AS o
+		SET app_domain_verified_at = CASE
+				WHEN @appVerified THEN coalesce(o.app_domain_verified_at, now())
+				ELSE o.app_domain_verified_at
+			END,
+			registry_domain_verified_at = CASE
+				WHEN @registryVerified THEN coalesce(o.registry_domain_verified_at, now())
+				ELSE o.registry_domain_verified_at
+			END
+		WHERE o.id = @id AND o.deleted_at IS NULL
+		RETURNING `+organizationDomainsOutputExpr,
+		pgx.NamedArgs{
+			"id":               orgID,
+			"appVerified":      appDomainVerified,
+			"registryVerified": registryDomainVerified,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not update Organization: %w", err)
+	}
+	result, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[types.OrganizationDomains])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierrors.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not collect Organization: %w", err)
+	}
+	return &result, nil
+}
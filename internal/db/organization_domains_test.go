@@ -0,0 +1,40 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+// TestUpdateOrganizationDomainsVerified checks that a failed check keeps an existing verification and that only
+// changing the domain resets it.
+func TestUpdateOrganizationDomainsVerified(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+	suffix := time.Now().UnixNano()
+
+	org := dbtest.CreateOrganization(t, ctx)
+	appDomain := util.PtrTo(fmt.Sprintf("app-%v.example.com", suffix))
+	registryDomain := util.PtrTo(fmt.Sprintf("registry-%v.example.com", suffix))
+	_, err := db.UpdateOrganizationDomains(ctx, org.ID, appDomain, registryDomain, "token")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	verified, err := db.UpdateOrganizationDomainsVerified(ctx, org.ID, true, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(verified.AppDomainVerifiedAt).NotTo(BeNil())
+	g.Expect(verified.RegistryDomainVerifiedAt).To(BeNil())
+
+	failed, err := db.UpdateOrganizationDomainsVerified(ctx, org.ID, false, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(failed.AppDomainVerifiedAt).To(Equal(verified.AppDomainVerifiedAt))
+	g.Expect(failed.RegistryDomainVerifiedAt).To(BeNil())
+
+	changed, err := db.UpdateOrganizationDomains(ctx, org.ID, util.PtrTo("other-"+*appDomain), registryDomain, "token")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed.AppDomainVerifiedAt).To(BeNil())
+}
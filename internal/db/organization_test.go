@@ -0,0 +1,35 @@
+package db_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+// TestCreateOrganizationRequireArtifactPrecreation checks that the setting is stored when an organization is created.
+func TestCreateOrganizationRequireArtifactPrecreation(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	suffix := time.Now().UnixNano()
+
+	for _, require := range []bool{true, false} {
+		t.Run(fmt.Sprint(require), func(t *testing.T) {
+			g := NewWithT(t)
+			org := types.Organization{
+				Name:                       "Precreation Test",
+				Slug:                       util.PtrTo(fmt.Sprintf("precreation-%v-%v", require, suffix)),
+				RequireArtifactPrecreation: require,
+			}
+			g.Expect(db.CreateOrganization(ctx, &org)).To(Succeed())
+			g.Expect(org.RequireArtifactPrecreation).To(Equal(require))
+			stored, err := db.GetOrganizationByID(ctx, org.ID)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(stored.RequireArtifactPrecreation).To(Equal(require))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TypeNames are the custom PostgreSQL types (and their array types) that have to be registered on a connection
+// before values of these types can be scanned or passed as arguments.
+var TypeNames = []string{
+	"DEPLOYMENT_TYPE",
+	"USER_ROLE",
+	"HELM_CHART_TYPE",
+	"DEPLOYMENT_STATUS_TYPE",
+	"FEATURE",
+	"_FEATURE",
+	"TUTORIAL",
+	"SUBSCRIPTIONTYPE",
+	"_SUBSCRIPTIONTYPE",
+	"CUSTOMER_ORGANIZATION_FEATURE",
+	"_CUSTOMER_ORGANIZATION_FEATURE",
+	"ALERT_TRANSITION",
+	"_ALERT_TRANSITION",
+}
+
+// RegisterTypes loads all types in TypeNames and registers them with the type map of conn. It is meant to be used as
+// the AfterConnect hook of a connection pool.
+func RegisterTypes(ctx context.Context, conn *pgx.Conn) error {
+	for _, typeName := range TypeNames {
+		if pgType, err := conn.LoadType(ctx, typeName); err != nil {
+			return err
+		} else {
+			conn.TypeMap().RegisterType(pgType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+)
+
+// TestGetUserAccountAndOrg checks that a user loses access to an organization once they are removed from it or it is
+// deleted, so that they can no longer switch into it.
+func TestGetUserAccountAndOrg(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	deletedOrg := dbtest.CreateOrganization(t, ctx)
+	otherOrg := dbtest.CreateOrganization(t, ctx)
+	user := dbtest.CreateUserAccount(t, ctx)
+	for _, o := range []types.Organization{org, deletedOrg} {
+		g.Expect(db.CreateUserAccountOrganizationAssignment(ctx, user.ID, o.ID, types.UserRoleAdmin, nil)).
+			To(Succeed())
+	}
+
+	u, o, err := db.GetUserAccountAndOrg(ctx, user.ID, org.ID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(u.ID).To(Equal(user.ID))
+	g.Expect(u.UserRole).To(Equal(types.UserRoleAdmin))
+	g.Expect(o.ID).To(Equal(org.ID))
+
+	_, _, err = db.GetUserAccountAndOrg(ctx, user.ID, otherOrg.ID)
+	g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+
+	g.Expect(db.SetOrganizationDeletedAtNow(ctx, deletedOrg.ID)).To(Succeed())
+	_, _, err = db.GetUserAccountAndOrg(ctx, user.ID, deletedOrg.ID)
+	g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+
+	g.Expect(db.DeleteUserAccountFromOrganization(ctx, user.ID, org.ID)).To(Succeed())
+	_, _, err = db.GetUserAccountAndOrg(ctx, user.ID, org.ID)
+	g.Expect(err).To(MatchError(apierrors.ErrNotFound))
+}
@@ -0,0 +1,123 @@
+package deploymentlogs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/google/uuid"
+)
+
+const tailSubscriberBufferSize = 256
+
+// Tail keeps a bounded in-memory ring buffer of the most recent log records of every deployment and forwards new
+// records to subscribers. It implements [Exporter], so it can be fed by the same code path that persists log records.
+//
+// The buffers are local to the current process and are dropped after they have been idle for the configured
+// duration, so persisted log records must still be used for anything older.
+type Tail struct {
+	bufferSize int
+	maxIdle    time.Duration
+	mut        sync.Mutex
+	buffers    map[uuid.UUID]*tailBuffer
+	lastEvict  time.Time
+}
+
+type tailBuffer struct {
+	records     []api.DeploymentLogRecord
+	next        int
+	lastWrite   time.Time
+	subscribers map[chan api.DeploymentLogRecord]struct{}
+}
+
+var _ Exporter = &Tail{}
+
+func NewTail(bufferSize int, maxIdle time.Duration) *Tail {
+	return &Tail{
+		bufferSize: bufferSize,
+		maxIdle:    maxIdle,
+		buffers:    make(map[uuid.UUID]*tailBuffer),
+	}
+}
+
+// ExportDeploymentLogs implements Exporter.
+func (t *Tail) ExportDeploymentLogs(ctx context.Context, records []api.DeploymentLogRecord) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	now := time.Now()
+	for _, record := range records {
+		buf := t.getOrCreateBuffer(record.DeploymentID)
+		buf.lastWrite = now
+		if len(buf.records) < t.bufferSize {
+			buf.records = append(buf.records, record)
+		} else {
+			buf.records[buf.next] = record
+			buf.next = (buf.next + 1) % t.bufferSize
+		}
+		for ch := range buf.subscribers {
+			select {
+			case ch <- record:
+			default:
+				// the subscriber is too slow, so the record is dropped for this subscriber only
+			}
+		}
+	}
+
+	t.evictIdle(now)
+	return nil
+}
+
+// Subscribe returns the currently buffered records of the given deployment in chronological order and a channel
+// that receives all records exported after this call. The returned cancel function must be called to release the
+// subscription.
+func (t *Tail) Subscribe(deploymentID uuid.UUID) ([]api.DeploymentLogRecord, <-chan api.DeploymentLogRecord, func()) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	buf := t.getOrCreateBuffer(deploymentID)
+	recent := make([]api.DeploymentLogRecord, 0, len(buf.records))
+	recent = append(recent, buf.records[buf.next:]...)
+	recent = append(recent, buf.records[:buf.next]...)
+
+	ch := make(chan api.DeploymentLogRecord, tailSubscriberBufferSize)
+	buf.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			t.mut.Lock()
+			defer t.mut.Unlock()
+			delete(buf.subscribers, ch)
+			close(ch)
+		})
+	}
+
+	return recent, ch, cancel
+}
+
+func (t *Tail) getOrCreateBuffer(deploymentID uuid.UUID) *tailBuffer {
+	buf, ok := t.buffers[deploymentID]
+	if !ok {
+		buf = &tailBuffer{
+			records:     make([]api.DeploymentLogRecord, 0, t.bufferSize),
+			lastWrite:   time.Now(),
+			subscribers: make(map[chan api.DeploymentLogRecord]struct{}),
+		}
+		t.buffers[deploymentID] = buf
+	}
+	return buf
+}
+
+func (t *Tail) evictIdle(now time.Time) {
+	if now.Sub(t.lastEvict) < t.maxIdle {
+		return
+	}
+	t.lastEvict = now
+	for id, buf := range t.buffers {
+		if len(buf.subscribers) == 0 && now.Sub(buf.lastWrite) > t.maxIdle {
+			delete(t.buffers, id)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package deploymentlogs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+func TestTail(t *testing.T) {
+	g := NewWithT(t)
+	tail := NewTail(3, time.Minute)
+	deploymentID := uuid.New()
+	otherDeploymentID := uuid.New()
+
+	records := make([]api.DeploymentLogRecord, 5)
+	for i := range records {
+		records[i] = NewRecord(deploymentID, uuid.Nil, "app", "Info", string(rune('a'+i)))
+	}
+	g.Expect(tail.ExportDeploymentLogs(context.Background(), records)).To(Succeed())
+
+	recent, ch, cancel := tail.Subscribe(deploymentID)
+	g.Expect(recent).To(Equal(records[2:]))
+
+	next := NewRecord(deploymentID, uuid.Nil, "app", "Info", "f")
+	other := NewRecord(otherDeploymentID, uuid.Nil, "app", "Info", "x")
+	g.Expect(tail.ExportDeploymentLogs(context.Background(), []api.DeploymentLogRecord{other, next})).To(Succeed())
+	g.Expect(ch).To(Receive(Equal(next)))
+	g.Expect(ch).NotTo(Receive())
+
+	recent, _, cancelOther := tail.Subscribe(deploymentID)
+	defer cancelOther()
+	g.Expect(recent).To(Equal([]api.DeploymentLogRecord{records[3], records[4], next}))
+
+	cancel()
+	g.Expect(ch).To(BeClosed())
+	cancel()
+}
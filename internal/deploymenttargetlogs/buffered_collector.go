@@ -13,16 +13,18 @@ const (
 	defaultFlushInterval = 30 * time.Second
 )
 
+// BufferedCollector accumulates log records and exports them to the Delegate in batches. The buffer is flushed once
+// it holds Size records or once the oldest buffered record is older than FlushInterval, whichever happens first.
+// Stop must be called on shutdown to flush the remaining records.
 type BufferedCollector struct {
 	Size          int
 	FlushInterval time.Duration
 	Delegate      Exporter
 
-	buf         []api.DeploymentTargetLogRecord
-	mu          sync.Mutex
-	initialized bool
-	stop        chan struct{}
-	done        chan struct{}
+	buf     []api.DeploymentTargetLogRecord
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
 }
 
 // ExportDeploymentTargetLogs implements [Exporter].
@@ -30,16 +32,18 @@ func (bc *BufferedCollector) ExportDeploymentTargetLogs(records ...api.Deploymen
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
-	if !bc.initialized {
-		bc.init()
-	}
-
 	for _, record := range records {
 		if err := bc.appendBuffer(record); err != nil {
 			return err
 		}
 	}
 
+	if bc.stopped {
+		return bc.syncNoLock()
+	} else if len(bc.buf) > 0 && bc.timer == nil {
+		bc.timer = time.AfterFunc(bc.flushIntervalOrDefault(), func() { _ = bc.Sync() })
+	}
+
 	return nil
 }
 
@@ -50,46 +54,28 @@ func (bc *BufferedCollector) Sync() error {
 	return bc.syncNoLock()
 }
 
+// Stop flushes all buffered records. Records exported after Stop was called are passed to the Delegate immediately.
 func (bc *BufferedCollector) Stop() error {
-	close(bc.stop)
-	<-bc.done
-	return bc.Sync()
-}
-
-func (bc *BufferedCollector) init() {
-	bc.initialized = true
-
-	bc.resetBuffer()
-
-	syncInterval := bc.FlushInterval
-	if syncInterval == 0 {
-		syncInterval = defaultFlushInterval
-	}
-
-	bc.stop = make(chan struct{})
-	bc.done = make(chan struct{})
-
-	tick := time.Tick(syncInterval)
-	go func() {
-		defer close(bc.done)
-		for {
-			select {
-			case <-tick:
-				_ = bc.Sync()
-			case <-bc.stop:
-				return
-			}
-		}
-	}()
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.stopped = true
+	return bc.syncNoLock()
 }
 
 func (bc *BufferedCollector) sizeOrDefault() int {
-	if bc.Size != 0 {
+	if bc.Size > 0 {
 		return bc.Size
 	}
 	return defaultBufferSize
 }
 
+func (bc *BufferedCollector) flushIntervalOrDefault() time.Duration {
+	if bc.FlushInterval > 0 {
+		return bc.FlushInterval
+	}
+	return defaultFlushInterval
+}
+
 func (bc *BufferedCollector) resetBuffer() {
 	bc.buf = make([]api.DeploymentTargetLogRecord, 0, bc.sizeOrDefault())
 }
@@ -105,6 +91,10 @@ func (bc *BufferedCollector) appendBuffer(record api.DeploymentTargetLogRecord)
 }
 
 func (bc *BufferedCollector) syncNoLock() error {
+	if bc.timer != nil {
+		bc.timer.Stop()
+		bc.timer = nil
+	}
 	if bc.Delegate == nil {
 		return errors.New("bufferedCollector has no Delegate")
 	}
@@ -0,0 +1,104 @@
+package deploymenttargetlogs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	. "github.com/onsi/gomega"
+)
+
+type recordingExporter struct {
+	mu      sync.Mutex
+	batches [][]api.DeploymentTargetLogRecord
+	err     error
+}
+
+func (e *recordingExporter) ExportDeploymentTargetLogs(records ...api.DeploymentTargetLogRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return e.err
+	}
+	e.batches = append(e.batches, records)
+	return nil
+}
+
+func (e *recordingExporter) Batches() [][]api.DeploymentTargetLogRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.batches
+}
+
+func logRecords(n int) []api.DeploymentTargetLogRecord {
+	records := make([]api.DeploymentTargetLogRecord, n)
+	for i := range records {
+		records[i] = api.DeploymentTargetLogRecord{Body: fmt.Sprint(i)}
+	}
+	return records
+}
+
+func TestBufferedCollectorFlushesWhenFull(t *testing.T) {
+	g := NewWithT(t)
+	exporter := &recordingExporter{}
+	bc := &BufferedCollector{Size: 3, FlushInterval: time.Hour, Delegate: exporter}
+
+	records := logRecords(7)
+	g.Expect(bc.ExportDeploymentTargetLogs(records[:2]...)).To(Succeed())
+	g.Expect(exporter.Batches()).To(BeEmpty())
+	g.Expect(bc.ExportDeploymentTargetLogs(records[2:]...)).To(Succeed())
+	g.Expect(exporter.Batches()).To(Equal([][]api.DeploymentTargetLogRecord{records[:3], records[3:6]}))
+
+	g.Expect(bc.Stop()).To(Succeed())
+	g.Expect(exporter.Batches()).To(Equal([][]api.DeploymentTargetLogRecord{records[:3], records[3:6], records[6:]}))
+}
+
+func TestBufferedCollectorFlushesAfterInterval(t *testing.T) {
+	g := NewWithT(t)
+	exporter := &recordingExporter{}
+	bc := &BufferedCollector{Size: 100, FlushInterval: 10 * time.Millisecond, Delegate: exporter}
+
+	records := logRecords(2)
+	g.Expect(bc.ExportDeploymentTargetLogs(records[0])).To(Succeed())
+	g.Expect(bc.ExportDeploymentTargetLogs(records[1])).To(Succeed())
+	g.Eventually(exporter.Batches).Should(Equal([][]api.DeploymentTargetLogRecord{records}))
+	g.Consistently(exporter.Batches, 50*time.Millisecond).Should(HaveLen(1))
+}
+
+func TestBufferedCollectorExportsImmediatelyAfterStop(t *testing.T) {
+	g := NewWithT(t)
+	exporter := &recordingExporter{}
+	bc := &BufferedCollector{Size: 100, FlushInterval: time.Hour, Delegate: exporter}
+
+	g.Expect(bc.Stop()).To(Succeed())
+	g.Expect(exporter.Batches()).To(BeEmpty())
+
+	records := logRecords(1)
+	g.Expect(bc.ExportDeploymentTargetLogs(records...)).To(Succeed())
+	g.Expect(exporter.Batches()).To(Equal([][]api.DeploymentTargetLogRecord{records}))
+}
+
+func TestBufferedCollectorKeepsRecordsOnError(t *testing.T) {
+	g := NewWithT(t)
+	exportErr := errors.New("export failed")
+	exporter := &recordingExporter{err: exportErr}
+	bc := &BufferedCollector{Size: 2, FlushInterval: time.Hour, Delegate: exporter}
+
+	records := logRecords(2)
+	g.Expect(bc.ExportDeploymentTargetLogs(records...)).To(MatchError(exportErr))
+
+	exporter.mu.Lock()
+	exporter.err = nil
+	exporter.mu.Unlock()
+	g.Expect(bc.Sync()).To(Succeed())
+	g.Expect(exporter.Batches()).To(Equal([][]api.DeploymentTargetLogRecord{records}))
+}
+
+func TestBufferedCollectorWithoutDelegate(t *testing.T) {
+	g := NewWithT(t)
+	bc := &BufferedCollector{Size: 1}
+	g.Expect(bc.ExportDeploymentTargetLogs(logRecords(1)...)).NotTo(Succeed())
+}
@@ -3,18 +3,24 @@ package env
 import (
 	"encoding/base64"
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/distr-sh/distr/internal/envparse"
 	"github.com/distr-sh/distr/internal/envutil"
+	"github.com/distr-sh/distr/internal/types"
 	"github.com/distr-sh/distr/internal/util"
 	"github.com/joho/godotenv"
 )
 
+// minDeploymentStatusStaleDuration is the lower bound of DeploymentStatusStaleDuration for short agent intervals.
+const minDeploymentStatusStaleDuration = 1 * time.Minute
+
 var (
 	databaseUrl                             string
 	databaseMaxConns                        *int
@@ -29,11 +35,14 @@ var (
 	statusEntriesMaxAge                     *time.Duration
 	metricsEntriesMaxAge                    *time.Duration
 	logRecordEntriesMaxCount                *int
+	statusMessageMaxLength                  int
 	sentryDSN                               string
 	sentryDebug                             bool
 	sentryEnvironment                       string
 	otelAgentSampler                        *SamplerConfig
 	otelRegistrySampler                     *SamplerConfig
+	otelRegistryPullSampler                 *SamplerConfig
+	otelRegistryPushSampler                 *SamplerConfig
 	otelExporterSentryEnabled               bool
 	otelExporterOtlpEnabled                 bool
 	enableQueryLogging                      bool
@@ -44,12 +53,25 @@ var (
 	frontendPosthogAPIHost                  *string
 	frontendPosthogUIHost                   *string
 	userEmailVerificationRequired           bool
+	readOnlyMode                            bool
+	trustedProxyCIDRs                       []netip.Prefix
+	trustedProxyHeaders                     []string
+	remoteAddressAnonymization              RemoteAddressAnonymizationMode
 	serverShutdownDelayDuration             *time.Duration
 	registration                            RegistrationMode
 	registryEnabled                         bool
 	registryS3Config                        S3Config
 	registryScratchDir                      *string
+	registryCORSAllowedOrigins              []string
+	registryDefaultPageSize                 int
+	registryMaxPageSize                     int
+	registryMaxManifestBytes                int
+	registryAuditRetryQueueSize             int
+	registryAuditRetryInterval              time.Duration
 	artifactTagsDefaultLimitPerOrg          int
+	artifactStorageDefaultLimitBytesPerOrg  int
+	artifactLicenseCheckTimeout             time.Duration
+	artifactMaxManifestDataBytes            int
 	cleanupDeploymentRevisionStatusCron     *string
 	cleanupDeploymentRevisionStatusTimeout  time.Duration
 	cleanupDeploymentTargetStatusCron       *string
@@ -62,8 +84,22 @@ var (
 	cleanupDeploymentTargetLogRecordTimeout time.Duration
 	cleanupOIDCStateCron                    *string
 	cleanupOIDCStateCronTimeout             time.Duration
+	cleanupDeletedArtifactsCron             *string
+	cleanupDeletedArtifactsTimeout          time.Duration
+	deletedArtifactsRetentionDays           int
+	cleanupAuditEventsCron                  *string
+	cleanupAuditEventsTimeout               time.Duration
+	auditEventsMaxAge                       *time.Duration
+	auditEventsPreservedTypes               []types.AuditEventType
 	deploymentStatusNotificationCron        *string
 	deploymentStatusNotificationTimeout     time.Duration
+	deploymentStatusNotificationConcurrency int
+	alertDigestCron                         string
+	alertDigestTimeout                      time.Duration
+	artifactPushWebhookCron                 string
+	artifactPushWebhookTimeout              time.Duration
+	agentMinVersion                         *semver.Version
+	agentMinVersionEnforced                 bool
 	oidcGithubEnabled                       bool
 	oidcGithubClientID                      *string
 	oidcGithubClientSecret                  *string
@@ -74,12 +110,21 @@ var (
 	oidcMicrosoftClientID                   *string
 	oidcMicrosoftClientSecret               *string
 	oidcMicrosoftTenantID                   *string
+	oidcOktaEnabled                         bool
+	oidcOktaClientID                        *string
+	oidcOktaClientSecret                    *string
+	oidcOktaDomain                          *string
+	oidcGitlabEnabled                       bool
+	oidcGitlabClientID                      *string
+	oidcGitlabClientSecret                  *string
+	oidcGitlabBaseURL                       string
 	oidcGenericEnabled                      bool
 	oidcGenericClientID                     *string
 	oidcGenericClientSecret                 *string
 	oidcGenericIssuer                       *string
 	oidcGenericScopes                       *string
 	oidcGenericPKCEEnabled                  bool
+	oidcGenericUseUserInfo                  bool
 	wellKnownMicrosoftIdentityAssociation   []byte
 	stripeWebhookSecret                     *string
 	stripeAPIKey                            *string
@@ -102,13 +147,26 @@ func Initialize() {
 	jwtSecret = envutil.RequireEnvParsed("JWT_SECRET", base64.StdEncoding.DecodeString)
 	host = envutil.RequireEnv("DISTR_HOST")
 	agentInterval = envutil.GetEnvParsedOrDefault("AGENT_INTERVAL", envparse.PositiveDuration, 5*time.Second)
+	agentMinVersion = envutil.GetEnvParsedOrDefault("AGENT_MIN_VERSION", envparse.SemanticVersion, nil)
+	agentMinVersionEnforced = envutil.GetEnvParsedOrDefault("AGENT_MIN_VERSION_ENFORCED", strconv.ParseBool, false)
 	statusEntriesMaxAge = envutil.GetEnvParsedOrNil("STATUS_ENTRIES_MAX_AGE", envparse.PositiveDuration)
 	metricsEntriesMaxAge = envutil.GetEnvParsedOrNil("METRICS_ENTRIES_MAX_AGE", envparse.PositiveDuration)
 	logRecordEntriesMaxCount = envutil.GetEnvParsedOrNil("LOG_RECORD_ENTRIES_MAX_COUNT", envparse.NonNegativeNumber)
+	statusMessageMaxLength = envutil.GetEnvParsedOrDefault(
+		"STATUS_MESSAGE_MAX_LENGTH", envparse.NonNegativeNumber, 10000,
+	)
 	enableQueryLogging = envutil.GetEnvParsedOrDefault("ENABLE_QUERY_LOGGING", strconv.ParseBool, false)
 	userEmailVerificationRequired = envutil.GetEnvParsedOrDefault(
 		"USER_EMAIL_VERIFICATION_REQUIRED", strconv.ParseBool, true,
 	)
+	readOnlyMode = envutil.GetEnvParsedOrDefault("READ_ONLY_MODE", strconv.ParseBool, false)
+	trustedProxyCIDRs = envutil.GetEnvParsedOrDefault("TRUSTED_PROXY_CIDRS", envparse.PrefixList, nil)
+	trustedProxyHeaders = envutil.GetEnvParsedOrDefault(
+		"TRUSTED_PROXY_HEADERS", envparse.CommaSeparatedList, []string{"X-Forwarded-For", "X-Real-IP"},
+	)
+	remoteAddressAnonymization = envutil.GetEnvParsedOrDefault(
+		"REMOTE_ADDRESS_ANONYMIZATION", parseRemoteAddressAnonymization, RemoteAddressAnonymizationNone,
+	)
 	serverShutdownDelayDuration = envutil.GetEnvParsedOrNil("SERVER_SHUTDOWN_DELAY_DURATION", envparse.PositiveDuration)
 	registration = envutil.GetEnvParsedOrDefault("REGISTRATION", parseRegistrationMode, RegistrationEnabled)
 	inviteTokenValidDuration = envutil.GetEnvParsedOrDefault(
@@ -155,10 +213,35 @@ func Initialize() {
 			"REGISTRY_RESIGN_FOR_GCP", strconv.ParseBool, false,
 		)
 		registryScratchDir = envutil.GetEnvOrNil("REGISTRY_SCRATCH_DIR")
+		registryCORSAllowedOrigins = envutil.GetEnvParsedOrDefault(
+			"REGISTRY_CORS_ALLOWED_ORIGINS", envparse.CommaSeparatedList, nil,
+		)
+		registryDefaultPageSize = envutil.GetEnvParsedOrDefault(
+			"REGISTRY_DEFAULT_PAGE_SIZE", envparse.PositiveNumber, 10000,
+		)
+		registryMaxPageSize = envutil.GetEnvParsedOrDefault("REGISTRY_MAX_PAGE_SIZE", envparse.PositiveNumber, 10000)
+		registryMaxManifestBytes = envutil.GetEnvParsedOrDefault(
+			"REGISTRY_MAX_MANIFEST_BYTES", envparse.PositiveNumber, 4<<20,
+		)
+		registryAuditRetryQueueSize = envutil.GetEnvParsedOrDefault(
+			"REGISTRY_AUDIT_RETRY_QUEUE_SIZE", envparse.NonNegativeNumber, 1000,
+		)
+		registryAuditRetryInterval = envutil.GetEnvParsedOrDefault(
+			"REGISTRY_AUDIT_RETRY_INTERVAL", envparse.PositiveDuration, 30*time.Second,
+		)
 	}
 	artifactTagsDefaultLimitPerOrg = envutil.GetEnvParsedOrDefault(
 		"ARTIFACT_TAGS_DEFAULT_LIMIT_PER_ORG", envparse.NonNegativeNumber, 0,
 	)
+	artifactStorageDefaultLimitBytesPerOrg = envutil.GetEnvParsedOrDefault(
+		"ARTIFACT_STORAGE_DEFAULT_LIMIT_BYTES_PER_ORG", envparse.NonNegativeNumber, 0,
+	)
+	artifactLicenseCheckTimeout = envutil.GetEnvParsedOrDefault(
+		"ARTIFACT_LICENSE_CHECK_TIMEOUT", envparse.PositiveDuration, 5*time.Second,
+	)
+	artifactMaxManifestDataBytes = envutil.GetEnvParsedOrDefault(
+		"ARTIFACT_MAX_MANIFEST_DATA_BYTES", envparse.PositiveNumber, 4<<20,
+	)
 
 	sentryDSN = envutil.GetEnv("SENTRY_DSN")
 	sentryDebug = envutil.GetEnvParsedOrDefault("SENTRY_DEBUG", strconv.ParseBool, false)
@@ -177,6 +260,18 @@ func Initialize() {
 			Arg:     envutil.GetEnvParsedOrDefault("OTEL_REGISTRY_SAMPLER_ARG", envparse.Float, 1.0),
 		}
 	}
+	if s := envutil.GetEnvParsedOrNil("OTEL_REGISTRY_PULL_SAMPLER", parseSamplerType); s != nil {
+		otelRegistryPullSampler = &SamplerConfig{
+			Sampler: *s,
+			Arg:     envutil.GetEnvParsedOrDefault("OTEL_REGISTRY_PULL_SAMPLER_ARG", envparse.Float, 1.0),
+		}
+	}
+	if s := envutil.GetEnvParsedOrNil("OTEL_REGISTRY_PUSH_SAMPLER", parseSamplerType); s != nil {
+		otelRegistryPushSampler = &SamplerConfig{
+			Sampler: *s,
+			Arg:     envutil.GetEnvParsedOrDefault("OTEL_REGISTRY_PUSH_SAMPLER_ARG", envparse.Float, 1.0),
+		}
+	}
 
 	agentDockerConfig = envutil.GetEnvParsedOrDefault("AGENT_DOCKER_CONFIG", envparse.ByteSlice, nil)
 	frontendSentryDSN = envutil.GetEnvOrNil("FRONTEND_SENTRY_DSN")
@@ -203,9 +298,29 @@ func Initialize() {
 	cleanupOIDCStateCron = envutil.GetEnvOrNil("CLEANUP_OIDC_STATE_CRON")
 	cleanupOIDCStateCronTimeout = envutil.GetEnvParsedOrDefault("CLEANUP_OIDC_STATE_CRON_TIMEOUT",
 		envparse.PositiveDuration, 0)
+	cleanupDeletedArtifactsCron = envutil.GetEnvOrNil("CLEANUP_DELETED_ARTIFACTS_CRON")
+	cleanupDeletedArtifactsTimeout = envutil.GetEnvParsedOrDefault("CLEANUP_DELETED_ARTIFACTS_TIMEOUT",
+		envparse.PositiveDuration, 0)
+	deletedArtifactsRetentionDays = envutil.GetEnvParsedOrDefault("DELETED_ARTIFACTS_RETENTION_DAYS",
+		envparse.NonNegativeNumber, 30)
+	cleanupAuditEventsCron = envutil.GetEnvOrNil("CLEANUP_AUDIT_EVENTS_CRON")
+	cleanupAuditEventsTimeout = envutil.GetEnvParsedOrDefault("CLEANUP_AUDIT_EVENTS_TIMEOUT",
+		envparse.PositiveDuration, 0)
+	auditEventsMaxAge = envutil.GetEnvParsedOrNil("AUDIT_EVENTS_MAX_AGE", envparse.PositiveDuration)
+	auditEventsPreservedTypes = envutil.GetEnvParsedOrDefault("AUDIT_EVENTS_PRESERVED_TYPES",
+		parseAuditEventTypes, nil)
 	deploymentStatusNotificationCron = envutil.GetEnvOrNil("DEPLOYMENT_STATUS_NOTIFICATION_CRON")
 	deploymentStatusNotificationTimeout = envutil.GetEnvParsedOrDefault("DEPLOYMENT_STATUS_NOTIFICATION_TIMEOUT",
 		envparse.PositiveDuration, 0)
+	deploymentStatusNotificationConcurrency = envutil.GetEnvParsedOrDefault(
+		"DEPLOYMENT_STATUS_NOTIFICATION_CONCURRENCY", envparse.PositiveNumber, 4,
+	)
+	alertDigestCron = envutil.GetEnvOrDefault("ALERT_DIGEST_CRON", "0 * * * *", envutil.GetEnvOpts{})
+	alertDigestTimeout = envutil.GetEnvParsedOrDefault("ALERT_DIGEST_TIMEOUT", envparse.PositiveDuration, 0)
+	artifactPushWebhookCron = envutil.GetEnvOrDefault("ARTIFACT_PUSH_WEBHOOK_CRON", "* * * * *", envutil.GetEnvOpts{})
+	artifactPushWebhookTimeout = envutil.GetEnvParsedOrDefault(
+		"ARTIFACT_PUSH_WEBHOOK_TIMEOUT", envparse.PositiveDuration, 0,
+	)
 
 	oidcGithubEnabled = envutil.GetEnvParsedOrDefault("OIDC_GITHUB_ENABLED", strconv.ParseBool, false)
 	if oidcGithubEnabled {
@@ -223,6 +338,20 @@ func Initialize() {
 		oidcMicrosoftClientSecret = util.PtrTo(envutil.RequireEnv("OIDC_MICROSOFT_CLIENT_SECRET"))
 		oidcMicrosoftTenantID = util.PtrTo(envutil.RequireEnv("OIDC_MICROSOFT_TENANT_ID"))
 	}
+	oidcOktaEnabled = envutil.GetEnvParsedOrDefault("OIDC_OKTA_ENABLED", strconv.ParseBool, false)
+	if oidcOktaEnabled {
+		oidcOktaClientID = util.PtrTo(envutil.RequireEnv("OIDC_OKTA_CLIENT_ID"))
+		oidcOktaClientSecret = util.PtrTo(envutil.RequireEnv("OIDC_OKTA_CLIENT_SECRET"))
+		oidcOktaDomain = util.PtrTo(envutil.RequireEnv("OIDC_OKTA_DOMAIN"))
+	}
+	oidcGitlabEnabled = envutil.GetEnvParsedOrDefault("OIDC_GITLAB_ENABLED", strconv.ParseBool, false)
+	if oidcGitlabEnabled {
+		oidcGitlabClientID = util.PtrTo(envutil.RequireEnv("OIDC_GITLAB_CLIENT_ID"))
+		oidcGitlabClientSecret = util.PtrTo(envutil.RequireEnv("OIDC_GITLAB_CLIENT_SECRET"))
+		oidcGitlabBaseURL = strings.TrimSuffix(
+			envutil.GetEnvOrDefault("OIDC_GITLAB_BASE_URL", "https://gitlab.com", envutil.GetEnvOpts{}), "/",
+		)
+	}
 	oidcGenericEnabled = envutil.GetEnvParsedOrDefault("OIDC_GENERIC_ENABLED", strconv.ParseBool, false)
 	if oidcGenericEnabled {
 		oidcGenericClientID = util.PtrTo(envutil.RequireEnv("OIDC_GENERIC_CLIENT_ID"))
@@ -230,6 +359,7 @@ func Initialize() {
 		oidcGenericIssuer = util.PtrTo(envutil.RequireEnv("OIDC_GENERIC_ISSUER"))
 		oidcGenericScopes = util.PtrTo(envutil.RequireEnv("OIDC_GENERIC_SCOPES"))
 		oidcGenericPKCEEnabled = envutil.GetEnvParsedOrDefault("OIDC_GENERIC_PKCE_ENABLED", strconv.ParseBool, false)
+		oidcGenericUseUserInfo = envutil.GetEnvParsedOrDefault("OIDC_GENERIC_USE_USERINFO", strconv.ParseBool, false)
 	}
 	wellKnownMicrosoftIdentityAssociation = envutil.GetEnvParsedOrDefault(
 		"WELLKNOWN_MICROSOFT_IDENTITY_ASSOCIATION_JSON", envparse.ByteSlice, nil)
@@ -278,6 +408,25 @@ func AgentInterval() time.Duration {
 	return agentInterval
 }
 
+// DeploymentStatusStaleDuration is the age after which the latest status of a deployment is considered stale. Agents
+// report a status once per AgentInterval, so a status is stale if a few intervals were missed, but at least after the
+// minimum of one minute.
+func DeploymentStatusStaleDuration() time.Duration {
+	return max(minDeploymentStatusStaleDuration, 3*agentInterval)
+}
+
+// AgentMinVersion is the oldest agent version that is fully supported. Older agents receive a warning and are shown as
+// outdated. If it is nil, all agent versions are supported.
+func AgentMinVersion() *semver.Version {
+	return agentMinVersion
+}
+
+// AgentMinVersionEnforced is true if agents older than AgentMinVersion must not submit data. They can still fetch
+// their resources and manifest, so that they are able to update themselves.
+func AgentMinVersionEnforced() bool {
+	return agentMinVersionEnforced
+}
+
 func SentryDSN() string {
 	return sentryDSN
 }
@@ -306,6 +455,12 @@ func LogRecordEntriesMaxCount() *int {
 	return logRecordEntriesMaxCount
 }
 
+// StatusMessageMaxLength is the maximum number of characters stored for a deployment status or notification message.
+// A value of 0 means that messages are not truncated.
+func StatusMessageMaxLength() int {
+	return statusMessageMaxLength
+}
+
 func AgentDockerConfig() []byte {
 	return agentDockerConfig
 }
@@ -334,6 +489,28 @@ func UserEmailVerificationRequired() bool {
 	return userEmailVerificationRequired
 }
 
+// ReadOnlyMode reports whether the instance should reject all write operations, e.g. during database maintenance.
+func ReadOnlyMode() bool {
+	return readOnlyMode
+}
+
+// TrustedProxyCIDRs returns the addresses of proxies whose forwarded headers are trusted to contain the client IP.
+// If empty, forwarded headers are ignored and the address of the direct connection is used.
+func TrustedProxyCIDRs() []netip.Prefix {
+	return trustedProxyCIDRs
+}
+
+// TrustedProxyHeaders returns the headers that are checked, in order, for the client IP of requests from trusted
+// proxies.
+func TrustedProxyHeaders() []string {
+	return trustedProxyHeaders
+}
+
+// RemoteAddressAnonymization returns how client addresses are anonymized before they are stored.
+func RemoteAddressAnonymization() RemoteAddressAnonymizationMode {
+	return remoteAddressAnonymization
+}
+
 func ServerShutdownDelayDuration() *time.Duration {
 	return serverShutdownDelayDuration
 }
@@ -350,6 +527,40 @@ func RegistryS3Config() S3Config {
 	return registryS3Config
 }
 
+// RegistryCORSAllowedOrigins returns the origins that browsers may use to access the registry. An element "*" allows
+// all origins, but only the origins that are listed explicitly may send credentials.
+func RegistryCORSAllowedOrigins() []string {
+	return registryCORSAllowedOrigins
+}
+
+// RegistryDefaultPageSize is the number of tags or repositories returned by the registry if the client does not
+// request a specific number.
+func RegistryDefaultPageSize() int {
+	return registryDefaultPageSize
+}
+
+// RegistryMaxPageSize is the maximum number of tags or repositories returned by the registry. Larger requests are
+// clamped to this number.
+func RegistryMaxPageSize() int {
+	return registryMaxPageSize
+}
+
+// RegistryMaxManifestBytes is the maximum size of a manifest that can be pushed to the registry.
+func RegistryMaxManifestBytes() int {
+	return registryMaxManifestBytes
+}
+
+// RegistryAuditRetryQueueSize is the maximum number of pulls that are kept in memory if they could not be recorded.
+// If it is 0, such pulls are not retried.
+func RegistryAuditRetryQueueSize() int {
+	return registryAuditRetryQueueSize
+}
+
+// RegistryAuditRetryInterval is the interval in which recording queued pulls is retried.
+func RegistryAuditRetryInterval() time.Duration {
+	return registryAuditRetryInterval
+}
+
 func RegistryScratchDir() *string {
 	return registryScratchDir
 }
@@ -358,6 +569,23 @@ func ArtifactTagsDefaultLimitPerOrg() int {
 	return artifactTagsDefaultLimitPerOrg
 }
 
+// ArtifactStorageDefaultLimitBytesPerOrg is the registry storage limit of organizations that do not have their own
+// limit. 0 means that there is no default limit.
+func ArtifactStorageDefaultLimitBytesPerOrg() int {
+	return artifactStorageDefaultLimitBytesPerOrg
+}
+
+// ArtifactLicenseCheckTimeout is the maximum duration of the license check that is done for every pull of a customer.
+func ArtifactLicenseCheckTimeout() time.Duration {
+	return artifactLicenseCheckTimeout
+}
+
+// ArtifactMaxManifestDataBytes is the maximum size of a manifest that is stored for an artifact version, regardless
+// of whether it is pushed to the registry or imported.
+func ArtifactMaxManifestDataBytes() int {
+	return artifactMaxManifestDataBytes
+}
+
 func OtelAgentSampler() *SamplerConfig {
 	return otelAgentSampler
 }
@@ -366,6 +594,16 @@ func OtelRegistrySampler() *SamplerConfig {
 	return otelRegistrySampler
 }
 
+// OtelRegistryPullSampler overrides [OtelRegistrySampler] for registry requests that only read data (e.g. pulls).
+func OtelRegistryPullSampler() *SamplerConfig {
+	return otelRegistryPullSampler
+}
+
+// OtelRegistryPushSampler overrides [OtelRegistrySampler] for registry requests that write data (e.g. pushes).
+func OtelRegistryPushSampler() *SamplerConfig {
+	return otelRegistryPushSampler
+}
+
 func OtelExporterSentryEnabled() bool {
 	return otelExporterSentryEnabled
 }
@@ -422,6 +660,30 @@ func DeploymentStatusNotificationTimeout() time.Duration {
 	return deploymentStatusNotificationTimeout
 }
 
+func DeploymentStatusNotificationConcurrency() int {
+	return deploymentStatusNotificationConcurrency
+}
+
+// AlertDigestCron returns the schedule of the job that sends digests. Unlike other jobs, it cannot be disabled,
+// because alert configurations in digest mode would never notify otherwise.
+func AlertDigestCron() string {
+	return alertDigestCron
+}
+
+func AlertDigestTimeout() time.Duration {
+	return alertDigestTimeout
+}
+
+// ArtifactPushWebhookCron returns the schedule of the job that retries failed artifact push webhook deliveries. Like
+// the alert digest job, it cannot be disabled, because failed deliveries would never be retried otherwise.
+func ArtifactPushWebhookCron() string {
+	return artifactPushWebhookCron
+}
+
+func ArtifactPushWebhookTimeout() time.Duration {
+	return artifactPushWebhookTimeout
+}
+
 func CleanupOIDCStateCron() *string {
 	return cleanupOIDCStateCron
 }
@@ -430,6 +692,36 @@ func CleanupOIDCStateCronTimeout() time.Duration {
 	return cleanupOIDCStateCronTimeout
 }
 
+func CleanupDeletedArtifactsCron() *string {
+	return cleanupDeletedArtifactsCron
+}
+
+func CleanupDeletedArtifactsTimeout() time.Duration {
+	return cleanupDeletedArtifactsTimeout
+}
+
+func DeletedArtifactsRetentionDays() int {
+	return deletedArtifactsRetentionDays
+}
+
+func CleanupAuditEventsCron() *string {
+	return cleanupAuditEventsCron
+}
+
+func CleanupAuditEventsTimeout() time.Duration {
+	return cleanupAuditEventsTimeout
+}
+
+// AuditEventsMaxAge is the age after which audit events are deleted. If nil, audit events are kept indefinitely.
+func AuditEventsMaxAge() *time.Duration {
+	return auditEventsMaxAge
+}
+
+// AuditEventsPreservedTypes are the audit event types that are never deleted, regardless of their age.
+func AuditEventsPreservedTypes() []types.AuditEventType {
+	return auditEventsPreservedTypes
+}
+
 func OIDCGithubEnabled() bool {
 	return oidcGithubEnabled
 }
@@ -470,11 +762,46 @@ func OIDCMicrosoftTenantID() *string {
 	return oidcMicrosoftTenantID
 }
 
+func OIDCOktaEnabled() bool {
+	return oidcOktaEnabled
+}
+
+func OIDCOktaClientID() *string {
+	return oidcOktaClientID
+}
+
+func OIDCOktaClientSecret() *string {
+	return oidcOktaClientSecret
+}
+
+// OIDCOktaDomain returns the domain of the Okta organization, e.g. "example.okta.com".
+func OIDCOktaDomain() *string {
+	return oidcOktaDomain
+}
+
+func OIDCGitlabEnabled() bool {
+	return oidcGitlabEnabled
+}
+
+func OIDCGitlabClientID() *string {
+	return oidcGitlabClientID
+}
+
+func OIDCGitlabClientSecret() *string {
+	return oidcGitlabClientSecret
+}
+
+// OIDCGitlabBaseURL returns the URL of the GitLab instance. It defaults to "https://gitlab.com".
+func OIDCGitlabBaseURL() string {
+	return oidcGitlabBaseURL
+}
+
 func OIDCGenericEnabled() bool         { return oidcGenericEnabled }
 func OIDCGenericClientID() *string     { return oidcGenericClientID }
 func OIDCGenericClientSecret() *string { return oidcGenericClientSecret }
 func OIDCGenericIssuer() *string       { return oidcGenericIssuer }
 func OIDCGenericPKCEEnabled() bool     { return oidcGenericPKCEEnabled }
+func OIDCGenericUseUserInfo() bool     { return oidcGenericUseUserInfo }
 
 // OIDCGenericScopes returns scopes as a string array
 // expecting user input as "foo bar baz" or "foo,bar,baz"
@@ -3,6 +3,9 @@ package env
 import (
 	"fmt"
 	"net/mail"
+
+	"github.com/distr-sh/distr/internal/envparse"
+	"github.com/distr-sh/distr/internal/types"
 )
 
 type RegistrationMode string
@@ -22,6 +25,39 @@ func parseRegistrationMode(value string) (RegistrationMode, error) {
 	}
 }
 
+// RemoteAddressAnonymizationMode controls how the remote addresses of clients are stored, e.g. for artifact pulls.
+type RemoteAddressAnonymizationMode string
+
+const (
+	RemoteAddressAnonymizationNone     RemoteAddressAnonymizationMode = "none"
+	RemoteAddressAnonymizationTruncate RemoteAddressAnonymizationMode = "truncate"
+	RemoteAddressAnonymizationHash     RemoteAddressAnonymizationMode = "hash"
+)
+
+func parseRemoteAddressAnonymization(value string) (RemoteAddressAnonymizationMode, error) {
+	switch value {
+	case string(RemoteAddressAnonymizationNone),
+		string(RemoteAddressAnonymizationTruncate),
+		string(RemoteAddressAnonymizationHash):
+		return RemoteAddressAnonymizationMode(value), nil
+	default:
+		return "", fmt.Errorf("invalid RemoteAddressAnonymizationMode: %v", value)
+	}
+}
+
+func parseAuditEventTypes(value string) ([]types.AuditEventType, error) {
+	items, _ := envparse.CommaSeparatedList(value)
+	result := make([]types.AuditEventType, 0, len(items))
+	for _, item := range items {
+		if t, err := types.ParseAuditEventType(item); err != nil {
+			return nil, err
+		} else {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
 type MailerTypeString string
 
 const (
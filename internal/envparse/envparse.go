@@ -2,9 +2,14 @@ package envparse
 
 import (
 	"errors"
+	"fmt"
 	"net/mail"
+	"net/netip"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 func PositiveDuration(value string) (time.Duration, error) {
@@ -35,6 +40,46 @@ func NonNegativeNumber(value string) (int, error) {
 	return parsed, err
 }
 
+func PositiveNumber(value string) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err == nil && parsed <= 0 {
+		err = errors.New("number must be positive")
+	}
+	return parsed, err
+}
+
 func Float(value string) (float64, error) {
 	return strconv.ParseFloat(value, 64)
 }
+
+// CommaSeparatedList splits value at commas and drops empty elements and surrounding whitespace.
+func CommaSeparatedList(value string) ([]string, error) {
+	var result []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// PrefixList parses a comma separated list of CIDRs. Single IP addresses are accepted as prefixes covering exactly
+// that address.
+func PrefixList(value string) ([]netip.Prefix, error) {
+	items, _ := CommaSeparatedList(value)
+	result := make([]netip.Prefix, 0, len(items))
+	for _, item := range items {
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			result = append(result, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(item); err == nil {
+			result = append(result, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", item, err)
+		}
+	}
+	return result, nil
+}
+
+func SemanticVersion(value string) (*semver.Version, error) {
+	return semver.NewVersion(value)
+}
@@ -0,0 +1,104 @@
+package envutil
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Entry describes a single environment variable that was read during an [Inspect] call.
+type Entry struct {
+	Key string
+	// Value is the raw value of the variable or the formatted default value, if the variable is not set.
+	Value string
+	// IsSet reports whether the variable was set. If false, Value is either the default value or empty.
+	IsSet bool
+	// IsDefault reports whether Value is the default value used by the application.
+	IsDefault bool
+}
+
+// Report is the result of an [Inspect] call.
+type Report struct {
+	Entries []Entry
+	Errors  []error
+}
+
+// inspecting is the report of the currently running [Inspect] call or nil, if none is running.
+var inspecting *Report
+
+// Inspect runs f and returns all environment variables it reads via this package in the order they were read.
+// Functions that usually panic on invalid or missing values instead add the error to the report and return the zero
+// value, so that all problems are reported at once.
+//
+// Inspect is not safe for concurrent use and must not be called while other goroutines read environment variables via
+// this package.
+func Inspect(f func()) Report {
+	report := Report{}
+	inspecting = &report
+	defer func() { inspecting = nil }()
+	f()
+	return report
+}
+
+func lookupEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	if inspecting != nil {
+		if entry := findEntry(key); entry == nil {
+			inspecting.Entries = append(inspecting.Entries, Entry{Key: key, Value: value, IsSet: ok})
+		}
+	}
+	return value, ok
+}
+
+func recordValue(key, value string) {
+	if entry := findEntry(key); entry != nil {
+		entry.Value = value
+		entry.IsSet = true
+		entry.IsDefault = false
+	}
+}
+
+func recordDefault(key string, value any) {
+	if entry := findEntry(key); entry != nil && !entry.IsSet {
+		entry.Value = formatDefault(value)
+		entry.IsDefault = true
+	}
+}
+
+func findEntry(key string) *Entry {
+	if inspecting == nil {
+		return nil
+	}
+	for i := range inspecting.Entries {
+		if inspecting.Entries[i].Key == key {
+			return &inspecting.Entries[i]
+		}
+	}
+	return nil
+}
+
+func formatDefault(value any) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case []string:
+		return strings.Join(v, ",")
+	case fmt.Stringer:
+		return v.String()
+	default:
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+}
+
+func require[T any](value T, err error) T {
+	if err != nil {
+		if inspecting == nil {
+			panic(err)
+		}
+		inspecting.Errors = append(inspecting.Errors, err)
+	}
+	return value
+}
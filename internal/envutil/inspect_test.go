@@ -0,0 +1,36 @@
+package envutil_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/envutil"
+	. "github.com/onsi/gomega"
+)
+
+func TestInspect(t *testing.T) {
+	g := NewWithT(t)
+	t.Setenv("TEST_INSPECT_SET", "value")
+	t.Setenv("TEST_INSPECT_MALFORMED", "not-a-bool")
+
+	report := envutil.Inspect(func() {
+		envutil.RequireEnv("TEST_INSPECT_SET")
+		envutil.RequireEnv("TEST_INSPECT_MISSING")
+		envutil.GetEnvParsedOrDefault("TEST_INSPECT_MALFORMED", strconv.ParseBool, false)
+		envutil.GetEnvParsedOrDefault("TEST_INSPECT_DEFAULT", time.ParseDuration, 5*time.Second)
+	})
+
+	g.Expect(report.Errors).To(HaveLen(2))
+	g.Expect(report.Entries).To(Equal([]envutil.Entry{
+		{Key: "TEST_INSPECT_SET", Value: "value", IsSet: true},
+		{Key: "TEST_INSPECT_MISSING"},
+		{Key: "TEST_INSPECT_MALFORMED", Value: "not-a-bool", IsSet: true},
+		{Key: "TEST_INSPECT_DEFAULT", Value: "5s", IsDefault: true},
+	}))
+}
+
+func TestRequireEnvPanicsOutsideOfInspect(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(func() { envutil.RequireEnv("TEST_INSPECT_MISSING") }).To(Panic())
+}
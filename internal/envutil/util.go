@@ -3,8 +3,6 @@ package envutil
 import (
 	"fmt"
 	"os"
-
-	"github.com/distr-sh/distr/internal/util"
 )
 
 type GetEnvOpts struct {
@@ -12,11 +10,12 @@ type GetEnvOpts struct {
 }
 
 func GetEnv(key string) string {
-	return os.Getenv(key)
+	value, _ := lookupEnv(key)
+	return value
 }
 
 func GetEnvOrNil(key string) *string {
-	if value, ok := os.LookupEnv(key); ok {
+	if value, ok := lookupEnv(key); ok {
 		return &value
 	}
 	return nil
@@ -26,17 +25,19 @@ func GetEnvOrDefault(key, defaultValue string, opts GetEnvOpts) string {
 	if value := GetEnv(key); value != "" {
 		return value
 	} else if opts.DeprecatedAlias != "" {
-		if value := GetEnv(opts.DeprecatedAlias); value != "" {
+		if value, _ := os.LookupEnv(opts.DeprecatedAlias); value != "" {
 			fmt.Fprintf(os.Stderr, "\nWARNING: use of deprecated variable \"%v\", please use \"%v\" instead\n\n",
 				opts.DeprecatedAlias, key)
+			recordValue(key, value)
 			return value
 		}
 	}
+	recordDefault(key, defaultValue)
 	return defaultValue
 }
 
 func GetEnvParsedOrNilErr[T any](key string, parseFunc func(string) (T, error)) (*T, error) {
-	if value, ok := os.LookupEnv(key); ok {
+	if value, ok := lookupEnv(key); ok {
 		if parsed, err := parseFunc(value); err != nil {
 			return nil, fmt.Errorf("malformed environment variable %v: %v", key, err)
 		} else {
@@ -47,22 +48,23 @@ func GetEnvParsedOrNilErr[T any](key string, parseFunc func(string) (T, error))
 }
 
 func GetEnvParsedOrNil[T any](key string, parseFunc func(string) (T, error)) *T {
-	return util.Require(GetEnvParsedOrNilErr(key, parseFunc))
+	return require(GetEnvParsedOrNilErr(key, parseFunc))
 }
 
 func GetEnvParsedOrDefaultErr[T any](key string, parseFunc func(string) (T, error), defaultValue T) (T, error) {
-	if value, ok := os.LookupEnv(key); ok {
+	if value, ok := lookupEnv(key); ok {
 		if parsed, err := parseFunc(value); err != nil {
 			return parsed, fmt.Errorf("malformed environment variable %v: %v", key, err)
 		} else {
 			return parsed, nil
 		}
 	}
+	recordDefault(key, defaultValue)
 	return defaultValue, nil
 }
 
 func GetEnvParsedOrDefault[T any](key string, parseFunc func(string) (T, error), defaultValue T) T {
-	return util.Require(GetEnvParsedOrDefaultErr(key, parseFunc, defaultValue))
+	return require(GetEnvParsedOrDefaultErr(key, parseFunc, defaultValue))
 }
 
 func RequireEnvErr(key string) (string, error) {
@@ -73,7 +75,7 @@ func RequireEnvErr(key string) (string, error) {
 }
 
 func RequireEnv(key string) string {
-	return util.Require(RequireEnvErr(key))
+	return require(RequireEnvErr(key))
 }
 
 func RequireEnvParsedErr[T any](key string, parseFunc func(string) (T, error)) (T, error) {
@@ -88,5 +90,5 @@ func RequireEnvParsedErr[T any](key string, parseFunc func(string) (T, error)) (
 }
 
 func RequireEnvParsed[T any](key string, parseFunc func(string) (T, error)) T {
-	return util.Require(RequireEnvParsedErr(key, parseFunc))
+	return require(RequireEnvParsedErr(key, parseFunc))
 }
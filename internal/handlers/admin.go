@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/cleanup"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/jobs"
+	"github.com/distr-sh/distr/internal/middleware"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/getsentry/sentry-go"
+	"github.com/oaswrap/spec/adapter/chiopenapi"
+	"github.com/oaswrap/spec/option"
+	"go.uber.org/zap"
+)
+
+func AdminRouter(scheduler *jobs.Scheduler) func(r chiopenapi.Router) {
+	return func(r chiopenapi.Router) {
+		r.WithOptions(option.GroupTags("Admin"), option.GroupHidden(true))
+		r.Use(middleware.RequireSuperAdmin)
+		r.Get("/cleanup-config", getCleanupConfigHandler(scheduler)).
+			With(option.Description("List all cleanup jobs with their retention settings and most recent run")).
+			With(option.Response(http.StatusOK, []api.CleanupJobConfigResponse{}))
+		r.Post("/jobs/{name}/run", runJobHandler(scheduler)).
+			With(option.Description("Run a scheduled job immediately")).
+			With(option.Request(struct {
+				Name string `path:"name"`
+			}{})).
+			With(option.Response(http.StatusOK, api.JobRunResponse{}))
+	}
+}
+
+func runJobHandler(scheduler *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := internalctx.GetLogger(ctx)
+		name := r.PathValue("name")
+
+		log.Info("running job on demand", zap.String("job", name))
+		// The job should not be aborted if the client disconnects. The job timeout still applies.
+		result, err := scheduler.RunJob(context.WithoutCancel(ctx), name)
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if errors.Is(err, jobs.ErrJobAlreadyRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		RespondJSON(w, jobRunResultToAPI(name, result))
+	}
+}
+
+func getCleanupConfigHandler(scheduler *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		lastResults, err := scheduler.LastResults(ctx)
+		if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to get last job results", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		cleanupJobs := cleanup.Jobs()
+		response := make([]api.CleanupJobConfigResponse, len(cleanupJobs))
+		for i, job := range cleanupJobs {
+			response[i] = api.CleanupJobConfigResponse{
+				Job:            job.Name,
+				Cron:           job.Cron,
+				TimeoutSeconds: job.Timeout.Seconds(),
+				MaxCount:       job.MaxCount,
+			}
+			if job.MaxAge != nil {
+				response[i].MaxAgeSeconds = util.PtrTo(int64(job.MaxAge.Seconds()))
+			}
+			if result, ok := lastResults[job.Name]; ok {
+				response[i].LastRun = util.PtrTo(jobRunResultToAPI(job.Name, result))
+			}
+		}
+		RespondJSON(w, response)
+	}
+}
+
+func jobRunResultToAPI(name string, result jobs.RunResult) api.JobRunResponse {
+	response := api.JobRunResponse{
+		Job:            name,
+		StartedAt:      result.StartedAt,
+		ElapsedSeconds: result.Elapsed.Seconds(),
+		Success:        result.Err == nil,
+		RowsAffected:   result.RowsAffected,
+	}
+	if result.Err != nil {
+		response.Error = util.PtrTo(result.Err.Error())
+	}
+	return response
+}
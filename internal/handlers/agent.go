@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -23,6 +24,7 @@ import (
 	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/deploymentvalues"
 	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/httpencoding"
 	"github.com/distr-sh/distr/internal/middleware"
 	"github.com/distr-sh/distr/internal/notification"
 	"github.com/distr-sh/distr/internal/security"
@@ -31,6 +33,7 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/go-chi/httprate"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/oaswrap/spec/adapter/chiopenapi"
 	"github.com/oaswrap/spec/option"
 	"go.uber.org/zap"
@@ -66,12 +69,18 @@ func AgentRouter(r chiopenapi.Router) {
 			rateLimitPerAgent,
 		).Group(func(r chiopenapi.Router) {
 			// agent routes, authenticated via token
+			// outdated agents must always be able to fetch their manifest and resources to update themselves
 			r.Get("/manifest", agentManifestHandler())
 			r.Get("/resources", agentResourcesHandler)
-			r.Post("/status", agentPostStatusHandler)
-			r.Post("/metrics", agentPostMetricsHander)
-			r.Put("/logs", agentPutDeploymentLogsHandler())
-			r.Put("/deployment-target-logs", agentPutDeploymentTargetLogsHandler())
+			r.With(middleware.ReadOnlyMode).Post("/heartbeat", agentPostHeartbeatHandler)
+			r.With(requireSupportedAgentVersion, middleware.ReadOnlyMode).Group(func(r chiopenapi.Router) {
+				r.Post("/status", agentPostStatusHandler)
+				r.Post("/status/batch", agentPostStatusBatchHandler)
+				r.Post("/metrics", agentPostMetricsHander)
+				r.With(middleware.DecompressRequestBody).Put("/logs", agentPutDeploymentLogsHandler())
+				r.With(middleware.DecompressRequestBody).
+					Put("/deployment-target-logs", agentPutDeploymentTargetLogsHandler())
+			})
 		})
 	})
 }
@@ -96,10 +105,7 @@ func connectHandler() http.HandlerFunc {
 			sentry.GetHubFromContext(ctx).CaptureException(err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		} else {
-			w.Header().Add("Content-Type", "application/yaml")
-			if _, err := io.Copy(w, manifest); err != nil {
-				log.Warn("writing to client failed", zap.Error(err))
-			}
+			writeAgentManifest(w, r, manifest, log)
 		}
 	}
 }
@@ -310,8 +316,27 @@ func agentPutDeploymentLogsHandler() http.HandlerFunc {
 			return
 		}
 
-		if err := db.SaveDeploymentLogRecords(ctx, records); errors.Is(err, apierrors.ErrBadRequest) {
+		deploymentTarget := internalctx.GetDeploymentTarget(ctx)
+		err = db.RunTx(ctx, func(ctx context.Context) error {
+			if err := db.SaveDeploymentLogRecords(ctx, records); err != nil {
+				return err
+			}
+			limit := deploymentTarget.GetEffectiveLogRecordEntriesMaxCount(env.LogRecordEntriesMaxCount())
+			if limit == nil {
+				return nil
+			}
+			deploymentIDs := make([]uuid.UUID, 0, len(records))
+			for _, record := range records {
+				if !slices.Contains(deploymentIDs, record.DeploymentID) {
+					deploymentIDs = append(deploymentIDs, record.DeploymentID)
+				}
+			}
+			_, err := db.TrimDeploymentLogRecords(ctx, deploymentIDs, *limit)
+			return err
+		})
+		if errors.Is(err, apierrors.ErrBadRequest) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		} else if err != nil {
 			log.Error("error saving deployment log records", zap.Error(err))
 			sentry.GetHubFromContext(ctx).CaptureException(err)
@@ -319,6 +344,8 @@ func agentPutDeploymentLogsHandler() http.HandlerFunc {
 			return
 		}
 
+		_ = deploymentLogTail.ExportDeploymentLogs(ctx, records)
+
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -333,7 +360,18 @@ func agentPutDeploymentTargetLogsHandler() http.HandlerFunc {
 			return
 		}
 
-		if err := db.SaveDeploymentTargetLogRecords(ctx, deploymentTarget.ID, records); err != nil {
+		err = db.RunTx(ctx, func(ctx context.Context) error {
+			if err := db.SaveDeploymentTargetLogRecords(ctx, deploymentTarget.ID, records); err != nil {
+				return err
+			}
+			if limit := deploymentTarget.GetEffectiveLogRecordEntriesMaxCount(env.LogRecordEntriesMaxCount()); limit != nil {
+				if _, err := db.TrimDeploymentTargetLogRecords(ctx, deploymentTarget.ID, *limit); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
 			log.Error("error saving deployment target log records", zap.Error(err))
 			sentry.GetHubFromContext(ctx).CaptureException(err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -404,9 +442,15 @@ func agentPostStatusHandler(w http.ResponseWriter, r *http.Request) {
 		Type:                 requestBody.Type,
 		Message:              requestBody.Message,
 	}
+	if requestBody.ObservedAt != nil {
+		status.CreatedAt = *requestBody.ObservedAt
+	}
 
-	if err := db.CreateDeploymentRevisionStatus(ctx, &status); err != nil {
-		if errors.Is(err, apierrors.ErrConflict) {
+	if err := db.CreateDeploymentRevisionStatus(ctx, &status, requestBody.IdempotencyKey); err != nil {
+		if errors.Is(err, apierrors.ErrAlreadyExists) {
+			log.Debug("ignoring duplicate deployment revision status", zap.Stringer("statusId", status.ID))
+			w.WriteHeader(http.StatusOK)
+		} else if errors.Is(err, apierrors.ErrConflict) {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		} else {
 			log.Error("failed to create deployment revision status", zap.Error(err))
@@ -435,6 +479,109 @@ func agentPostStatusHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// agentPostStatusBatchHandler stores multiple statuses at once. Statuses for revisions that do not belong to the
+// deployment target are reported as failed in the response, all other statuses are stored.
+func agentPostStatusBatchHandler(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := JsonBody[[]api.AgentDeploymentStatus](w, r)
+	if err != nil {
+		return
+	} else if len(requestBody) > api.MaxAgentDeploymentStatusBatchSize {
+		http.Error(w, fmt.Sprintf("at most %v statuses can be submitted at once", api.MaxAgentDeploymentStatusBatchSize),
+			http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	sentry := sentry.GetHubFromContext(ctx)
+	deploymentTarget := internalctx.GetDeploymentTarget(ctx)
+
+	revisionIDs := make([]uuid.UUID, len(requestBody))
+	for i, status := range requestBody {
+		revisionIDs[i] = status.RevisionID
+	}
+	deploymentIDs, err := db.GetDeploymentIDsForRevisionIDs(ctx, revisionIDs)
+	if err != nil {
+		sentry.CaptureException(err)
+		log.Error("failed to get deployment IDs", zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	response := api.AgentDeploymentStatusBatchResponse{Failed: []api.AgentDeploymentStatusBatchFailure{}}
+	deployments := make(map[uuid.UUID]types.DeploymentWithLatestRevision)
+	previousStatuses := make(map[uuid.UUID]*types.DeploymentRevisionStatus)
+	accepted := make([]api.AgentDeploymentStatus, 0, len(requestBody))
+	for _, status := range requestBody {
+		deploymentID, ok := deploymentIDs[status.RevisionID]
+		if !ok {
+			response.Failed = append(response.Failed, api.AgentDeploymentStatusBatchFailure{
+				RevisionID:     status.RevisionID,
+				IdempotencyKey: status.IdempotencyKey,
+				Error:          "revision not found",
+			})
+			continue
+		}
+		if _, ok := deployments[deploymentID]; !ok {
+			i := slices.IndexFunc(
+				deploymentTarget.Deployments,
+				func(d types.DeploymentWithLatestRevision) bool { return d.ID == deploymentID },
+			)
+			if i < 0 {
+				response.Failed = append(response.Failed, api.AgentDeploymentStatusBatchFailure{
+					RevisionID:     status.RevisionID,
+					IdempotencyKey: status.IdempotencyKey,
+					Error:          "revision does not belong to a deployment of this deployment target",
+				})
+				continue
+			}
+			previousStatus, err := db.GetLatestDeploymentRevisionStatus(ctx, deploymentID)
+			if err != nil {
+				sentry.CaptureException(err)
+				log.Error("failed to get latest deployment revision status", zap.Error(err))
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			deployments[deploymentID] = deploymentTarget.Deployments[i]
+			previousStatuses[deploymentID] = previousStatus
+		}
+		accepted = append(accepted, status)
+	}
+
+	created, err := db.BulkCreateDeploymentRevisionStatus(ctx, accepted)
+	if errors.Is(err, apierrors.ErrConflict) {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Error("failed to create deployment revision statuses", zap.Error(err))
+		sentry.CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	go func(ctx context.Context) {
+		asyncCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		for _, status := range created {
+			deploymentID := deploymentIDs[status.DeploymentRevisionID]
+			if err := notification.SendDeploymentStatusNotifications(
+				asyncCtx,
+				*deploymentTarget,
+				deployments[deploymentID],
+				previousStatuses[deploymentID],
+				status,
+			); err != nil {
+				sentry.CaptureException(err)
+				log.Error("failed to dispatch deployment status notification", zap.Error(err))
+			}
+			previousStatuses[deploymentID] = &status
+		}
+	}(context.WithoutCancel(ctx))
+
+	RespondJSON(w, response)
+}
+
 func agentPostMetricsHander(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := internalctx.GetLogger(ctx)
@@ -459,6 +606,20 @@ func agentPostMetricsHander(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func agentPostHeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dt := internalctx.GetDeploymentTarget(ctx)
+
+	if err := db.UpdateDeploymentTargetLastSeenAt(ctx, dt.ID); err != nil {
+		internalctx.GetLogger(ctx).Error("failed to update deployment target last seen", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func queryAuthDeploymentTargetCtxMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -496,14 +657,32 @@ func agentManifestHandler() http.HandlerFunc {
 			sentry.GetHubFromContext(ctx).CaptureException(err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		} else {
-			w.Header().Add("Content-Type", "application/yaml")
-			if _, err := io.Copy(w, manifest); err != nil {
-				log.Warn("writing to client failed", zap.Error(err))
-			}
+			writeAgentManifest(w, r, manifest, log)
 		}
 	}
 }
 
+// writeAgentManifest writes the manifest to the response. It is gzip compressed if the client supports it, because
+// Kubernetes manifests can be large and agents may run on metered connections.
+func writeAgentManifest(w http.ResponseWriter, r *http.Request, manifest io.Reader, log *zap.Logger) {
+	w.Header().Add("Content-Type", "application/yaml")
+	w.Header().Add("Vary", "Accept-Encoding")
+	if httpencoding.Negotiate(r, httpencoding.Gzip) == "" {
+		if _, err := io.Copy(w, manifest); err != nil {
+			log.Warn("writing to client failed", zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Encoding", httpencoding.Gzip)
+	gz := gzip.NewWriter(w)
+	if _, err := io.Copy(gz, manifest); err != nil {
+		log.Warn("writing to client failed", zap.Error(err))
+	} else if err := gz.Close(); err != nil {
+		log.Warn("writing to client failed", zap.Error(err))
+	}
+}
+
 func agentAuthDeploymentTargetCtxMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -517,9 +696,15 @@ func agentAuthDeploymentTargetCtxMiddleware(next http.Handler) http.Handler {
 		} else if err != nil {
 			log.Error("failed to get DeploymentTarget", zap.Error(err))
 			w.WriteHeader(http.StatusInternalServerError)
+		} else if token, ok := auth.Token().(jwt.Token); ok &&
+			!deploymentTarget.AcceptsAgentTokenIssuedAt(token.IssuedAt()) {
+			// the secret was rotated, so the agent has to log in again with the new secret
+			w.WriteHeader(http.StatusUnauthorized)
 		} else {
-			if ua := r.UserAgent(); strings.HasPrefix(ua, fmt.Sprintf("%v/", useragent.DistrAgentUserAgent)) {
-				reportedVersionName := strings.TrimPrefix(ua, fmt.Sprintf("%v/", useragent.DistrAgentUserAgent))
+			if reportedVersionName, ok := getReportedAgentVersionName(r); ok {
+				if err := types.CheckAgentVersionName(reportedVersionName, env.AgentMinVersion()); err != nil {
+					w.Header().Set(api.AgentVersionWarningHeader, err.Error())
+				}
 				if reportedVersion, err := db.GetAgentVersionWithName(ctx, reportedVersionName); err != nil {
 					log.Error("could not get reported agent version", zap.Error(err))
 					sentry.GetHubFromContext(ctx).CaptureException(err)
@@ -538,6 +723,33 @@ func agentAuthDeploymentTargetCtxMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requireSupportedAgentVersion rejects requests of agents that are older than the minimum supported version, if it is
+// enforced. Requests without a version, e.g. from custom clients, are not rejected.
+func requireSupportedAgentVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !env.AgentMinVersionEnforced() {
+			next.ServeHTTP(w, r)
+		} else if name, ok := getReportedAgentVersionName(r); !ok {
+			next.ServeHTTP(w, r)
+		} else if err := types.CheckAgentVersionName(name, env.AgentMinVersion()); err == nil {
+			next.ServeHTTP(w, r)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUpgradeRequired)
+			_ = json.NewEncoder(w).Encode(api.AgentVersionOutdatedResponse{
+				Message:         err.Error(),
+				ReportedVersion: name,
+				MinVersion:      env.AgentMinVersion().String(),
+			})
+		}
+	})
+}
+
+// getReportedAgentVersionName returns the version that the agent sent in its user agent.
+func getReportedAgentVersionName(r *http.Request) (string, bool) {
+	return strings.CutPrefix(r.UserAgent(), fmt.Sprintf("%v/", useragent.DistrAgentUserAgent))
+}
+
 func getVerifiedDeploymentTarget(
 	ctx context.Context,
 	targetID uuid.UUID,
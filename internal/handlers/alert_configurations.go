@@ -82,15 +82,22 @@ func createAlertConfigurationHandler() http.HandlerFunc {
 		config, err := JsonBody[types.AlertConfiguration](w, r)
 		if err != nil {
 			return
+		} else if err := config.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
 		config.OrganizationID = *auth.CurrentOrgID()
 		config.CustomerOrganizationID = auth.CurrentCustomerOrgID()
 
 		if err := db.CreateAlertConfiguration(ctx, &config); err != nil {
-			internalctx.GetLogger(ctx).Error("failed to create alert configuration", zap.Error(err))
-			sentry.GetHubFromContext(ctx).CaptureException(err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			if errors.Is(err, apierrors.ErrBadRequest) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				internalctx.GetLogger(ctx).Error("failed to create alert configuration", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -113,6 +120,9 @@ func updateAlertConfigurationHandler() http.HandlerFunc {
 		config, err := JsonBody[types.AlertConfiguration](w, r)
 		if err != nil {
 			return
+		} else if err := config.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
 		config.ID = id
@@ -120,9 +130,15 @@ func updateAlertConfigurationHandler() http.HandlerFunc {
 		config.CustomerOrganizationID = auth.CurrentCustomerOrgID()
 
 		if err := db.UpdateAlertConfiguration(ctx, &config); err != nil {
-			internalctx.GetLogger(ctx).Error("failed to update alert configuration", zap.Error(err))
-			sentry.GetHubFromContext(ctx).CaptureException(err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			if errors.Is(err, apierrors.ErrBadRequest) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else if errors.Is(err, apierrors.ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				internalctx.GetLogger(ctx).Error("failed to update alert configuration", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -73,7 +73,10 @@ func createArtifactLicense(w http.ResponseWriter, r *http.Request) {
 	}
 	license.OrganizationID = *auth.CurrentOrgID()
 
-	if err = validateLicenseSelections(license); err != nil {
+	if err = license.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err = validateLicenseSelections(license); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -109,7 +112,10 @@ func updateArtifactLicense(w http.ResponseWriter, r *http.Request) {
 	}
 	license.OrganizationID = *auth.CurrentOrgID()
 
-	if err = validateLicenseSelections(license); err != nil {
+	if err = license.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err = validateLicenseSelections(license); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -1,17 +1,26 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/mapping"
 	"github.com/distr-sh/distr/internal/middleware"
+	"github.com/distr-sh/distr/internal/subscription"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
 	"github.com/oaswrap/spec/adapter/chiopenapi"
 	"github.com/oaswrap/spec/option"
 	"go.uber.org/zap"
@@ -24,12 +33,31 @@ func ArtifactPullsRouter(r chiopenapi.Router) {
 		middleware.RequireVendor,
 	)
 	r.Get("/", getArtifactPullsHandler()).
-		With(option.Description("List artifact version pulls")).
+		With(option.Description("List artifact version pulls, newest first. To get the next page, pass the createdAt " +
+			"and id of the last pull as before and beforeId, so that pulls with the same timestamp are not skipped.")).
 		With(option.Request(struct {
-			Before *time.Time `query:"before"`
-			Count  *int       `query:"count"`
+			Before   *time.Time `query:"before"`
+			BeforeID *uuid.UUID `query:"beforeId"`
+			Count    *int       `query:"count"`
 		}{})).
 		With(option.Response(http.StatusOK, []api.ArtifactVersionPullResponse{}))
+	r.Get("/by-customer", getArtifactPullsByCustomerHandler()).
+		With(option.Description(
+			"List pull totals per customer organization within a period, which defaults to the last 30 days",
+		)).
+		With(option.Request(struct {
+			From *time.Time `query:"from"`
+			To   *time.Time `query:"to"`
+		}{})).
+		With(option.Response(http.StatusOK, []types.CustomerOrganizationPullStats{}))
+	r.Get("/export", exportArtifactPullsHandler()).
+		With(option.Description("Export artifact version pulls as CSV")).
+		With(option.Request(struct {
+			Before     *time.Time `query:"before"`
+			After      *time.Time `query:"after"`
+			ArtifactID *uuid.UUID `query:"artifactId"`
+		}{})).
+		With(option.Response(http.StatusOK, nil, option.ContentType("text/csv")))
 }
 
 func getArtifactPullsHandler() http.HandlerFunc {
@@ -38,6 +66,7 @@ func getArtifactPullsHandler() http.HandlerFunc {
 		log := internalctx.GetLogger(ctx)
 		auth := auth.Authentication.Require(ctx)
 		before := time.Now()
+		var beforeID *uuid.UUID
 		count := 50
 		if s := r.FormValue("before"); s != "" {
 			if t, err := time.Parse(time.RFC3339Nano, s); err != nil {
@@ -47,15 +76,23 @@ func getArtifactPullsHandler() http.HandlerFunc {
 				before = t
 			}
 		}
+		if s := r.FormValue("beforeId"); s != "" {
+			if id, err := uuid.Parse(s); err != nil {
+				http.Error(w, "beforeId must be a UUID", http.StatusBadRequest)
+				return
+			} else {
+				beforeID = &id
+			}
+		}
 		if s := r.FormValue("count"); s != "" {
-			if n, err := strconv.Atoi(s); err != nil {
-				http.Error(w, "limit must be a date", http.StatusBadRequest)
+			if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+				http.Error(w, "count must be a positive number", http.StatusBadRequest)
 				return
 			} else {
 				count = n
 			}
 		}
-		pulls, err := db.GetArtifactVersionPulls(ctx, *auth.CurrentOrgID(), count, before)
+		pulls, err := db.GetArtifactVersionPulls(ctx, *auth.CurrentOrgID(), count, before, beforeID)
 		if err != nil {
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			sentry.GetHubFromContext(ctx).CaptureException(err)
@@ -66,3 +103,117 @@ func getArtifactPullsHandler() http.HandlerFunc {
 		RespondJSON(w, mapping.List(pulls, mapping.ArtifactVersionPullToAPI))
 	}
 }
+
+func getArtifactPullsByCustomerHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := internalctx.GetLogger(ctx)
+		auth := auth.Authentication.Require(ctx)
+
+		from, to, err := PeriodQueryParams(r, 30*24*time.Hour)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if stats, err := db.GetPullsGroupedByCustomerOrganization(ctx, *auth.CurrentOrgID(), nil, from, to, 0); err != nil {
+			log.Error("failed to get pulls by customer organization", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		} else {
+			RespondJSON(w, stats)
+		}
+	}
+}
+
+func exportArtifactPullsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := internalctx.GetLogger(ctx)
+		authInfo := auth.Authentication.Require(ctx)
+		org := authInfo.CurrentOrg()
+		limit := int(subscription.GetLogExportRowsLimit(org.SubscriptionType))
+
+		before, err := QueryParam(r, "before", ParseTimeFunc(time.RFC3339Nano))
+		if err != nil && !errors.Is(err, ErrParamNotDefined) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		after, err := QueryParam(r, "after", ParseTimeFunc(time.RFC3339Nano))
+		if err != nil && !errors.Is(err, ErrParamNotDefined) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var artifactID *uuid.UUID
+		var artifactName string
+		if id, err := QueryParam(r, "artifactId", uuid.Parse); err == nil {
+			if artifact, err := db.GetArtifactByID(ctx, org.ID, id, nil); errors.Is(err, apierrors.ErrNotFound) {
+				http.Error(w, "artifact not found", http.StatusBadRequest)
+				return
+			} else if err != nil {
+				log.Error("failed to get artifact", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			} else {
+				artifactID = &artifact.ID
+				artifactName = artifact.Name
+			}
+		} else if !errors.Is(err, ErrParamNotDefined) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		SetFileDownloadHeaders(w, artifactPullsExportFilename(artifactName, before, after))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"Date", "Customer", "User", "Email", "Address", "Artifact", "Version"})
+		err = db.GetArtifactVersionPullsForExport(
+			ctx, org.ID, artifactID, before, after, limit,
+			func(pull types.ArtifactVersionPull) error {
+				response := mapping.ArtifactVersionPullToAPI(pull)
+				return cw.Write([]string{
+					response.CreatedAt.Format(time.RFC3339),
+					util.PtrDerefOrZero(response.CustomerOrganizationName),
+					util.PtrDerefOrZero(response.UserAccountName),
+					util.PtrDerefOrZero(response.UserAccountEmail),
+					util.PtrDerefOrZero(response.RemoteAddress),
+					response.Artifact.Name,
+					response.ArtifactVersion.Name,
+				})
+			},
+		)
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+		if err != nil {
+			log.Error("failed to export artifact pulls", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			// Note: If headers were already sent, we can't send error response
+			return
+		}
+	}
+}
+
+// artifactPullsExportFilename includes the artifact name and the filter bounds in the filename, e.g.
+// "nginx_pulls_2024-01-01_to_2024-01-31.csv". If no range is given, the current date is used instead.
+func artifactPullsExportFilename(artifactName string, before, after time.Time) string {
+	const layout = "2006-01-02"
+	prefix := "pulls"
+	if artifactName != "" {
+		prefix = strings.ReplaceAll(artifactName, "/", "-") + "_" + prefix
+	}
+	switch {
+	case before.IsZero() && after.IsZero():
+		return fmt.Sprintf("%s_%s.csv", time.Now().Format(layout), prefix)
+	case after.IsZero():
+		return fmt.Sprintf("%s_until_%s.csv", prefix, before.Format(layout))
+	case before.IsZero():
+		return fmt.Sprintf("%s_%s_to_%s.csv", prefix, after.Format(layout), time.Now().Format(layout))
+	default:
+		return fmt.Sprintf("%s_%s_to_%s.csv", prefix, after.Format(layout), before.Format(layout))
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// TestGetArtifactPullsByCustomerHandler checks that only pulls of customer organizations of the current organization
+// are reported.
+func TestGetArtifactPullsByCustomerHandler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	user := dbtest.CreateUserAccount(t, ctx)
+	var orgs []types.Organization
+	var customerOrgs []types.CustomerOrganization
+	for i := range 2 {
+		org := dbtest.CreateOrganization(t, ctx)
+		customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: fmt.Sprintf("Customer %v", i)}
+		g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+		artifact := dbtest.CreateArtifact(t, ctx, org.ID, "pulls/app")
+		version := dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, "1.0.0", digest.FromString(fmt.Sprint(i)), imgspecv1.MediaTypeImageManifest)
+		g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", &customerOrg.ID, time.Now().Add(-time.Hour))).
+			To(Succeed())
+		g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", nil, time.Now().Add(-time.Hour))).
+			To(Succeed())
+		orgs = append(orgs, org)
+		customerOrgs = append(customerOrgs, customerOrg)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(
+		withTestAuth(ctx, &testAuthInfo{user: &user, org: &orgs[0], role: types.UserRoleAdmin}),
+		http.MethodGet, "/api/v1/artifact-pulls/by-customer", nil)
+	getArtifactPullsByCustomerHandler()(rr, req)
+
+	g.Expect(rr.Code).To(Equal(http.StatusOK))
+	var stats []types.CustomerOrganizationPullStats
+	g.Expect(json.Unmarshal(rr.Body.Bytes(), &stats)).To(Succeed())
+	g.Expect(stats).To(HaveLen(1))
+	g.Expect(stats[0].CustomerOrganizationID).To(Equal(customerOrgs[0].ID))
+	g.Expect(stats[0].PullsTotal).To(Equal(1))
+	g.Expect(stats[0].DistinctArtifacts).To(Equal(1))
+}
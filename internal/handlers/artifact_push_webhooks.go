@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/middleware"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"github.com/oaswrap/spec/adapter/chiopenapi"
+	"github.com/oaswrap/spec/option"
+	"go.uber.org/zap"
+)
+
+const artifactPushWebhookDeliveriesLimit = 50
+
+func ArtifactPushWebhooksRouter(r chiopenapi.Router) {
+	r.WithOptions(option.GroupTags("Artifacts"))
+	r.Use(
+		middleware.RequireOrgAndRole,
+		middleware.RequireVendor,
+		middleware.BlockSuperAdmin,
+		middleware.RequireAdmin,
+	)
+
+	r.Get("/", getArtifactPushWebhooksHandler()).
+		With(option.Description("List all artifact push webhooks")).
+		With(option.Response(http.StatusOK, []types.ArtifactPushWebhook{}))
+
+	r.Post("/", createArtifactPushWebhookHandler()).
+		With(option.Description("Create a webhook that is called whenever a tag of a matching artifact is pushed. " +
+			"Requests are signed with an HMAC-SHA256 of the secret in the X-Distr-Signature header.")).
+		With(option.Request(types.ArtifactPushWebhook{})).
+		With(option.Response(http.StatusOK, types.ArtifactPushWebhook{}))
+
+	r.Route("/{id}", func(r chiopenapi.Router) {
+		type IDRequest struct {
+			ID string `path:"id"`
+		}
+
+		r.Put("/", updateArtifactPushWebhookHandler()).
+			With(option.Description("Update an artifact push webhook. The secret is only replaced if it is set.")).
+			With(option.Request(struct {
+				IDRequest
+				types.ArtifactPushWebhook
+			}{})).
+			With(option.Response(http.StatusOK, types.ArtifactPushWebhook{}))
+
+		r.Delete("/", deleteArtifactPushWebhookHandler()).
+			With(option.Description("Delete an artifact push webhook")).
+			With(option.Request(IDRequest{}))
+
+		r.Get("/deliveries", getArtifactPushWebhookDeliveriesHandler()).
+			With(option.Description("List the most recent deliveries of an artifact push webhook")).
+			With(option.Request(IDRequest{})).
+			With(option.Response(http.StatusOK, []types.ArtifactPushWebhookDelivery{}))
+	})
+}
+
+func getArtifactPushWebhooksHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		webhooks, err := db.GetArtifactPushWebhooks(ctx, *auth.CurrentOrgID())
+		if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to get artifact push webhooks", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		for i := range webhooks {
+			webhooks[i].Secret = ""
+		}
+		RespondJSON(w, webhooks)
+	}
+}
+
+func createArtifactPushWebhookHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		webhook, err := JsonBody[types.ArtifactPushWebhook](w, r)
+		if err != nil {
+			return
+		} else if err := webhook.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if strings.TrimSpace(webhook.Secret) == "" {
+			http.Error(w, "secret must not be empty", http.StatusBadRequest)
+			return
+		}
+
+		webhook.OrganizationID = *auth.CurrentOrgID()
+		if err := db.CreateArtifactPushWebhook(ctx, &webhook); err != nil {
+			internalctx.GetLogger(ctx).Error("failed to create artifact push webhook", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		webhook.Secret = ""
+		RespondJSON(w, webhook)
+	}
+}
+
+func updateArtifactPushWebhookHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook, err := JsonBody[types.ArtifactPushWebhook](w, r)
+		if err != nil {
+			return
+		} else if err := webhook.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook.ID = id
+		webhook.OrganizationID = *auth.CurrentOrgID()
+		if err := db.UpdateArtifactPushWebhook(ctx, &webhook); errors.Is(err, apierrors.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		} else if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to update artifact push webhook", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		webhook.Secret = ""
+		RespondJSON(w, webhook)
+	}
+}
+
+func deleteArtifactPushWebhookHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := db.DeleteArtifactPushWebhook(ctx, id, *auth.CurrentOrgID()); errors.Is(err, apierrors.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to delete artifact push webhook", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func getArtifactPushWebhookDeliveriesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := db.GetArtifactPushWebhookDeliveries(
+			ctx, id, *auth.CurrentOrgID(), artifactPushWebhookDeliveriesLimit,
+		)
+		if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to get artifact push webhook deliveries", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		RespondJSON(w, deliveries)
+	}
+}
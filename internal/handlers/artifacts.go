@@ -3,8 +3,14 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/containers/image/v5/manifest"
 	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
@@ -13,10 +19,13 @@ import (
 	"github.com/distr-sh/distr/internal/mapping"
 	"github.com/distr-sh/distr/internal/middleware"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/getsentry/sentry-go"
 	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/oaswrap/spec/adapter/chiopenapi"
 	"github.com/oaswrap/spec/option"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/zap"
 )
 
@@ -25,8 +34,42 @@ func ArtifactsRouter(r chiopenapi.Router) {
 	r.Use(middleware.RequireOrgAndRole)
 	r.Get("/", getArtifacts).
 		With(option.Description("List all artifacts")).
+		With(option.Request(struct {
+			Search  string `query:"search"`
+			Type    string `query:"type"`
+			SortBy  string `query:"sortBy"`
+			SortDir string `query:"sortDir"`
+			Limit   *int   `query:"limit"`
+			Offset  *int   `query:"offset"`
+		}{})).
 		With(option.Response(http.StatusOK, []api.ArtifactsResponse{}))
-	r.With(artifactMiddleware).Route("/{artifactId}", func(r chiopenapi.Router) {
+	r.With(middleware.RequireVendor, middleware.RequireReadWriteOrAdmin, middleware.BlockSuperAdmin).
+		Post("/", createArtifactHandler).
+		With(option.Description("Create an empty artifact, so that it can be pushed to even if the organization " +
+			"requires artifacts to be created before they can be pushed")).
+		With(option.Request(api.CreateArtifactRequest{})).
+		With(option.Response(http.StatusOK, api.ArtifactResponse{}))
+	r.With(middleware.RequireVendor).Get("/stats/top", getTopArtifactsByPullsHandler).
+		With(option.Description("List the most pulled artifacts within a period, which defaults to the last 30 days")).
+		With(option.Request(struct {
+			From  *time.Time `query:"from"`
+			To    *time.Time `query:"to"`
+			Limit *int       `query:"limit"`
+		}{})).
+		With(option.Response(http.StatusOK, []types.ArtifactPullStats{}))
+	r.With(middleware.RequireVendor, middleware.RequireAdmin, middleware.BlockSuperAdmin).
+		Route("/deleted", func(r chiopenapi.Router) {
+			r.Get("/", getDeletedArtifactsHandler).
+				With(option.Description("List deleted artifacts that can still be restored")).
+				With(option.Response(http.StatusOK, []types.DeletedArtifact{}))
+			r.Post("/{artifactId}/restore", restoreArtifactHandler).
+				With(option.Description("Restore a deleted artifact")).
+				With(option.Request(struct {
+					ArtifactID uuid.UUID `path:"artifactId"`
+				}{})).
+				With(option.Response(http.StatusOK, api.ArtifactResponse{}))
+		})
+	r.With(artifactMiddleware, requireArtifactReadAccess).Route("/{artifactId}", func(r chiopenapi.Router) {
 		type ArtifactRequest struct {
 			ArtifactID uuid.UUID `path:"artifactId"`
 		}
@@ -35,7 +78,71 @@ func ArtifactsRouter(r chiopenapi.Router) {
 			With(option.Description("Get an artifact by ID")).
 			With(option.Request(ArtifactRequest{})).
 			With(option.Response(http.StatusOK, []api.ArtifactResponse{}))
-		r.With(middleware.RequireVendor, middleware.RequireReadWriteOrAdmin, middleware.BlockSuperAdmin).
+		r.Get("/versions/{reference}/inspect", getArtifactVersionInspectionHandler).
+			With(option.Description(
+				"Get the parsed manifest of an artifact version, identified by tag or digest, including its config, " +
+					"layers, the platforms of an index and annotations",
+			)).
+			With(option.Request(struct {
+				ArtifactRequest
+				Reference string `path:"reference"`
+			}{})).
+			With(option.Response(http.StatusOK, api.ManifestInspection{}))
+		r.Get("/versions/{reference}/platform", getArtifactVersionPlatformHandler).
+			With(option.Description(
+				"Resolve the manifest for a platform from an index, identified by tag or digest. " +
+					"Responds with 404 Not Found if the index has no manifest for this platform.",
+			)).
+			With(option.Request(struct {
+				ArtifactRequest
+				Reference string `path:"reference"`
+				OS        string `query:"os"`
+				Arch      string `query:"arch"`
+				Variant   string `query:"variant"`
+			}{})).
+			With(option.Response(http.StatusOK, api.ManifestDescriptor{}))
+		r.With(middleware.RequireVendor, middleware.BlockSuperAdmin).
+			Get("/deletions", getArtifactDeletionsHandler).
+			With(option.Description("List recent deletions of tags of an artifact, including who deleted them")).
+			With(option.Request(struct {
+				ArtifactRequest
+				Limit *int `query:"limit"`
+			}{})).
+			With(option.Response(http.StatusOK, []types.ArtifactDeletion{}))
+		r.With(middleware.RequireVendor, middleware.BlockSuperAdmin, middleware.LicensingFeatureFlagEnabledMiddleware).
+			Get("/customer-access", getArtifactCustomerAccessHandler).
+			With(option.Description(
+				"List the customer organizations that can pull an artifact because of a license that has not expired. " +
+					"If the organization has no artifact licenses, all customer organizations are listed.",
+			)).
+			With(option.Request(ArtifactRequest{})).
+			With(option.Response(http.StatusOK, []types.ArtifactCustomerAccess{}))
+		r.With(middleware.RequireVendor, middleware.RequireAdmin, middleware.BlockSuperAdmin).
+			Route("/access-rules", func(r chiopenapi.Router) {
+				r.Get("/", getArtifactAccessRulesHandler).
+					With(option.Description("List all access rules of an artifact")).
+					With(option.Request(ArtifactRequest{})).
+					With(option.Response(http.StatusOK, []types.ArtifactAccessRule{}))
+				r.Post("/", createArtifactAccessRuleHandler).
+					With(option.Description("Create an access rule for an artifact")).
+					With(option.Request(struct {
+						ArtifactRequest
+						api.CreateArtifactAccessRuleRequest
+					}{})).
+					With(option.Response(http.StatusOK, types.ArtifactAccessRule{}))
+				r.Delete("/{accessRuleId}", deleteArtifactAccessRuleHandler).
+					With(option.Description("Delete an access rule of an artifact")).
+					With(option.Request(struct {
+						ArtifactRequest
+						AccessRuleID uuid.UUID `path:"accessRuleId"`
+					}{}))
+			})
+		r.With(
+			middleware.RequireVendor,
+			middleware.RequireReadWriteOrAdmin,
+			middleware.BlockSuperAdmin,
+			requireArtifactWriteAccess,
+		).
 			Group(func(r chiopenapi.Router) {
 				r.Patch("/image", patchImageArtifactHandler).
 					With(option.Description("Update artifact image")).
@@ -45,8 +152,34 @@ func ArtifactsRouter(r chiopenapi.Router) {
 					}{})).
 					With(option.Response(http.StatusOK, []api.ArtifactResponse{}))
 				r.Delete("/", deleteArtifactHandler).
-					With(option.Description("Delete an artifact")).
-					With(option.Request(ArtifactRequest{}))
+					With(option.Description(
+						"Delete an artifact with all its versions. " +
+							"Deleted artifacts can be restored until they are purged. " +
+							"If the optional confirm query parameter is set, it must match the name of the artifact. " +
+							"If the artifact is referenced by licenses, the request fails with 409 Conflict unless force is " +
+							"set, in which case the artifact is removed from those licenses. Only admins can use force.",
+					)).
+					With(option.Request(struct {
+						ArtifactRequest
+						Confirm string `query:"confirm"`
+						Force   bool   `query:"force"`
+					}{}))
+				r.Post("/tags", createArtifactTagHandler).
+					With(option.Description(
+						"Create a new tag for an existing version of an artifact, identified by its digest",
+					)).
+					With(option.Request(struct {
+						ArtifactRequest
+						api.CreateArtifactTagRequest
+					}{})).
+					With(option.Response(http.StatusOK, api.ArtifactResponse{}))
+				r.Delete("/tags", deleteArtifactTagsHandler).
+					With(option.Description("Delete all artifact tags matching a glob pattern")).
+					With(option.Request(struct {
+						ArtifactRequest
+						Pattern string `query:"pattern"`
+					}{})).
+					With(option.Response(http.StatusOK, []string{}))
 				r.Delete("/tags/{tagName}", deleteArtifactTagHandler).
 					With(option.Description("Delete an artifact tag")).
 					With(option.Request(struct {
@@ -62,8 +195,16 @@ func getArtifacts(w http.ResponseWriter, r *http.Request) {
 	log := internalctx.GetLogger(ctx)
 	auth := auth.Authentication.Require(ctx)
 
+	filter, err := artifactListFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if auth.CurrentCustomerOrgID() == nil && auth.CurrentUserRole() != nil {
+		filter.RestrictToReadableBy(auth.CurrentUserID(), *auth.CurrentUserRole())
+	}
+
 	var artifacts []types.ArtifactWithDownloads
-	var err error
 	if auth.CurrentOrg().HasFeature(types.FeatureLicensing) && auth.CurrentCustomerOrgID() != nil {
 		if licenses, err1 := db.GetArtifactLicenses(ctx, *auth.CurrentOrgID()); err1 != nil {
 			log.Error("failed to get artifact licenses", zap.Error(err1))
@@ -71,12 +212,12 @@ func getArtifacts(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		} else if len(licenses) > 0 {
-			artifacts, err = db.GetArtifactsByLicenseOwnerID(ctx, *auth.CurrentOrgID(), *auth.CurrentCustomerOrgID())
+			artifacts, err = db.GetArtifactsByLicenseOwnerID(ctx, *auth.CurrentOrgID(), *auth.CurrentCustomerOrgID(), filter)
 		} else {
-			artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID())
+			artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID(), filter)
 		}
 	} else {
-		artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID())
+		artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID(), filter)
 	}
 
 	if err != nil {
@@ -88,6 +229,26 @@ func getArtifacts(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func artifactListFilterFromRequest(r *http.Request) (types.ArtifactListFilter, error) {
+	filter := types.ArtifactListFilter{
+		NameQuery: strings.TrimSpace(r.FormValue("search")),
+		Type:      types.ManifestType(r.FormValue("type")),
+		SortBy:    types.ArtifactSortBy(r.FormValue("sortBy")),
+		SortDir:   types.SortDirection(r.FormValue("sortDir")),
+	}
+	if limit, err := QueryParam(r, "limit", strconv.Atoi, Min(1)); err == nil {
+		filter.Limit = limit
+	} else if !errors.Is(err, ErrParamNotDefined) {
+		return filter, err
+	}
+	if offset, err := QueryParam(r, "offset", strconv.Atoi, Min(0)); err == nil {
+		filter.Offset = offset
+	} else if !errors.Is(err, ErrParamNotDefined) {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
+
 func getArtifact(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	RespondJSON(w, mapping.ArtifactToAPI(*internalctx.GetArtifact(ctx)))
@@ -105,29 +266,51 @@ var patchImageArtifactHandler = patchImageHandler(func(ctx context.Context, body
 func deleteArtifactHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
 	artifact := internalctx.GetArtifact(ctx)
 
-	err := db.RunTx(ctx, func(ctx context.Context) error {
-		isReferenced, err := db.ArtifactIsReferencedInLicenses(ctx, artifact.ID)
+	if confirm := r.FormValue("confirm"); confirm != "" && confirm != artifact.Name {
+		http.Error(w, "query parameter confirm must match the name of the artifact", http.StatusBadRequest)
+		return
+	}
+
+	force := r.FormValue("force") == "true"
+	if force && *auth.CurrentUserRole() != types.UserRoleAdmin {
+		http.Error(w, "only admins can force the deletion of an artifact", http.StatusForbidden)
+		return
+	}
+
+	var deletedVersions int64
+	var licenses []types.ArtifactLicenseBase
+	err := db.RunTx(ctx, func(ctx context.Context) (err error) {
+		licenses, err = db.GetArtifactLicensesByArtifactID(ctx, artifact.ID)
 		if err != nil {
 			return err
 		}
-		if isReferenced {
-			return apierrors.NewBadRequest("Cannot delete artifact: it is referenced in one or more licenses.")
+		if len(licenses) > 0 {
+			if !force {
+				return apierrors.ErrConflict
+			}
+			if err := db.RemoveArtifactFromAllLicenses(ctx, artifact.ID); err != nil {
+				return err
+			}
 		}
 
-		if err := db.DeleteArtifactWithID(ctx, artifact.ID); err != nil {
-			return err
-		}
-		return nil
+		deletedVersions, err = db.DeleteArtifactWithID(ctx, artifact.ID, auth.CurrentUserID())
+		return err
 	})
 	if err != nil {
 		if errors.Is(err, apierrors.ErrNotFound) {
 			http.NotFound(w, r)
 			return
 		}
-		if errors.Is(err, apierrors.ErrBadRequest) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if errors.Is(err, apierrors.ErrConflict) {
+			if !force && len(licenses) > 0 {
+				http.Error(w, "artifact is referenced by licenses: "+strings.Join(licenseNames(licenses), ", "),
+					http.StatusConflict)
+			} else {
+				http.Error(w, "artifact is still in use", http.StatusConflict)
+			}
 			return
 		}
 		log.Error("error deleting artifact", zap.Error(err))
@@ -136,12 +319,182 @@ func deleteArtifactHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(licenses) > 0 {
+		log.Warn("artifact deleted by force, access revoked from licenses",
+			zap.Stringer("artifactId", artifact.ID),
+			zap.String("artifactName", artifact.Name),
+			zap.Stringer("userId", auth.CurrentUserID()),
+			zap.Strings("licenses", licenseNames(licenses)),
+			zap.Int64("deletedVersions", deletedVersions))
+	} else {
+		log.Info("artifact deleted",
+			zap.Stringer("artifactId", artifact.ID), zap.Int64("deletedVersions", deletedVersions))
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func getDeletedArtifactsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	if artifacts, err := db.GetDeletedArtifactsByOrgID(ctx, *auth.CurrentOrgID()); err != nil {
+		log.Error("failed to get deleted artifacts", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, artifacts)
+	}
+}
+
+func createArtifactHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	body, err := JsonBody[api.CreateArtifactRequest](w, r)
+	if err != nil {
+		return
+	} else if err := body.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result *types.ArtifactWithTaggedVersion
+	err = db.RunTx(ctx, func(ctx context.Context) error {
+		artifact := types.Artifact{Name: body.Name, OrganizationID: *auth.CurrentOrgID()}
+		if err := db.CreateArtifact(ctx, &artifact); err != nil {
+			return err
+		}
+		result, err = db.GetArtifactByID(ctx, *auth.CurrentOrgID(), artifact.ID, nil)
+		return err
+	})
+	if errors.Is(err, apierrors.ErrConflict) {
+		http.Error(w, "an artifact with this name already exists", http.StatusConflict)
+	} else if err != nil {
+		log.Error("failed to create artifact", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		log.Info("artifact created", zap.Stringer("artifactId", result.ID))
+		RespondJSON(w, mapping.ArtifactToAPI(*result))
+	}
+}
+
+func restoreArtifactHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	artifactID, err := uuid.Parse(r.PathValue("artifactId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var artifact *types.ArtifactWithTaggedVersion
+	err = db.RunTx(ctx, func(ctx context.Context) (err error) {
+		if _, err = db.RestoreArtifact(ctx, *auth.CurrentOrgID(), artifactID); err != nil {
+			return err
+		}
+		artifact, err = db.GetArtifactByID(ctx, *auth.CurrentOrgID(), artifactID, nil)
+		return err
+	})
+	if errors.Is(err, apierrors.ErrNotFound) {
+		http.NotFound(w, r)
+	} else if errors.Is(err, apierrors.ErrConflict) {
+		http.Error(w, "an artifact with this name already exists", http.StatusConflict)
+	} else if err != nil {
+		log.Error("failed to restore artifact", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		log.Info("artifact restored", zap.Stringer("artifactId", artifactID))
+		RespondJSON(w, mapping.ArtifactToAPI(*artifact))
+	}
+}
+
+func licenseNames(licenses []types.ArtifactLicenseBase) []string {
+	names := make([]string, len(licenses))
+	for i, license := range licenses {
+		names[i] = license.Name
+	}
+	return names
+}
+
+func createArtifactTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	body, err := JsonBody[api.CreateArtifactTagRequest](w, r)
+	if err != nil {
+		return
+	} else if err := body.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result *types.ArtifactWithTaggedVersion
+	var licenseErr error
+	err = db.RunTx(ctx, func(ctx context.Context) error {
+		if existing, err := db.GetArtifactVersionByTag(ctx, artifact.ID, body.Tag); err != nil {
+			if !errors.Is(err, apierrors.ErrNotFound) {
+				return err
+			} else if quotaOk, err := db.EnsureArtifactTagLimitForInsert(ctx, *auth.CurrentOrgID()); err != nil {
+				return err
+			} else if !quotaOk {
+				return apierrors.ErrTagQuotaExceeded
+			}
+		} else if existing.ManifestBlobDigest == body.Digest {
+			return nil
+		} else if !auth.CurrentOrg().HasFeature(types.FeatureArtifactVersionMutable) {
+			return fmt.Errorf("%w: tag %v already exists with different content", apierrors.ErrConflict, body.Tag)
+		} else if versionsWithSameDigest, err :=
+			db.GetArtifactVersionsByDigest(ctx, artifact.ID, string(existing.ManifestBlobDigest)); err != nil {
+			return err
+		} else if err := db.CheckArtifactVersionDeletionForLicenses(
+			ctx, artifact.ID, existing, versionsWithSameDigest,
+		); errors.Is(err, apierrors.ErrBadRequest) {
+			licenseErr = err
+			return apierrors.ErrConflict
+		} else if err != nil {
+			return err
+		} else if err := db.DeleteArtifactVersion(ctx, artifact.ID, body.Tag, auth.CurrentUserID()); err != nil {
+			return err
+		}
+
+		_, err := db.CreateArtifactTagForDigest(ctx, artifact.ID, body.Tag, body.Digest, auth.CurrentUserID())
+		return err
+	})
+	if err == nil {
+		result, err = db.GetArtifactByID(ctx, *auth.CurrentOrgID(), artifact.ID, nil)
+	}
+
+	if errors.Is(err, apierrors.ErrNotFound) {
+		http.Error(w, "artifact has no version with this digest", http.StatusBadRequest)
+	} else if licenseErr != nil {
+		http.Error(w, licenseErr.Error(), http.StatusConflict)
+	} else if errors.Is(err, apierrors.ErrConflict) {
+		http.Error(w, "tag already exists with different content", http.StatusConflict)
+	} else if errors.Is(err, apierrors.ErrQuotaExceeded) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+	} else if err != nil {
+		log.Error("failed to create artifact tag", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		log.Info("artifact tag created",
+			zap.Stringer("artifactId", artifact.ID), zap.String("tag", body.Tag), zap.String("digest", string(body.Digest)))
+		RespondJSON(w, mapping.ArtifactToAPI(*result))
+	}
+}
+
 func deleteArtifactTagHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
 	artifact := internalctx.GetArtifact(ctx)
 
 	tagName := r.PathValue("tagName")
@@ -180,7 +533,7 @@ func deleteArtifactTagHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Step 5: Delete the tag
-		return db.DeleteArtifactVersion(ctx, artifact.ID, tagName)
+		return db.DeleteArtifactVersion(ctx, artifact.ID, tagName, auth.CurrentUserID())
 	})
 	if err != nil {
 		if errors.Is(err, apierrors.ErrNotFound) {
@@ -204,6 +557,170 @@ func deleteArtifactTagHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func deleteArtifactTagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	pattern := r.FormValue("pattern")
+	if pattern == "" {
+		http.Error(w, "query parameter pattern is required", http.StatusBadRequest)
+		return
+	}
+
+	var deleted []string
+	err := db.RunTx(ctx, func(ctx context.Context) (err error) {
+		deleted, err = db.DeleteArtifactVersionsByPattern(ctx, artifact.ID, pattern, auth.CurrentUserID())
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, apierrors.ErrBadRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, apierrors.ErrConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Error("error deleting artifact tags", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	RespondJSON(w, deleted)
+}
+
+// manifestInspectionCache holds parsed manifests by digest. Manifests are content addressed, so entries never become
+// stale.
+var manifestInspectionCache = util.Require(lru.New[types.Digest, *api.ManifestInspection](1024))
+
+func getArtifactVersionInspectionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+
+	if version := getArtifactVersionFromRequest(w, r); version == nil {
+		return
+	} else if inspection, err := inspectArtifactVersion(*version); err != nil {
+		log.Error("failed to parse manifest", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, inspection)
+	}
+}
+
+func getArtifactVersionPlatformHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	wantOS, wantArch, wantVariant := r.FormValue("os"), r.FormValue("arch"), r.FormValue("variant")
+	if wantOS == "" || wantArch == "" {
+		http.Error(w, "os and arch are required", http.StatusBadRequest)
+		return
+	}
+
+	version := getArtifactVersionFromRequest(w, r)
+	if version == nil {
+		return
+	}
+	if !manifest.MIMETypeIsMultiImage(version.ManifestContentType) {
+		http.Error(w, "artifact version is not an index", http.StatusBadRequest)
+		return
+	}
+
+	match, platform, err := db.GetArtifactVersionForPlatform(
+		ctx, artifact.ID, version.ManifestBlobDigest, wantOS, wantArch, wantVariant,
+	)
+	if errors.Is(err, apierrors.ErrNotFound) {
+		http.Error(w, "no manifest matches the requested platform", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error("failed to get manifest for platform", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// a license can be restricted to single manifests of an index, so the resolved manifest is checked separately
+	if auth.CurrentCustomerOrgID() != nil && auth.CurrentOrg().HasFeature(types.FeatureLicensing) {
+		if err := db.CheckLicenseForArtifact(ctx,
+			artifact.OrganizationSlug,
+			artifact.Name,
+			string(match.ManifestBlobDigest),
+			*auth.CurrentCustomerOrgID(),
+			*auth.CurrentOrgID(),
+		); errors.Is(err, apierrors.ErrForbidden) {
+			http.Error(w, "the manifest for the requested platform is not licensed", http.StatusForbidden)
+			return
+		} else if err != nil {
+			log.Error("failed to check license", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	RespondJSON(w, api.ManifestDescriptor{
+		MediaType: match.ManifestContentType,
+		Digest:    match.ManifestBlobDigest,
+		Size:      match.ManifestBlobSize,
+		Platform: &imgspecv1.Platform{
+			OS:           platform.OS,
+			Architecture: platform.Architecture,
+			Variant:      platform.Variant,
+		},
+	})
+}
+
+// getArtifactVersionFromRequest returns the version of the artifact in the context that is identified by the
+// reference path parameter. If it does not exist or is not visible to the current user, an error response is written
+// and nil is returned.
+func getArtifactVersionFromRequest(w http.ResponseWriter, r *http.Request) *types.ArtifactVersion {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+	reference := r.PathValue("reference")
+
+	// customers may only access the versions they can see, vendors may also access the manifests of an index
+	if auth.CurrentCustomerOrgID() != nil &&
+		!slices.ContainsFunc(artifact.Versions, func(v types.TaggedArtifactVersion) bool {
+			return v.Digest == reference ||
+				slices.ContainsFunc(v.Tags, func(t types.ArtifactVersionTag) bool { return t.Name == reference })
+		}) {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	version, err := db.GetArtifactVersionByTag(ctx, artifact.ID, reference)
+	if errors.Is(err, apierrors.ErrNotFound) {
+		http.NotFound(w, r)
+		return nil
+	} else if err != nil {
+		log.Error("failed to get artifact version", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	}
+	return version
+}
+
+// inspectArtifactVersion parses the manifest of the given version, or returns the cached result for its digest.
+func inspectArtifactVersion(version types.ArtifactVersion) (*api.ManifestInspection, error) {
+	if inspection, ok := manifestInspectionCache.Get(version.ManifestBlobDigest); ok {
+		return inspection, nil
+	} else if inspection, err := mapping.ManifestInspectionToAPI(version); err != nil {
+		return nil, err
+	} else {
+		manifestInspectionCache.Add(version.ManifestBlobDigest, inspection)
+		return inspection, nil
+	}
+}
+
 func artifactMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -235,3 +752,200 @@ func artifactMiddleware(h http.Handler) http.Handler {
 		}
 	})
 }
+
+var (
+	requireArtifactReadAccess  = requireArtifactAccess(types.ArtifactPermissionRead)
+	requireArtifactWriteAccess = requireArtifactAccess(types.ArtifactPermissionWrite)
+)
+
+// requireArtifactAccess enforces the access rules of the artifact in the current context for vendor users. Customer
+// access is governed by licenses instead, which are checked when the artifact is loaded.
+func requireArtifactAccess(permission types.ArtifactPermission) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			log := internalctx.GetLogger(ctx)
+			auth := auth.Authentication.Require(ctx)
+			artifact := internalctx.GetArtifact(ctx)
+
+			if auth.CurrentCustomerOrgID() != nil && permission == types.ArtifactPermissionRead {
+				h.ServeHTTP(w, r)
+			} else if auth.CurrentUserRole() == nil {
+				http.Error(w, "insufficient permissions", http.StatusForbidden)
+			} else if rules, err := db.GetArtifactAccessRules(ctx, artifact.ID); err != nil {
+				log.Error("failed to get artifact access rules", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			} else if !types.ArtifactAccessAllowed(rules, auth.CurrentUserID(), *auth.CurrentUserRole(), permission) {
+				http.Error(w, "insufficient permissions", http.StatusForbidden)
+			} else {
+				h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+func getArtifactDeletionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	limit, err := QueryParam(r, "limit", strconv.Atoi, Min(1), Max(1000))
+	if errors.Is(err, ErrParamNotDefined) {
+		limit = 100
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if deletions, err := db.GetArtifactDeletions(ctx, artifact.ID, limit); err != nil {
+		log.Error("failed to get artifact deletions", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, deletions)
+	}
+}
+
+func getArtifactCustomerAccessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	if access, err := db.GetCustomerOrganizationsWithAccessToArtifact(ctx, *auth.CurrentOrgID(), artifact.ID); err != nil {
+		log.Error("failed to get customer organizations with access to artifact", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, access)
+	}
+}
+
+func getTopArtifactsByPullsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	from, to, err := PeriodQueryParams(r, 30*24*time.Hour)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := QueryParam(r, "limit", strconv.Atoi, Min(1), Max(100))
+	if errors.Is(err, ErrParamNotDefined) {
+		limit = 10
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if stats, err := db.GetTopArtifactsByPulls(ctx, *auth.CurrentOrgID(), nil, from, to, limit); err != nil {
+		log.Error("failed to get top artifacts by pulls", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, stats)
+	}
+}
+
+func getArtifactAccessRulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	if rules, err := db.GetArtifactAccessRules(ctx, artifact.ID); err != nil {
+		log.Error("failed to get artifact access rules", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, rules)
+	}
+}
+
+func createArtifactAccessRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	body, err := JsonBody[api.CreateArtifactAccessRuleRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	if (body.UserAccountID == nil) == (body.UserRole == nil) {
+		http.Error(w, "exactly one of userAccountId and userRole must be set", http.StatusBadRequest)
+		return
+	} else if body.Permission != types.ArtifactPermissionRead && body.Permission != types.ArtifactPermissionWrite {
+		http.Error(w, "permission must be one of read, write", http.StatusBadRequest)
+		return
+	} else if body.UserRole != nil && !slices.Contains(
+		[]types.UserRole{types.UserRoleReadOnly, types.UserRoleReadWrite, types.UserRoleAdmin}, *body.UserRole,
+	) {
+		http.Error(w, "invalid userRole", http.StatusBadRequest)
+		return
+	}
+
+	if body.UserAccountID != nil {
+		if user, err := db.GetUserAccountWithRole(ctx, *body.UserAccountID, *auth.CurrentOrgID(), nil); err != nil {
+			if errors.Is(err, apierrors.ErrNotFound) {
+				http.Error(w, "user account not found", http.StatusBadRequest)
+			} else {
+				log.Error("failed to get user account", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		} else if user.CustomerOrganizationID != nil {
+			http.Error(w, "access rules can only be created for vendor users", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rule := types.ArtifactAccessRule{
+		ArtifactID:    artifact.ID,
+		UserAccountID: body.UserAccountID,
+		UserRole:      body.UserRole,
+		Permission:    body.Permission,
+	}
+	if err := db.CreateArtifactAccessRule(ctx, &rule); err != nil {
+		if errors.Is(err, apierrors.ErrConflict) {
+			http.Error(w, "an access rule for this user or role already exists", http.StatusConflict)
+		} else if errors.Is(err, apierrors.ErrBadRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			log.Error("failed to create artifact access rule", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	RespondJSON(w, rule)
+}
+
+func deleteArtifactAccessRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	artifact := internalctx.GetArtifact(ctx)
+
+	ruleID, err := uuid.Parse(r.PathValue("accessRuleId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := db.DeleteArtifactAccessRule(ctx, artifact.ID, ruleID); err != nil {
+		if errors.Is(err, apierrors.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			log.Error("failed to delete artifact access rule", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestGetTopArtifactsByPullsHandlerInvalidParams(t *testing.T) {
+	info := &testAuthInfo{
+		user: &types.UserAccount{},
+		org:  &types.Organization{},
+		role: types.UserRoleAdmin,
+	}
+	for _, query := range []string{
+		"from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+		"from=yesterday",
+		"limit=0",
+		"limit=101",
+	} {
+		t.Run(query, func(t *testing.T) {
+			g := NewWithT(t)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(withTestAuth(context.Background(), info),
+				http.MethodGet, "/api/v1/artifacts/stats/top?"+query, nil)
+			getTopArtifactsByPullsHandler(rr, req)
+			g.Expect(rr.Code).To(Equal(http.StatusBadRequest))
+		})
+	}
+}
+
+func TestGetTopArtifactsByPullsHandler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org := dbtest.CreateOrganization(t, ctx)
+	user := dbtest.CreateUserAccount(t, ctx)
+	pulledAt := time.Now().Add(-time.Hour)
+	for i, name := range []string{"top/a", "top/b"} {
+		artifact := dbtest.CreateArtifact(t, ctx, org.ID, name)
+		version := dbtest.CreateArtifactVersion(
+			t, ctx, artifact.ID, "1.0.0", digest.FromString(name), imgspecv1.MediaTypeImageManifest)
+		for range i + 1 {
+			g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", nil, pulledAt)).To(Succeed())
+		}
+	}
+
+	info := &testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin}
+	request := func(query url.Values) []types.ArtifactPullStats {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(withTestAuth(ctx, info),
+			http.MethodGet, "/api/v1/artifacts/stats/top?"+query.Encode(), nil)
+		getTopArtifactsByPullsHandler(rr, req)
+		g.Expect(rr.Code).To(Equal(http.StatusOK))
+		var stats []types.ArtifactPullStats
+		g.Expect(json.Unmarshal(rr.Body.Bytes(), &stats)).To(Succeed())
+		return stats
+	}
+
+	stats := request(url.Values{})
+	g.Expect(stats).To(HaveLen(2))
+	g.Expect(stats[0].ArtifactName).To(Equal("top/b"))
+	g.Expect(stats[0].PullsTotal).To(Equal(2))
+	g.Expect(stats[1].ArtifactName).To(Equal("top/a"))
+	g.Expect(stats[1].PullsTotal).To(Equal(1))
+
+	g.Expect(request(url.Values{"limit": {"1"}})).To(HaveLen(1))
+	g.Expect(request(url.Values{"from": {pulledAt.Add(time.Second).Format(time.RFC3339Nano)}})).To(BeEmpty())
+}
+
+func TestGetArtifactVersionPlatformHandler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "platform/app")
+	customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: "Customer"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+	createVersion := func(d digest.Digest, contentType string) types.ArtifactVersion {
+		return dbtest.CreateArtifactVersion(t, ctx, artifact.ID, d.String(), d, contentType)
+	}
+	child := createVersion(digest.FromString("amd64"), imgspecv1.MediaTypeImageManifest)
+	index := createVersion(digest.FromString("index"), imgspecv1.MediaTypeImageIndex)
+	g.Expect(db.CreateArtifactVersionPlatforms(ctx, index.ID, []types.ArtifactVersionPlatform{
+		{Digest: child.ManifestBlobDigest, OS: "linux", Architecture: "amd64"},
+	})).To(Succeed())
+
+	vendor := &testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin}
+	request := func(info *testAuthInfo, reference, query string) *httptest.ResponseRecorder {
+		ctx := internalctx.WithArtifact(withTestAuth(ctx, info), &types.ArtifactWithTaggedVersion{
+			ArtifactWithDownloads: types.ArtifactWithDownloads{Artifact: artifact, OrganizationSlug: *org.Slug},
+		})
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/?"+query, nil)
+		req.SetPathValue("reference", reference)
+		getArtifactVersionPlatformHandler(rr, req)
+		return rr
+	}
+
+	t.Run("match", func(t *testing.T) {
+		g := NewWithT(t)
+		rr := request(vendor, string(index.ManifestBlobDigest), "os=linux&arch=amd64")
+		g.Expect(rr.Code).To(Equal(http.StatusOK))
+		var descriptor api.ManifestDescriptor
+		g.Expect(json.Unmarshal(rr.Body.Bytes(), &descriptor)).To(Succeed())
+		g.Expect(descriptor.Digest).To(Equal(child.ManifestBlobDigest))
+		g.Expect(descriptor.MediaType).To(Equal(child.ManifestContentType))
+		g.Expect(descriptor.Platform).NotTo(BeNil())
+		g.Expect(descriptor.Platform.OS).To(Equal("linux"))
+		g.Expect(descriptor.Platform.Architecture).To(Equal("amd64"))
+	})
+
+	for _, tc := range []struct {
+		name      string
+		info      *testAuthInfo
+		reference string
+		query     string
+		status    int
+	}{
+		{"missing arch", vendor, string(index.ManifestBlobDigest), "os=linux", http.StatusBadRequest},
+		{"no match", vendor, string(index.ManifestBlobDigest), "os=linux&arch=arm64", http.StatusNotFound},
+		{"not an index", vendor, string(child.ManifestBlobDigest), "os=linux&arch=amd64", http.StatusBadRequest},
+		{"unknown reference", vendor, "latest", "os=linux&arch=amd64", http.StatusNotFound},
+		{
+			"version not visible to customer",
+			&testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin, customerOrgID: &customerOrg.ID},
+			string(index.ManifestBlobDigest),
+			"os=linux&arch=amd64",
+			http.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			NewWithT(t).Expect(request(tc.info, tc.reference, tc.query).Code).To(Equal(tc.status))
+		})
+	}
+}
+
+func TestGetArtifactCustomerAccessHandler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "access/app")
+	licensed := types.CustomerOrganization{OrganizationID: org.ID, Name: "Licensed"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &licensed)).To(Succeed())
+	unlicensed := types.CustomerOrganization{OrganizationID: org.ID, Name: "Unlicensed"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &unlicensed)).To(Succeed())
+	license := types.ArtifactLicenseBase{Name: "License", OrganizationID: org.ID, CustomerOrganizationID: &licensed.ID}
+	g.Expect(db.CreateArtifactLicense(ctx, &license)).To(Succeed())
+	g.Expect(db.AddArtifactToArtifactLicense(ctx, license.ID, artifact.ID, nil)).To(Succeed())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(
+		internalctx.WithArtifact(
+			withTestAuth(ctx, &testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin}),
+			&types.ArtifactWithTaggedVersion{ArtifactWithDownloads: types.ArtifactWithDownloads{Artifact: artifact}},
+		),
+		http.MethodGet, "/", nil)
+	getArtifactCustomerAccessHandler(rr, req)
+
+	g.Expect(rr.Code).To(Equal(http.StatusOK))
+	var access []types.ArtifactCustomerAccess
+	g.Expect(json.Unmarshal(rr.Body.Bytes(), &access)).To(Succeed())
+	g.Expect(access).To(HaveLen(1))
+	g.Expect(access[0].ID).To(Equal(licensed.ID))
+	g.Expect(access[0].AllVersions).To(BeTrue())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
 	"github.com/distr-sh/distr/internal/authjwt"
+	"github.com/distr-sh/distr/internal/authkey"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/customdomains"
 	"github.com/distr-sh/distr/internal/db"
@@ -24,6 +25,7 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/go-chi/httprate"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/oaswrap/spec/adapter/chiopenapi"
 	"github.com/oaswrap/spec/option"
 	"github.com/pquerna/otp/totp"
@@ -35,17 +37,59 @@ func AuthRouter(r chiopenapi.Router) {
 	r.Use(httprate.Limit(
 		10,
 		1*time.Minute,
-		httprate.WithKeyFuncs(httprate.KeyByRealIP, httprate.KeyByEndpoint),
+		httprate.WithKeyFuncs(middleware.RateLimitRequestIPKey, httprate.KeyByEndpoint),
 	))
 	r.Route("/login", func(r chiopenapi.Router) {
 		r.Post("/", authLoginHandler)
 		r.Get("/config", authLoginConfigHandler())
 	})
 	r.Route("/oidc", AuthOIDCRouter)
-	r.Post("/register", authRegisterHandler)
-	r.Post("/reset", authResetPasswordHandler)
+	r.With(middleware.ReadOnlyMode).Post("/register", authRegisterHandler)
+	r.With(middleware.ReadOnlyMode).Post("/reset", authResetPasswordHandler)
 	r.With(middleware.SentryUser, auth.Authentication.Middleware, middleware.RequireOrgAndRole).
 		Post("/switch-context", authSwitchContextHandler())
+	r.With(middleware.SentryUser, auth.Authentication.Middleware, middleware.RequireOrgAndRole).
+		Get("/introspect", authIntrospectHandler()).
+		With(option.Description("Get the user, organization, role and expiry that the current token resolves to")).
+		With(option.Response(http.StatusOK, api.AuthIntrospectResponse{}))
+}
+
+func authIntrospectHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+		response := api.AuthIntrospectResponse{
+			UserID:                 auth.CurrentUserID(),
+			UserEmail:              auth.CurrentUserEmail(),
+			UserEmailVerified:      auth.CurrentUserEmailVerified(),
+			OrganizationID:         auth.CurrentOrgID(),
+			UserRole:               auth.CurrentUserRole(),
+			CustomerOrganizationID: auth.CurrentCustomerOrgID(),
+			SuperAdmin:             auth.IsSuperAdmin(),
+		}
+
+		switch token := auth.Token().(type) {
+		case jwt.Token:
+			response.TokenType = api.AuthTokenTypeJWT
+			if exp := token.Expiration(); !exp.IsZero() {
+				response.ExpiresAt = &exp
+			}
+		case authkey.Key:
+			response.TokenType = api.AuthTokenTypeAccessToken
+			if at, err := db.GetAccessTokenByKey(ctx, token); err != nil {
+				internalctx.GetLogger(ctx).Error("failed to get access token", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			} else {
+				response.TokenID = &at.ID
+				response.TokenLabel = at.Label
+				response.ExpiresAt = at.ExpiresAt
+			}
+		}
+
+		RespondJSON(w, response)
+	}
 }
 
 func authSwitchContextHandler() func(writer http.ResponseWriter, request *http.Request) {
@@ -105,10 +149,11 @@ func authSwitchContextHandler() func(writer http.ResponseWriter, request *http.R
 			return
 		}
 
-		// Regular users: validate membership
+		// Regular users: GetUserAccountAndOrg only finds organizations the user is a member of, so that users who were
+		// removed from an organization can not switch back
 		if user, org, err := db.GetUserAccountAndOrg(
 			ctx, auth.CurrentUserID(), request.OrganizationID); errors.Is(err, apierrors.ErrNotFound) {
-			http.Error(w, "bad request", http.StatusBadRequest)
+			http.Error(w, "not a member of this organization", http.StatusForbidden)
 			return
 		} else if err != nil {
 			sentry.GetHubFromContext(ctx).CaptureException(err)
@@ -191,6 +236,7 @@ func authLoginHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		var recoveryCodesLow bool
 		if user.MFAEnabled {
 			if request.MFACode == nil {
 				RespondJSON(w, api.AuthLoginResponse{RequiresMFA: true})
@@ -207,29 +253,26 @@ func authLoginHandler(w http.ResponseWriter, r *http.Request) {
 			valid := totp.Validate(*request.MFACode, *user.MFASecret)
 
 			if !valid {
-				normalized := security.NormalizeRecoveryCode(*request.MFACode)
-				codes, err := db.GetUnusedMFARecoveryCodes(ctx, user.ID)
+				code, err := db.FindMatchingUnusedRecoveryCode(ctx, user.ID, *request.MFACode)
 				if err != nil {
-					return fmt.Errorf("failed to get recovery codes: %w", err)
+					return fmt.Errorf("failed to find recovery code: %w", err)
 				}
 
-				var matchedCodeID *uuid.UUID
-				for _, code := range codes {
-					if security.VerifyRecoveryCode(normalized, code.CodeSalt, code.CodeHash) {
-						matchedCodeID = &code.ID
-						break
-					}
-				}
-
-				if matchedCodeID == nil {
+				if code == nil {
 					http.Error(w, "invalid MFA code or recovery code", http.StatusUnauthorized)
 					return nil
 				}
 
-				if err := db.MarkMFARecoveryCodeAsUsed(ctx, *matchedCodeID); err != nil {
+				if err := db.MarkMFARecoveryCodeAsUsed(ctx, code.ID); err != nil {
 					return err
 				}
 			}
+
+			if count, err := db.CountUnusedMFARecoveryCodes(ctx, user.ID); err != nil {
+				return fmt.Errorf("failed to count recovery codes: %w", err)
+			} else {
+				recoveryCodesLow = count < security.RecoveryCodesLowThreshold
+			}
 		}
 
 		if _, tokenString, err := authjwt.GenerateDefaultToken(*user, org); err != nil {
@@ -237,7 +280,7 @@ func authLoginHandler(w http.ResponseWriter, r *http.Request) {
 		} else if err = db.UpdateUserAccountLastLoggedIn(ctx, user.ID); err != nil {
 			return err
 		} else {
-			RespondJSON(w, api.AuthLoginResponse{Token: tokenString})
+			RespondJSON(w, api.AuthLoginResponse{Token: tokenString, RecoveryCodesLow: recoveryCodesLow})
 			return nil
 		}
 	})
@@ -254,12 +297,16 @@ func authLoginConfigHandler() http.HandlerFunc {
 		OIDCGithubEnabled    bool `json:"oidcGithubEnabled"`
 		OIDCGoogleEnabled    bool `json:"oidcGoogleEnabled"`
 		OIDCMicrosoftEnabled bool `json:"oidcMicrosoftEnabled"`
+		OIDCOktaEnabled      bool `json:"oidcOktaEnabled"`
+		OIDCGitlabEnabled    bool `json:"oidcGitlabEnabled"`
 		OIDCGenericEnabled   bool `json:"oidcGenericEnabled"`
 	}{
 		RegistrationEnabled:  env.Registration() == env.RegistrationEnabled,
 		OIDCGithubEnabled:    env.OIDCGithubEnabled(),
 		OIDCGoogleEnabled:    env.OIDCGoogleEnabled(),
 		OIDCMicrosoftEnabled: env.OIDCMicrosoftEnabled(),
+		OIDCOktaEnabled:      env.OIDCOktaEnabled(),
+		OIDCGitlabEnabled:    env.OIDCGitlabEnabled(),
 		OIDCGenericEnabled:   env.OIDCGenericEnabled(),
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
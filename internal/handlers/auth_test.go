@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+func switchContext(ctx context.Context, info *testAuthInfo, orgID uuid.UUID) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(api.AuthSwitchContextRequest{OrganizationID: orgID})
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(withTestAuth(ctx, info),
+		http.MethodPost, "/api/v1/auth/switch-context", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	authSwitchContextHandler()(rr, req)
+	return rr
+}
+
+func TestAuthSwitchContextHandlerInvalidRequest(t *testing.T) {
+	g := NewWithT(t)
+	org := &types.Organization{ID: uuid.New()}
+	info := &testAuthInfo{user: &types.UserAccount{ID: uuid.New()}, org: org, role: types.UserRoleAdmin}
+	g.Expect(switchContext(context.Background(), info, uuid.Nil).Code).To(Equal(http.StatusBadRequest))
+	g.Expect(switchContext(context.Background(), info, org.ID).Code).To(Equal(http.StatusNoContent))
+}
+
+// TestAuthSwitchContextHandler checks that users can not switch into organizations they are not a member of, were
+// removed from or that were deleted.
+func TestAuthSwitchContextHandler(t *testing.T) {
+	ctx := dbtest.TxContext(t, nil)
+	g := NewWithT(t)
+
+	createOrg := func() *types.Organization {
+		org := dbtest.CreateOrganization(t, ctx)
+		return &org
+	}
+	current := createOrg()
+	removed := createOrg()
+	deleted := createOrg()
+	other := createOrg()
+	user := dbtest.CreateUserAccount(t, ctx)
+	for _, org := range []*types.Organization{current, removed, deleted} {
+		g.Expect(db.CreateUserAccountOrganizationAssignment(ctx, user.ID, org.ID, types.UserRoleAdmin, nil)).
+			To(Succeed())
+	}
+	g.Expect(db.DeleteUserAccountFromOrganization(ctx, user.ID, removed.ID)).To(Succeed())
+	g.Expect(db.SetOrganizationDeletedAtNow(ctx, deleted.ID)).To(Succeed())
+
+	info := &testAuthInfo{user: &user, org: current, role: types.UserRoleAdmin}
+	for name, org := range map[string]*types.Organization{"removed": removed, "deleted": deleted, "other": other} {
+		t.Run(name, func(t *testing.T) {
+			NewWithT(t).Expect(switchContext(ctx, info, org.ID).Code).To(Equal(http.StatusForbidden))
+		})
+	}
+	t.Run("unknown", func(t *testing.T) {
+		NewWithT(t).Expect(switchContext(ctx, info, uuid.New()).Code).To(Equal(http.StatusForbidden))
+	})
+}
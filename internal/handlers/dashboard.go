@@ -34,7 +34,7 @@ func getArtifactsByCustomer(w http.ResponseWriter, r *http.Request) {
 		log.Error("failed to get customers", zap.Error(err))
 		sentry.GetHubFromContext(ctx).CaptureException(err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-	} else if artifacts, err := db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID()); err != nil {
+	} else if artifacts, err := db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID(), types.ArtifactListFilter{}); err != nil {
 		log.Error("failed to get artifacts", zap.Error(err))
 		sentry.GetHubFromContext(ctx).CaptureException(err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
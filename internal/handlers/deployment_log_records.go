@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,12 +15,24 @@ import (
 	"github.com/distr-sh/distr/internal/auth"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/deploymentlogs"
 	"github.com/distr-sh/distr/internal/subscription"
 	"github.com/distr-sh/distr/internal/types"
 	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	deploymentLogTailBufferSize     = 200
+	deploymentLogTailMaxIdle        = 15 * time.Minute
+	deploymentLogStreamHistoryLimit = 25
+	deploymentLogStreamKeepAlive    = 15 * time.Second
+)
+
+// deploymentLogTail is fed by agentPutDeploymentLogsHandler and consumed by streamDeploymentLogsHandler.
+var deploymentLogTail = deploymentlogs.NewTail(deploymentLogTailBufferSize, deploymentLogTailMaxIdle)
+
 func getDeploymentLogsResourcesHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -149,6 +164,121 @@ func getDeploymentLogsHandler() http.HandlerFunc {
 	}
 }
 
+func streamDeploymentLogsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := internalctx.GetLogger(ctx)
+		deployment := internalctx.GetDeployment(ctx)
+		resource := r.FormValue("resource")
+		if resource == "" {
+			http.Error(w, "query parameter resource is required", http.StatusBadRequest)
+			return
+		}
+
+		replacer, err := deploymentSecretReplacer(ctx, deployment.ID)
+		if err != nil {
+			log.Error("failed to get secrets", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// Subscribe before loading the history so that no records are missed in between.
+		recent, records, cancel := deploymentLogTail.Subscribe(deployment.ID)
+		defer cancel()
+
+		history := slices.DeleteFunc(recent, func(record api.DeploymentLogRecord) bool {
+			return record.Resource != resource
+		})
+		if len(history) == 0 {
+			persisted, err := db.GetDeploymentLogRecords(
+				ctx, deployment.ID, resource, deploymentLogStreamHistoryLimit, time.Time{}, time.Time{},
+			)
+			if err != nil {
+				log.Error("failed to get log records", zap.Error(err))
+				sentry.GetHubFromContext(ctx).CaptureException(err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			for _, record := range slices.Backward(persisted) {
+				history = append(history, api.DeploymentLogRecord{
+					DeploymentID:         record.DeploymentID,
+					DeploymentRevisionID: record.DeploymentRevisionID,
+					Resource:             record.Resource,
+					Timestamp:            record.Timestamp,
+					Severity:             record.Severity,
+					Body:                 record.Body,
+				})
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		// The stream is long-lived, so the server write timeout must not apply.
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		var lastSent time.Time
+		send := func(record api.DeploymentLogRecord) error {
+			record.Body = replacer.Replace(record.Body)
+			if data, err := json.Marshal(record); err != nil {
+				return err
+			} else if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return err
+			}
+			lastSent = record.Timestamp
+			return rc.Flush()
+		}
+
+		for _, record := range history {
+			if err := send(record); err != nil {
+				log.Debug("failed to send log record", zap.Error(err))
+				return
+			}
+		}
+
+		keepAlive := time.NewTicker(deploymentLogStreamKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case record, ok := <-records:
+				if !ok {
+					return
+				}
+				if record.Resource != resource || record.Timestamp.Before(lastSent) {
+					continue
+				}
+				if err := send(record); err != nil {
+					log.Debug("failed to send log record", zap.Error(err))
+					return
+				}
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				} else if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func deploymentSecretReplacer(ctx context.Context, deploymentID uuid.UUID) (*strings.Replacer, error) {
+	if dt, err := db.GetDeploymentTargetForDeploymentID(ctx, deploymentID); err != nil {
+		return nil, fmt.Errorf("failed to get deployment target: %w", err)
+	} else if secrets, err := db.GetSecretsForDeploymentTarget(ctx, dt.DeploymentTarget); err != nil {
+		return nil, fmt.Errorf("failed to get secrets: %w", err)
+	} else {
+		return secretReplacer(secrets), nil
+	}
+}
+
 func secretReplacer(secrets []types.SecretWithUpdatedBy) *strings.Replacer {
 	pairs := make([]string, 0, 2*len(secrets))
 	for _, secret := range secrets {
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/agentconnect"
+	"github.com/distr-sh/distr/internal/agentmanifest"
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
 	"github.com/distr-sh/distr/internal/authn/authinfo"
@@ -66,7 +68,22 @@ func DeploymentTargetsRouter(r chiopenapi.Router) {
 				With(option.Description("Create access token for deployment target")).
 				With(option.Request(DeploymentTargetIDRequest{})).
 				With(option.Response(http.StatusOK, api.DeploymentTargetAccessTokenResponse{}))
+			r.Post("/rotate-secret", rotateDeploymentTargetSecretHandler()).
+				With(option.Description("Replace the secret of a deployment target, for example if it was leaked. " +
+					"The connected agent is disconnected and has to be re-installed with the returned manifest. " +
+					"The new secret is only returned once.")).
+				With(option.Request(DeploymentTargetIDRequest{})).
+				With(option.Response(http.StatusOK, api.DeploymentTargetRotateSecretResponse{}))
+			r.Post("/install-script", getDeploymentTargetInstallScript).
+				With(option.Description("Create a one-time agent install script for deployment target")).
+				With(option.Request(DeploymentTargetIDRequest{})).
+				With(option.Response(http.StatusOK, nil, option.ContentType("text/x-shellscript")))
 		})
+		r.Get("/manifest/preview", getDeploymentTargetManifestPreviewHandler()).
+			With(option.Description("Preview the agent manifest of a deployment target. " +
+				"All credentials are redacted, so the manifest cannot be used to connect the agent.")).
+			With(option.Request(DeploymentTargetIDRequest{})).
+			With(option.Response(http.StatusOK, nil, option.ContentType("application/yaml")))
 		r.Route("/notes", func(r chiopenapi.Router) {
 			r.Get("/", getDeploymentTargetNotesHandler()).
 				With(option.Description("Get notes for this deployment target")).
@@ -192,6 +209,11 @@ func updateDeploymentTarget(w http.ResponseWriter, r *http.Request) {
 		dt.AgentVersionID = &dt.AgentVersion.ID
 	}
 
+	if dt.LogRecordEntriesMaxCount != nil && *dt.LogRecordEntriesMaxCount < 0 {
+		http.Error(w, "log record entries max count must not be negative", http.StatusBadRequest)
+		return
+	}
+
 	existing := internalctx.GetDeploymentTarget(ctx)
 	if dt.ID == uuid.Nil {
 		dt.ID = existing.ID
@@ -235,26 +257,9 @@ func createAccessForDeploymentTarget(w http.ResponseWriter, r *http.Request) {
 	deploymentTarget := internalctx.GetDeploymentTarget(ctx)
 	auth := auth.Authentication.Require(ctx)
 
-	var targetSecret string
-	var err error
-	if targetSecret, err = security.GenerateAccessKey(); err != nil {
-		log.Error("failed to generate access key", zap.Error(err))
-		sentry.GetHubFromContext(ctx).CaptureException(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if salt, hash, err := security.HashAccessKey(targetSecret); err != nil {
-		log.Error("failed to hash access key", zap.Error(err))
-		sentry.GetHubFromContext(ctx).CaptureException(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	} else {
-		deploymentTarget.AccessKeySalt = &salt
-		deploymentTarget.AccessKeyHash = &hash
-	}
-
-	if err := db.UpdateDeploymentTargetAccess(ctx, &deploymentTarget.DeploymentTarget, *auth.CurrentOrgID()); err != nil {
-		log.Warn("could not update DeploymentTarget", zap.Error(err))
+	targetSecret, err := issueDeploymentTargetSecret(ctx, deploymentTarget, *auth.CurrentOrgID())
+	if err != nil {
+		log.Error("failed to issue deployment target secret", zap.Error(err))
 		sentry.GetHubFromContext(ctx).CaptureException(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -291,6 +296,130 @@ func createAccessForDeploymentTarget(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func rotateDeploymentTargetSecretHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		deploymentTarget := internalctx.GetDeploymentTarget(ctx)
+		auth := auth.Authentication.Require(ctx)
+		org := auth.CurrentOrg()
+		log := internalctx.GetLogger(ctx).With(zap.String("deploymentTargetId", deploymentTarget.ID.String()))
+
+		var response api.DeploymentTargetRotateSecretResponse
+		err := db.RunTx(ctx, func(ctx context.Context) error {
+			targetSecret, err := issueDeploymentTargetSecret(ctx, deploymentTarget, org.ID)
+			if err != nil {
+				return err
+			}
+
+			response.TargetID = deploymentTarget.ID
+			response.TargetSecret = targetSecret
+			if response.ConnectURL, err = agentconnect.BuildConnectURL(deploymentTarget.ID, *org, targetSecret); err != nil {
+				return fmt.Errorf("could not create connect URL: %w", err)
+			} else if response.ConnectCommand, err = agentconnect.GenerateConnectCommand(
+				deploymentTarget.DeploymentTarget, *org, targetSecret,
+			); err != nil {
+				return fmt.Errorf("could not create connect command: %w", err)
+			} else if manifest, err := agentmanifest.Get(ctx, *deploymentTarget, *org, &targetSecret); err != nil {
+				return fmt.Errorf("could not render agent manifest: %w", err)
+			} else if data, err := io.ReadAll(manifest); err != nil {
+				return fmt.Errorf("could not render agent manifest: %w", err)
+			} else {
+				response.Manifest = string(data)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error("failed to rotate deployment target secret", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("rotated deployment target secret", zap.Stringer("userId", auth.CurrentUserID()))
+		w.Header().Set("Cache-Control", "no-store")
+		RespondJSON(w, response)
+	}
+}
+
+func getDeploymentTargetInstallScript(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deploymentTarget := internalctx.GetDeploymentTarget(ctx)
+	auth := auth.Authentication.Require(ctx)
+	log := internalctx.GetLogger(ctx).With(zap.String("deploymentTargetId", deploymentTarget.ID.String()))
+
+	targetSecret, err := issueDeploymentTargetSecret(ctx, deploymentTarget, *auth.CurrentOrgID())
+	if err != nil {
+		log.Error("failed to issue deployment target secret", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	script, err := agentconnect.GenerateInstallScript(deploymentTarget.DeploymentTarget, *auth.CurrentOrg(), targetSecret)
+	if err != nil {
+		log.Error("could not generate install script", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("issued one-time deployment target secret via install script",
+		zap.Stringer("userId", auth.CurrentUserID()),
+		zap.String("deploymentTargetType", string(deploymentTarget.Type)))
+
+	SetFileDownloadHeaders(w, "distr-agent-install.sh")
+	w.Header().Set("Content-Type", "text/x-shellscript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write([]byte(script)); err != nil {
+		log.Warn("writing to client failed", zap.Error(err))
+	}
+}
+
+func getDeploymentTargetManifestPreviewHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		deploymentTarget := internalctx.GetDeploymentTarget(ctx)
+		auth := auth.Authentication.Require(ctx)
+		log := internalctx.GetLogger(ctx).With(zap.String("deploymentTargetId", deploymentTarget.ID.String()))
+
+		manifest, err := agentmanifest.Preview(ctx, *deploymentTarget, *auth.CurrentOrg())
+		if err != nil {
+			log.Error("could not render agent manifest preview", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		if _, err := io.Copy(w, manifest); err != nil {
+			log.Warn("writing to client failed", zap.Error(err))
+		}
+	}
+}
+
+// issueDeploymentTargetSecret generates a new access key for the deployment target and stores its hash,
+// invalidating any previously issued secret and the agent tokens issued with it.
+func issueDeploymentTargetSecret(
+	ctx context.Context,
+	deploymentTarget *types.DeploymentTargetFull,
+	orgID uuid.UUID,
+) (string, error) {
+	targetSecret, err := security.GenerateAccessKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate access key: %w", err)
+	}
+	salt, hash, err := security.HashAccessKey(targetSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash access key: %w", err)
+	}
+	deploymentTarget.AccessKeySalt = &salt
+	deploymentTarget.AccessKeyHash = &hash
+	if err := db.UpdateDeploymentTargetAccess(ctx, &deploymentTarget.DeploymentTarget, orgID); err != nil {
+		return "", fmt.Errorf("could not update DeploymentTarget: %w", err)
+	}
+	return targetSecret, nil
+}
+
 func deploymentTargetMiddleware(wh http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
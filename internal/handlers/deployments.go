@@ -29,6 +29,17 @@ import (
 func DeploymentsRouter(r chiopenapi.Router) {
 	r.WithOptions(option.GroupTags("Deployments"))
 	r.Use(middleware.RequireOrgAndRole)
+	r.Get("/", getDeploymentsHandler()).
+		With(option.Description("List all deployments with their latest status. A deployment whose status is stale " +
+			"and whose agent is not online is most likely disconnected rather than failing. At most limit deployments " +
+			"are returned, 100 by default and 1000 at most.")).
+		With(option.Request(struct {
+			Status             string     `query:"status"`
+			DeploymentTargetID *uuid.UUID `query:"deploymentTargetId"`
+			Limit              *int       `query:"limit"`
+			Offset             *int       `query:"offset"`
+		}{})).
+		With(option.Response(http.StatusOK, []types.DeploymentOverview{}))
 	r.With(middleware.RequireReadWriteOrAdmin, middleware.BlockSuperAdmin).
 		Put("/", putDeployment).
 		With(option.Description("Create or update a deployment")).
@@ -66,6 +77,13 @@ func DeploymentsRouter(r chiopenapi.Router) {
 			With(option.Description("Get deployment log resources")).
 			With(option.Request(DeploymentIDRequest{})).
 			With(option.Response(http.StatusOK, []string{}))
+		r.Get("/logs/stream", streamDeploymentLogsHandler()).
+			With(option.Description("Stream deployment logs as server-sent events")).
+			With(option.Request(struct {
+				DeploymentIDRequest
+				ResourceRequest
+			}{})).
+			With(option.Response(http.StatusOK, nil, option.ContentType("text/event-stream")))
 		r.Get("/logs/export", exportDeploymentLogsHandler()).
 			With(option.Description("Export deployment logs")).
 			With(option.Request(struct {
@@ -88,6 +106,53 @@ func DeploymentsRouter(r chiopenapi.Router) {
 	})
 }
 
+func getDeploymentsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auth := auth.Authentication.Require(ctx)
+
+		filter, err := deploymentListFilterFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		deployments, err := db.GetDeploymentsForOrganization(
+			ctx, *auth.CurrentOrgID(), auth.CurrentCustomerOrgID(), filter,
+		)
+		if err != nil {
+			internalctx.GetLogger(ctx).Error("failed to get deployments", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		RespondJSON(w, deployments)
+	}
+}
+
+func deploymentListFilterFromRequest(r *http.Request) (types.DeploymentListFilter, error) {
+	filter := types.DeploymentListFilter{StatusType: types.DeploymentStatusType(r.FormValue("status"))}
+	if id, err := QueryParam(r, "deploymentTargetId", uuid.Parse); err == nil {
+		filter.DeploymentTargetID = &id
+	} else if !errors.Is(err, ErrParamNotDefined) {
+		return filter, err
+	}
+	if limit, err := QueryParam(r, "limit", strconv.Atoi, Min(1), Max(1000)); err == nil {
+		filter.Limit = limit
+	} else if errors.Is(err, ErrParamNotDefined) {
+		filter.Limit = 100
+	} else {
+		return filter, err
+	}
+	if offset, err := QueryParam(r, "offset", strconv.Atoi, Min(0)); err == nil {
+		filter.Offset = offset
+	} else if !errors.Is(err, ErrParamNotDefined) {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
+
 func putDeployment(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := internalctx.GetLogger(ctx)
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDeploymentListFilterFromRequest(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		g := NewWithT(t)
+		filter, err := deploymentListFilterFromRequest(httptest.NewRequest("GET", "/", nil))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(filter.Limit).To(Equal(100))
+		g.Expect(filter.Offset).To(Equal(0))
+	})
+
+	t.Run("limit and offset", func(t *testing.T) {
+		g := NewWithT(t)
+		filter, err := deploymentListFilterFromRequest(httptest.NewRequest("GET", "/?limit=1000&offset=20", nil))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(filter.Limit).To(Equal(1000))
+		g.Expect(filter.Offset).To(Equal(20))
+	})
+
+	for _, query := range []string{"limit=0", "limit=1001", "limit=x", "offset=-1", "status=unknown"} {
+		t.Run(query, func(t *testing.T) {
+			_, err := deploymentListFilterFromRequest(httptest.NewRequest("GET", "/?"+query, nil))
+			NewWithT(t).Expect(err).To(HaveOccurred())
+		})
+	}
+}
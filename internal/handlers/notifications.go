@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/middleware"
+	"github.com/distr-sh/distr/internal/notification"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/getsentry/sentry-go"
+	"github.com/go-chi/httprate"
+	"github.com/oaswrap/spec/adapter/chiopenapi"
+	"github.com/oaswrap/spec/option"
+	"go.uber.org/zap"
+)
+
+func NotificationsRouter(r chiopenapi.Router) {
+	r.WithOptions(option.GroupTags("Notifications"))
+
+	r.With(testNotificationRateLimitPerUser, middleware.RequireVendor, middleware.RequireAdmin,
+		middleware.BlockSuperAdmin).
+		Post("/test", sendTestNotificationHandler()).
+		With(option.Description("Send a sample notification through all configured notification channels")).
+		With(option.Response(http.StatusOK, api.TestNotificationResponse{}))
+}
+
+func sendTestNotificationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := internalctx.GetLogger(ctx)
+		auth := auth.Authentication.Require(ctx)
+
+		user, err := db.GetUserAccountByID(ctx, auth.CurrentUserID())
+		if err != nil {
+			log.Error("failed to get user account", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		organization, err := db.GetOrganizationWithBranding(ctx, *auth.CurrentOrgID())
+		if err != nil {
+			log.Error("failed to get organization", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		results := notification.SendTestNotifications(ctx, *organization, *user)
+
+		response := api.TestNotificationResponse{Channels: make([]api.TestNotificationChannelResult, len(results))}
+		for i, result := range results {
+			response.Channels[i] = api.TestNotificationChannelResult{
+				Channel: string(result.Channel),
+				Success: result.Err == nil,
+			}
+			if result.Err != nil {
+				response.Channels[i].Error = util.PtrTo(result.Err.Error())
+			}
+		}
+
+		RespondJSON(w, response)
+	}
+}
+
+var testNotificationRateLimitPerUser = httprate.Limit(
+	3,
+	10*time.Minute,
+	httprate.WithKeyFuncs(middleware.RateLimitUserIDKey),
+)
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/distr-sh/distr/api"
@@ -48,10 +50,106 @@ func OrganizationRouter(r chiopenapi.Router) {
 
 			r.Delete("/", deleteOrganizationHandler()).
 				With(option.Description("Delete current organization"))
+
+			r.With(middleware.ProFeature).Get("/export", exportOrganizationCatalogHandler).
+				With(option.Description("Export the metadata of all artifacts, versions and tags of the current organization")).
+				With(option.Response(http.StatusOK, api.CatalogExport{}))
+
+			r.With(middleware.ProFeature, middleware.DecompressRequestBody).Post("/import", importOrganizationCatalogHandler).
+				With(option.Description("Restore artifacts, versions and tags from a catalog export. " +
+					"All referenced blobs must already exist in the registry storage. " +
+					"The request body may be gzip compressed.")).
+				With(option.Request(api.CatalogExport{})).
+				With(option.Response(http.StatusOK, api.CatalogImportResponse{}))
 		})
 	})
 
+	r.Get("/download-stats", getOrganizationDownloadStatsHandler).
+		With(option.Description("Get aggregated pulls of all artifacts within a period, which defaults to the last 30 " +
+			"days. Customer users only see the pulls of their own customer organization.")).
+		With(option.Request(struct {
+			From  *time.Time `query:"from"`
+			To    *time.Time `query:"to"`
+			Limit *int       `query:"limit"`
+		}{})).
+		With(option.Response(http.StatusOK, api.OrganizationDownloadStats{}))
+
+	r.With(middleware.RequireVendor).Get("/registry-quota", getRegistryQuotaHandler).
+		With(option.Description("Get registry usage and limits of the current organization")).
+		With(option.Response(http.StatusOK, types.RegistryQuota{}))
+
 	r.Route("/branding", OrganizationBrandingRouter)
+	r.Route("/domains", OrganizationDomainsRouter)
+}
+
+func getRegistryQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth := auth.Authentication.Require(ctx)
+	if quota, err := db.GetRegistryQuota(ctx, *auth.CurrentOrgID()); err != nil {
+		internalctx.GetLogger(ctx).Error("failed to get registry quota", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, quota)
+	}
+}
+
+func getOrganizationDownloadStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	from, to, err := PeriodQueryParams(r, 30*24*time.Hour)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := QueryParam(r, "limit", strconv.Atoi, Min(1), Max(100))
+	if errors.Is(err, ErrParamNotDefined) {
+		limit = 10
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	orgID := *auth.CurrentOrgID()
+	customerOrgID := auth.CurrentCustomerOrgID()
+	result := api.OrganizationDownloadStats{From: from, To: to}
+
+	if result.TopArtifacts, err = db.GetTopArtifactsByPulls(ctx, orgID, customerOrgID, from, to, limit); err != nil {
+		log.Error("failed to get top artifacts by pulls", zap.Error(err))
+	} else if result.TopCustomerOrganizations, err = db.GetPullsGroupedByCustomerOrganization(
+		ctx, orgID, customerOrgID, from, to, limit,
+	); err != nil {
+		log.Error("failed to get pulls by customer organization", zap.Error(err))
+	} else if result.PullsOverTime, err = db.GetPullCountsOverTime(
+		ctx, orgID, customerOrgID, from, to, downloadStatsBucketSize(to.Sub(from)),
+	); err != nil {
+		log.Error("failed to get pull counts over time", zap.Error(err))
+	}
+	if err != nil {
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for _, bucket := range result.PullsOverTime {
+		result.PullsTotal += bucket.PullsTotal
+	}
+	RespondJSON(w, result)
+}
+
+// downloadStatsBucketSize returns the size of the buckets of the download stats time series, so that there are at
+// most a few hundred buckets for any period.
+func downloadStatsBucketSize(period time.Duration) time.Duration {
+	switch {
+	case period <= 2*24*time.Hour:
+		return time.Hour
+	case period <= 180*24*time.Hour:
+		return 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
 }
 
 func getOrganization(w http.ResponseWriter, r *http.Request) {
@@ -105,13 +203,16 @@ func createOrganization(w http.ResponseWriter, r *http.Request) {
 	}
 
 	organization := types.Organization{
-		Name:                body.Name,
-		Slug:                body.Slug,
-		SubscriptionType:    types.SubscriptionTypeTrial,
-		Features:            subscription.ProFeatures,
-		PreConnectScript:    body.PreConnectScript,
-		PostConnectScript:   body.PostConnectScript,
-		ConnectScriptIsSudo: body.ConnectScriptIsSudo,
+		Name:                       body.Name,
+		Slug:                       body.Slug,
+		SubscriptionType:           types.SubscriptionTypeTrial,
+		Features:                   subscription.ProFeatures,
+		PreConnectScript:           body.PreConnectScript,
+		PostConnectScript:          body.PostConnectScript,
+		ConnectScriptIsSudo:        body.ConnectScriptIsSudo,
+		QuotaExceededWebhookURL:    body.QuotaExceededWebhookURL,
+		QuotaExceededEmailEnabled:  body.QuotaExceededEmailEnabled,
+		RequireArtifactPrecreation: body.RequireArtifactPrecreation,
 	}
 
 	if buildconfig.IsCommunityEdition() {
@@ -161,6 +262,13 @@ func validateOrganizationRequest(w http.ResponseWriter, organization api.CreateU
 			return false
 		}
 	}
+	if organization.QuotaExceededWebhookURL != nil {
+		if u, err := url.Parse(*organization.QuotaExceededWebhookURL); err != nil ||
+			(u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, "Quota exceeded webhook URL is invalid", http.StatusBadRequest)
+			return false
+		}
+	}
 	return true
 }
 
@@ -219,6 +327,21 @@ func handleUpdateOrganization(
 			needsUpdate = true
 		}
 
+		if !util.PtrEq(org.QuotaExceededWebhookURL, request.QuotaExceededWebhookURL) {
+			org.QuotaExceededWebhookURL = request.QuotaExceededWebhookURL
+			needsUpdate = true
+		}
+
+		if request.QuotaExceededEmailEnabled != org.QuotaExceededEmailEnabled {
+			org.QuotaExceededEmailEnabled = request.QuotaExceededEmailEnabled
+			needsUpdate = true
+		}
+
+		if request.RequireArtifactPrecreation != org.RequireArtifactPrecreation {
+			org.RequireArtifactPrecreation = request.RequireArtifactPrecreation
+			needsUpdate = true
+		}
+
 		if request.ArtifactVersionMutable != org.HasFeature(types.FeatureArtifactVersionMutable) {
 			org.SetFeature(types.FeatureArtifactVersionMutable, request.ArtifactVersionMutable)
 			needsUpdate = true
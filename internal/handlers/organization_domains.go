@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/customdomains"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/middleware"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/getsentry/sentry-go"
+	"github.com/go-chi/httprate"
+	"github.com/oaswrap/spec/adapter/chiopenapi"
+	"github.com/oaswrap/spec/option"
+	"go.uber.org/zap"
+)
+
+func OrganizationDomainsRouter(r chiopenapi.Router) {
+	r.Use(middleware.RequireOrgAndRole, middleware.RequireVendor, middleware.RequireAdmin, middleware.BlockSuperAdmin)
+
+	r.Get("/", getOrganizationDomainsHandler).
+		With(option.Description("Get the custom domains of the current organization and their verification status")).
+		With(option.Response(http.StatusOK, api.OrganizationDomainsResponse{}))
+	r.Put("/", updateOrganizationDomainsHandler).
+		With(option.Description("Set the custom app and registry domains of the current organization")).
+		With(option.Request(api.UpdateOrganizationDomainsRequest{})).
+		With(option.Response(http.StatusOK, api.OrganizationDomainsResponse{}))
+	r.With(verifyOrganizationDomainsRateLimitPerUser).Post("/verify", verifyOrganizationDomainsHandler).
+		With(option.Description("Check the DNS records and TLS certificates of the custom domains")).
+		With(option.Response(http.StatusOK, api.OrganizationDomainsResponse{}))
+}
+
+func getOrganizationDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth := auth.Authentication.Require(ctx)
+
+	if domains, err := db.GetOrganizationDomains(ctx, *auth.CurrentOrgID()); err != nil {
+		internalctx.GetLogger(ctx).Error("failed to get organization domains", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, organizationDomainsToAPI(*domains, nil, nil))
+	}
+}
+
+func updateOrganizationDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth := auth.Authentication.Require(ctx)
+
+	body, err := JsonBody[api.UpdateOrganizationDomainsRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	appDomain, err := normalizeCustomDomain(body.AppDomain)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("appDomain: %v", err), http.StatusBadRequest)
+		return
+	}
+	registryDomain, err := normalizeCustomDomain(body.RegistryDomain)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("registryDomain: %v", err), http.StatusBadRequest)
+		return
+	}
+	if appDomain != nil && registryDomain != nil && *appDomain == *registryDomain {
+		http.Error(w, "appDomain and registryDomain must be different", http.StatusBadRequest)
+		return
+	}
+
+	domains, err := db.UpdateOrganizationDomains(ctx, *auth.CurrentOrgID(), appDomain, registryDomain, rand.Text())
+	if err != nil {
+		internalctx.GetLogger(ctx).Error("failed to update organization domains", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, organizationDomainsToAPI(*domains, nil, nil))
+	}
+}
+
+func verifyOrganizationDomainsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	domains, err := db.GetOrganizationDomains(ctx, *auth.CurrentOrgID())
+	if err != nil {
+		log.Error("failed to get organization domains", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var token string
+	if domains.VerificationToken != nil {
+		token = *domains.VerificationToken
+	}
+
+	var appCheck, registryCheck *customdomains.DomainCheck
+	if domains.AppDomain != nil {
+		var wellKnownPath string
+		if env.WellKnownMicrosoftIdentityAssociation() != nil {
+			wellKnownPath = customdomains.MicrosoftIdentityAssociationPath
+		}
+		appCheck = util.PtrTo(customdomains.CheckDomain(ctx, *domains.AppDomain, token, env.Host(), wellKnownPath))
+	}
+	if domains.RegistryDomain != nil {
+		registryCheck = util.PtrTo(customdomains.CheckDomain(ctx, *domains.RegistryDomain, token, env.RegistryHost(), ""))
+	}
+
+	domains, err = db.UpdateOrganizationDomainsVerified(ctx, *auth.CurrentOrgID(),
+		appCheck != nil && appCheck.Verified,
+		registryCheck != nil && registryCheck.Verified,
+	)
+	if errors.Is(err, apierrors.ErrNotFound) {
+		http.NotFound(w, r)
+	} else if err != nil {
+		log.Error("failed to update organization domain verification", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		RespondJSON(w, organizationDomainsToAPI(*domains, appCheck, registryCheck))
+	}
+}
+
+// normalizeCustomDomain returns nil for an empty domain and a lowercase host name without trailing dot otherwise.
+func normalizeCustomDomain(domain *string) (*string, error) {
+	if domain == nil {
+		return nil, nil
+	}
+	d := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(*domain)), ".")
+	if d == "" {
+		return nil, nil
+	}
+	if err := customdomains.ValidateDomain(d); err != nil {
+		return nil, err
+	}
+	if d == customdomains.Hostname(env.Host()) || d == customdomains.Hostname(env.RegistryHost()) {
+		return nil, errors.New("must not be the default domain")
+	}
+	return &d, nil
+}
+
+func organizationDomainsToAPI(
+	domains types.OrganizationDomains,
+	appCheck, registryCheck *customdomains.DomainCheck,
+) api.OrganizationDomainsResponse {
+	response := api.OrganizationDomainsResponse{VerificationToken: domains.VerificationToken}
+	if domains.AppDomain != nil {
+		response.AppDomain = organizationDomainStatusToAPI(
+			*domains.AppDomain, env.Host(), domains.AppDomainVerifiedAt, appCheck,
+		)
+	}
+	if domains.RegistryDomain != nil {
+		response.RegistryDomain = organizationDomainStatusToAPI(
+			*domains.RegistryDomain, env.RegistryHost(), domains.RegistryDomainVerifiedAt, registryCheck,
+		)
+	}
+	return response
+}
+
+func organizationDomainStatusToAPI(
+	domain, defaultHost string,
+	verifiedAt *time.Time,
+	check *customdomains.DomainCheck,
+) *api.OrganizationDomainStatus {
+	status := api.OrganizationDomainStatus{
+		Domain:                 domain,
+		VerificationRecordName: customdomains.VerificationRecordPrefix + customdomains.Hostname(domain),
+		CNAMETarget:            customdomains.Hostname(defaultHost),
+		Verified:               verifiedAt != nil,
+		VerifiedAt:             verifiedAt,
+	}
+	if check != nil {
+		status.DNSConfigured = &check.DNSConfigured
+		status.TLSReady = &check.TLSReady
+		status.WellKnownReady = check.WellKnownReady
+		status.Errors = check.Errors
+	}
+	return &status
+}
+
+var verifyOrganizationDomainsRateLimitPerUser = httprate.Limit(
+	10,
+	10*time.Minute,
+	httprate.WithKeyFuncs(middleware.RateLimitUserIDKey),
+)
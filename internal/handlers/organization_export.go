@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/mapping"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportOrganizationCatalogHandler streams an [api.CatalogExport] of all artifacts of the current organization.
+// Artifacts are written one at a time, so that the whole catalog never has to be held in memory.
+// Nothing is written before the first artifact is complete, so errors that occur early, e.g. because the query fails,
+// are still reported with an error status. A later error leaves the document incomplete and thus invalid.
+func exportOrganizationCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+	org := auth.CurrentOrg()
+
+	name := org.ID.String()
+	if org.Slug != nil {
+		name = *org.Slug
+	}
+	SetFileDownloadHeaders(w, fmt.Sprintf("%s_catalog_%s.json", time.Now().Format("2006-01-02"), name))
+	w.Header().Set("Content-Type", "application/json")
+
+	cw := catalogExportWriter{w: w, header: api.CatalogExport{
+		FormatVersion:    api.CatalogExportFormatVersion,
+		ExportedAt:       time.Now().UTC(),
+		OrganizationSlug: org.Slug,
+	}}
+	err := db.GetCatalogForExport(ctx, org.ID, cw.add)
+	if err == nil {
+		err = cw.close()
+	}
+	if err != nil {
+		log.Error("failed to export catalog", zap.Error(err))
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		if !cw.started {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}
+}
+
+// catalogExportWriter writes the artifacts array of an [api.CatalogExport] incrementally. Versions must be added
+// grouped by artifact. The header fields are written together with the first artifact or when the writer is closed.
+type catalogExportWriter struct {
+	w          io.Writer
+	header     api.CatalogExport
+	started    bool
+	err        error
+	artifactID uuid.UUID
+	artifact   *api.CatalogExportArtifact
+	count      int
+}
+
+func (cw *catalogExportWriter) writeHeader() {
+	cw.started = true
+	cw.write(`{"formatVersion":`)
+	cw.writeJSON(cw.header.FormatVersion)
+	cw.write(`,"exportedAt":`)
+	cw.writeJSON(cw.header.ExportedAt)
+	if cw.header.OrganizationSlug != nil {
+		cw.write(`,"organizationSlug":`)
+		cw.writeJSON(cw.header.OrganizationSlug)
+	}
+	cw.write(`,"artifacts":[`)
+}
+
+func (cw *catalogExportWriter) add(version types.CatalogExportVersion) error {
+	if cw.artifact == nil || cw.artifactID != version.ArtifactID {
+		cw.flushArtifact()
+		cw.artifactID = version.ArtifactID
+		cw.artifact = &api.CatalogExportArtifact{Name: version.ArtifactName, CreatedAt: version.ArtifactCreatedAt}
+	}
+	cw.artifact.Versions = append(cw.artifact.Versions, mapping.CatalogExportVersionToAPI(version))
+	return cw.err
+}
+
+func (cw *catalogExportWriter) close() error {
+	cw.flushArtifact()
+	if !cw.started {
+		cw.writeHeader()
+	}
+	cw.write("]}")
+	return cw.err
+}
+
+func (cw *catalogExportWriter) flushArtifact() {
+	if cw.artifact == nil {
+		return
+	}
+	if !cw.started {
+		cw.writeHeader()
+	}
+	if cw.count > 0 {
+		cw.write(",")
+	}
+	cw.writeJSON(cw.artifact)
+	cw.artifact = nil
+	cw.count++
+}
+
+func (cw *catalogExportWriter) writeJSON(v any) {
+	if cw.err != nil {
+		return
+	}
+	if data, err := json.Marshal(v); err != nil {
+		cw.err = err
+	} else {
+		_, cw.err = cw.w.Write(data)
+	}
+}
+
+func (cw *catalogExportWriter) write(s string) {
+	if cw.err == nil {
+		_, cw.err = io.WriteString(cw.w, s)
+	}
+}
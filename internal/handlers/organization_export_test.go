@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+func TestCatalogExportWriterEmpty(t *testing.T) {
+	g := NewWithT(t)
+	var buf bytes.Buffer
+	cw := catalogExportWriter{w: &buf, header: api.CatalogExport{FormatVersion: 1, OrganizationSlug: util.PtrTo("org")}}
+
+	g.Expect(cw.close()).To(Succeed())
+	var export api.CatalogExport
+	g.Expect(json.Unmarshal(buf.Bytes(), &export)).To(Succeed())
+	g.Expect(export.FormatVersion).To(Equal(1))
+	g.Expect(export.OrganizationSlug).To(HaveValue(Equal("org")))
+	g.Expect(export.Artifacts).To(BeEmpty())
+}
+
+func TestCatalogExportWriterGroupsVersionsByArtifact(t *testing.T) {
+	g := NewWithT(t)
+	var buf bytes.Buffer
+	cw := catalogExportWriter{w: &buf, header: api.CatalogExport{FormatVersion: 1, ExportedAt: time.Now().UTC()}}
+
+	first, second := uuid.New(), uuid.New()
+	version := func(artifactID uuid.UUID, artifactName string, digest types.Digest) types.CatalogExportVersion {
+		return types.CatalogExportVersion{
+			ArtifactID:         artifactID,
+			ArtifactName:       artifactName,
+			ManifestBlobDigest: digest,
+			ManifestBlobSize:   10,
+			Tags:               []string{"latest"},
+			Blobs:              []types.CatalogExportBlob{{Digest: "sha256:layer", Size: 100}},
+		}
+	}
+
+	g.Expect(cw.add(version(first, "a", "sha256:1"))).To(Succeed())
+	g.Expect(cw.add(version(first, "a", "sha256:2"))).To(Succeed())
+	g.Expect(buf.Len()).To(BeZero(), "nothing must be written before the first artifact is complete")
+	g.Expect(cw.started).To(BeFalse())
+
+	g.Expect(cw.add(version(second, "b", "sha256:3"))).To(Succeed())
+	g.Expect(cw.started).To(BeTrue())
+	g.Expect(cw.close()).To(Succeed())
+
+	var export api.CatalogExport
+	g.Expect(json.Unmarshal(buf.Bytes(), &export)).To(Succeed())
+	g.Expect(export.Artifacts).To(HaveLen(2))
+	g.Expect(export.Artifacts[0].Name).To(Equal("a"))
+	g.Expect(export.Artifacts[0].Versions).To(HaveLen(2))
+	g.Expect(export.Artifacts[0].Versions[1].Digest).To(BeEquivalentTo("sha256:2"))
+	g.Expect(export.Artifacts[0].Versions[1].Size).To(BeEquivalentTo(110))
+	g.Expect(export.Artifacts[1].Name).To(Equal("b"))
+	g.Expect(export.Artifacts[1].Versions).To(HaveLen(1))
+}
+
+type failingWriter struct{}
+
+var errWriteFailed = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWriteFailed }
+
+func TestCatalogExportWriterReturnsWriteErrors(t *testing.T) {
+	g := NewWithT(t)
+	cw := catalogExportWriter{w: failingWriter{}}
+	g.Expect(cw.add(types.CatalogExportVersion{ArtifactID: uuid.New()})).To(Succeed())
+	g.Expect(cw.add(types.CatalogExportVersion{ArtifactID: uuid.New()})).To(MatchError(errWriteFailed))
+	g.Expect(cw.close()).To(MatchError(errWriteFailed))
+}
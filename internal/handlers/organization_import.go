@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
+	"github.com/distr-sh/distr/internal/catalog"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/registry/blob"
+	"github.com/distr-sh/distr/internal/registry/blob/s3"
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+)
+
+// importOrganizationCatalogHandler restores the catalog metadata from an [api.CatalogExport]. The blobs referenced by
+// the export must already be present in the registry bucket.
+func importOrganizationCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	auth := auth.Authentication.Require(ctx)
+
+	if !env.RegistryEnabled() {
+		http.Error(w, "registry is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	body, err := JsonBody[api.CatalogExport](w, r)
+	if err != nil {
+		return
+	}
+
+	blobs, ok := s3.NewBlobHandler(ctx).(blob.BlobStatHandler)
+	if !ok {
+		http.Error(w, "blob storage does not support lookups", http.StatusInternalServerError)
+		return
+	}
+
+	if result, err := catalog.Import(ctx, *auth.CurrentOrg(), auth.CurrentUserID(), blobs, body); err != nil {
+		switch {
+		case errors.Is(err, apierrors.ErrBadRequest):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, apierrors.ErrQuotaExceeded):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			log.Error("failed to import catalog", zap.Error(err))
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	} else {
+		RespondJSON(w, result)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDownloadStatsBucketSize(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(downloadStatsBucketSize(time.Hour)).To(Equal(time.Hour))
+	g.Expect(downloadStatsBucketSize(2 * 24 * time.Hour)).To(Equal(time.Hour))
+	g.Expect(downloadStatsBucketSize(3 * 24 * time.Hour)).To(Equal(24 * time.Hour))
+	g.Expect(downloadStatsBucketSize(180 * 24 * time.Hour)).To(Equal(24 * time.Hour))
+	g.Expect(downloadStatsBucketSize(365 * 24 * time.Hour)).To(Equal(7 * 24 * time.Hour))
+}
+
+// TestGetOrganizationDownloadStatsHandler checks that vendors see all pulls and customers only the pulls of their own
+// customer organization.
+func TestGetOrganizationDownloadStatsHandler(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+
+	org, user, artifact := dbtest.CreateOrgWithArtifact(t, ctx, "stats/app")
+	customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: "Customer"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+	otherCustomerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: "Other Customer"}
+	g.Expect(db.CreateCustomerOrganization(ctx, &otherCustomerOrg)).To(Succeed())
+	version := dbtest.CreateArtifactVersion(
+		t, ctx, artifact.ID, "1.0.0", digest.FromString("stats"), imgspecv1.MediaTypeImageManifest)
+	pulledAt := time.Now().Add(-time.Hour)
+	g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", &customerOrg.ID, pulledAt)).To(Succeed())
+	for range 2 {
+		g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", &otherCustomerOrg.ID, pulledAt)).
+			To(Succeed())
+	}
+	g.Expect(db.CreateArtifactPullLogEntry(ctx, version.ID, user.ID, "", nil, pulledAt)).To(Succeed())
+
+	request := func(info *testAuthInfo) api.OrganizationDownloadStats {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(withTestAuth(ctx, info),
+			http.MethodGet, "/api/v1/organization/download-stats", nil)
+		getOrganizationDownloadStatsHandler(rr, req)
+		g.Expect(rr.Code).To(Equal(http.StatusOK))
+		var stats api.OrganizationDownloadStats
+		g.Expect(json.Unmarshal(rr.Body.Bytes(), &stats)).To(Succeed())
+		return stats
+	}
+
+	vendorStats := request(&testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin})
+	g.Expect(vendorStats.PullsTotal).To(Equal(4))
+	g.Expect(vendorStats.PullsOverTime).To(HaveLen(30))
+	g.Expect(vendorStats.TopArtifacts).To(HaveLen(1))
+	g.Expect(vendorStats.TopArtifacts[0].PullsTotal).To(Equal(4))
+	g.Expect(vendorStats.TopCustomerOrganizations).To(HaveLen(2))
+	g.Expect(vendorStats.TopCustomerOrganizations[0].CustomerOrganizationID).To(Equal(otherCustomerOrg.ID))
+
+	customerStats := request(
+		&testAuthInfo{user: &user, org: &org, role: types.UserRoleAdmin, customerOrgID: &customerOrg.ID},
+	)
+	g.Expect(customerStats.PullsTotal).To(Equal(1))
+	g.Expect(customerStats.TopArtifacts).To(HaveLen(1))
+	g.Expect(customerStats.TopArtifacts[0].PullsTotal).To(Equal(1))
+	g.Expect(customerStats.TopCustomerOrganizations).To(HaveLen(1))
+	g.Expect(customerStats.TopCustomerOrganizations[0].CustomerOrganizationID).To(Equal(customerOrg.ID))
+}
@@ -53,3 +53,24 @@ func Max(max int) func(int) error {
 		return nil
 	}
 }
+
+// PeriodQueryParams parses the optional "from" and "to" query parameters. If not given, to defaults to now and from
+// defaults to the given duration before to.
+func PeriodQueryParams(r *http.Request, defaultDuration time.Duration) (from, to time.Time, err error) {
+	to, err = QueryParam(r, "to", ParseTimeFunc(time.RFC3339Nano))
+	if errors.Is(err, ErrParamNotDefined) {
+		to = time.Now()
+	} else if err != nil {
+		return from, to, err
+	}
+	from, err = QueryParam(r, "from", ParseTimeFunc(time.RFC3339Nano))
+	if errors.Is(err, ErrParamNotDefined) {
+		from = to.Add(-defaultDuration)
+	} else if err != nil {
+		return from, to, err
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("parameter from must be before to")
+	}
+	return from, to, nil
+}
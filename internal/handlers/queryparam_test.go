@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/handlers"
+	. "github.com/onsi/gomega"
+)
+
+func TestPeriodQueryParams(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		g := NewWithT(t)
+		before := time.Now()
+		from, to, err := handlers.PeriodQueryParams(httptest.NewRequest("GET", "/", nil), time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(to).To(BeTemporally("~", before, time.Second))
+		g.Expect(to.Sub(from)).To(Equal(time.Hour))
+	})
+
+	t.Run("from defaults to the duration before to", func(t *testing.T) {
+		g := NewWithT(t)
+		from, to, err := handlers.PeriodQueryParams(
+			httptest.NewRequest("GET", "/?to=2026-01-02T00:00:00Z", nil), 24*time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(from).To(Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		g.Expect(to).To(Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("explicit period", func(t *testing.T) {
+		g := NewWithT(t)
+		from, to, err := handlers.PeriodQueryParams(
+			httptest.NewRequest("GET", "/?from=2026-01-01T12:00:00Z&to=2026-01-02T00:00:00Z", nil), time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(from).To(Equal(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)))
+		g.Expect(to).To(Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	for _, query := range []string{
+		"from=2026-01-02T00:00:00Z&to=2026-01-02T00:00:00Z",
+		"from=2026-01-03T00:00:00Z&to=2026-01-02T00:00:00Z",
+		"from=2026-01-01",
+		"to=now",
+	} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			g := NewWithT(t)
+			_, _, err := handlers.PeriodQueryParams(httptest.NewRequest("GET", "/?"+query, nil), time.Hour)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err).NotTo(MatchError(handlers.ErrParamNotDefined))
+		})
+	}
+}
@@ -75,6 +75,12 @@ func SettingsRouter(r chiopenapi.Router) {
 		r.Get("/recovery-codes/status", mfaRecoveryCodesStatusHandler).
 			With(option.Description("Get the count of remaining unused recovery codes")).
 			With(option.Response(http.StatusOK, api.MFARecoveryCodesStatusResponse{}))
+
+		r.With(verifyRecoveryCodeRateLimitPerUser).
+			Post("/recovery-codes/verify", mfaVerifyRecoveryCodeHandler).
+			With(option.Description("Check whether a recovery code is valid without marking it as used")).
+			With(option.Request(api.VerifyMFARecoveryCodeRequest{})).
+			With(option.Response(http.StatusOK, api.VerifyMFARecoveryCodeResponse{}))
 	})
 
 	r.Route("/tokens", func(r chiopenapi.Router) {
@@ -286,6 +292,12 @@ var requestVerificationMailRateLimitPerUser = httprate.Limit(
 	httprate.WithKeyFuncs(middleware.RateLimitUserIDKey),
 )
 
+var verifyRecoveryCodeRateLimitPerUser = httprate.Limit(
+	5,
+	10*time.Minute,
+	httprate.WithKeyFuncs(middleware.RateLimitUserIDKey),
+)
+
 var inviteUserRateLimiter = httprate.Limit(
 	3,
 	10*time.Minute,
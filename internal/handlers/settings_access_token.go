@@ -41,6 +41,11 @@ func createAccessTokenHandler() http.HandlerFunc {
 			return
 		}
 
+		if err := types.ValidateCIDRs(request.AllowedCIDRs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		key, err := authkey.NewKey()
 		if err != nil {
 			log.Warn("error creating token", zap.Error(err))
@@ -55,6 +60,7 @@ func createAccessTokenHandler() http.HandlerFunc {
 			UserAccountID:  auth.CurrentUserID(),
 			Key:            key,
 			OrganizationID: *auth.CurrentOrgID(),
+			AllowedCIDRs:   request.AllowedCIDRs,
 		}
 		if err := db.CreateAccessToken(ctx, &token); err != nil {
 			log.Warn("error creating token", zap.Error(err))
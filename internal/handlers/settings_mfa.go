@@ -337,3 +337,42 @@ func mfaRecoveryCodesStatusHandler(w http.ResponseWriter, r *http.Request) {
 		RemainingCodes: count,
 	})
 }
+
+func mfaVerifyRecoveryCodeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := internalctx.GetLogger(ctx)
+	authInfo := auth.Authentication.Require(ctx)
+	userID := authInfo.CurrentUserID()
+
+	request, err := JsonBody[api.VerifyMFARecoveryCodeRequest](w, r)
+	if err != nil {
+		return
+	}
+
+	user, err := db.GetUserAccountByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apierrors.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+		} else {
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			log.Error("failed to get user", zap.Error(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !user.MFAEnabled {
+		http.Error(w, "MFA is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	code, err := db.FindMatchingUnusedRecoveryCode(ctx, userID, request.Code)
+	if err != nil {
+		sentry.GetHubFromContext(ctx).CaptureException(err)
+		log.Error("failed to verify recovery code", zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	RespondJSON(w, api.VerifyMFARecoveryCodeResponse{Valid: code != nil})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// testAuthInfo authenticates a user of an organization without a token, for calling handlers directly in tests.
+type testAuthInfo struct {
+	user          *types.UserAccount
+	org           *types.Organization
+	role          types.UserRole
+	customerOrgID *uuid.UUID
+}
+
+func (i *testAuthInfo) CurrentUserID() uuid.UUID         { return i.user.ID }
+func (i *testAuthInfo) CurrentUserEmail() string         { return i.user.Email }
+func (i *testAuthInfo) CurrentUserRole() *types.UserRole { return &i.role }
+func (i *testAuthInfo) CurrentOrgID() *uuid.UUID         { return &i.org.ID }
+func (i *testAuthInfo) CurrentCustomerOrgID() *uuid.UUID { return i.customerOrgID }
+func (i *testAuthInfo) CurrentUserEmailVerified() bool   { return true }
+func (i *testAuthInfo) IsSuperAdmin() bool               { return false }
+func (i *testAuthInfo) Token() any                       { return nil }
+func (i *testAuthInfo) CurrentOrg() *types.Organization  { return i.org }
+func (i *testAuthInfo) CurrentUser() *types.UserAccount  { return i.user }
+
+// withTestAuth returns a context with a no-op logger and the given authentication, like the middlewares set up for an
+// authenticated request.
+func withTestAuth(ctx context.Context, info *testAuthInfo) context.Context {
+	return auth.Authentication.NewContext(internalctx.WithLogger(ctx, zap.NewNop()), info)
+}
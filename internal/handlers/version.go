@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/buildconfig"
+	"github.com/oaswrap/spec/adapter/chiopenapi"
+	"github.com/oaswrap/spec/option"
+)
+
+func VersionRouter(r chiopenapi.Router) {
+	r.WithOptions(option.GroupTags("Miscellaneous"))
+	r.Get("/", getVersionHandler()).
+		With(option.Description("Get the version of this Distr Hub")).
+		With(option.Response(http.StatusOK, api.VersionResponse{}))
+}
+
+func getVersionHandler() http.HandlerFunc {
+	response := api.VersionResponse{
+		Version:     buildconfig.Version(),
+		Commit:      buildconfig.Commit(),
+		BuildDate:   buildconfig.BuildDate(),
+		Edition:     buildconfig.Edition(),
+		Development: buildconfig.IsDevelopment(),
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		RespondJSON(w, response)
+	}
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import "net/http"
+
+// WellKnownMicrosoftIdentityAssociationHandler serves the document that Microsoft Entra ID uses to verify the
+// publisher domain of an app registration. If no document is configured, it responds with 404.
+func WellKnownMicrosoftIdentityAssociationHandler(data []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(data) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/handlers"
+	. "github.com/onsi/gomega"
+)
+
+func TestWellKnownMicrosoftIdentityAssociationHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	data := []byte(`{"associatedApplications":[{"applicationId":"00000000-0000-0000-0000-000000000000"}]}`)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/microsoft-identity-association.json", nil)
+	handlers.WellKnownMicrosoftIdentityAssociationHandler(data).ServeHTTP(rr, req)
+
+	g.Expect(rr.Code).To(Equal(http.StatusOK))
+	g.Expect(rr.Header().Get("Content-Type")).To(Equal("application/json"))
+	g.Expect(rr.Body.Bytes()).To(Equal(data))
+}
+
+func TestWellKnownMicrosoftIdentityAssociationHandler_NotConfigured(t *testing.T) {
+	g := NewWithT(t)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/microsoft-identity-association.json", nil)
+	handlers.WellKnownMicrosoftIdentityAssociationHandler(nil).ServeHTTP(rr, req)
+
+	g.Expect(rr.Code).To(Equal(http.StatusNotFound))
+	g.Expect(rr.Header().Get("Content-Type")).NotTo(Equal("application/json"))
+}
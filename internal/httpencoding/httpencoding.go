@@ -0,0 +1,45 @@
+package httpencoding
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// Negotiate returns the encoding that the client prefers according to the Accept-Encoding headers of the request, out
+// of the given supported encodings, or an empty string if none is acceptable. If the client has no preference among
+// several encodings, the one that comes first in supported is returned.
+func Negotiate(r *http.Request, supported ...string) string {
+	var result string
+	var resultQ float64
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for part := range strings.SplitSeq(value, ",") {
+			name, params, _ := strings.Cut(part, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			index := slices.Index(supported, name)
+			if index < 0 {
+				continue
+			}
+
+			q := 1.0
+			if qs, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+					q = parsed
+				}
+			}
+
+			// a weight of 0 explicitly forbids the encoding
+			if q > 0 && (q > resultQ || (q == resultQ && index < slices.Index(supported, result))) {
+				result = name
+				resultQ = q
+			}
+		}
+	}
+	return result
+}
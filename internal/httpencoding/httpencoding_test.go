@@ -0,0 +1,40 @@
+package httpencoding_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/httpencoding"
+	. "github.com/onsi/gomega"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		acceptEncoding []string
+		supported      []string
+		expected       string
+	}{
+		{nil, []string{httpencoding.Gzip}, ""},
+		{[]string{"gzip"}, []string{httpencoding.Gzip}, "gzip"},
+		{[]string{"GZIP"}, []string{httpencoding.Gzip}, "gzip"},
+		{[]string{"deflate, br"}, []string{httpencoding.Gzip}, ""},
+		{[]string{"gzip;q=0"}, []string{httpencoding.Gzip}, ""},
+		{[]string{"gzip; q=0.5"}, []string{httpencoding.Gzip}, "gzip"},
+		{[]string{"br", "gzip"}, []string{httpencoding.Gzip}, "gzip"},
+		{[]string{"zstd"}, []string{httpencoding.Gzip}, ""},
+		{[]string{"gzip, zstd"}, []string{httpencoding.Zstd, httpencoding.Gzip}, "zstd"},
+		{[]string{"zstd, gzip"}, []string{httpencoding.Gzip, httpencoding.Zstd}, "gzip"},
+		{[]string{"gzip;q=1, zstd;q=0.5"}, []string{httpencoding.Zstd, httpencoding.Gzip}, "gzip"},
+		{[]string{"gzip, zstd;q=0"}, []string{httpencoding.Zstd, httpencoding.Gzip}, "gzip"},
+		{[]string{"gzip;q=invalid"}, []string{httpencoding.Gzip}, "gzip"},
+	} {
+		t.Run(fmt.Sprint(tc.acceptEncoding, tc.supported), func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for _, value := range tc.acceptEncoding {
+				r.Header.Add("Accept-Encoding", value)
+			}
+			NewWithT(t).Expect(httpencoding.Negotiate(r, tc.supported...)).To(Equal(tc.expected))
+		})
+	}
+}
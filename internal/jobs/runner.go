@@ -2,12 +2,19 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/distr-sh/distr/internal/buildconfig"
 	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/db/queryable"
+	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/mail"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -17,21 +24,27 @@ const (
 	tracerScope = "github.com/distr-sh/distr/internal/jobs"
 )
 
+var ErrJobAlreadyRunning = errors.New("job is already running")
+
 type runner struct {
 	db     queryable.Queryable
 	mailer mail.Mailer
 	logger *zap.Logger
 	tracer trace.Tracer
+	// locks holds a *sync.Mutex for every job name that is held while the job is running on this instance.
+	locks sync.Map
 }
 
+type ctxKeyRowsAffected struct{}
+
 func NewRunner(
 	logger *zap.Logger,
-	db queryable.Queryable,
+	database queryable.Queryable,
 	mailer mail.Mailer,
 	traceProvider trace.TracerProvider,
 ) *runner {
 	runner := runner{
-		db:     db,
+		db:     database,
 		mailer: mailer,
 		logger: logger,
 		tracer: traceProvider.Tracer(tracerScope, trace.WithInstrumentationVersion(buildconfig.Version())),
@@ -39,14 +52,74 @@ func NewRunner(
 	return &runner
 }
 
+// RunResult describes the outcome of a single job execution.
+type RunResult struct {
+	StartedAt time.Time
+	Elapsed   time.Duration
+	Err       error
+	// RowsAffected is the number of rows reported by the job via [AddRowsAffected] or nil if the job did not report
+	// any.
+	RowsAffected *int64
+}
+
+func runResultFromJobExecution(execution types.JobExecution) RunResult {
+	result := RunResult{
+		StartedAt:    execution.StartedAt,
+		Elapsed:      execution.FinishedAt.Sub(execution.StartedAt),
+		RowsAffected: execution.RowsAffected,
+	}
+	if execution.Error != nil {
+		result.Err = errors.New(*execution.Error)
+	}
+	return result
+}
+
+// AddRowsAffected adds n to the number of rows affected by the job running with the given context. It does nothing if
+// the context does not belong to a job.
+func AddRowsAffected(ctx context.Context, n int64) {
+	if counter, ok := ctx.Value(ctxKeyRowsAffected{}).(*rowsAffectedCounter); ok {
+		counter.reported.Store(true)
+		counter.count.Add(n)
+	}
+}
+
+type rowsAffectedCounter struct {
+	count    atomic.Int64
+	reported atomic.Bool
+}
+
 func (runner *runner) RunJobFunc(job Job) func(ctx context.Context) {
-	return func(ctx context.Context) { runner.Run(ctx, job) }
+	return func(ctx context.Context) {
+		// All jobs write to the database or send notifications based on agent data that is not updated in read-only
+		// mode, so they are skipped until the instance leaves read-only mode.
+		if env.ReadOnlyMode() {
+			runner.logger.Info("job skipped because the instance is in read-only mode", zap.String("job", job.name))
+			return
+		}
+		if _, err := runner.TryRun(ctx, job); errors.Is(err, ErrJobAlreadyRunning) {
+			runner.logger.Info("job skipped because it is already running", zap.String("job", job.name))
+		}
+	}
 }
 
-func (runner *runner) Run(ctx context.Context, job Job) {
+// TryRun runs the job unless another execution of the same job is still in progress on this instance, in which case
+// it returns ErrJobAlreadyRunning.
+func (runner *runner) TryRun(ctx context.Context, job Job) (RunResult, error) {
+	lock, _ := runner.locks.LoadOrStore(job.name, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	if !mu.TryLock() {
+		return RunResult{}, ErrJobAlreadyRunning
+	}
+	defer mu.Unlock()
+	return runner.Run(ctx, job), nil
+}
+
+func (runner *runner) Run(ctx context.Context, job Job) RunResult {
 	log := runner.logger.With(zap.String("job", job.name))
 
 	ctx = runner.jobCtx(ctx, job)
+	rowsAffected := &rowsAffectedCounter{}
+	ctx = context.WithValue(ctx, ctxKeyRowsAffected{}, rowsAffected)
 	ctx, span := runner.tracer.Start(ctx, job.name, trace.WithSpanKind(trace.SpanKindInternal))
 	defer span.End()
 
@@ -69,7 +142,37 @@ func (runner *runner) Run(ctx context.Context, job Job) {
 		span.SetStatus(codes.Ok, "job finished")
 		log.Info("job finished", zap.Duration("elapsed", elapsed))
 	}
-	// TODO: save result to DB
+	result := RunResult{StartedAt: startedAt, Elapsed: elapsed, Err: err}
+	if rowsAffected.reported.Load() {
+		result.RowsAffected = util.PtrTo(rowsAffected.count.Load())
+	}
+
+	execution := types.JobExecution{
+		JobName:      job.name,
+		StartedAt:    startedAt,
+		FinishedAt:   startedAt.Add(elapsed),
+		RowsAffected: result.RowsAffected,
+	}
+	if err != nil {
+		execution.Error = util.PtrTo(err.Error())
+	}
+	// The result must also be saved if the job was canceled or has timed out.
+	if err := db.CreateJobExecution(context.WithoutCancel(ctx), &execution); err != nil {
+		log.Warn("could not save job execution", zap.Error(err))
+	}
+	return result
+}
+
+func (runner *runner) lastResults(ctx context.Context) (map[string]RunResult, error) {
+	executions, err := db.GetLatestJobExecutions(internalctx.WithDb(ctx, runner.db))
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]RunResult, len(executions))
+	for _, execution := range executions {
+		results[execution.JobName] = runResultFromJobExecution(execution)
+	}
+	return results, nil
 }
 
 func (runner *runner) jobCtx(ctx context.Context, job Job) context.Context {
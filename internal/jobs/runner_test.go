@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+func TestRunResultFromJobExecution(t *testing.T) {
+	g := NewWithT(t)
+	startedAt := time.Now()
+
+	result := runResultFromJobExecution(types.JobExecution{
+		StartedAt:    startedAt,
+		FinishedAt:   startedAt.Add(time.Second),
+		RowsAffected: util.PtrTo(int64(2)),
+	})
+	g.Expect(result.StartedAt).To(Equal(startedAt))
+	g.Expect(result.Elapsed).To(Equal(time.Second))
+	g.Expect(result.Err).NotTo(HaveOccurred())
+	g.Expect(result.RowsAffected).To(Equal(util.PtrTo(int64(2))))
+
+	result = runResultFromJobExecution(types.JobExecution{Error: util.PtrTo("boom")})
+	g.Expect(result.Err).To(MatchError("boom"))
+	g.Expect(result.RowsAffected).To(BeNil())
+}
+
+func TestRunnerPersistsResults(t *testing.T) {
+	g := NewWithT(t)
+	ctx := dbtest.TxContext(t, nil)
+	runner := NewRunner(zap.NewNop(), internalctx.GetDb(ctx), nil, noop.NewTracerProvider())
+	suffix := time.Now().UnixNano()
+
+	succeeding := NewJob(fmt.Sprintf("succeeding-%v", suffix), func(ctx context.Context) error {
+		AddRowsAffected(ctx, 2)
+		AddRowsAffected(ctx, 3)
+		return nil
+	}, 0)
+	failing := NewJob(fmt.Sprintf("failing-%v", suffix), func(ctx context.Context) error {
+		return errors.New("boom")
+	}, 0)
+	timingOut := NewJob(fmt.Sprintf("timing-out-%v", suffix), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Millisecond)
+
+	g.Expect(runner.Run(ctx, succeeding).RowsAffected).To(Equal(util.PtrTo(int64(5))))
+	g.Expect(runner.Run(ctx, failing).Err).To(MatchError("boom"))
+	g.Expect(runner.Run(ctx, timingOut).Err).To(MatchError(context.DeadlineExceeded))
+
+	results, err := runner.lastResults(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(results).To(HaveKey(succeeding.name))
+	g.Expect(results[succeeding.name].Err).NotTo(HaveOccurred())
+	g.Expect(results[succeeding.name].RowsAffected).To(Equal(util.PtrTo(int64(5))))
+	g.Expect(results).To(HaveKey(failing.name))
+	g.Expect(results[failing.name].Err).To(MatchError("boom"))
+	g.Expect(results[failing.name].RowsAffected).To(BeNil())
+	g.Expect(results).To(HaveKey(timingOut.name))
+	g.Expect(results[timingOut.name].Err).To(MatchError(context.DeadlineExceeded.Error()))
+}
@@ -1,6 +1,9 @@
 package jobs
 
 import (
+	"context"
+	"errors"
+
 	"github.com/distr-sh/distr/internal/db/queryable"
 	"github.com/distr-sh/distr/internal/mail"
 	"github.com/go-co-op/gocron/v2"
@@ -8,10 +11,13 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrJobNotFound = errors.New("job not found")
+
 type Scheduler struct {
 	scheduler gocron.Scheduler
 	logger    *zap.Logger
 	runner    *runner
+	jobs      map[string]Job
 }
 
 func NewScheduler(
@@ -29,6 +35,7 @@ func NewScheduler(
 			scheduler: scheduler,
 			logger:    logger,
 			runner:    NewRunner(logger, db, mailer, traceProvider),
+			jobs:      make(map[string]Job),
 		}, nil
 	}
 }
@@ -40,9 +47,29 @@ func (s *Scheduler) RegisterCronJob(cron string, job Job) error {
 		gocron.WithName(job.name),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
+	if err == nil {
+		s.jobs[job.name] = job
+	}
 	return err
 }
 
+// RunJob runs the registered job with the given name once, independently of its schedule.
+// It returns ErrJobNotFound if no such job is registered and ErrJobAlreadyRunning if the job is currently running on
+// this instance, either scheduled or on demand.
+func (s *Scheduler) RunJob(ctx context.Context, name string) (RunResult, error) {
+	if job, ok := s.jobs[name]; !ok {
+		return RunResult{}, ErrJobNotFound
+	} else {
+		return s.runner.TryRun(ctx, job)
+	}
+}
+
+// LastResults returns the result of the most recent execution of every job by any instance, either scheduled or on
+// demand, keyed by job name. Jobs that have never run are not included.
+func (s *Scheduler) LastResults(ctx context.Context) (map[string]RunResult, error) {
+	return s.runner.lastResults(ctx)
+}
+
 func (s *Scheduler) Start() {
 	s.logger.Info("job scheduler starting", zap.Int("jobs", len(s.scheduler.Jobs())))
 	s.scheduler.Start()
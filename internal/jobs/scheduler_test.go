@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/env"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+func TestRunJobAlreadyRunning(t *testing.T) {
+	g := NewWithT(t)
+	scheduler, err := NewScheduler(zap.NewNop(), nil, nil, noop.NewTracerProvider())
+	g.Expect(err).NotTo(HaveOccurred())
+	job := NewJob("test", func(ctx context.Context) error { return nil }, 0)
+	g.Expect(scheduler.RegisterCronJob("0 0 * * *", job)).To(Succeed())
+
+	// simulate a scheduled execution that is still in progress
+	lock, _ := scheduler.runner.locks.LoadOrStore(job.name, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	_, err = scheduler.RunJob(t.Context(), job.name)
+	g.Expect(err).To(MatchError(ErrJobAlreadyRunning))
+
+	_, err = scheduler.RunJob(t.Context(), "unknown")
+	g.Expect(err).To(MatchError(ErrJobNotFound))
+}
+
+func TestRunJobFuncReadOnlyMode(t *testing.T) {
+	g := NewWithT(t)
+	t.Setenv("DATABASE_URL", "postgres://localhost/distr")
+	t.Setenv("JWT_SECRET", base64.StdEncoding.EncodeToString([]byte("secret")))
+	t.Setenv("DISTR_HOST", "https://distr.example.com")
+	t.Setenv("READ_ONLY_MODE", "true")
+	env.Initialize()
+
+	runner := NewRunner(zap.NewNop(), nil, nil, noop.NewTracerProvider())
+	ran := false
+	job := NewJob("test", func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 0)
+
+	runner.RunJobFunc(job)(t.Context())
+	g.Expect(ran).To(BeFalse())
+}
@@ -0,0 +1,29 @@
+package mailsending
+
+import (
+	"context"
+	"fmt"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/mail"
+	"github.com/distr-sh/distr/internal/mailtemplates"
+	"github.com/distr-sh/distr/internal/types"
+)
+
+func AlertDigest(
+	ctx context.Context,
+	user types.UserAccount,
+	organization types.Organization,
+	config types.AlertConfiguration,
+	records []types.NotificationRecordWithCurrentStatus,
+) error {
+	mailer := internalctx.GetMailer(ctx)
+
+	mail := mail.New(
+		mail.Subject(fmt.Sprintf("[Digest] %v %v: %v notifications", organization.Name, config.Name, len(records))),
+		mail.HtmlBodyTemplate(mailtemplates.AlertDigest(config, records)),
+		mail.To(user.Email),
+	)
+
+	return mailer.Send(ctx, mail)
+}
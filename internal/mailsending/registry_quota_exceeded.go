@@ -0,0 +1,29 @@
+package mailsending
+
+import (
+	"context"
+	"fmt"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/mail"
+	"github.com/distr-sh/distr/internal/mailtemplates"
+	"github.com/distr-sh/distr/internal/types"
+)
+
+func RegistryQuotaExceeded(
+	ctx context.Context,
+	user types.UserAccount,
+	organization types.Organization,
+	kind types.RegistryQuotaKind,
+	quota types.RegistryQuota,
+) error {
+	mailer := internalctx.GetMailer(ctx)
+
+	mail := mail.New(
+		mail.Subject(fmt.Sprintf("[Quota exceeded] %v registry %v quota", organization.Name, kind)),
+		mail.HtmlBodyTemplate(mailtemplates.RegistryQuotaExceeded(kind, quota)),
+		mail.To(user.Email),
+	)
+
+	return mailer.Send(ctx, mail)
+}
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/distr-sh/distr/internal/customdomains"
 	"github.com/distr-sh/distr/internal/env"
@@ -22,6 +23,7 @@ var (
 		"UnsafeHTMLAttr": func(value string) template.HTMLAttr { return template.HTMLAttr(value) },
 		"UnsafeHTML":     func(value string) template.HTML { return template.HTML(value) },
 		"UnsafeURL":      func(value string) template.URL { return template.URL(value) },
+		"FormatTime":     func(value time.Time) string { return value.UTC().Format("2006-01-02 15:04 MST") },
 	}
 )
 
@@ -152,3 +154,37 @@ func deploymentStatusNotification(
 		"CurrentStatus":    currentStatus,
 	}
 }
+
+type alertDigestEntry struct {
+	EventType string
+	types.NotificationRecordWithCurrentStatus
+}
+
+func AlertDigest(
+	config types.AlertConfiguration,
+	records []types.NotificationRecordWithCurrentStatus,
+) (*template.Template, any) {
+	entries := make([]alertDigestEntry, len(records))
+	for i, record := range records {
+		entries[i] = alertDigestEntry{NotificationRecordWithCurrentStatus: record}
+		if record.CurrentDeploymentRevisionStatus == nil {
+			entries[i].EventType = "stale"
+		} else if record.CurrentDeploymentRevisionStatus.Type == types.DeploymentStatusTypeError {
+			entries[i].EventType = "error"
+		} else {
+			entries[i].EventType = "recovered"
+		}
+	}
+
+	return templates.Lookup("alert-digest.html"), map[string]any{
+		"AlertConfiguration": config,
+		"Entries":            entries,
+	}
+}
+
+func RegistryQuotaExceeded(kind types.RegistryQuotaKind, quota types.RegistryQuota) (*template.Template, any) {
+	return templates.Lookup("registry-quota-exceeded.html"), map[string]any{
+		"Kind":  kind,
+		"Quota": quota,
+	}
+}
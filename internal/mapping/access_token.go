@@ -7,10 +7,11 @@ import (
 
 func AccessTokenToDTO(model types.AccessToken) api.AccessToken {
 	return api.AccessToken{
-		ID:         model.ID,
-		CreatedAt:  model.CreatedAt,
-		ExpiresAt:  model.ExpiresAt,
-		LastUsedAt: model.LastUsedAt,
-		Label:      model.Label,
+		ID:           model.ID,
+		CreatedAt:    model.CreatedAt,
+		ExpiresAt:    model.ExpiresAt,
+		LastUsedAt:   model.LastUsedAt,
+		Label:        model.Label,
+		AllowedCIDRs: model.AllowedCIDRs,
 	}
 }
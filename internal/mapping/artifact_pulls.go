@@ -7,6 +7,7 @@ import (
 
 func ArtifactVersionPullToAPI(pull types.ArtifactVersionPull) api.ArtifactVersionPullResponse {
 	response := api.ArtifactVersionPullResponse{
+		ID:              pull.ID,
 		CreatedAt:       pull.CreatedAt,
 		RemoteAddress:   pull.RemoteAddress,
 		Artifact:        pull.Artifact,
@@ -0,0 +1,39 @@
+package mapping
+
+import (
+	"encoding/json"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/types"
+)
+
+func CatalogExportVersionToAPI(v types.CatalogExportVersion) api.CatalogExportVersion {
+	result := api.CatalogExportVersion{
+		Digest:       v.ManifestBlobDigest,
+		CreatedAt:    v.CreatedAt,
+		MediaType:    v.ManifestContentType,
+		Tags:         v.Tags,
+		Annotations:  manifestAnnotations(v.ManifestData),
+		ManifestSize: v.ManifestBlobSize,
+		Size:         v.ManifestBlobSize,
+		Manifest:     v.ManifestData,
+		Blobs:        make([]api.CatalogExportBlob, len(v.Blobs)),
+	}
+	for i, blob := range v.Blobs {
+		result.Blobs[i] = api.CatalogExportBlob{Digest: blob.Digest, Size: blob.Size}
+		result.Size += blob.Size
+	}
+	return result
+}
+
+// manifestAnnotations returns the top level annotations of an OCI manifest or index. Manifests that are not JSON or
+// do not have annotations yield nil.
+func manifestAnnotations(data []byte) map[string]string {
+	var manifest struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+	return manifest.Annotations
+}
@@ -0,0 +1,59 @@
+package mapping
+
+import (
+	"github.com/containers/image/v5/manifest"
+	imagetypes "github.com/containers/image/v5/types"
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/types"
+)
+
+// ManifestInspectionToAPI parses the manifest of the given version the same way the registry does when it is pushed.
+// Indexes are parsed with manifest.ListFromBlob, all other manifests with manifest.FromBlob.
+func ManifestInspectionToAPI(av types.ArtifactVersion) (*api.ManifestInspection, error) {
+	result := api.ManifestInspection{
+		Digest:      av.ManifestBlobDigest,
+		MediaType:   av.ManifestContentType,
+		Annotations: manifestAnnotations(av.ManifestData),
+	}
+
+	if manifest.MIMETypeIsMultiImage(av.ManifestContentType) {
+		list, err := manifest.ListFromBlob(av.ManifestData, av.ManifestContentType)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list.Instances() {
+			instance, err := list.Instance(d)
+			if err != nil {
+				return nil, err
+			}
+			result.Manifests = append(result.Manifests, api.ManifestDescriptor{
+				MediaType:   instance.MediaType,
+				Digest:      types.Digest(instance.Digest),
+				Size:        instance.Size,
+				Platform:    instance.ReadOnly.Platform,
+				Annotations: instance.ReadOnly.Annotations,
+			})
+		}
+	} else {
+		parsed, err := manifest.FromBlob(av.ManifestData, av.ManifestContentType)
+		if err != nil {
+			return nil, err
+		}
+		config := blobInfoToDescriptor(parsed.ConfigInfo())
+		result.Config = &config
+		for _, layer := range parsed.LayerInfos() {
+			result.Layers = append(result.Layers, blobInfoToDescriptor(layer.BlobInfo))
+		}
+	}
+
+	return &result, nil
+}
+
+func blobInfoToDescriptor(info imagetypes.BlobInfo) api.ManifestDescriptor {
+	return api.ManifestDescriptor{
+		MediaType:   info.MediaType,
+		Digest:      types.Digest(info.Digest),
+		Size:        info.Size,
+		Annotations: info.Annotations,
+	}
+}
@@ -0,0 +1,103 @@
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/distr-sh/distr/api"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestManifestInspectionToAPI(t *testing.T) {
+	config := imgspecv1.Descriptor{
+		MediaType: imgspecv1.MediaTypeImageConfig,
+		Digest:    digest.FromString("config"),
+		Size:      2,
+	}
+	layer := imgspecv1.Descriptor{
+		MediaType:   imgspecv1.MediaTypeImageLayerGzip,
+		Digest:      digest.FromString("layer"),
+		Size:        1024,
+		Annotations: map[string]string{"org.opencontainers.image.title": "layer.tar.gz"},
+	}
+	platform := imgspecv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	child := imgspecv1.Descriptor{
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Digest:    digest.FromString("child"),
+		Size:      512,
+		Platform:  &platform,
+	}
+	annotations := map[string]string{"org.opencontainers.image.version": "1.0.0"}
+	version := func(mediaType string, v any) types.ArtifactVersion {
+		data, err := json.Marshal(v)
+		NewWithT(t).Expect(err).NotTo(HaveOccurred())
+		return types.ArtifactVersion{
+			ManifestBlobDigest:  types.Digest(digest.FromBytes(data)),
+			ManifestContentType: mediaType,
+			ManifestData:        data,
+		}
+	}
+
+	t.Run("image manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		av := version(imgspecv1.MediaTypeImageManifest, imgspecv1.Manifest{
+			Versioned:   specs.Versioned{SchemaVersion: 2},
+			MediaType:   imgspecv1.MediaTypeImageManifest,
+			Config:      config,
+			Layers:      []imgspecv1.Descriptor{layer},
+			Annotations: annotations,
+		})
+		result, err := ManifestInspectionToAPI(av)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*result).To(Equal(api.ManifestInspection{
+			Digest:    av.ManifestBlobDigest,
+			MediaType: imgspecv1.MediaTypeImageManifest,
+			Config: &api.ManifestDescriptor{
+				MediaType: config.MediaType,
+				Digest:    types.Digest(config.Digest),
+				Size:      config.Size,
+			},
+			Layers: []api.ManifestDescriptor{{
+				MediaType:   layer.MediaType,
+				Digest:      types.Digest(layer.Digest),
+				Size:        layer.Size,
+				Annotations: layer.Annotations,
+			}},
+			Annotations: annotations,
+		}))
+	})
+
+	t.Run("index", func(t *testing.T) {
+		g := NewWithT(t)
+		av := version(imgspecv1.MediaTypeImageIndex, imgspecv1.Index{
+			Versioned:   specs.Versioned{SchemaVersion: 2},
+			MediaType:   imgspecv1.MediaTypeImageIndex,
+			Manifests:   []imgspecv1.Descriptor{child},
+			Annotations: annotations,
+		})
+		result, err := ManifestInspectionToAPI(av)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.Config).To(BeNil())
+		g.Expect(result.Layers).To(BeEmpty())
+		g.Expect(result.Annotations).To(Equal(annotations))
+		g.Expect(result.Manifests).To(Equal([]api.ManifestDescriptor{{
+			MediaType: child.MediaType,
+			Digest:    types.Digest(child.Digest),
+			Size:      child.Size,
+			Platform:  &platform,
+		}}))
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := ManifestInspectionToAPI(types.ArtifactVersion{
+			ManifestContentType: imgspecv1.MediaTypeImageManifest,
+			ManifestData:        []byte("not json"),
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -17,6 +17,7 @@ func NotificationRecordWithCurrentStatusToAPI(
 			PreviousDeploymentRevisionStatusID: record.PreviousDeploymentRevisionStatusID,
 			CurrentDeploymentRevisionStatusID:  record.CurrentDeploymentRevisionStatusID,
 			Message:                            record.Message,
+			DeliveredAt:                        record.DeliveredAt,
 		},
 		DeploymentTargetName:     record.DeploymentTargetName,
 		CustomerOrganizationName: record.CustomerOrganizationName,
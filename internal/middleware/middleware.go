@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"slices"
 	"strings"
 	"time"
@@ -13,9 +15,11 @@ import (
 	"github.com/distr-sh/distr/internal/authn"
 	"github.com/distr-sh/distr/internal/authn/authinfo"
 	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/mail"
 	"github.com/distr-sh/distr/internal/oidc"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"github.com/getsentry/sentry-go"
 	sentryhttp "github.com/getsentry/sentry-go/http"
 	"github.com/go-chi/chi/v5"
@@ -69,6 +73,98 @@ func LoggingMiddleware(handler http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// maxDecompressedRequestBodySize limits the size of request bodies after decompression.
+const maxDecompressedRequestBodySize = 64 * 1024 * 1024
+
+// DecompressRequestBody transparently decompresses gzip encoded request bodies. Requests with any other content
+// encoding are rejected.
+func DecompressRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Content-Encoding") {
+		case "", "identity":
+			next.ServeHTTP(w, r)
+		case "gzip":
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid gzip body: %v", err), http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			r.Body = http.MaxBytesReader(w, gz, maxDecompressedRequestBodySize)
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+			next.ServeHTTP(w, r)
+		default:
+			http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+		}
+	})
+}
+
+// RealIP replaces the RemoteAddr of requests from trusted proxies with the client IP taken from the first of the given
+// headers that is present. Comma separated headers like X-Forwarded-For are read from right to left and the first
+// address that does not belong to a trusted proxy is used. Requests from any other address are not modified, so
+// clients cannot spoof their address.
+func RealIP(trustedProxies []netip.Prefix, headers []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if addr, ok := forwardedClientIP(r, trustedProxies, headers); ok {
+				r.RemoteAddr = addr.String()
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func forwardedClientIP(r *http.Request, trustedProxies []netip.Prefix, headers []string) (netip.Addr, bool) {
+	if len(trustedProxies) == 0 {
+		return netip.Addr{}, false
+	}
+
+	remoteAddr, err := netip.ParseAddrPort(r.RemoteAddr)
+	var addr netip.Addr
+	if err == nil {
+		addr = remoteAddr.Addr()
+	} else if addr, err = netip.ParseAddr(r.RemoteAddr); err != nil {
+		return netip.Addr{}, false
+	}
+	if !isTrustedProxy(addr.Unmap(), trustedProxies) {
+		return netip.Addr{}, false
+	}
+
+	for _, header := range headers {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		items := strings.Split(strings.Join(values, ","), ",")
+		var client netip.Addr
+		for i := len(items) - 1; i >= 0; i-- {
+			parsed, err := netip.ParseAddr(strings.TrimSpace(items[i]))
+			if err != nil {
+				break
+			}
+			client = parsed.Unmap()
+			if !isTrustedProxy(client, trustedProxies) {
+				break
+			}
+		}
+		if client.IsValid() {
+			return client, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func isSuperAdmin(ctx context.Context) bool {
 	if auth, err := auth.Authentication.Get(ctx); err == nil {
 		return auth.IsSuperAdmin()
@@ -189,6 +285,12 @@ func RateLimitUserIDKey(r *http.Request) (string, error) {
 	}
 }
 
+// RateLimitRequestIPKey returns the client IP address resolved by [RealIP]. Unlike httprate.KeyByIP, forwarded
+// headers of untrusted clients are ignored, so the limit can not be evaded by sending a different header every time.
+func RateLimitRequestIPKey(r *http.Request) (string, error) {
+	return util.NormalizeIPAddress(internalctx.GetRequestIPAddress(r.Context())), nil
+}
+
 func RateLimitPathValueKey(name string) func(r *http.Request) (string, error) {
 	return func(r *http.Request) (string, error) {
 		return r.PathValue(name), nil
@@ -243,6 +345,42 @@ func BlockSuperAdmin(handler http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+const (
+	ReadOnlyModeMessage = "this instance is in read-only mode for maintenance, please try again later"
+	// ReadOnlyModeRetryAfter is the value of the Retry-After header, in seconds, of requests rejected in read-only mode.
+	ReadOnlyModeRetryAfter = "300"
+)
+
+// ReadOnlyMode rejects all requests that could modify resources with 503 Service Unavailable if the instance is in
+// read-only mode. Routes that only modify bookkeeping data as a side effect, like the last login of a user, are not
+// guarded, so that users and agents can still log in.
+func ReadOnlyMode(handler http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if env.ReadOnlyMode() && !isReadOnlyMethod(r.Method) {
+			w.Header().Set("Retry-After", ReadOnlyModeRetryAfter)
+			http.Error(w, ReadOnlyModeMessage, http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func RequireSuperAdmin(handler http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !isSuperAdmin(r.Context()) {
+			http.Error(w, "insufficient permissions", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
 func FeatureFlagMiddleware(feature types.Feature) func(handler http.Handler) http.Handler {
 	return func(handler http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
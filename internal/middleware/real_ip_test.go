@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/middleware"
+	. "github.com/onsi/gomega"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	headers := []string{"X-Forwarded-For", "X-Real-IP"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		{
+			name:       "untrusted remote ignores headers",
+			remoteAddr: "203.0.113.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			expected:   "203.0.113.1:1234",
+		},
+		{
+			name:       "trusted remote without headers",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "10.0.0.1:1234",
+		},
+		{
+			name:       "trusted remote uses last untrusted forwarded address",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"192.0.2.1, 198.51.100.1", "10.0.0.2"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "trusted remote falls back to next header",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Real-Ip": {"198.51.100.1"}},
+			expected:   "198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var remoteAddr string
+			handler := middleware.RealIP(trusted, headers)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { remoteAddr = r.RemoteAddr }),
+			)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.header {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			g.Expect(remoteAddr).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestRateLimitRequestIPKey(t *testing.T) {
+	g := NewWithT(t)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req = req.WithContext(internalctx.WithRequestIPAddress(req.Context(), req.RemoteAddr))
+	key, err := middleware.RateLimitRequestIPKey(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key).To(Equal("203.0.113.1"))
+}
@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget DROP COLUMN access_key_rotated_at;
@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget ADD COLUMN access_key_rotated_at TIMESTAMP WITH TIME ZONE;
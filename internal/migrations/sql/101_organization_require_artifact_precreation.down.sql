@@ -0,0 +1,2 @@
+ALTER TABLE Organization
+  DROP COLUMN require_artifact_precreation;
@@ -0,0 +1,2 @@
+ALTER TABLE Organization
+  ADD COLUMN require_artifact_precreation BOOLEAN NOT NULL DEFAULT false;
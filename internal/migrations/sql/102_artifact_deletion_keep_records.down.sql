@@ -0,0 +1,11 @@
+DELETE FROM ArtifactDeletion WHERE artifact_id IS NULL;
+
+DROP INDEX ArtifactDeletion_organization_id_created_at;
+
+ALTER TABLE ArtifactDeletion
+  DROP CONSTRAINT artifactdeletion_artifact_id_fkey,
+  ADD CONSTRAINT artifactdeletion_artifact_id_fkey
+    FOREIGN KEY (artifact_id) REFERENCES Artifact (id) ON DELETE CASCADE,
+  ALTER COLUMN artifact_id SET NOT NULL,
+  DROP COLUMN artifact_name,
+  DROP COLUMN organization_id;
@@ -0,0 +1,18 @@
+ALTER TABLE ArtifactDeletion
+  ADD COLUMN organization_id UUID REFERENCES Organization (id) ON DELETE CASCADE,
+  ADD COLUMN artifact_name TEXT;
+
+UPDATE ArtifactDeletion ad
+SET organization_id = a.organization_id, artifact_name = a.name
+FROM Artifact a
+WHERE a.id = ad.artifact_id;
+
+ALTER TABLE ArtifactDeletion
+  ALTER COLUMN organization_id SET NOT NULL,
+  ALTER COLUMN artifact_name SET NOT NULL,
+  ALTER COLUMN artifact_id DROP NOT NULL,
+  DROP CONSTRAINT artifactdeletion_artifact_id_fkey,
+  ADD CONSTRAINT artifactdeletion_artifact_id_fkey
+    FOREIGN KEY (artifact_id) REFERENCES Artifact (id) ON DELETE SET NULL;
+
+CREATE INDEX ArtifactDeletion_organization_id_created_at ON ArtifactDeletion (organization_id, created_at DESC);
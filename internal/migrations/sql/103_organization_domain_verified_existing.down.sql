@@ -0,0 +1 @@
+-- verification timestamps of existing domains are kept
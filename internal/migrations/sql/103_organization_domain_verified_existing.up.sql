@@ -0,0 +1,9 @@
+-- Custom domains are only used once they are verified. Domains that were configured before domain verification was
+-- introduced, i.e. without a verification token, are considered verified so that they keep working.
+UPDATE Organization
+SET app_domain_verified_at = coalesce(app_domain_verified_at, now())
+WHERE app_domain IS NOT NULL AND domain_verification_token IS NULL;
+
+UPDATE Organization
+SET registry_domain_verified_at = coalesce(registry_domain_verified_at, now())
+WHERE registry_domain IS NOT NULL AND domain_verification_token IS NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE JobExecution (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  job_name TEXT NOT NULL,
+  started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  error TEXT, -- NULL if the job succeeded
+  rows_affected BIGINT -- NULL if the job did not report any
+);
+
+CREATE INDEX JobExecution_job_name_started_at ON JobExecution (job_name, started_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ArtifactVersion_artifact_id_subject_digest;
+
+ALTER TABLE ArtifactVersion DROP COLUMN IF EXISTS subject_digest;
@@ -0,0 +1,13 @@
+-- The subject of a manifest is stored for its digest version, so that the referrers of a manifest can be looked up
+-- without reading every manifest of the artifact.
+ALTER TABLE ArtifactVersion ADD COLUMN subject_digest TEXT;
+
+UPDATE ArtifactVersion
+SET subject_digest = convert_from(manifest_data, 'UTF8')::jsonb #>> '{subject,digest}'
+WHERE name LIKE '%:%'
+  AND manifest_data IS NOT NULL
+  AND pg_input_is_valid(convert_from(manifest_data, 'UTF8'), 'jsonb');
+
+CREATE INDEX ArtifactVersion_artifact_id_subject_digest
+  ON ArtifactVersion (artifact_id, subject_digest)
+  WHERE subject_digest IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_artifact_version_pull_created_at_id;
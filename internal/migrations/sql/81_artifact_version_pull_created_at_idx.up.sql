@@ -0,0 +1,2 @@
+CREATE INDEX idx_artifact_version_pull_created_at_id
+  ON ArtifactVersionPull (created_at DESC, id DESC);
@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget DROP COLUMN log_record_entries_max_count;
@@ -0,0 +1,2 @@
+ALTER TABLE DeploymentTarget
+  ADD COLUMN log_record_entries_max_count INTEGER CHECK (log_record_entries_max_count >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX DeploymentRevisionStatus_idempotency_key;
+
+ALTER TABLE DeploymentRevisionStatus DROP COLUMN idempotency_key;
@@ -0,0 +1,5 @@
+ALTER TABLE DeploymentRevisionStatus
+  ADD COLUMN idempotency_key UUID;
+
+CREATE UNIQUE INDEX DeploymentRevisionStatus_idempotency_key
+  ON DeploymentRevisionStatus (deployment_revision_id, idempotency_key);
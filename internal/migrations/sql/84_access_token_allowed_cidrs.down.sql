@@ -0,0 +1 @@
+ALTER TABLE AccessToken DROP COLUMN allowed_cidrs;
@@ -0,0 +1,2 @@
+ALTER TABLE AccessToken
+  ADD COLUMN allowed_cidrs TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
@@ -0,0 +1,4 @@
+ALTER TABLE Organization
+  DROP COLUMN quota_exceeded_webhook_url,
+  DROP COLUMN quota_exceeded_email_enabled,
+  DROP COLUMN quota_exceeded_notified_at;
@@ -0,0 +1,4 @@
+ALTER TABLE Organization
+  ADD COLUMN quota_exceeded_webhook_url TEXT,
+  ADD COLUMN quota_exceeded_email_enabled BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN quota_exceeded_notified_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP TABLE ArtifactAccessRule;
+
+DROP TYPE ARTIFACT_PERMISSION;
@@ -0,0 +1,12 @@
+CREATE TYPE ARTIFACT_PERMISSION AS ENUM ('read', 'write');
+
+CREATE TABLE ArtifactAccessRule (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  artifact_id UUID NOT NULL REFERENCES Artifact (id) ON DELETE CASCADE,
+  user_account_id UUID REFERENCES UserAccount (id) ON DELETE CASCADE,
+  user_role USER_ROLE,
+  permission ARTIFACT_PERMISSION NOT NULL,
+  CONSTRAINT ArtifactAccessRule_subject CHECK ((user_account_id IS NULL) <> (user_role IS NULL)),
+  CONSTRAINT ArtifactAccessRule_unique UNIQUE NULLS NOT DISTINCT (artifact_id, user_account_id, user_role)
+);
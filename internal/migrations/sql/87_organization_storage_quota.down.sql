@@ -0,0 +1 @@
+ALTER TABLE Organization DROP COLUMN storage_quota_bytes;
@@ -0,0 +1 @@
+ALTER TABLE Organization ADD COLUMN storage_quota_bytes BIGINT CHECK (storage_quota_bytes >= 0);
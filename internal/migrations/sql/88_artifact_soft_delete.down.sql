@@ -0,0 +1,6 @@
+DELETE FROM Artifact WHERE deleted_at IS NOT NULL;
+
+DROP INDEX Artifact_unique_name;
+ALTER TABLE Artifact ADD CONSTRAINT Artifact_unique_name UNIQUE (organization_id, name);
+
+ALTER TABLE Artifact DROP COLUMN deleted_at;
@@ -0,0 +1,5 @@
+ALTER TABLE Artifact ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- a deleted artifact must not prevent pushing a new artifact with the same name
+ALTER TABLE Artifact DROP CONSTRAINT Artifact_unique_name;
+CREATE UNIQUE INDEX Artifact_unique_name ON Artifact (organization_id, name) WHERE deleted_at IS NULL;
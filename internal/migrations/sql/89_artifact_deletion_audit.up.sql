@@ -0,0 +1,10 @@
+CREATE TABLE ArtifactDeletion (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT current_timestamp,
+  artifact_id UUID NOT NULL REFERENCES Artifact (id) ON DELETE CASCADE,
+  tag_name TEXT, -- NULL means the whole artifact was deleted
+  manifest_blob_digest TEXT,
+  deleted_by_useraccount_id UUID REFERENCES UserAccount (id) ON DELETE SET NULL
+);
+
+CREATE INDEX ArtifactDeletion_artifact_id_created_at ON ArtifactDeletion (artifact_id, created_at DESC);
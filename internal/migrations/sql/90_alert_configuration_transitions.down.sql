@@ -0,0 +1,3 @@
+ALTER TABLE AlertConfiguration DROP COLUMN transitions;
+
+DROP TYPE ALERT_TRANSITION;
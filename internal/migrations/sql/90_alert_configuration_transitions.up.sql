@@ -0,0 +1,4 @@
+CREATE TYPE ALERT_TRANSITION AS ENUM ('error', 'error_recovered', 'stale', 'stale_recovered');
+
+ALTER TABLE AlertConfiguration
+  ADD COLUMN transitions ALERT_TRANSITION[] DEFAULT '{error,error_recovered,stale,stale_recovered}' NOT NULL;
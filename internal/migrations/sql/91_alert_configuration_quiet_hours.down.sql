@@ -0,0 +1,6 @@
+ALTER TABLE AlertConfiguration
+  DROP CONSTRAINT AlertConfiguration_quiet_hours_complete,
+  DROP COLUMN quiet_hours_start,
+  DROP COLUMN quiet_hours_end,
+  DROP COLUMN quiet_hours_timezone,
+  DROP COLUMN quiet_hours_allow_errors;
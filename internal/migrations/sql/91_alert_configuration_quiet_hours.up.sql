@@ -0,0 +1,9 @@
+ALTER TABLE AlertConfiguration
+  ADD COLUMN quiet_hours_start TEXT CHECK (quiet_hours_start ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+  ADD COLUMN quiet_hours_end TEXT CHECK (quiet_hours_end ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+  ADD COLUMN quiet_hours_timezone TEXT,
+  ADD COLUMN quiet_hours_allow_errors BOOLEAN NOT NULL DEFAULT true,
+  ADD CONSTRAINT AlertConfiguration_quiet_hours_complete CHECK (
+    (quiet_hours_start IS NULL) = (quiet_hours_end IS NULL)
+    AND (quiet_hours_start IS NULL) = (quiet_hours_timezone IS NULL)
+  );
@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget DROP COLUMN last_seen_at;
@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+DROP INDEX NotificationRecord_pending;
+
+ALTER TABLE NotificationRecord DROP COLUMN delivered_at;
+
+ALTER TABLE AlertConfiguration DROP COLUMN digest;
@@ -0,0 +1,7 @@
+ALTER TABLE AlertConfiguration ADD COLUMN digest BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE NotificationRecord ADD COLUMN delivered_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE NotificationRecord SET delivered_at = created_at;
+
+CREATE INDEX NotificationRecord_pending ON NotificationRecord (alert_configuration_id) WHERE delivered_at IS NULL;
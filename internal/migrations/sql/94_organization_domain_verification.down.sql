@@ -0,0 +1,4 @@
+ALTER TABLE Organization
+  DROP COLUMN domain_verification_token,
+  DROP COLUMN app_domain_verified_at,
+  DROP COLUMN registry_domain_verified_at;
@@ -0,0 +1,4 @@
+ALTER TABLE Organization
+  ADD COLUMN domain_verification_token TEXT DEFAULT NULL,
+  ADD COLUMN app_domain_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+  ADD COLUMN registry_domain_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
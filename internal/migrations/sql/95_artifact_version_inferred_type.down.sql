@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ArtifactVersion_artifact_id_inferred_type;
+
+ALTER TABLE ArtifactVersion DROP COLUMN inferred_type;
@@ -0,0 +1,20 @@
+ALTER TABLE ArtifactVersion ADD COLUMN inferred_type TEXT NOT NULL DEFAULT 'generic';
+
+-- Manifests that are not valid JSON can not be classified any further and stay generic.
+UPDATE ArtifactVersion
+SET inferred_type = CASE
+  WHEN manifest_content_type LIKE 'application/vnd.docker%' THEN 'container-image'
+  WHEN manifest_content_type <> 'application/vnd.oci.image.manifest.v1+json' THEN 'generic'
+  WHEN jsonb_path_exists(
+    convert_from(manifest_data, 'UTF8')::jsonb,
+    '$.config.mediaType ? (@ starts with "application/vnd.cncf.helm")'
+  ) OR jsonb_path_exists(
+    convert_from(manifest_data, 'UTF8')::jsonb,
+    '$.layers[*].mediaType ? (@ starts with "application/vnd.cncf.helm")'
+  ) THEN 'helm-chart'
+  ELSE 'generic'
+END
+WHERE manifest_content_type <> 'application/vnd.oci.image.manifest.v1+json'
+  OR pg_input_is_valid(convert_from(manifest_data, 'UTF8'), 'jsonb');
+
+CREATE INDEX ArtifactVersion_artifact_id_inferred_type ON ArtifactVersion (artifact_id, inferred_type);
@@ -0,0 +1,32 @@
+CREATE TABLE ArtifactVersionPlatform (
+  artifact_version_id UUID NOT NULL REFERENCES ArtifactVersion (id) ON DELETE CASCADE,
+  manifest_blob_digest TEXT NOT NULL,
+  os TEXT NOT NULL,
+  architecture TEXT NOT NULL,
+  variant TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (artifact_version_id, manifest_blob_digest, os, architecture, variant)
+);
+
+-- Platforms are only stored for the version named by the digest of an index, not for its tags.
+-- Indexes that are not valid JSON are skipped.
+INSERT INTO ArtifactVersionPlatform (artifact_version_id, manifest_blob_digest, os, architecture, variant)
+SELECT av.id, m ->> 'digest', m -> 'platform' ->> 'os', coalesce(m -> 'platform' ->> 'architecture', ''),
+  coalesce(m -> 'platform' ->> 'variant', '')
+FROM (
+  SELECT id, CASE
+    WHEN pg_input_is_valid(convert_from(manifest_data, 'UTF8'), 'jsonb')
+      THEN convert_from(manifest_data, 'UTF8')::jsonb -> 'manifests'
+  END AS manifests
+  FROM ArtifactVersion
+  WHERE name LIKE '%:%'
+    AND manifest_content_type IN (
+      'application/vnd.oci.image.index.v1+json',
+      'application/vnd.docker.distribution.manifest.list.v2+json'
+    )
+) av
+CROSS JOIN LATERAL jsonb_array_elements(
+  CASE WHEN jsonb_typeof(av.manifests) = 'array' THEN av.manifests ELSE '[]'::jsonb END
+) m
+WHERE m ->> 'digest' IS NOT NULL
+  AND coalesce(m -> 'platform' ->> 'os', '') <> ''
+ON CONFLICT DO NOTHING;
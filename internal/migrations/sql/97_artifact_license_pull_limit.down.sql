@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS ArtifactVersionPull_customer_organization_id_created_at;
+
+ALTER TABLE ArtifactLicense
+  DROP CONSTRAINT artifact_license_pull_limit_period_required,
+  DROP COLUMN pull_limit_period,
+  DROP COLUMN max_pulls;
@@ -0,0 +1,9 @@
+ALTER TABLE ArtifactLicense
+  ADD COLUMN max_pulls INTEGER CHECK (max_pulls > 0),
+  ADD COLUMN pull_limit_period TEXT CHECK (pull_limit_period IN ('day', 'week', 'month', 'year')),
+  ADD CONSTRAINT artifact_license_pull_limit_period_required
+    CHECK ((max_pulls IS NULL) = (pull_limit_period IS NULL));
+
+CREATE INDEX ArtifactVersionPull_customer_organization_id_created_at
+  ON ArtifactVersionPull (customer_organization_id, created_at)
+  WHERE customer_organization_id IS NOT NULL;
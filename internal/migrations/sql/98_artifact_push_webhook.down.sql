@@ -0,0 +1,2 @@
+DROP TABLE ArtifactPushWebhookDelivery;
+DROP TABLE ArtifactPushWebhook;
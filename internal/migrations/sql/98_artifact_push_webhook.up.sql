@@ -0,0 +1,29 @@
+CREATE TABLE ArtifactPushWebhook (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT current_timestamp,
+  organization_id UUID NOT NULL REFERENCES Organization (id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  artifact_name_filter TEXT -- NULL means the webhook is called for all artifacts
+);
+
+CREATE INDEX fk_ArtifactPushWebhook_organization_id ON ArtifactPushWebhook (organization_id);
+
+CREATE TABLE ArtifactPushWebhookDelivery (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT current_timestamp,
+  webhook_id UUID NOT NULL REFERENCES ArtifactPushWebhook (id) ON DELETE CASCADE,
+  payload JSONB NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT current_timestamp, -- NULL once delivered or given up
+  last_attempt_at TIMESTAMP WITH TIME ZONE,
+  last_status_code INTEGER,
+  last_error TEXT,
+  delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX ArtifactPushWebhookDelivery_webhook_id_created_at
+  ON ArtifactPushWebhookDelivery (webhook_id, created_at DESC);
+CREATE INDEX ArtifactPushWebhookDelivery_next_attempt_at
+  ON ArtifactPushWebhookDelivery (next_attempt_at)
+  WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,13 @@
+UPDATE DeploymentTarget
+SET resources_cpu_request = NULL,
+  resources_memory_request = NULL,
+  resources_cpu_limit = NULL,
+  resources_memory_limit = NULL
+WHERE type = 'docker';
+
+ALTER TABLE DeploymentTarget
+  ADD CONSTRAINT type_docker_resources_check CHECK (
+    type != 'docker'
+    OR
+    (resources_cpu_limit IS NULL AND resources_memory_limit IS NULL AND resources_cpu_request IS NULL AND resources_memory_request IS NULL)
+  );
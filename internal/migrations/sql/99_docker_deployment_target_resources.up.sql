@@ -0,0 +1 @@
+ALTER TABLE DeploymentTarget DROP CONSTRAINT type_docker_resources_check;
@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	artifactPushWebhookBatchSize   = 50
+	artifactPushWebhookMaxAttempts = 10
+	// artifactPushWebhookLease must be longer than the time it takes to deliver a whole batch.
+	artifactPushWebhookLease        = 15 * time.Minute
+	artifactPushWebhookBaseBackoff  = 1 * time.Minute
+	artifactPushWebhookMaxBackoff   = 6 * time.Hour
+	artifactPushWebhookAsyncTimeout = 5 * time.Minute
+)
+
+// EnqueueArtifactPushWebhooks queues a delivery of the push event for every webhook of the organization that matches
+// the artifact. It should be called in the same transaction that creates the tag, so that no event is lost or sent
+// for a push that was rolled back. It returns true if at least one delivery was queued.
+func EnqueueArtifactPushWebhooks(
+	ctx context.Context,
+	orgID uuid.UUID,
+	orgName, artifactName, tag string,
+	digest types.Digest,
+	userID uuid.UUID,
+	userEmail string,
+) (bool, error) {
+	webhooks, err := db.GetArtifactPushWebhooks(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+
+	event := types.ArtifactPushEvent{
+		Event:          "artifact.pushed",
+		OrganizationID: orgID,
+		Artifact:       orgName + "/" + artifactName,
+		Tag:            tag,
+		Digest:         digest,
+		Timestamp:      time.Now().UTC(),
+	}
+	event.PushedBy.ID = userID
+	event.PushedBy.Email = userEmail
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, err
+	}
+
+	queued := false
+	for _, webhook := range webhooks {
+		if !webhook.Matches(artifactName) {
+			continue
+		} else if err := db.CreateArtifactPushWebhookDelivery(ctx, webhook.ID, payload); err != nil {
+			return false, err
+		}
+		queued = true
+	}
+	return queued, nil
+}
+
+// DeliverArtifactPushWebhooksAsync delivers all due artifact push webhooks in the background, so that pushes are not
+// delayed by slow webhook receivers. Failed deliveries are retried by RunArtifactPushWebhookDeliveries.
+func DeliverArtifactPushWebhooksAsync(ctx context.Context) {
+	go func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, artifactPushWebhookAsyncTimeout)
+		defer cancel()
+
+		if err := RunArtifactPushWebhookDeliveries(ctx); err != nil {
+			sentry.CaptureException(err)
+			internalctx.GetLogger(ctx).Error("failed to deliver artifact push webhooks", zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+// RunArtifactPushWebhookDeliveries attempts all due artifact push webhook deliveries. Failed deliveries are retried
+// with exponential backoff until artifactPushWebhookMaxAttempts is reached. The result of every attempt is recorded.
+func RunArtifactPushWebhookDeliveries(ctx context.Context) error {
+	log := internalctx.GetLogger(ctx)
+
+	var count int
+	for {
+		deliveries, err := db.ClaimDueArtifactPushWebhookDeliveries(
+			ctx, artifactPushWebhookBatchSize, artifactPushWebhookLease,
+		)
+		if err != nil {
+			return err
+		} else if len(deliveries) == 0 {
+			break
+		}
+
+		for _, delivery := range deliveries {
+			log := log.With(zap.Stringer("deliveryId", delivery.ID), zap.Stringer("webhookId", delivery.WebhookID))
+
+			statusCode, deliveryErr := sendArtifactPushWebhook(ctx, delivery)
+			var nextAttemptAt *time.Time
+			if deliveryErr != nil {
+				log.Warn("artifact push webhook delivery failed", zap.Error(deliveryErr))
+				if attempts := delivery.Attempts + 1; attempts < artifactPushWebhookMaxAttempts {
+					nextAttemptAt = util.PtrTo(time.Now().Add(artifactPushWebhookBackoff(attempts)))
+				}
+			} else {
+				count++
+			}
+
+			if err := db.UpdateArtifactPushWebhookDeliveryAttempt(
+				ctx, delivery.ID, statusCode, deliveryErr, nextAttemptAt,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info("artifact push webhooks delivered", zap.Int("count", count))
+	return nil
+}
+
+// artifactPushWebhookBackoff returns the delay before the next attempt after the given number of failed attempts.
+func artifactPushWebhookBackoff(attempts int) time.Duration {
+	backoff := artifactPushWebhookBaseBackoff
+	for range attempts - 1 {
+		if backoff *= 2; backoff >= artifactPushWebhookMaxBackoff {
+			return artifactPushWebhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// sendArtifactPushWebhook posts the payload of the delivery to its webhook. The payload is signed with an HMAC-SHA256
+// of the webhook secret, which is sent in the X-Distr-Signature header. Receivers can use the X-Distr-Delivery header
+// to detect duplicates, because a delivery may be attempted again if recording its result fails.
+func sendArtifactPushWebhook(
+	ctx context.Context,
+	delivery types.ArtifactPushWebhookDeliveryWithTarget,
+) (*int, error) {
+	mac := hmac.New(sha256.New, []byte(delivery.Secret))
+	mac.Write(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Distr-Event", "artifact.pushed")
+	req.Header.Set("X-Distr-Delivery", delivery.ID.String())
+	req.Header.Set("X-Distr-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &resp.StatusCode, fmt.Errorf("webhook responded with unexpected status code %v", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
@@ -0,0 +1,22 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestArtifactPushWebhookBackoff(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(artifactPushWebhookBackoff(1)).To(Equal(artifactPushWebhookBaseBackoff))
+	g.Expect(artifactPushWebhookBackoff(2)).To(Equal(2 * time.Minute))
+	g.Expect(artifactPushWebhookBackoff(5)).To(Equal(16 * time.Minute))
+	g.Expect(artifactPushWebhookBackoff(9)).To(Equal(256 * time.Minute))
+	g.Expect(artifactPushWebhookBackoff(10)).To(Equal(artifactPushWebhookMaxBackoff))
+	g.Expect(artifactPushWebhookBackoff(100)).To(Equal(artifactPushWebhookMaxBackoff))
+
+	for attempts := 1; attempts < artifactPushWebhookMaxAttempts; attempts++ {
+		g.Expect(artifactPushWebhookBackoff(attempts + 1)).To(BeNumerically(">=", artifactPushWebhookBackoff(attempts)))
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/distr-sh/distr/internal/apierrors"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/mailsending"
 	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
 	"go.uber.org/zap"
 )
 
@@ -52,6 +56,12 @@ func SendDeploymentStatusNotifications(
 	return nil
 }
 
+type staleDeploymentNotification struct {
+	deploymentTarget types.DeploymentTargetFull
+	deployment       types.DeploymentWithLatestRevision
+	config           types.AlertConfiguration
+}
+
 func RunDeploymentStatusNotifications(ctx context.Context) error {
 	log := internalctx.GetLogger(ctx)
 
@@ -62,6 +72,8 @@ func RunDeploymentStatusNotifications(ctx context.Context) error {
 		return fmt.Errorf("failed to get all configs: %w", err)
 	}
 
+	var aggErr error
+	var notifications []staleDeploymentNotification
 	for _, config := range configs {
 		log := log.With(zap.Stringer("configId", config.ID))
 		if !config.Enabled {
@@ -69,38 +81,98 @@ func RunDeploymentStatusNotifications(ctx context.Context) error {
 			continue
 		}
 
+		if !config.Digest && config.InQuietHours(time.Now()) {
+			log.Debug("skip config during quiet hours")
+			continue
+		}
+
 		for _, deploymentTargetID := range config.DeploymentTargetIDs {
 			log := log.With(zap.Stringer("deploymentTargetId", deploymentTargetID))
 			deploymentTarget, err := db.GetDeploymentTarget(ctx, deploymentTargetID, nil)
 			if err != nil {
-				return fmt.Errorf("failed to get deployment target: %w", err)
+				log.Warn("failed to get deployment target", zap.Error(err))
+				aggErr = errors.Join(aggErr, fmt.Errorf("failed to get deployment target: %w", err))
+				continue
 			}
 
 			for _, deployment := range deploymentTarget.Deployments {
 				log := log.With(zap.Stringer("deploymentId", deployment.ID))
-				ctx := internalctx.WithLogger(ctx, log)
 				if deployment.LatestStatus == nil {
 					log.Debug("skip deployment with no status")
 					continue
 				}
 
-				if !deployment.LatestStatus.IsStale() {
+				if !deployment.LatestStatus.IsStale(env.DeploymentStatusStaleDuration()) {
 					log.Debug("skip deployment with latest status not stale")
 					continue
 				}
 
-				if err := sendDeploymentStatusNotificationsWithConfig(
-					ctx, *deploymentTarget, deployment, deployment.LatestStatus, nil, config,
-				); err != nil {
-					return fmt.Errorf("failed to send deployment status notifications with config: %w", err)
-				}
+				notifications = append(notifications, staleDeploymentNotification{
+					deploymentTarget: *deploymentTarget,
+					deployment:       deployment,
+					config:           config,
+				})
 			}
 		}
 	}
 
-	log.Info("stale status notifications sent")
+	aggErr = errors.Join(aggErr, sendStaleDeploymentNotifications(ctx, notifications,
+		env.DeploymentStatusNotificationConcurrency()))
 
-	return nil
+	log.Info("stale status notifications sent", zap.Int("count", len(notifications)))
+
+	return aggErr
+}
+
+// sendStaleDeploymentNotifications sends the given notifications with at most concurrency of them in flight.
+// A failed notification does not prevent the others from being sent. Once ctx is done, no further notifications are
+// started.
+func sendStaleDeploymentNotifications(
+	ctx context.Context,
+	notifications []staleDeploymentNotification,
+	concurrency int,
+) error {
+	log := internalctx.GetLogger(ctx)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var aggErr error
+	sem := make(chan struct{}, max(concurrency, 1))
+
+	for _, n := range notifications {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			aggErr = errors.Join(aggErr, fmt.Errorf("stopped sending notifications: %w", err))
+			mu.Unlock()
+			break
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			log := log.With(
+				zap.Stringer("configId", n.config.ID),
+				zap.Stringer("deploymentTargetId", n.deploymentTarget.ID),
+				zap.Stringer("deploymentId", n.deployment.ID),
+			)
+			ctx := internalctx.WithLogger(ctx, log)
+			if err := sendDeploymentStatusNotificationsWithConfig(
+				ctx, n.deploymentTarget, n.deployment, n.deployment.LatestStatus, nil, n.config,
+			); err != nil {
+				log.Warn("failed to send deployment status notifications with config", zap.Error(err))
+				mu.Lock()
+				aggErr = errors.Join(aggErr, err)
+				mu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+	return aggErr
 }
 
 func sendDeploymentStatusNotificationsWithConfig(
@@ -130,18 +202,35 @@ func sendDeploymentStatusNotificationsWithConfig(
 		}
 	}
 
-	if currentStatus == nil {
+	transition, ok := alertTransition(previousStatus, currentStatus)
+	if !ok {
+		log.Debug("skip notifications because the status change is not a transition")
+		return nil
+	} else if !config.NotifiesOn(transition) {
+		log.Debug("skip notifications because transition is not enabled", zap.String("transition", string(transition)))
+		return nil
+	}
+
+	// Suppressed stale notifications are not recorded, so they are sent by the first job run after the quiet hours.
+	// Digests are queued regardless, since quiet hours are applied when the digest is sent.
+	if !config.Digest && config.InQuietHours(time.Now()) &&
+		(transition != types.AlertTransitionError || !config.QuietHoursAllowErrors) {
+		log.Debug("skip notifications during quiet hours", zap.String("transition", string(transition)))
+		return nil
+	}
+
+	switch transition {
+	case types.AlertTransitionStale:
 		if existingRecord != nil {
 			log.Debug("skip stale notifications because it was already sent")
 			return nil
 		}
-	} else if shouldNotifyError(previousStatus, *currentStatus) ||
-		shouldNotifyErrorRecovered(previousStatus, *currentStatus) {
+	case types.AlertTransitionError, types.AlertTransitionErrorRecovered:
 		if existingRecord != nil && existingRecord.CurrentDeploymentRevisionStatusID != nil {
 			log.Debug("skip error/recovery notifications because it was already sent")
 			return nil
 		}
-	} else {
+	case types.AlertTransitionStaleRecovered:
 		if existingRecord == nil {
 			log.Debug("skip stale-recovery notifications because no previous stale notification was sent")
 			return nil
@@ -149,43 +238,49 @@ func sendDeploymentStatusNotificationsWithConfig(
 	}
 
 	var aggErr error
-	for _, user := range config.UserAccounts {
-		log := log.With(zap.Stringer("userId", user.ID))
-		log.Info("send notification")
-		var err error
-		if currentStatus == nil {
-			err = mailsending.DeploymentStatusNotificationStale(
-				ctx,
-				user,
-				*organization,
-				deploymentTarget,
-				deployment,
-				*previousStatus,
-			)
-		} else if currentStatus.Type == types.DeploymentStatusTypeError {
-			err = mailsending.DeploymentStatusNotificationError(
-				ctx,
-				user,
-				*organization,
-				deploymentTarget,
-				deployment,
-				*currentStatus,
-			)
-		} else {
-			err = mailsending.DeploymentStatusNotificationRecovered(
-				ctx,
-				user,
-				*organization,
-				deploymentTarget,
-				deployment,
-				*currentStatus,
-			)
-		}
+	var deliveredAt *time.Time
+	if config.Digest {
+		log.Info("queue notification for digest")
+	} else {
+		for _, user := range config.UserAccounts {
+			log := log.With(zap.Stringer("userId", user.ID))
+			log.Info("send notification")
+			var err error
+			if currentStatus == nil {
+				err = mailsending.DeploymentStatusNotificationStale(
+					ctx,
+					user,
+					*organization,
+					deploymentTarget,
+					deployment,
+					*previousStatus,
+				)
+			} else if currentStatus.Type == types.DeploymentStatusTypeError {
+				err = mailsending.DeploymentStatusNotificationError(
+					ctx,
+					user,
+					*organization,
+					deploymentTarget,
+					deployment,
+					*currentStatus,
+				)
+			} else {
+				err = mailsending.DeploymentStatusNotificationRecovered(
+					ctx,
+					user,
+					*organization,
+					deploymentTarget,
+					deployment,
+					*currentStatus,
+				)
+			}
 
-		if err != nil {
-			log.Warn("notification sending failed", zap.Error(err))
-			aggErr = errors.Join(aggErr, err)
+			if err != nil {
+				log.Warn("notification sending failed", zap.Error(err))
+				aggErr = errors.Join(aggErr, err)
+			}
 		}
+		deliveredAt = util.PtrTo(time.Now())
 	}
 
 	record := types.NotificationRecord{
@@ -193,6 +288,7 @@ func sendDeploymentStatusNotificationsWithConfig(
 		CustomerOrganizationID: config.CustomerOrganizationID,
 		DeploymentTargetID:     &deploymentTarget.ID,
 		AlertConfigurationID:   &config.ID,
+		DeliveredAt:            deliveredAt,
 	}
 
 	if currentStatus != nil {
@@ -221,14 +317,15 @@ func shouldNotifyError(
 	return currentStatus.Type == types.DeploymentStatusTypeError &&
 		(previousStatus == nil ||
 			previousStatus.Type != types.DeploymentStatusTypeError ||
-			previousStatus.IsStale())
+			previousStatus.IsStale(env.DeploymentStatusStaleDuration()))
 }
 
 func shouldNotifyStaleRecovered(
 	previousStatus *types.DeploymentRevisionStatus,
 	currentStatus types.DeploymentRevisionStatus,
 ) bool {
-	return previousStatus != nil && previousStatus.IsStale() && currentStatus.Type != types.DeploymentStatusTypeError
+	return previousStatus != nil && previousStatus.IsStale(env.DeploymentStatusStaleDuration()) &&
+		currentStatus.Type != types.DeploymentStatusTypeError
 }
 
 func shouldNotifyErrorRecovered(
@@ -241,8 +338,28 @@ func shouldNotifyErrorRecovered(
 		currentStatus.Type != types.DeploymentStatusTypeProgressing
 }
 
+// alertTransition returns the kind of transition from previousStatus to currentStatus. A nil currentStatus means that
+// previousStatus has become stale. The second return value is false if the change is not a transition that alerts can
+// be configured for, e.g. from one healthy status to the next.
+func alertTransition(
+	previousStatus *types.DeploymentRevisionStatus,
+	currentStatus *types.DeploymentRevisionStatus,
+) (types.AlertTransition, bool) {
+	switch {
+	case currentStatus == nil:
+		return types.AlertTransitionStale, true
+	case shouldNotifyError(previousStatus, *currentStatus):
+		return types.AlertTransitionError, true
+	case shouldNotifyErrorRecovered(previousStatus, *currentStatus):
+		return types.AlertTransitionErrorRecovered, true
+	case shouldNotifyStaleRecovered(previousStatus, *currentStatus):
+		return types.AlertTransitionStaleRecovered, true
+	default:
+		return "", false
+	}
+}
+
 func shouldNotify(previousStatus *types.DeploymentRevisionStatus, currentStatus types.DeploymentRevisionStatus) bool {
-	return shouldNotifyError(previousStatus, currentStatus) ||
-		shouldNotifyStaleRecovered(previousStatus, currentStatus) ||
-		shouldNotifyErrorRecovered(previousStatus, currentStatus)
+	_, ok := alertTransition(previousStatus, &currentStatus)
+	return ok
 }
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/env"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestAlertTransition(t *testing.T) {
+	status := func(statusType types.DeploymentStatusType, stale bool) *types.DeploymentRevisionStatus {
+		s := &types.DeploymentRevisionStatus{Type: statusType, CreatedAt: time.Now()}
+		if stale {
+			s.CreatedAt = s.CreatedAt.Add(-2 * env.DeploymentStatusStaleDuration())
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		previous *types.DeploymentRevisionStatus
+		current  *types.DeploymentRevisionStatus
+		expected types.AlertTransition
+		ok       bool
+	}{
+		{"stale", status(types.DeploymentStatusTypeHealthy, true), nil, types.AlertTransitionStale, true},
+		{"first error", nil, status(types.DeploymentStatusTypeError, false), types.AlertTransitionError, true},
+		{
+			"healthy to error",
+			status(types.DeploymentStatusTypeHealthy, false),
+			status(types.DeploymentStatusTypeError, false),
+			types.AlertTransitionError,
+			true,
+		},
+		{
+			"stale error to error",
+			status(types.DeploymentStatusTypeError, true),
+			status(types.DeploymentStatusTypeError, false),
+			types.AlertTransitionError,
+			true,
+		},
+		{
+			"error to healthy",
+			status(types.DeploymentStatusTypeError, false),
+			status(types.DeploymentStatusTypeHealthy, false),
+			types.AlertTransitionErrorRecovered,
+			true,
+		},
+		{
+			"stale error to healthy",
+			status(types.DeploymentStatusTypeError, true),
+			status(types.DeploymentStatusTypeHealthy, false),
+			types.AlertTransitionErrorRecovered,
+			true,
+		},
+		{
+			"stale to healthy",
+			status(types.DeploymentStatusTypeHealthy, true),
+			status(types.DeploymentStatusTypeHealthy, false),
+			types.AlertTransitionStaleRecovered,
+			true,
+		},
+		{
+			"stale to progressing",
+			status(types.DeploymentStatusTypeHealthy, true),
+			status(types.DeploymentStatusTypeProgressing, false),
+			types.AlertTransitionStaleRecovered,
+			true,
+		},
+		{"first healthy", nil, status(types.DeploymentStatusTypeHealthy, false), "", false},
+		{
+			"healthy to healthy",
+			status(types.DeploymentStatusTypeHealthy, false),
+			status(types.DeploymentStatusTypeHealthy, false),
+			"",
+			false,
+		},
+		{
+			"error to error",
+			status(types.DeploymentStatusTypeError, false),
+			status(types.DeploymentStatusTypeError, false),
+			"",
+			false,
+		},
+		{
+			"error to progressing",
+			status(types.DeploymentStatusTypeError, false),
+			status(types.DeploymentStatusTypeProgressing, false),
+			"",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			transition, ok := alertTransition(tt.previous, tt.current)
+			g.Expect(ok).To(Equal(tt.ok))
+			g.Expect(transition).To(Equal(tt.expected))
+			if tt.current != nil {
+				g.Expect(shouldNotify(tt.previous, *tt.current)).To(Equal(tt.ok))
+			}
+		})
+	}
+}
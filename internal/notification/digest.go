@@ -0,0 +1,96 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/mailsending"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RunAlertDigests sends a summary of all pending notifications to the users of each digest alert configuration and
+// marks the notifications as delivered. Configurations that are currently in quiet hours are skipped, so their
+// notifications are included in the next digest.
+func RunAlertDigests(ctx context.Context) error {
+	log := internalctx.GetLogger(ctx)
+
+	configs, err := db.GetAlertConfigurationsForAllOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get all configs: %w", err)
+	}
+
+	var aggErr error
+	var count int
+	for _, config := range configs {
+		log := log.With(zap.Stringer("configId", config.ID))
+		if !config.Digest || !config.Enabled {
+			continue
+		}
+
+		if config.InQuietHours(time.Now()) {
+			log.Debug("skip digest during quiet hours")
+			continue
+		}
+
+		ctx := internalctx.WithLogger(ctx, log)
+		if sent, err := sendAlertDigest(ctx, config); err != nil {
+			log.Warn("failed to send digest", zap.Error(err))
+			aggErr = errors.Join(aggErr, err)
+		} else if sent {
+			count++
+		}
+	}
+
+	log.Info("alert digests sent", zap.Int("count", count))
+
+	return aggErr
+}
+
+func sendAlertDigest(ctx context.Context, config types.AlertConfiguration) (bool, error) {
+	log := internalctx.GetLogger(ctx)
+
+	records, err := db.GetPendingNotificationRecords(ctx, config.ID)
+	if err != nil {
+		return false, err
+	} else if len(records) == 0 {
+		log.Debug("skip digest without pending notifications")
+		return false, nil
+	}
+
+	organization, err := db.GetOrganizationByID(ctx, config.OrganizationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	var aggErr error
+	for _, user := range config.UserAccounts {
+		log := log.With(zap.Stringer("userId", user.ID))
+		log.Info("send digest", zap.Int("notifications", len(records)))
+		if err := mailsending.AlertDigest(ctx, user, *organization, config, records); err != nil {
+			log.Warn("digest sending failed", zap.Error(err))
+			aggErr = errors.Join(aggErr, err)
+		}
+	}
+
+	ids := make([]uuid.UUID, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+
+	var message string
+	if aggErr != nil {
+		message = aggErr.Error()
+	}
+
+	if err := db.MarkNotificationRecordsDelivered(ctx, ids, message); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
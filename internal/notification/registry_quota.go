@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/mailsending"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// registryQuotaExceededInterval is the minimum time between two quota exceeded notifications for the same
+	// organization. Rejected pushes are usually retried many times, so notifying on every rejection would be spam.
+	registryQuotaExceededInterval = 1 * time.Hour
+)
+
+type registryQuotaExceededWebhookPayload struct {
+	Event          string                  `json:"event"`
+	OrganizationID uuid.UUID               `json:"organizationId"`
+	Quota          types.RegistryQuotaKind `json:"quota"`
+	Usage          types.RegistryQuota     `json:"usage"`
+	Timestamp      time.Time               `json:"timestamp"`
+}
+
+// SendRegistryQuotaExceededNotifications notifies the given organization that a registry push was rejected because
+// the given quota was exceeded. Depending on the organization settings, a webhook is called and/or an email is sent to
+// all vendor admins. Notifications are deduplicated so that at most one is sent per organization and interval.
+func SendRegistryQuotaExceededNotifications(
+	ctx context.Context,
+	orgID uuid.UUID,
+	kind types.RegistryQuotaKind,
+) error {
+	log := internalctx.GetLogger(ctx).With(zap.Stringer("orgId", orgID), zap.String("quota", string(kind)))
+
+	organization, err := db.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	if organization.QuotaExceededWebhookURL == nil && !organization.QuotaExceededEmailEnabled {
+		log.Debug("quota exceeded notifications not configured")
+		return nil
+	}
+
+	if ok, err := db.ClaimQuotaExceededNotification(ctx, orgID, registryQuotaExceededInterval); err != nil {
+		return err
+	} else if !ok {
+		log.Debug("skip quota exceeded notification because one was sent recently")
+		return nil
+	}
+
+	// sent is true if at least one notification was delivered. Otherwise, the claim is released below so that a
+	// failing webhook or mail server does not suppress the notification for the whole interval.
+	sent := false
+	defer func() {
+		if !sent {
+			if err := db.ReleaseQuotaExceededNotification(context.WithoutCancel(ctx), orgID); err != nil {
+				log.Warn("could not release quota exceeded notification", zap.Error(err))
+			}
+		}
+	}()
+
+	quota, err := db.GetRegistryQuota(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get registry quota: %w", err)
+	}
+
+	var aggErr error
+
+	if organization.QuotaExceededWebhookURL != nil {
+		log.Info("send quota exceeded webhook")
+		if err := sendWebhook(ctx, *organization.QuotaExceededWebhookURL,
+			registryQuotaExceededWebhookPayload{
+				Event:          "registry.quota_exceeded",
+				OrganizationID: orgID,
+				Quota:          kind,
+				Usage:          *quota,
+				Timestamp:      time.Now().UTC(),
+			},
+		); err != nil {
+			log.Warn("webhook sending failed", zap.Error(err))
+			aggErr = errors.Join(aggErr, err)
+		} else {
+			sent = true
+		}
+	}
+
+	if organization.QuotaExceededEmailEnabled {
+		users, err := db.GetUserAccountsByOrgID(ctx, orgID)
+		if err != nil {
+			return errors.Join(aggErr, fmt.Errorf("failed to get user accounts: %w", err))
+		}
+
+		for _, user := range users {
+			if user.CustomerOrganizationID != nil || user.UserRole != types.UserRoleAdmin {
+				continue
+			}
+
+			log := log.With(zap.Stringer("userId", user.ID))
+			log.Info("send quota exceeded notification")
+			if err := mailsending.RegistryQuotaExceeded(
+				ctx, user.AsUserAccount(), *organization, kind, *quota,
+			); err != nil {
+				log.Warn("notification sending failed", zap.Error(err))
+				aggErr = errors.Join(aggErr, err)
+			} else {
+				sent = true
+			}
+		}
+	}
+
+	return aggErr
+}
+
+func sendWebhook(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with unexpected status code %v", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/mailsending"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TestChannel string
+
+const (
+	TestChannelEmail   TestChannel = "email"
+	TestChannelWebhook TestChannel = "webhook"
+)
+
+// ErrTestNotificationFailed is returned in a [TestResult] instead of the actual error, which is only logged. The
+// actual error may contain details about the mail server or the webhook endpoint that must not be shown to users.
+var ErrTestNotificationFailed = errors.New("the notification could not be sent, please check the channel configuration")
+
+type TestResult struct {
+	Channel TestChannel
+	Err     error
+}
+
+type testWebhookPayload struct {
+	Event          string    `json:"event"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// SendTestNotifications sends a sample notification through every channel configured for the organization, using the
+// same senders as real notifications. The email is a deployment status notification for a synthetic deployment and is
+// only sent to the given user instead of all recipients of real notifications, so that a test does not spam the whole
+// organization. Failed channels report [ErrTestNotificationFailed].
+func SendTestNotifications(
+	ctx context.Context,
+	organization types.OrganizationWithBranding,
+	user types.UserAccount,
+) []TestResult {
+	log := internalctx.GetLogger(ctx).With(zap.Stringer("orgId", organization.ID))
+
+	results := []TestResult{{
+		Channel: TestChannelEmail,
+		Err:     sendTestMail(ctx, organization.Organization, user),
+	}}
+
+	if organization.QuotaExceededWebhookURL != nil {
+		results = append(results, TestResult{
+			Channel: TestChannelWebhook,
+			Err: sendWebhook(ctx, *organization.QuotaExceededWebhookURL, testWebhookPayload{
+				Event:          "notification.test",
+				OrganizationID: organization.ID,
+				Timestamp:      time.Now().UTC(),
+			}),
+		})
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			log.Warn("test notification failed", zap.String("channel", string(result.Channel)), zap.Error(result.Err))
+			results[i].Err = ErrTestNotificationFailed
+		}
+	}
+
+	return results
+}
+
+func sendTestMail(ctx context.Context, organization types.Organization, user types.UserAccount) error {
+	deploymentTarget := types.DeploymentTargetFull{
+		DeploymentTarget: types.DeploymentTarget{Name: "Test deployment target"},
+	}
+	deployment := types.DeploymentWithLatestRevision{
+		Application:            types.Application{Name: "Test application"},
+		ApplicationVersionName: "1.0.0",
+	}
+	status := types.DeploymentRevisionStatus{
+		CreatedAt: time.Now().UTC(),
+		Type:      types.DeploymentStatusTypeError,
+		Message:   "This is a test notification. No action is required.",
+	}
+	return mailsending.DeploymentStatusNotificationError(ctx, user, organization, deploymentTarget, deployment, status)
+}
@@ -0,0 +1,13 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/distr-sh/distr/internal/publicnet"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// webhookClient is used for all webhooks that organizations configure. It only connects to public addresses, so that
+// webhook URLs can not be used to reach services in the internal network of the hub.
+var webhookClient = publicnet.NewHTTPClient(webhookTimeout)
@@ -21,32 +21,90 @@ const (
 	ProviderGithub    Provider = "github"
 	ProviderGoogle    Provider = "google"
 	ProviderMicrosoft Provider = "microsoft"
+	ProviderOkta      Provider = "okta"
+	ProviderGitlab    Provider = "gitlab"
 	ProviderGeneric   Provider = "generic"
 )
 
 type EmailExtractorFunc func(context.Context, *oauth2.Token) (string, bool, error)
 
+type emailClaims struct {
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+}
+
 func verifiedIdTokenEmailExtractor(verifier *oidc.IDTokenVerifier) EmailExtractorFunc {
 	return func(ctx context.Context, token *oauth2.Token) (string, bool, error) {
-		idTokenStr, ok := token.Extra("id_token").(string)
-		if !ok {
-			return "", false, fmt.Errorf("id_token not found in token response")
+		_, claims, err := verifyIdToken(ctx, verifier, token)
+		if err != nil {
+			return "", false, err
 		}
-		idToken, err := verifier.Verify(ctx, idTokenStr)
+		return claims.Email, claims.EmailVerified != nil && *claims.EmailVerified, nil
+	}
+}
+
+// idTokenWithUserInfoEmailExtractor works like verifiedIdTokenEmailExtractor, but falls back to the userinfo endpoint
+// if the ID token does not contain the email address or, in case email verification is required, the email_verified
+// claim. Some identity providers (e.g. Keycloak, depending on the client scopes) only include these claims in the
+// userinfo response. If forceUserInfo is true, the userinfo endpoint is always queried.
+func idTokenWithUserInfoEmailExtractor(
+	provider *oidc.Provider,
+	verifier *oidc.IDTokenVerifier,
+	forceUserInfo bool,
+) EmailExtractorFunc {
+	return func(ctx context.Context, token *oauth2.Token) (string, bool, error) {
+		idToken, claims, err := verifyIdToken(ctx, verifier, token)
 		if err != nil {
-			return "", false, fmt.Errorf("failed to verify id_token: %w", err)
+			return "", false, err
 		}
-		var claims struct {
-			Email         string `json:"email"`
-			EmailVerified bool   `json:"email_verified"`
+
+		if forceUserInfo || claims.Email == "" ||
+			(claims.EmailVerified == nil && env.UserEmailVerificationRequired()) {
+			userInfo, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+			if err != nil {
+				return "", false, fmt.Errorf("failed to get userinfo: %w", err)
+			} else if userInfo.Subject != idToken.Subject {
+				return "", false, fmt.Errorf("userinfo subject does not match id_token subject")
+			}
+			var userInfoClaims emailClaims
+			if err := userInfo.Claims(&userInfoClaims); err != nil {
+				return "", false, fmt.Errorf("failed to parse userinfo claims: %w", err)
+			}
+			if userInfoClaims.Email != "" && userInfoClaims.Email != claims.Email {
+				// the verification status of the ID token refers to a different address
+				claims = &userInfoClaims
+			} else if userInfoClaims.EmailVerified != nil {
+				claims.EmailVerified = userInfoClaims.EmailVerified
+			}
 		}
-		if err := idToken.Claims(&claims); err != nil {
-			return "", false, fmt.Errorf("failed to parse id_token claims: %w", err)
+
+		if claims.Email == "" {
+			return "", false, fmt.Errorf("email not found in id_token or userinfo")
 		}
-		return claims.Email, claims.EmailVerified, nil
+		return claims.Email, claims.EmailVerified != nil && *claims.EmailVerified, nil
 	}
 }
 
+func verifyIdToken(
+	ctx context.Context,
+	verifier *oidc.IDTokenVerifier,
+	token *oauth2.Token,
+) (*oidc.IDToken, *emailClaims, error) {
+	idTokenStr, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("id_token not found in token response")
+	}
+	idToken, err := verifier.Verify(ctx, idTokenStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+	var claims emailClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+	return idToken, &claims, nil
+}
+
 type providerContext struct {
 	oauth2Config   func(r *http.Request) *config
 	emailExtractor EmailExtractorFunc
@@ -90,6 +148,52 @@ func NewOIDCer(ctx context.Context, log *zap.Logger) (*OIDCer, error) {
 			emailExtractor: verifiedIdTokenEmailExtractor(microsoftVerifier),
 		}
 	}
+	if env.OIDCOktaEnabled() {
+		log.Info("initializing okta OIDC")
+		oktaProvider, err := oidc.NewProvider(ctx, fmt.Sprintf("https://%v", *env.OIDCOktaDomain()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Okta OIDC provider: %w", err)
+		}
+		oktaVerifier := oktaProvider.Verifier(&oidc.Config{ClientID: *env.OIDCOktaClientID()})
+		p[ProviderOkta] = &providerContext{
+			oauth2Config: func(r *http.Request) *config {
+				return &config{
+					Config: oauth2.Config{
+						ClientID:     *env.OIDCOktaClientID(),
+						ClientSecret: *env.OIDCOktaClientSecret(),
+						RedirectURL:  getRedirectURL(r, ProviderOkta),
+						Endpoint:     oktaProvider.Endpoint(),
+						Scopes:       []string{oidc.ScopeOpenID, "email"},
+					},
+					pkceEnabled: true,
+				}
+			},
+			emailExtractor: idTokenWithUserInfoEmailExtractor(oktaProvider, oktaVerifier, false),
+		}
+	}
+	if env.OIDCGitlabEnabled() {
+		log.Info("initializing gitlab OIDC")
+		gitlabProvider, err := oidc.NewProvider(ctx, env.OIDCGitlabBaseURL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize GitLab OIDC provider: %w", err)
+		}
+		gitlabVerifier := gitlabProvider.Verifier(&oidc.Config{ClientID: *env.OIDCGitlabClientID()})
+		p[ProviderGitlab] = &providerContext{
+			oauth2Config: func(r *http.Request) *config {
+				return &config{
+					Config: oauth2.Config{
+						ClientID:     *env.OIDCGitlabClientID(),
+						ClientSecret: *env.OIDCGitlabClientSecret(),
+						RedirectURL:  getRedirectURL(r, ProviderGitlab),
+						Endpoint:     gitlabProvider.Endpoint(),
+						Scopes:       []string{oidc.ScopeOpenID, "email"},
+					},
+					pkceEnabled: true,
+				}
+			},
+			emailExtractor: idTokenWithUserInfoEmailExtractor(gitlabProvider, gitlabVerifier, false),
+		}
+	}
 	if env.OIDCGithubEnabled() {
 		log.Info("initializing github OIDC")
 		p[ProviderGithub] = &providerContext{
@@ -118,7 +222,9 @@ func NewOIDCer(ctx context.Context, log *zap.Logger) (*OIDCer, error) {
 					pkceEnabled: env.OIDCGenericPKCEEnabled(),
 				}
 			},
-			emailExtractor: verifiedIdTokenEmailExtractor(genericVerifier),
+			emailExtractor: idTokenWithUserInfoEmailExtractor(
+				genericProvider, genericVerifier, env.OIDCGenericUseUserInfo(),
+			),
 		}
 	}
 	return &OIDCer{providers: p}, nil
@@ -0,0 +1,72 @@
+// Package publicnet provides network primitives for outgoing requests to user supplied URLs, like webhooks. Such
+// requests must not be able to reach the internal network of the hub, so only public addresses may be dialed.
+package publicnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrNotPublic = errors.New("address is not public")
+
+// nonPublicPrefixes are special purpose ranges that are not covered by the methods of [netip.Addr].
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublic reports whether addr is a globally routable unicast address. Loopback, private, link-local, multicast and
+// other special purpose addresses are not public.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewDialer returns a [net.Dialer] that refuses to connect to addresses that are not public. The check happens after
+// the host name has been resolved, so it cannot be circumvented with DNS records pointing to internal addresses.
+func NewDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if addrPort, err := netip.ParseAddrPort(address); err != nil {
+				return fmt.Errorf("%w: %v", ErrNotPublic, address)
+			} else if !IsPublic(addrPort.Addr()) {
+				return fmt.Errorf("%w: %v", ErrNotPublic, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+}
+
+// NewHTTPClient returns an [http.Client] with the given timeout that only connects to public addresses, see
+// [NewDialer]. Proxy settings from the environment are ignored, because the proxy itself is usually not public.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = NewDialer(timeout).DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -2,37 +2,184 @@ package audit
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/env"
+	registryerror "github.com/distr-sh/distr/internal/registry/error"
 	"github.com/distr-sh/distr/internal/registry/name"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type ArtifactAuditor interface {
 	AuditPull(ctx context.Context, name, reference string) error
+	// AuditPullWithinLimit is like AuditPull, but for customers of organizations with licensing it first checks the
+	// pull limits of their licenses in the same transaction that records the pull. It returns
+	// apierrors.ErrPullLimitExceeded without recording the pull if the limits are exhausted.
+	AuditPullWithinLimit(ctx context.Context, name, reference string) error
 }
 
-type auditor struct{}
+type pullEntry struct {
+	name          string
+	reference     string
+	userID        uuid.UUID
+	customerOrgID *uuid.UUID
+	remoteAddress string
+	pulledAt      time.Time
+	// checkPullLimit is only set for the first attempt. Pulls queued for retry have been served already.
+	checkPullLimit bool
+}
+
+type auditor struct {
+	retryQueue chan pullEntry
+	record     func(ctx context.Context, entry pullEntry) error
+}
 
 func NewAuditor() ArtifactAuditor {
-	return &auditor{}
+	return &auditor{record: recordPull}
+}
+
+// NewRetryingAuditor returns an auditor that keeps pulls which could not be recorded, for example because the
+// database is briefly unavailable, in a queue of the given size and retries them every interval until ctx is done. If
+// the queue is full, pulls are dropped. ctx must contain the database and logger used for retries.
+func NewRetryingAuditor(ctx context.Context, queueSize int, interval time.Duration) ArtifactAuditor {
+	return newRetryingAuditor(ctx, queueSize, interval, recordPull)
+}
+
+func newRetryingAuditor(
+	ctx context.Context,
+	queueSize int,
+	interval time.Duration,
+	record func(ctx context.Context, entry pullEntry) error,
+) ArtifactAuditor {
+	if queueSize <= 0 {
+		return &auditor{record: record}
+	}
+	a := &auditor{retryQueue: make(chan pullEntry, queueSize), record: record}
+	go a.retryLoop(ctx, interval)
+	return a
 }
 
-// AuditPull implements ArtifactAuditor.
+// AuditPull implements ArtifactAuditor. Pulls are not recorded while the instance is in read-only mode.
 func (a *auditor) AuditPull(ctx context.Context, nameStr string, reference string) error {
+	return a.audit(ctx, nameStr, reference, false)
+}
+
+// AuditPullWithinLimit implements ArtifactAuditor. Pull limits are not checked while the instance is in read-only
+// mode, because no pulls are recorded.
+func (a *auditor) AuditPullWithinLimit(ctx context.Context, nameStr string, reference string) error {
+	return a.audit(ctx, nameStr, reference, true)
+}
+
+func (a *auditor) audit(ctx context.Context, nameStr string, reference string, checkPullLimit bool) error {
+	if env.ReadOnlyMode() {
+		return nil
+	}
 	auth := auth.ArtifactsAuthentication.Require(ctx)
-	if name, err := name.Parse(nameStr); err != nil {
-		return err
-	} else if digestVersion, err := db.GetArtifactVersion(ctx, name.OrgName, name.ArtifactName, reference); err != nil {
+	entry := pullEntry{
+		name:          nameStr,
+		reference:     reference,
+		userID:        auth.CurrentUserID(),
+		customerOrgID: auth.CurrentCustomerOrgID(),
+		remoteAddress: internalctx.GetRequestIPAddress(ctx),
+		pulledAt:      time.Now(),
+		checkPullLimit: checkPullLimit && auth.CurrentCustomerOrgID() != nil &&
+			auth.CurrentOrg().HasFeature(types.FeatureLicensing),
+	}
+	if err := a.record(ctx, entry); err != nil {
+		if a.retryQueue == nil || !isRetryable(err) {
+			return err
+		}
+		entry.checkPullLimit = false
+		select {
+		case a.retryQueue <- entry:
+			return fmt.Errorf("pull queued for retry: %w", err)
+		default:
+			return fmt.Errorf("retry queue is full, pull dropped: %w", err)
+		}
+	}
+	return nil
+}
+
+func recordPull(ctx context.Context, entry pullEntry) error {
+	name, err := name.Parse(entry.name)
+	if err != nil {
 		return err
-	} else {
-		return db.CreateArtifactPullLogEntry(
-			ctx,
-			digestVersion.ID,
-			auth.CurrentUserID(),
-			internalctx.GetRequestIPAddress(ctx),
-			auth.CurrentCustomerOrgID(),
-		)
 	}
+	create := func(ctx context.Context) error {
+		if digestVersion, err := db.GetArtifactVersion(
+			ctx, name.OrgName, name.ArtifactName, entry.reference,
+		); err != nil {
+			return err
+		} else {
+			return db.CreateArtifactPullLogEntry(
+				ctx,
+				digestVersion.ID,
+				entry.userID,
+				entry.remoteAddress,
+				entry.customerOrgID,
+				entry.pulledAt,
+			)
+		}
+	}
+	if !entry.checkPullLimit {
+		return create(ctx)
+	}
+	return db.RunTx(ctx, func(ctx context.Context) error {
+		if err := db.EnsurePullLimitForInsert(ctx, name.OrgName, name.ArtifactName, *entry.customerOrgID); err != nil {
+			return err
+		}
+		return create(ctx)
+	})
+}
+
+func (a *auditor) retryLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.flush(ctx)
+		}
+	}
+}
+
+// flush retries all queued pulls. It stops at the first retryable error, because then the database is most likely
+// still unavailable.
+func (a *auditor) flush(ctx context.Context) {
+	log := internalctx.GetLogger(ctx)
+	for range len(a.retryQueue) {
+		entry := <-a.retryQueue
+		if err := a.record(ctx, entry); err == nil {
+			continue
+		} else if !isRetryable(err) {
+			log.Warn("dropping queued pull that can not be recorded", zap.Error(err))
+		} else {
+			select {
+			case a.retryQueue <- entry:
+			default:
+				log.Warn("retry queue is full, pull dropped", zap.Error(err))
+			}
+			log.Debug("retrying queued pulls failed", zap.Error(err), zap.Int("queued", len(a.retryQueue)))
+			return
+		}
+	}
+}
+
+// isRetryable reports whether recording a pull might succeed later. Pulls with an invalid name, of versions that do
+// not exist (anymore) or over the pull limit are never retried.
+func isRetryable(err error) bool {
+	return !errors.Is(err, apierrors.ErrNotFound) &&
+		!errors.Is(err, apierrors.ErrPullLimitExceeded) &&
+		!errors.Is(err, registryerror.ErrInvalidArtifactName) &&
+		!errors.Is(err, context.Canceled)
 }
@@ -0,0 +1,238 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
+	"github.com/distr-sh/distr/internal/authn/authinfo"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	registryerror "github.com/distr-sh/distr/internal/registry/error"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var errUnavailable = errors.New("database unavailable")
+
+type testAuthInfo struct{ authinfo.AuthInfo }
+
+func (testAuthInfo) CurrentUserID() uuid.UUID         { return uuid.Nil }
+func (testAuthInfo) CurrentCustomerOrgID() *uuid.UUID { return nil }
+func (testAuthInfo) CurrentOrg() *types.Organization  { return nil }
+
+// customerAuthInfo authenticates a customer of an organization with the given features.
+type customerAuthInfo struct {
+	testAuthInfo
+	customerOrgID uuid.UUID
+	features      []types.Feature
+}
+
+func (a customerAuthInfo) CurrentCustomerOrgID() *uuid.UUID { return &a.customerOrgID }
+func (a customerAuthInfo) CurrentOrg() *types.Organization {
+	return &types.Organization{Features: a.features}
+}
+
+// fakeRecorder records pulls in memory and fails with the errors queued in errs first.
+type fakeRecorder struct {
+	mu       sync.Mutex
+	errs     []error
+	recorded []string
+	entries  []pullEntry
+}
+
+func (r *fakeRecorder) fail(errs ...error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, errs...)
+}
+
+func (r *fakeRecorder) record(ctx context.Context, entry pullEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	if len(r.errs) > 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		return err
+	}
+	r.recorded = append(r.recorded, entry.reference)
+	return nil
+}
+
+func (r *fakeRecorder) pulls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.recorded...)
+}
+
+func testContext() context.Context {
+	ctx := internalctx.WithRequestIPAddress(internalctx.WithLogger(context.Background(), zap.NewNop()), "127.0.0.1")
+	return auth.ArtifactsAuthentication.NewContext(ctx, testAuthInfo{})
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{errUnavailable, true},
+		{context.DeadlineExceeded, true},
+		{apierrors.ErrNotFound, false},
+		{fmt.Errorf("could not get version: %w", apierrors.ErrNotFound), false},
+		{registryerror.ErrInvalidArtifactName, false},
+		{apierrors.ErrPullLimitExceeded, false},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			NewWithT(t).Expect(isRetryable(tt.err)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestNewRetryingAuditorWithoutQueue(t *testing.T) {
+	g := NewWithT(t)
+	recorder := &fakeRecorder{}
+	a := newRetryingAuditor(context.Background(), 0, time.Millisecond, recorder.record)
+	g.Expect(a.(*auditor).retryQueue).To(BeNil())
+
+	recorder.fail(errUnavailable)
+	g.Expect(a.AuditPull(testContext(), "org/app", "1.0.0")).To(MatchError(errUnavailable))
+	g.Expect(recorder.pulls()).To(BeEmpty())
+}
+
+func TestAuditPullQueue(t *testing.T) {
+	g := NewWithT(t)
+	ctx := testContext()
+	recorder := &fakeRecorder{}
+	a := &auditor{retryQueue: make(chan pullEntry, 1), record: recorder.record}
+
+	g.Expect(a.AuditPull(ctx, "org/app", "1")).To(Succeed())
+	g.Expect(recorder.pulls()).To(Equal([]string{"1"}))
+
+	recorder.fail(apierrors.ErrNotFound)
+	g.Expect(a.AuditPull(ctx, "org/app", "2")).To(MatchError(apierrors.ErrNotFound))
+	g.Expect(a.retryQueue).To(BeEmpty())
+
+	recorder.fail(errUnavailable, errUnavailable)
+	err := a.AuditPull(ctx, "org/app", "3")
+	g.Expect(err).To(MatchError(errUnavailable))
+	g.Expect(err).To(MatchError(ContainSubstring("queued for retry")))
+	err = a.AuditPull(ctx, "org/app", "4")
+	g.Expect(err).To(MatchError(errUnavailable))
+	g.Expect(err).To(MatchError(ContainSubstring("pull dropped")))
+	g.Expect(a.retryQueue).To(HaveLen(1))
+}
+
+func TestAuditPullWithinLimit(t *testing.T) {
+	licensing := []types.Feature{types.FeatureLicensing}
+	customerContext := func(features []types.Feature) context.Context {
+		return auth.ArtifactsAuthentication.NewContext(testContext(),
+			customerAuthInfo{customerOrgID: uuid.New(), features: features})
+	}
+
+	t.Run("checks the limit only for customers of organizations with licensing", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{record: recorder.record}
+		g.Expect(a.AuditPullWithinLimit(customerContext(licensing), "org/app", "1")).To(Succeed())
+		g.Expect(a.AuditPullWithinLimit(customerContext(nil), "org/app", "2")).To(Succeed())
+		g.Expect(a.AuditPullWithinLimit(testContext(), "org/app", "3")).To(Succeed())
+		g.Expect(a.AuditPull(customerContext(licensing), "org/app", "4")).To(Succeed())
+		g.Expect(recorder.entries).To(HaveLen(4))
+		g.Expect(recorder.entries[0].checkPullLimit).To(BeTrue())
+		g.Expect(recorder.entries[1].checkPullLimit).To(BeFalse())
+		g.Expect(recorder.entries[2].checkPullLimit).To(BeFalse())
+		g.Expect(recorder.entries[3].checkPullLimit).To(BeFalse())
+	})
+
+	t.Run("does not queue pulls over the limit", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{retryQueue: make(chan pullEntry, 1), record: recorder.record}
+		recorder.fail(apierrors.ErrPullLimitExceeded)
+		err := a.AuditPullWithinLimit(customerContext(licensing), "org/app", "1")
+		g.Expect(err).To(MatchError(apierrors.ErrPullLimitExceeded))
+		g.Expect(a.retryQueue).To(BeEmpty())
+	})
+
+	t.Run("retries queued pulls without the limit", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{retryQueue: make(chan pullEntry, 1), record: recorder.record}
+		recorder.fail(errUnavailable)
+		err := a.AuditPullWithinLimit(customerContext(licensing), "org/app", "1")
+		g.Expect(err).To(MatchError(errUnavailable))
+		g.Expect(a.retryQueue).To(HaveLen(1))
+		a.flush(testContext())
+		g.Expect(recorder.pulls()).To(Equal([]string{"1"}))
+		g.Expect(recorder.entries).To(HaveLen(2))
+		g.Expect(recorder.entries[0].checkPullLimit).To(BeTrue())
+		g.Expect(recorder.entries[1].checkPullLimit).To(BeFalse())
+	})
+}
+
+func TestFlush(t *testing.T) {
+	ctx := testContext()
+	queue := func(a *auditor, references ...string) {
+		for _, reference := range references {
+			a.retryQueue <- pullEntry{reference: reference}
+		}
+	}
+
+	t.Run("all recorded", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{retryQueue: make(chan pullEntry, 3), record: recorder.record}
+		queue(a, "1", "2", "3")
+		a.flush(ctx)
+		g.Expect(recorder.pulls()).To(Equal([]string{"1", "2", "3"}))
+		g.Expect(a.retryQueue).To(BeEmpty())
+	})
+
+	t.Run("stops at the first retryable error", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{retryQueue: make(chan pullEntry, 3), record: recorder.record}
+		queue(a, "1", "2", "3")
+		recorder.fail(errUnavailable)
+		a.flush(ctx)
+		g.Expect(recorder.pulls()).To(BeEmpty())
+		g.Expect(a.retryQueue).To(HaveLen(3))
+
+		a.flush(ctx)
+		g.Expect(recorder.pulls()).To(ConsistOf("1", "2", "3"))
+		g.Expect(a.retryQueue).To(BeEmpty())
+	})
+
+	t.Run("drops pulls that can not be recorded", func(t *testing.T) {
+		g := NewWithT(t)
+		recorder := &fakeRecorder{}
+		a := &auditor{retryQueue: make(chan pullEntry, 3), record: recorder.record}
+		queue(a, "1", "2", "3")
+		recorder.fail(apierrors.ErrNotFound)
+		a.flush(ctx)
+		g.Expect(recorder.pulls()).To(Equal([]string{"2", "3"}))
+		g.Expect(a.retryQueue).To(BeEmpty())
+	})
+}
+
+func TestNewRetryingAuditorRetries(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(testContext())
+	defer cancel()
+	recorder := &fakeRecorder{}
+	a := newRetryingAuditor(ctx, 10, 10*time.Millisecond, recorder.record)
+
+	recorder.fail(errUnavailable, errUnavailable)
+	g.Expect(a.AuditPull(ctx, "org/app", "1.0.0")).To(MatchError(errUnavailable))
+	g.Eventually(recorder.pulls).Should(Equal([]string{"1.0.0"}))
+	g.Expect(a.(*auditor).retryQueue).To(BeEmpty())
+}
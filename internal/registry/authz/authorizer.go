@@ -6,6 +6,7 @@ import (
 
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
+	"github.com/distr-sh/distr/internal/authn/authinfo"
 	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/registry/name"
 	"github.com/distr-sh/distr/internal/types"
@@ -48,9 +49,9 @@ func (a *authorizer) Authorize(ctx context.Context, nameStr string, action Actio
 		return err
 	} else if org.Slug == nil || *org.Slug != name.OrgName {
 		return ErrAccessDenied
+	} else {
+		return a.authorizeAccessRules(ctx, auth, *name, action)
 	}
-
-	return nil
 }
 
 // AuthorizeReference implements ArtifactsAuthorizer.
@@ -69,6 +70,8 @@ func (a *authorizer) AuthorizeReference(ctx context.Context, nameStr string, ref
 		return err
 	} else if org.Slug == nil || *org.Slug != name.OrgName {
 		return ErrAccessDenied
+	} else if err := a.authorizeAccessRules(ctx, auth, *name, action); err != nil {
+		return err
 	} else if action != ActionWrite && auth.CurrentCustomerOrgID() != nil {
 		if org.HasFeature(types.FeatureLicensing) {
 			err := db.CheckLicenseForArtifact(ctx,
@@ -83,6 +86,16 @@ func (a *authorizer) AuthorizeReference(ctx context.Context, nameStr string, ref
 			} else if err != nil {
 				return err
 			}
+			// HEAD requests are recorded as pulls as well, but they are not limited so that clients can still resolve
+			// tags. Manifest downloads check the limit again when the pull is recorded, see audit.AuditPullWithinLimit.
+			if action == ActionRead {
+				err := db.CheckPullLimitForArtifact(ctx, name.OrgName, name.ArtifactName, *auth.CurrentCustomerOrgID())
+				if errors.Is(err, apierrors.ErrPullLimitExceeded) {
+					return ErrPullLimitExceeded
+				} else if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -111,3 +124,29 @@ func (a *authorizer) AuthorizeBlob(ctx context.Context, digest digest.Digest, ac
 
 	return nil
 }
+
+// authorizeAccessRules checks the per-artifact access rules for vendor users. Customer access is governed by licenses
+// instead. Blobs are content addressable and can be shared between artifacts, so AuthorizeBlob does not check them.
+func (a *authorizer) authorizeAccessRules(
+	ctx context.Context,
+	auth authinfo.AuthInfoWithOrganization,
+	artifact name.Name,
+	action Action,
+) error {
+	if auth.CurrentCustomerOrgID() != nil || auth.CurrentUserRole() == nil {
+		return nil
+	}
+
+	permission := types.ArtifactPermissionRead
+	if action == ActionWrite {
+		permission = types.ArtifactPermissionWrite
+	}
+
+	if rules, err := db.GetArtifactAccessRulesByName(ctx, artifact.OrgName, artifact.ArtifactName); err != nil {
+		return err
+	} else if !types.ArtifactAccessAllowed(rules, auth.CurrentUserID(), *auth.CurrentUserRole(), permission) {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
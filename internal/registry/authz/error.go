@@ -2,4 +2,7 @@ package authz
 
 import "errors"
 
-var ErrAccessDenied = errors.New("access denied")
+var (
+	ErrAccessDenied      = errors.New("access denied")
+	ErrPullLimitExceeded = errors.New("pull limit exceeded")
+)
@@ -16,25 +16,40 @@ package registry
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/distr-sh/distr/internal/auth"
 	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/registry/authz"
 	"github.com/distr-sh/distr/internal/registry/blob"
 	registryerror "github.com/distr-sh/distr/internal/registry/error"
 	"github.com/distr-sh/distr/internal/registry/verify"
+	"github.com/distr-sh/distr/internal/types"
 	"github.com/google/uuid"
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/opencontainers/go-digest"
 	"go.uber.org/zap"
 )
 
 const (
 	uploads = "uploads"
+	exists  = "exists"
+	// maxBlobExistsDigests limits the number of digests that can be checked with a single request.
+	maxBlobExistsDigests = 1000
+	// maxBlobExistsBodySize limits the size of the request body of an exists request. It is large enough for
+	// maxBlobExistsDigests sha512 digests.
+	maxBlobExistsBodySize = 256 * 1024
 )
 
 // Returns whether this url should be handled by the blob handler
@@ -118,6 +133,9 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 			return regErrInternal(err)
 		}
+		if target == exists {
+			return b.handleExists(resp, req, repo)
+		}
 		return b.handlePost(resp, req, repo, target, digestFromQuery)
 	case http.MethodPatch:
 		if err := b.authz.Authorize(req.Context(), repo, authz.ActionWrite); err != nil {
@@ -198,6 +216,93 @@ func (b *blobs) handleHead(resp http.ResponseWriter, req *http.Request, repo, ta
 	return nil
 }
 
+// handleExists responds with those of the requested digests that do not have to be uploaded again, so that clients
+// can skip uploading them before pushing a manifest. Only blobs that are part of an artifact of the current
+// organization are reported as present, so that the endpoint does not reveal blobs of other organizations.
+func (b *blobs) handleExists(resp http.ResponseWriter, req *http.Request, repo string) *regError {
+	type existsRequest struct {
+		Digests []string `json:"digests"`
+	}
+	type existsResult struct {
+		Present []string `json:"present"`
+		Missing []string `json:"missing"`
+	}
+
+	var body existsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(resp, req.Body, maxBlobExistsBodySize)).Decode(&body); err != nil {
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			return &regError{
+				Status:  http.StatusRequestEntityTooLarge,
+				Code:    "UNSUPPORTED",
+				Message: fmt.Sprintf("request body exceeds the maximum size of %v bytes", maxBytesErr.Limit),
+			}
+		}
+		return &regError{Status: http.StatusBadRequest, Code: "UNSUPPORTED", Message: err.Error()}
+	} else if len(body.Digests) > maxBlobExistsDigests {
+		return &regError{
+			Status:  http.StatusBadRequest,
+			Code:    "UNSUPPORTED",
+			Message: fmt.Sprintf("at most %v digests can be checked at once", maxBlobExistsDigests),
+		}
+	}
+	for _, d := range body.Digests {
+		if _, err := digest.Parse(d); err != nil {
+			return regErrDigestInvalid
+		}
+	}
+
+	auth := auth.ArtifactsAuthentication.Require(req.Context())
+	known, err := db.GetArtifactBlobDigestsInOrganization(req.Context(), *auth.CurrentOrgID(), body.Digests)
+	if err != nil {
+		return regErrInternal(err)
+	}
+
+	result := existsResult{Present: []string{}, Missing: []string{}}
+	for _, d := range body.Digests {
+		if !slices.Contains(known, d) {
+			result.Missing = append(result.Missing, d)
+		} else if present, err := b.blobExists(req.Context(), repo, digest.Digest(d)); err != nil {
+			return regErrInternal(err)
+		} else if present {
+			result.Present = append(result.Present, d)
+		} else {
+			result.Missing = append(result.Missing, d)
+		}
+	}
+
+	msg, err := json.Marshal(result)
+	if err != nil {
+		return regErrInternal(err)
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(msg)))
+	resp.WriteHeader(http.StatusOK)
+	if _, err := resp.Write(msg); err != nil {
+		return regErrInternal(err)
+	}
+	return nil
+}
+
+// blobExists checks whether the blob is stored, like handleHead does.
+func (b *blobs) blobExists(ctx context.Context, repo string, h digest.Digest) (bool, error) {
+	var err error
+	if bsh, ok := b.blobHandler.(blob.BlobStatHandler); ok {
+		_, err = bsh.Stat(ctx, repo, h)
+	} else {
+		var rc io.ReadCloser
+		if rc, err = b.blobHandler.Get(ctx, repo, h, true); err == nil {
+			rc.Close()
+		}
+	}
+	var rerr blob.RedirectError
+	if err == nil || errors.As(err, &rerr) {
+		return true, nil
+	} else if errors.Is(err, blob.ErrNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
 func (b *blobs) handleGet(resp http.ResponseWriter, req *http.Request, repo, target, rangeHeader string) *regError {
 	h, err := digest.Parse(target)
 	if err != nil {
@@ -343,6 +448,10 @@ func (b *blobs) handlePost(resp http.ResponseWriter, req *http.Request, repo, ta
 		}
 		defer vrc.Close()
 
+		if rerr := ensureStorageQuota(req.Context(), max(req.ContentLength, 0), false); rerr != nil {
+			return rerr
+		}
+
 		if err = bph.Put(req.Context(), repo, h, "", vrc); err != nil {
 			if errors.As(err, &verify.Error{}) {
 				log := internalctx.GetLogger(req.Context())
@@ -407,6 +516,10 @@ func (b *blobs) handlePatch(
 		return regErrInternal(err)
 	}
 
+	if rerr := ensureStorageQuota(req.Context(), size, true); rerr != nil {
+		return rerr
+	}
+
 	resp.Header().Set("Location", req.URL.Path)
 	resp.Header().Set("Range", fmt.Sprintf("0-%d", size-1))
 	resp.WriteHeader(http.StatusAccepted)
@@ -444,6 +557,7 @@ func (b *blobs) handlePut(
 		return regErrDigestInvalid
 	}
 
+	var uploaded int64
 	if req.ContentLength > 0 {
 		var start, end int64 = 0, 0
 		if contentRange != "" {
@@ -471,6 +585,11 @@ func (b *blobs) handlePut(
 				Message: "size of uploaded chunks does not match requested range",
 			}
 		}
+		uploaded = size
+	}
+
+	if rerr := ensureStorageQuota(req.Context(), uploaded, false); rerr != nil {
+		return rerr
 	}
 
 	err = bph.CompleteSession(req.Context(), repo, target, h)
@@ -500,3 +619,31 @@ func (b *blobs) handlePut(
 // 	resp.WriteHeader(http.StatusAccepted)
 // 	return nil
 // }
+
+// storageQuotaCache holds the storage quota of organizations for chunked uploads, so that the storage usage does not
+// have to be aggregated for every chunk. Blobs only count towards the usage once they are referenced by a manifest, and
+// putting the manifest checks the exact usage, so a slightly outdated value is good enough here.
+var storageQuotaCache = expirable.NewLRU[uuid.UUID, types.RegistryQuota](1024, nil, time.Minute)
+
+// ensureStorageQuota rejects uploads that would exceed the storage quota of the current organization. If cached is
+// true, the quota may be taken from storageQuotaCache.
+func ensureStorageQuota(ctx context.Context, additional int64, cached bool) *regError {
+	orgID := auth.ArtifactsAuthentication.Require(ctx).CurrentOrgID()
+	if orgID == nil {
+		return nil
+	}
+	quota, ok := storageQuotaCache.Get(*orgID)
+	if !ok || !cached {
+		if q, err := db.GetOrganizationStorageQuota(ctx, *orgID); err != nil {
+			return regErrInternal(err)
+		} else {
+			quota = *q
+			storageQuotaCache.Add(*orgID, quota)
+		}
+	}
+	if err := db.CheckStorageQuota(quota, additional); err != nil {
+		notifyQuotaExceeded(ctx, err)
+		return regErrDeniedStorageQuotaExceeded(err)
+	}
+	return nil
+}
@@ -3,20 +3,31 @@ package inmemory
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"sync"
 
 	"github.com/distr-sh/distr/internal/registry/and"
 	"github.com/distr-sh/distr/internal/registry/blob"
+	"github.com/google/uuid"
 	"github.com/opencontainers/go-digest"
 )
 
 type blobHandler struct {
-	m    map[string][]byte
-	lock sync.Mutex
+	m        map[string][]byte
+	sessions map[string][]byte
+	lock     sync.Mutex
 }
 
-func NewBlobHandler() blob.BlobHandler { return &blobHandler{m: map[string][]byte{}} }
+var (
+	_ blob.BlobStatHandler   = &blobHandler{}
+	_ blob.BlobPutHandler    = &blobHandler{}
+	_ blob.BlobDeleteHandler = &blobHandler{}
+)
+
+func NewBlobHandler() blob.BlobHandler {
+	return &blobHandler{m: map[string][]byte{}, sessions: map[string][]byte{}}
+}
 
 func (m *blobHandler) Stat(_ context.Context, _ string, h digest.Digest) (int64, error) {
 	m.lock.Lock()
@@ -55,6 +66,63 @@ func (m *blobHandler) Put(_ context.Context, _ string, h digest.Digest, _ string
 	return nil
 }
 
+func (m *blobHandler) StartSession(_ context.Context, _ string) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	id := uuid.NewString()
+	m.sessions[id] = []byte{}
+	return id, nil
+}
+
+func (m *blobHandler) PutChunk(_ context.Context, id string, r io.Reader, start int64) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if rc, ok := r.(io.ReadCloser); ok {
+		defer rc.Close()
+	}
+	data, found := m.sessions[id]
+	if !found {
+		return 0, blob.NewErrBadUpload("unknown upload session")
+	} else if int64(len(data)) != start {
+		return 0, blob.NewErrBadUpload("range is not as expected")
+	}
+	chunk, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	data = append(data, chunk...)
+	m.sessions[id] = data
+	return int64(len(data)), nil
+}
+
+func (m *blobHandler) GetUploadedPartsSize(_ context.Context, id string) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if data, found := m.sessions[id]; !found {
+		return 0, blob.NewErrBadUpload("unknown upload session")
+	} else {
+		return int64(len(data)), nil
+	}
+}
+
+func (m *blobHandler) CompleteSession(_ context.Context, _, id string, h digest.Digest) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	data, found := m.sessions[id]
+	if !found {
+		return blob.NewErrBadUpload("unknown upload session")
+	} else if actual := h.Algorithm().FromBytes(data); actual != h {
+		return blob.NewErrBadUpload(fmt.Sprintf("digest mismatch: expected %v, got %v", h, actual))
+	}
+	delete(m.sessions, id)
+	m.m[h.String()] = data
+	return nil
+}
+
 func (m *blobHandler) Delete(_ context.Context, _ string, h digest.Digest) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -44,6 +44,26 @@ var (
 
 func NewBlobHandler(ctx context.Context) blob.BlobHandler {
 	s3Config := env.RegistryS3Config()
+	s3Client := newClient(ctx, s3Config)
+	return &blobHandler{
+		s3Client:        s3Client,
+		s3PresignClient: s3.NewPresignClient(s3Client),
+		allowRedirect:   s3Config.AllowRedirect,
+		bucket:          s3Config.Bucket,
+	}
+}
+
+// NewBucketCheck returns a function that verifies that the configured registry bucket is reachable.
+func NewBucketCheck(ctx context.Context) func(ctx context.Context) error {
+	s3Config := env.RegistryS3Config()
+	s3Client := newClient(ctx, s3Config)
+	return func(ctx context.Context) error {
+		_, err := s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &s3Config.Bucket})
+		return err
+	}
+}
+
+func newClient(ctx context.Context, s3Config env.S3Config) *s3.Client {
 	var s3Client *s3.Client
 	if config, err := awsconfig.LoadDefaultConfig(ctx); err != nil {
 		s3Client = s3.New(s3.Options{}, clientOpts(s3Config))
@@ -57,12 +77,7 @@ func NewBlobHandler(ctx context.Context) blob.BlobHandler {
 			s3Client = s3.NewFromConfig(config, clientOpts(s3Config), ResignForGCP)
 		}
 	}
-	return &blobHandler{
-		s3Client:        s3Client,
-		s3PresignClient: s3.NewPresignClient(s3Client),
-		allowRedirect:   s3Config.AllowRedirect,
-		bucket:          s3Config.Bucket,
-	}
+	return s3Client
 }
 
 func clientOpts(s3Config env.S3Config) func(o *s3.Options) {
@@ -0,0 +1,49 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestBlobExistsInvalidRequest(t *testing.T) {
+	server := newTestRegistry(t)
+	exists := func(g Gomega, body []byte) *http.Response {
+		resp, _ := doRequest(g, server, http.MethodPost, "/v2/org/app/blobs/exists", nil, body)
+		return resp
+	}
+	digests := func(g Gomega, digests ...string) []byte {
+		data, err := json.Marshal(map[string][]string{"digests": digests})
+		g.Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(exists(g, []byte("not json")).StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	t.Run("invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(exists(g, digests(g, "sha256:invalid")).StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	t.Run("too many digests", func(t *testing.T) {
+		g := NewWithT(t)
+		d := make([]string, 1001)
+		for i := range d {
+			d[i] = digest.FromString(strings.Repeat("a", i)).String()
+		}
+		g.Expect(exists(g, digests(g, d...)).StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		g := NewWithT(t)
+		body := `{"digests":["` + strings.Repeat("a", 256*1024) + `"]}`
+		g.Expect(exists(g, []byte(body)).StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+}
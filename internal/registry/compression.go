@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+
+	"github.com/distr-sh/distr/internal/httpencoding"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionMinSize is the minimum body size for which compression is applied. For smaller bodies, the overhead of
+// compressing usually outweighs the savings.
+const compressionMinSize = 1024
+
+// zstdEncoder is only used with EncodeAll, which is safe for concurrent use.
+var zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+
+// writeCompressible writes body with status OK, compressing it if the client accepts a supported encoding and the body
+// is large enough.
+func writeCompressible(resp http.ResponseWriter, req *http.Request, body []byte) error {
+	resp.Header().Add("Vary", "Accept-Encoding")
+
+	if len(body) >= compressionMinSize {
+		// zstd is preferred over gzip if the client has no preference
+		switch httpencoding.Negotiate(req, httpencoding.Zstd, httpencoding.Gzip) {
+		case httpencoding.Zstd:
+			resp.Header().Set("Content-Encoding", httpencoding.Zstd)
+			body = zstdEncoder.EncodeAll(body, make([]byte, 0, len(body)/2))
+		case httpencoding.Gzip:
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			if _, err := gz.Write(body); err != nil {
+				return err
+			} else if err := gz.Close(); err != nil {
+				return err
+			}
+			resp.Header().Set("Content-Encoding", httpencoding.Gzip)
+			body = buf.Bytes()
+		}
+	}
+
+	resp.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	resp.WriteHeader(http.StatusOK)
+	_, err := resp.Write(body)
+	return err
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	corsAllowedHeaders = "Authorization, Content-Type, Content-Length, Content-Range, Range, Accept"
+	corsExposedHeaders = "Docker-Content-Digest, Docker-Distribution-API-Version, Docker-Upload-UUID, Location, " +
+		"Range, Link, OCI-Subject, OCI-Filters-Applied, WWW-Authenticate"
+	corsMaxAge = "600"
+)
+
+// CORS returns a middleware that allows browsers on one of the given origins to access the registry.
+//
+// Preflight requests do not carry credentials, so they are answered here instead of by the route handlers. The
+// middleware must therefore be installed before authentication. Requests without an Origin header, i.e. all
+// non-browser clients, are passed through unchanged.
+//
+// Only origins that are listed explicitly may send credentials. If the origin is only allowed by the wildcard "*",
+// the response allows any origin but no credentials, so that arbitrary websites cannot use the cookies or the
+// authorization of the browser to access the registry.
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowAll := slices.Contains(allowedOrigins, "*")
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				h.ServeHTTP(resp, req)
+				return
+			}
+
+			originListed := slices.Contains(allowedOrigins, origin)
+			originAllowed := originListed || allowAll
+			if originListed {
+				resp.Header().Add("Vary", "Origin")
+				resp.Header().Set("Access-Control-Allow-Origin", origin)
+				resp.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else if originAllowed {
+				resp.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+			if originAllowed {
+				resp.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			}
+
+			if req.Method != http.MethodOptions {
+				h.ServeHTTP(resp, req)
+				return
+			}
+
+			methods := allowedMethods(req)
+			if methods == nil {
+				h.ServeHTTP(resp, req)
+				return
+			}
+
+			resp.Header().Set("Allow", strings.Join(methods, ", "))
+			if originAllowed {
+				resp.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				resp.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+				resp.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			}
+			resp.WriteHeader(http.StatusOK)
+		})
+	}
+}
+
+// allowedMethods returns the methods supported by the registry route of req or nil if req does not match any route.
+func allowedMethods(req *http.Request) []string {
+	switch {
+	case isBlob(req):
+		elem := strings.Split(strings.TrimSuffix(req.URL.Path, "/"), "/")
+		switch {
+		case elem[len(elem)-1] == uploads, elem[len(elem)-1] == exists:
+			return []string{http.MethodPost, http.MethodOptions}
+		case elem[len(elem)-2] == uploads:
+			return []string{http.MethodGet, http.MethodPatch, http.MethodPut, http.MethodOptions}
+		default:
+			return []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+		}
+	case isManifest(req):
+		if strings.HasSuffix(req.URL.Path, "/manifests/validate") {
+			return []string{http.MethodPost, http.MethodOptions}
+		}
+		return []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions}
+	case isTags(req), isCatalog(req), isReferrers(req), req.URL.Path == "/v2/", req.URL.Path == "/v2":
+		return []string{http.MethodGet, http.MethodOptions}
+	default:
+		return nil
+	}
+}
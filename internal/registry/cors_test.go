@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func serveCORS(allowedOrigins []string, method, path, origin string) *httptest.ResponseRecorder {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	CORS(allowedOrigins)(next).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSWithoutOrigin(t *testing.T) {
+	g := NewWithT(t)
+	rec := serveCORS([]string{"*"}, http.MethodGet, "/v2/org/app/manifests/latest", "")
+	g.Expect(rec.Code).To(Equal(http.StatusTeapot))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+}
+
+func TestCORSListedOrigin(t *testing.T) {
+	g := NewWithT(t)
+	origins := []string{"https://a.example.com", "*"}
+
+	rec := serveCORS(origins, http.MethodGet, "/v2/org/app/manifests/latest", "https://a.example.com")
+	g.Expect(rec.Code).To(Equal(http.StatusTeapot))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(Equal("https://a.example.com"))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+	g.Expect(rec.Header().Values("Vary")).To(ContainElement("Origin"))
+	g.Expect(rec.Header().Get("Access-Control-Expose-Headers")).To(ContainSubstring("Docker-Content-Digest"))
+
+	rec = serveCORS(origins, http.MethodOptions, "/v2/org/app/manifests/latest", "https://a.example.com")
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, HEAD, PUT, DELETE, OPTIONS"))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Headers")).To(ContainSubstring("Authorization"))
+}
+
+func TestCORSWildcardOriginHasNoCredentials(t *testing.T) {
+	g := NewWithT(t)
+	origins := []string{"https://a.example.com", "*"}
+
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		rec := serveCORS(origins, method, "/v2/org/app/tags/list", "https://evil.example.com")
+		g.Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(Equal("*"))
+		g.Expect(rec.Header().Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+	}
+}
+
+func TestCORSOriginNotAllowed(t *testing.T) {
+	g := NewWithT(t)
+	origins := []string{"https://a.example.com"}
+
+	rec := serveCORS(origins, http.MethodGet, "/v2/org/app/tags/list", "https://evil.example.com")
+	g.Expect(rec.Code).To(Equal(http.StatusTeapot))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+
+	rec = serveCORS(origins, http.MethodOptions, "/v2/org/app/tags/list", "https://evil.example.com")
+	g.Expect(rec.Code).To(Equal(http.StatusOK))
+	g.Expect(rec.Header().Get("Allow")).To(Equal("GET, OPTIONS"))
+	g.Expect(rec.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+	g.Expect(rec.Header().Get("Access-Control-Allow-Methods")).To(BeEmpty())
+}
+
+func TestCORSAllowedMethods(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/v2/", []string{http.MethodGet, http.MethodOptions}},
+		{"/v2/_catalog", []string{http.MethodGet, http.MethodOptions}},
+		{"/v2/org/app/tags/list", []string{http.MethodGet, http.MethodOptions}},
+		{"/v2/org/app/manifests/latest", []string{
+			http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions,
+		}},
+		{"/v2/org/app/blobs/sha256:abc", []string{http.MethodGet, http.MethodHead, http.MethodOptions}},
+		{"/v2/org/app/blobs/uploads/", []string{http.MethodPost, http.MethodOptions}},
+		{"/v2/org/app/blobs/uploads/1234", []string{
+			http.MethodGet, http.MethodPatch, http.MethodPut, http.MethodOptions,
+		}},
+		{"/other", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(allowedMethods(httptest.NewRequest(http.MethodOptions, tt.path, nil))).To(Equal(tt.expected))
+		})
+	}
+}
@@ -16,7 +16,10 @@ package registry
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+
+	imanifest "github.com/distr-sh/distr/internal/registry/manifest"
 )
 
 type regError struct {
@@ -24,9 +27,15 @@ type regError struct {
 	Code    string
 	Message string
 	Error   error
+	Header  http.Header
 }
 
 func (r *regError) Write(resp http.ResponseWriter) error {
+	for key, values := range r.Header {
+		for _, value := range values {
+			resp.Header().Add(key, value)
+		}
+	}
 	resp.WriteHeader(r.Status)
 
 	type err struct {
@@ -107,6 +116,12 @@ var regErrNameUnknown = &regError{
 	Message: "Unknown name",
 }
 
+var regErrNameUnknownArtifactNotCreated = &regError{
+	Status:  http.StatusNotFound,
+	Code:    "NAME_UNKNOWN",
+	Message: "this artifact does not exist and must be created before it can be pushed",
+}
+
 var regErrMethodUnknown = &regError{
 	Status:  http.StatusBadRequest,
 	Code:    "METHOD_UNKNOWN",
@@ -125,12 +140,39 @@ var regErrDeniedQuotaExceeded = &regError{
 	Message: "You have exhausted your organizations tag quota",
 }
 
+var regErrTooManyRequestsPullLimitExceeded = &regError{
+	Status:  http.StatusTooManyRequests,
+	Code:    "TOOMANYREQUESTS",
+	Message: "You have exhausted the pull limit of your license for the current period",
+}
+
+func regErrDeniedStorageQuotaExceeded(err error) *regError {
+	return &regError{
+		Status:  http.StatusForbidden,
+		Code:    "DENIED",
+		Message: fmt.Sprintf("You have exhausted your organizations storage quota: %v", err),
+		Error:   err,
+	}
+}
+
 var regErrTagAlreadyExists = &regError{
 	Status:  http.StatusConflict,
 	Code:    "UNSUPPORTED",
 	Message: "this tag already exists and cannot be overwritten",
 }
 
+// regErrTagAlreadyExistsWithDigest returns a conflict error that exposes the digest currently referenced by the tag,
+// both in the message and in the Docker-Content-Digest header, so that clients can check whether the push is needed.
+func regErrTagAlreadyExistsWithDigest(err *imanifest.TagAlreadyExistsError) *regError {
+	return &regError{
+		Status:  http.StatusConflict,
+		Code:    "UNSUPPORTED",
+		Message: fmt.Sprintf("this tag already exists with digest %v and cannot be overwritten", err.Digest),
+		Error:   err,
+		Header:  http.Header{"Docker-Content-Digest": []string{err.Digest.String()}},
+	}
+}
+
 var regErrConflict = &regError{
 	Status:  http.StatusConflict,
 	Code:    "DENIED",
@@ -142,3 +184,21 @@ var regErrBadRequest = &regError{
 	Code:    "UNSUPPORTED",
 	Message: "the request is invalid or not supported",
 }
+
+var regErrManifestNotAcceptable = &regError{
+	Status:  http.StatusNotAcceptable,
+	Code:    "MANIFEST_UNKNOWN",
+	Message: "the manifest is not available in any of the accepted media types",
+}
+
+var regErrReadOnly = &regError{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "UNAVAILABLE",
+	Message: "This registry is in read-only mode for maintenance, please try again later",
+}
+
+var regErrRegistryDisabled = &regError{
+	Status:  http.StatusNotFound,
+	Code:    "UNSUPPORTED",
+	Message: "The registry is not enabled on this instance. Set REGISTRY_ENABLED=true to enable it.",
+}
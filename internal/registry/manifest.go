@@ -21,19 +21,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/containers/image/v5/manifest"
 	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
 	internalctx "github.com/distr-sh/distr/internal/context"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/notification"
 	"github.com/distr-sh/distr/internal/registry/audit"
 	"github.com/distr-sh/distr/internal/registry/authz"
 	"github.com/distr-sh/distr/internal/registry/blob"
 	registryerror "github.com/distr-sh/distr/internal/registry/error"
 	imanifest "github.com/distr-sh/distr/internal/registry/manifest"
+	"github.com/distr-sh/distr/internal/types"
 	"github.com/getsentry/sentry-go"
 	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
@@ -52,11 +58,14 @@ type listTags struct {
 }
 
 type manifests struct {
-	blobHandler     blob.BlobHandler
-	manifestHandler imanifest.ManifestHandler
-	authz           authz.Authorizer
-	audit           audit.ArtifactAuditor
-	log             *zap.SugaredLogger
+	blobHandler      blob.BlobHandler
+	manifestHandler  imanifest.ManifestHandler
+	authz            authz.Authorizer
+	audit            audit.ArtifactAuditor
+	log              *zap.SugaredLogger
+	defaultPageSize  int
+	maxPageSize      int
+	maxManifestBytes int64
 }
 
 func isManifest(req *http.Request) bool {
@@ -110,6 +119,8 @@ func (handler *manifests) handle(resp http.ResponseWriter, req *http.Request) *r
 		if err := handler.authz.AuthorizeReference(req.Context(), repo, target, authz.ActionRead); err != nil {
 			if errors.Is(err, authz.ErrAccessDenied) {
 				return regErrDenied
+			} else if errors.Is(err, authz.ErrPullLimitExceeded) {
+				return regErrTooManyRequestsPullLimitExceeded
 			} else if errors.Is(err, registryerror.ErrInvalidArtifactName) {
 				return regErrNameInvalid
 			}
@@ -136,6 +147,18 @@ func (handler *manifests) handle(resp http.ResponseWriter, req *http.Request) *r
 			return regErrInternal(err)
 		}
 		return handler.handlePut(resp, req, repo, target)
+	case http.MethodPost:
+		if target != "validate" {
+			return regErrMethodUnknown
+		} else if err := handler.authz.Authorize(req.Context(), repo, authz.ActionWrite); err != nil {
+			if errors.Is(err, authz.ErrAccessDenied) {
+				return regErrDenied
+			} else if errors.Is(err, registryerror.ErrInvalidArtifactName) {
+				return regErrNameInvalid
+			}
+			return regErrInternal(err)
+		}
+		return handler.handleValidate(resp, req, repo)
 	case http.MethodDelete:
 		if err := handler.authz.AuthorizeReference(req.Context(), repo, target, authz.ActionWrite); err != nil {
 			if errors.Is(err, authz.ErrAccessDenied) {
@@ -167,24 +190,26 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 		}
 
 		last := req.URL.Query().Get("last")
-		n := 10000
-		if ns := req.URL.Query().Get("n"); ns != "" {
-			if parsed, err := strconv.Atoi(ns); err != nil {
-				return &regError{
-					Status:  http.StatusBadRequest,
-					Code:    "BAD_REQUEST",
-					Message: fmt.Sprintf("parsing n: %v", err),
-				}
-			} else {
-				n = parsed
-			}
+		n, rerr := m.pageSize(req)
+		if rerr != nil {
+			return rerr
 		}
 
-		references, err := m.manifestHandler.ListTags(req.Context(), repo, n, last)
-		if errors.Is(err, imanifest.ErrNameUnknown) {
-			return regErrNameUnknown
-		} else if err != nil {
-			return regErrInternal(err)
+		// the manifest handler treats 0 as unlimited, but clients requesting 0 tags expect an empty list
+		references := []string{}
+		if n > 0 {
+			var err error
+			// one more tag than requested is listed to find out whether there is a next page
+			references, err = m.manifestHandler.ListTags(req.Context(), repo, n+1, last)
+			if errors.Is(err, imanifest.ErrNameUnknown) {
+				return regErrNameUnknown
+			} else if err != nil {
+				return regErrInternal(err)
+			}
+			if len(references) > n {
+				references = references[:n]
+				resp.Header().Set("Link", nextPageLink(req, n, references[n-1]))
+			}
 		}
 
 		tagsToList := listTags{
@@ -196,9 +221,7 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 		if err != nil {
 			return regErrInternal(err)
 		}
-		resp.Header().Set("Content-Length", strconv.Itoa(len(msg)))
-		resp.WriteHeader(http.StatusOK)
-		if _, err := io.Copy(resp, bytes.NewReader(msg)); err != nil {
+		if err := writeCompressible(resp, req, msg); err != nil {
 			return regErrInternal(err)
 		}
 		return nil
@@ -207,18 +230,49 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 	return regErrMethodUnknown
 }
 
-func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *regError {
-	query := req.URL.Query()
-	nStr := query.Get("n")
-	n := 10000
-	if nStr != "" {
-		n, _ = strconv.Atoi(nStr)
+// pageSize returns the page size requested with the "n" query parameter, which is limited to the maximum page size.
+// If the parameter is not set, the default page size is used. A page size of 0 requests an empty list.
+func (m *manifests) pageSize(req *http.Request) (int, *regError) {
+	ns := req.URL.Query().Get("n")
+	if ns == "" {
+		return min(m.defaultPageSize, m.maxPageSize), nil
 	}
+	n, err := strconv.Atoi(ns)
+	if err == nil && n < 0 {
+		err = errors.New("must not be negative")
+	}
+	if err != nil {
+		return 0, &regError{
+			Status:  http.StatusBadRequest,
+			Code:    "BAD_REQUEST",
+			Message: fmt.Sprintf("parsing n: %v", err),
+		}
+	}
+	return min(n, m.maxPageSize), nil
+}
+
+// nextPageLink returns the value of the Link header that refers to the next page of a list, as defined by the OCI
+// distribution spec.
+func nextPageLink(req *http.Request, n int, last string) string {
+	query := url.Values{"n": {strconv.Itoa(n)}, "last": {last}}
+	return fmt.Sprintf(`<%v?%v>; rel="next"`, req.URL.Path, query.Encode())
+}
 
+func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *regError {
 	if req.Method == http.MethodGet {
-		repos, err := m.manifestHandler.List(req.Context(), n)
-		if err != nil {
-			return regErrInternal(err)
+		n, rerr := m.pageSize(req)
+		if rerr != nil {
+			return rerr
+		}
+
+		// Repositories without tags cannot be pulled by tag, so they are omitted unless explicitly requested
+		includeEmpty, _ := strconv.ParseBool(req.URL.Query().Get("includeEmpty"))
+		repos := []string{}
+		if n > 0 {
+			var err error
+			if repos, err = m.manifestHandler.List(req.Context(), n, includeEmpty); err != nil {
+				return regErrInternal(err)
+			}
 		}
 
 		repositoriesToList := catalog{Repos: repos}
@@ -227,9 +281,7 @@ func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *
 		if err != nil {
 			return regErrInternal(err)
 		}
-		resp.Header().Set("Content-Length", strconv.Itoa(len(msg)))
-		resp.WriteHeader(http.StatusOK)
-		if _, err := io.Copy(resp, bytes.NewReader(msg)); err != nil {
+		if err := writeCompressible(resp, req, msg); err != nil {
 			return regErrInternal(err)
 		}
 		return nil
@@ -260,7 +312,8 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 	}
 
 	// Validate that incoming target is a valid digest
-	if _, err := digest.Parse(target); err != nil {
+	targetDigest, err := digest.Parse(target)
+	if err != nil {
 		return &regError{
 			Status:  http.StatusBadRequest,
 			Code:    "UNSUPPORTED",
@@ -268,13 +321,6 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 		}
 	}
 
-	digests, err := m.manifestHandler.ListDigests(req.Context(), repo)
-	if errors.Is(err, imanifest.ErrNameUnknown) {
-		return regErrNameUnknown
-	} else if err != nil {
-		return regErrInternal(err)
-	}
-
 	im := manifest.OCI1Index{
 		Index: imgspecv1.Index{
 			Versioned: specs.Versioned{
@@ -284,6 +330,30 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 			Manifests: []imgspecv1.Descriptor{},
 		},
 	}
+
+	// Handlers that can look up the referrers directly make it unnecessary to read every manifest.
+	if rh, ok := m.manifestHandler.(imanifest.ReferrersHandler); ok {
+		referrers, err := rh.ListReferrers(req.Context(), repo, targetDigest)
+		if errors.Is(err, imanifest.ErrNameUnknown) {
+			return regErrNameUnknown
+		} else if err != nil {
+			return regErrInternal(err)
+		}
+		im.Manifests = append(im.Manifests, referrers...)
+		msg, err := json.Marshal(&im)
+		if err != nil {
+			return regErrInternal(err)
+		}
+		return writeReferrersIndex(resp, msg)
+	}
+
+	digests, err := m.manifestHandler.ListDigests(req.Context(), repo)
+	if errors.Is(err, imanifest.ErrNameUnknown) {
+		return regErrNameUnknown
+	} else if err != nil {
+		return regErrInternal(err)
+	}
+
 	for _, reference := range digests {
 		manifest, err := m.manifestHandler.Get(req.Context(), repo, reference.String())
 		if err != nil {
@@ -336,6 +406,10 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 	if err != nil {
 		return regErrInternal(err)
 	}
+	return writeReferrersIndex(resp, msg)
+}
+
+func writeReferrersIndex(resp http.ResponseWriter, msg []byte) *regError {
 	resp.Header().Set("Content-Length", strconv.Itoa(len(msg)))
 	resp.Header().Set("Content-Type", string(imgspecv1.MediaTypeImageIndex))
 	resp.WriteHeader(http.StatusOK)
@@ -356,13 +430,19 @@ func (handler *manifests) handleGet(resp http.ResponseWriter, req *http.Request,
 		return regErrInternal(err)
 	}
 
+	if !acceptsMediaType(req.Header.Values("Accept"), m.ContentType) {
+		return regErrManifestNotAcceptable
+	}
+
 	// TODO: remove in v2
 	if len(m.Data) == 0 {
 		b, err := handler.blobHandler.Get(ctx, repo, m.Digest, true)
 		if err != nil {
 			var rerr blob.RedirectError
 			if errors.As(err, &rerr) {
-				if err := handler.audit.AuditPull(ctx, repo, target); err != nil {
+				if err := handler.audit.AuditPullWithinLimit(ctx, repo, target); errors.Is(err, apierrors.ErrPullLimitExceeded) {
+					return regErrTooManyRequestsPullLimitExceeded
+				} else if err != nil {
 					log := internalctx.GetLogger(ctx)
 					log.Warn("failed to audit-log pull", zap.Error(err))
 					sentry.GetHubFromContext(ctx)
@@ -380,7 +460,9 @@ func (handler *manifests) handleGet(resp http.ResponseWriter, req *http.Request,
 		}
 	}
 
-	if err := handler.audit.AuditPull(ctx, repo, target); err != nil {
+	if err := handler.audit.AuditPullWithinLimit(ctx, repo, target); errors.Is(err, apierrors.ErrPullLimitExceeded) {
+		return regErrTooManyRequestsPullLimitExceeded
+	} else if err != nil {
 		log := internalctx.GetLogger(ctx)
 		log.Warn("failed to audit-log pull", zap.Error(err))
 		sentry.GetHubFromContext(ctx)
@@ -396,6 +478,37 @@ func (handler *manifests) handleGet(resp http.ResponseWriter, req *http.Request,
 	return nil
 }
 
+// acceptsMediaType reports whether mediaType matches any of the media ranges in the given Accept header values.
+// Requests without an Accept header accept any media type, as do manifests without a stored media type.
+func acceptsMediaType(accept []string, mediaType string) bool {
+	if mediaType == "" {
+		return true
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	hasRange := false
+	for _, value := range accept {
+		for mediaRange := range strings.SplitSeq(value, ",") {
+			if strings.TrimSpace(mediaRange) == "" {
+				continue
+			}
+			hasRange = true
+			accepted, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			} else if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			if accepted == "*/*" || accepted == mediaType ||
+				(strings.HasSuffix(accepted, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(accepted, "*"))) {
+				return true
+			}
+		}
+	}
+	return !hasRange
+}
+
 func (handler *manifests) handleHead(resp http.ResponseWriter, req *http.Request, repo, target string) *regError {
 	ctx := req.Context()
 	m, err := handler.manifestHandler.Get(ctx, repo, target)
@@ -407,6 +520,10 @@ func (handler *manifests) handleHead(resp http.ResponseWriter, req *http.Request
 		return regErrInternal(err)
 	}
 
+	if !acceptsMediaType(req.Header.Values("Accept"), m.ContentType) {
+		return regErrManifestNotAcceptable
+	}
+
 	if err := handler.audit.AuditPull(ctx, repo, target); err != nil {
 		log := internalctx.GetLogger(ctx)
 		log.Warn("failed to audit-log pull", zap.Error(err))
@@ -421,10 +538,125 @@ func (handler *manifests) handleHead(resp http.ResponseWriter, req *http.Request
 }
 
 func (handler *manifests) handlePut(resp http.ResponseWriter, req *http.Request, repo, target string) *regError {
-	buf := &bytes.Buffer{}
-	if _, err := io.Copy(buf, req.Body); err != nil {
+	mf, blobs, rerr := readManifest(req, handler.maxManifestBytes)
+	if rerr != nil {
+		return rerr
+	} else if rerr := checkTargetDigest(target, mf); rerr != nil {
+		return rerr
+	}
+
+	// Allow future references by target (tag) and immutable digest.
+	// See https://docs.docker.com/engine/reference/commandline/pull/#pull-an-image-by-digest-immutable-identifier.
+	err := db.RunTx(req.Context(), func(ctx context.Context) error {
+		return handler.putManifest(ctx, repo, target, mf, blobs)
+	})
+	if errors.Is(err, apierrors.ErrQuotaExceeded) {
+		notifyQuotaExceeded(req.Context(), err)
+	}
+	if rerr := manifestPutError(err); rerr != nil {
+		return rerr
+	}
+
+	resp.Header().Set("Docker-Content-Digest", mf.Digest.String())
+	resp.Header().Set("OCI-Subject", mf.Digest.String())
+	resp.Header().Set("Location", req.URL.JoinPath(mf.Blob.Digest.String()).Path)
+	resp.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// handleValidate runs the same checks as handlePut without persisting anything and responds with all errors that a
+// push of the given manifest would produce. The target tag can be passed with the "tag" query parameter.
+func (handler *manifests) handleValidate(resp http.ResponseWriter, req *http.Request, repo string) *regError {
+	type validationError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	type validationResult struct {
+		Valid  bool              `json:"valid"`
+		Digest string            `json:"digest,omitempty"`
+		Errors []validationError `json:"errors"`
+	}
+
+	result := validationResult{Errors: []validationError{}}
+	addError := func(rerr *regError) {
+		result.Errors = append(result.Errors, validationError{Code: rerr.Code, Message: rerr.Message})
+	}
+
+	if mf, blobs, rerr := readManifest(req, handler.maxManifestBytes); rerr != nil {
+		addError(rerr)
+	} else {
+		result.Digest = mf.Digest.String()
+
+		if rerr := checkTargetDigest(req.URL.Query().Get("tag"), mf); rerr != nil {
+			addError(rerr)
+		}
+
+		if rerr := handler.checkReferencesExist(req.Context(), repo, mf, blobs); rerr != nil {
+			if rerr.Status == http.StatusInternalServerError {
+				return rerr
+			}
+			addError(rerr)
+		}
+
+		err := db.RunTx(req.Context(), func(ctx context.Context) error {
+			if err := handler.putManifest(ctx, repo, req.URL.Query().Get("tag"), mf, blobs); err != nil {
+				return err
+			}
+			return errManifestDryRun
+		})
+		if err != nil && !errors.Is(err, errManifestDryRun) {
+			if rerr := manifestPutError(err); rerr.Status == http.StatusInternalServerError {
+				return rerr
+			} else {
+				addError(rerr)
+			}
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	msg, err := json.Marshal(result)
+	if err != nil {
 		return regErrInternal(err)
 	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(msg)))
+	resp.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(resp, bytes.NewReader(msg)); err != nil {
+		return regErrInternal(err)
+	}
+	return nil
+}
+
+// errManifestDryRun is used to roll back the transaction of a manifest validation.
+var errManifestDryRun = errors.New("dry run")
+
+// checkTargetDigest verifies that a manifest pushed by digest matches that digest, which is computed with the algorithm
+// of the target. Tags can not contain a colon, so every target that does is treated as a digest. Tags are not checked.
+func checkTargetDigest(target string, mf imanifest.Manifest) *regError {
+	if !strings.Contains(target, ":") {
+		return nil
+	} else if d, err := digest.Parse(target); err != nil {
+		return regErrDigestInvalid
+	} else if d != d.Algorithm().FromBytes(mf.Data) {
+		return regErrDigestMismatch
+	}
+	return nil
+}
+
+// readManifest reads the manifest from the request body and returns it together with all blobs it references.
+// Manifests larger than maxBytes are rejected without reading more than maxBytes+1 bytes of the body.
+func readManifest(req *http.Request, maxBytes int64) (imanifest.Manifest, []imanifest.Blob, *regError) {
+	errTooLarge := regErrManifestInvalid(fmt.Errorf("manifest exceeds the maximum size of %v bytes", maxBytes))
+	if req.ContentLength > maxBytes {
+		return imanifest.Manifest{}, nil, errTooLarge
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, io.LimitReader(req.Body, maxBytes+1)); err != nil {
+		return imanifest.Manifest{}, nil, regErrInternal(err)
+	} else if int64(buf.Len()) > maxBytes {
+		return imanifest.Manifest{}, nil, errTooLarge
+	}
 
 	mf := imanifest.Manifest{
 		ContentType: req.Header.Get("Content-Type"),
@@ -441,19 +673,19 @@ func (handler *manifests) handlePut(resp http.ResponseWriter, req *http.Request,
 	if manifest.MIMETypeIsMultiImage(mf.ContentType) {
 		im, err := manifest.ListFromBlob(buf.Bytes(), mf.ContentType)
 		if err != nil {
-			return regErrManifestInvalid(err)
+			return mf, nil, regErrManifestInvalid(err)
 		}
 		for _, d := range im.Instances() {
 			i, err := im.Instance(d)
 			if err != nil {
-				return regErrManifestInvalid(err)
+				return mf, nil, regErrManifestInvalid(err)
 			}
 			blobs = append(blobs, imanifest.Blob{Digest: i.Digest, Size: i.Size})
 		}
 	} else {
 		m, err := manifest.FromBlob(buf.Bytes(), mf.ContentType)
 		if err != nil {
-			return regErrManifestInvalid(err)
+			return mf, nil, regErrManifestInvalid(err)
 		}
 		c := m.ConfigInfo()
 		blobs = append(blobs, imanifest.Blob{Digest: c.Digest, Size: c.Size})
@@ -463,29 +695,83 @@ func (handler *manifests) handlePut(resp http.ResponseWriter, req *http.Request,
 	}
 
 	if err := checkIncompatibleManifest(buf.Bytes()); err != nil {
-		return err
+		return mf, nil, err
 	}
 
-	// Allow future references by target (tag) and immutable digest.
-	// See https://docs.docker.com/engine/reference/commandline/pull/#pull-an-image-by-digest-immutable-identifier.
-	err := db.RunTx(req.Context(), func(ctx context.Context) error {
-		return multierr.Combine(
-			handler.manifestHandler.Put(ctx, repo, mf.Digest.String(), mf, blobs),
-			handler.manifestHandler.Put(ctx, repo, target, mf, blobs),
-		)
-	})
-	if errors.Is(err, apierrors.ErrQuotaExceeded) {
+	return mf, blobs, nil
+}
+
+// putManifest stores the manifest by its digest and, if target is not empty, by target.
+func (handler *manifests) putManifest(
+	ctx context.Context,
+	repo, target string,
+	mf imanifest.Manifest,
+	blobs []imanifest.Blob,
+) error {
+	err := handler.manifestHandler.Put(ctx, repo, mf.Digest.String(), mf, blobs)
+	if target != "" && target != mf.Digest.String() {
+		err = multierr.Combine(err, handler.manifestHandler.Put(ctx, repo, target, mf, blobs))
+	}
+	return err
+}
+
+func manifestPutError(err error) *regError {
+	var tagErr *imanifest.TagAlreadyExistsError
+	if err == nil {
+		return nil
+	} else if errors.Is(err, apierrors.ErrStorageQuotaExceeded) {
+		return regErrDeniedStorageQuotaExceeded(err)
+	} else if errors.Is(err, apierrors.ErrQuotaExceeded) {
 		return regErrDeniedQuotaExceeded
+	} else if errors.Is(err, apierrors.ErrManifestTooLarge) {
+		return regErrManifestInvalid(err)
+	} else if errors.Is(err, imanifest.ErrArtifactNotCreated) {
+		return regErrNameUnknownArtifactNotCreated
+	} else if errors.As(err, &tagErr) {
+		return regErrTagAlreadyExistsWithDigest(tagErr)
 	} else if errors.Is(err, imanifest.ErrTagAlreadyExists) {
 		return regErrTagAlreadyExists
-	} else if err != nil {
+	} else {
 		return regErrInternal(err)
 	}
+}
 
-	resp.Header().Set("Docker-Content-Digest", mf.Digest.String())
-	resp.Header().Set("OCI-Subject", mf.Digest.String())
-	resp.Header().Set("Location", req.URL.JoinPath(mf.Blob.Digest.String()).Path)
-	resp.WriteHeader(http.StatusCreated)
+// checkReferencesExist verifies that all blobs referenced by an image manifest, or all manifests referenced by an
+// index, have already been pushed.
+func (handler *manifests) checkReferencesExist(
+	ctx context.Context,
+	repo string,
+	mf imanifest.Manifest,
+	blobs []imanifest.Blob,
+) *regError {
+	var missing []string
+	for _, b := range blobs {
+		if manifest.MIMETypeIsMultiImage(mf.ContentType) {
+			if _, err := handler.manifestHandler.Get(ctx, repo, b.Digest.String()); err != nil {
+				if !errors.Is(err, imanifest.ErrManifestUnknown) && !errors.Is(err, imanifest.ErrNameUnknown) {
+					return regErrInternal(err)
+				}
+				missing = append(missing, b.Digest.String())
+			}
+		} else if bsh, ok := handler.blobHandler.(blob.BlobStatHandler); ok {
+			if _, err := bsh.Stat(ctx, repo, b.Digest); err != nil {
+				var rerr blob.RedirectError
+				if errors.As(err, &rerr) {
+					continue
+				} else if !errors.Is(err, blob.ErrNotFound) {
+					return regErrInternal(err)
+				}
+				missing = append(missing, b.Digest.String())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return &regError{
+			Status:  http.StatusBadRequest,
+			Code:    regErrBlobUnknown.Code,
+			Message: fmt.Sprintf("referenced content has not been pushed: %v", strings.Join(missing, ", ")),
+		}
+	}
 	return nil
 }
 
@@ -522,3 +808,27 @@ func checkIncompatibleManifest(data []byte) *regError {
 	}
 	return nil
 }
+
+// notifyQuotaExceeded asynchronously dispatches the quota exceeded notifications of the current organization.
+// The notifications must not delay the response to the client, so they are sent in the background.
+func notifyQuotaExceeded(ctx context.Context, err error) {
+	orgID := auth.ArtifactsAuthentication.Require(ctx).CurrentOrgID()
+	if orgID == nil {
+		return
+	}
+
+	kind := types.RegistryQuotaKindStorage
+	if errors.Is(err, apierrors.ErrTagQuotaExceeded) {
+		kind = types.RegistryQuotaKindTags
+	}
+
+	go func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		if err := notification.SendRegistryQuotaExceededNotifications(ctx, *orgID, kind); err != nil {
+			sentry.GetHubFromContext(ctx).CaptureException(err)
+			internalctx.GetLogger(ctx).Error("failed to dispatch quota exceeded notification", zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/distr-sh/distr/internal/apierrors"
 	"github.com/distr-sh/distr/internal/auth"
 	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/notification"
 	"github.com/distr-sh/distr/internal/registry/manifest"
 	"github.com/distr-sh/distr/internal/registry/name"
 	"github.com/distr-sh/distr/internal/types"
@@ -24,6 +25,8 @@ func NewManifestHandler() manifest.ManifestHandler {
 
 // Delete removes a manifest by tag reference.
 func (h *handler) Delete(ctx context.Context, nameStr string, reference string) error {
+	auth := auth.ArtifactsAuthentication.Require(ctx)
+
 	if _, err := digest.Parse(reference); err == nil {
 		return apierrors.NewBadRequest("digest-based deletion is not supported, delete by tag instead")
 	}
@@ -69,7 +72,7 @@ func (h *handler) Delete(ctx context.Context, nameStr string, reference string)
 			)
 		}
 
-		return db.DeleteArtifactVersion(ctx, artifact.ID, reference)
+		return db.DeleteArtifactVersion(ctx, artifact.ID, reference, auth.CurrentUserID())
 	})
 }
 
@@ -97,20 +100,26 @@ func (h *handler) Get(ctx context.Context, nameStr string, reference string) (*m
 }
 
 // List implements manifest.ManifestHandler.
-func (h *handler) List(ctx context.Context, n int) ([]string, error) {
+func (h *handler) List(ctx context.Context, n int, includeEmpty bool) ([]string, error) {
 	auth := auth.ArtifactsAuthentication.Require(ctx)
+	filter := types.ArtifactListFilter{TaggedOnly: !includeEmpty}
+	if auth.CurrentCustomerOrgID() == nil && auth.CurrentUserRole() != nil {
+		filter.RestrictToReadableBy(auth.CurrentUserID(), *auth.CurrentUserRole())
+	}
 	var artifacts []types.ArtifactWithDownloads
 	var err error
 	if auth.CurrentOrg().HasFeature(types.FeatureLicensing) && auth.CurrentCustomerOrgID() != nil {
 		if licenses, err1 := db.GetArtifactLicenses(ctx, *auth.CurrentOrgID()); err1 != nil {
 			err = err1
 		} else if len(licenses) > 0 {
-			artifacts, err = db.GetArtifactsByLicenseOwnerID(ctx, *auth.CurrentOrgID(), *auth.CurrentCustomerOrgID())
+			artifacts, err = db.GetArtifactsByLicenseOwnerID(
+				ctx, *auth.CurrentOrgID(), *auth.CurrentCustomerOrgID(), filter,
+			)
 		} else {
-			artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID())
+			artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID(), filter)
 		}
 	} else {
-		artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID())
+		artifacts, err = db.GetArtifactsByOrgID(ctx, *auth.CurrentOrgID(), filter)
 	}
 	if err != nil {
 		return nil, err
@@ -142,26 +151,34 @@ func (h *handler) ListDigests(ctx context.Context, nameStr string) ([]digest.Dig
 				return nil, fmt.Errorf("%w: %w", manifest.ErrNameUnknown, err)
 			}
 			return nil, err
-		} else if versions, err := db.GetVersionsForArtifact(
-			ctx,
-			artifact.ID,
-			licenseCustomerOrgID,
-		); err != nil {
+		} else if licenseCustomerOrgID == nil {
+			// without licensing, the cheaper query without license checks and download metrics is sufficient
+			if versions, err := db.GetVersionsForArtifactBasic(ctx, artifact.ID); err != nil {
+				return nil, err
+			} else {
+				return parseDigests(versions, func(v types.BasicArtifactVersion) string {
+					return string(v.ManifestBlobDigest)
+				}), nil
+			}
+		} else if versions, err := db.GetVersionsForArtifact(ctx, artifact.ID, licenseCustomerOrgID); err != nil {
 			return nil, err
 		} else {
-			var result []digest.Digest
-			for _, version := range versions {
-				if h, err := digest.Parse(version.Digest); err != nil {
-					continue
-				} else {
-					result = append(result, h)
-				}
-			}
-			return result, nil
+			return parseDigests(versions, func(v types.TaggedArtifactVersion) string { return v.Digest }), nil
 		}
 	}
 }
 
+// parseDigests returns the valid digests of the given versions.
+func parseDigests[T any](versions []T, getDigest func(T) string) []digest.Digest {
+	var result []digest.Digest
+	for _, version := range versions {
+		if h, err := digest.Parse(getDigest(version)); err == nil {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
 // ListTags implements manifest.ManifestHandler.
 func (h *handler) ListTags(ctx context.Context, nameStr string, n int, last string) ([]string, error) {
 	if name, err := name.Parse(nameStr); err != nil {
@@ -177,21 +194,24 @@ func (h *handler) ListTags(ctx context.Context, nameStr string, n int, last stri
 				return nil, fmt.Errorf("%w: %w", manifest.ErrNameUnknown, err)
 			}
 			return nil, err
-		} else if versions, err := db.GetVersionsForArtifact(
-			ctx,
-			artifact.ID,
-			licenseCustomerOrgID,
-		); err != nil {
-			return nil, err
 		} else {
-			var result []string
-			for _, version := range versions {
-				for _, tag := range version.Tags {
-					result = append(result, tag.Name)
-				}
-			}
-			return result, nil
+			return db.GetTagNamesForArtifact(ctx, artifact.ID, licenseCustomerOrgID, last, n)
+		}
+	}
+}
+
+// getArtifactForPut returns the artifact a manifest is pushed to. If it does not exist yet, it is created, unless the
+// organization requires artifacts to be created before they can be pushed.
+func getArtifactForPut(ctx context.Context, org types.Organization, name name.Name) (*types.Artifact, error) {
+	if !org.RequireArtifactPrecreation {
+		return db.GetOrCreateArtifact(ctx, org.ID, name.ArtifactName)
+	} else if artifact, err := db.GetArtifactByName(ctx, name.OrgName, name.ArtifactName); err != nil {
+		if errors.Is(err, apierrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %v", manifest.ErrArtifactNotCreated, name)
 		}
+		return nil, err
+	} else {
+		return artifact, nil
 	}
 }
 
@@ -207,8 +227,9 @@ func (h *handler) Put(
 	if err != nil {
 		return err
 	}
-	return db.RunTx(ctx, func(ctx context.Context) error {
-		artifact, err := db.GetOrCreateArtifact(ctx, *auth.CurrentOrgID(), name.ArtifactName)
+	webhooksQueued := false
+	err = db.RunTx(ctx, func(ctx context.Context) error {
+		artifact, err := getArtifactForPut(ctx, *auth.CurrentOrg(), *name)
 		if err != nil {
 			return err
 		}
@@ -221,6 +242,7 @@ func (h *handler) Put(
 			ManifestContentType:    manifestData.ContentType,
 			ManifestData:           manifestData.Data,
 			ArtifactID:             artifact.ID,
+			InferredType:           types.InferManifestType(manifestData.ContentType, manifestData.Data),
 		}
 
 		if existingVersion, err := db.GetArtifactVersion(ctx, name.OrgName, name.ArtifactName, reference); err != nil {
@@ -229,14 +251,19 @@ func (h *handler) Put(
 			} else if quotaOk, err := db.EnsureArtifactTagLimitForInsert(ctx, *auth.CurrentOrgID()); err != nil {
 				return err
 			} else if !quotaOk {
-				return apierrors.ErrQuotaExceeded
+				return apierrors.ErrTagQuotaExceeded
 			}
 		} else if existingVersion.ManifestBlobDigest == types.Digest(reference) {
 			// Tag already exists with the same content: nothing to do
 			return nil
 		} else if !auth.CurrentOrg().HasFeature(types.FeatureArtifactVersionMutable) {
-			return fmt.Errorf("%w: tag %s already exists with different content", manifest.ErrTagAlreadyExists, reference)
-		} else if err := db.DeleteArtifactVersion(ctx, existingVersion.ArtifactID, existingVersion.Name); err != nil {
+			return &manifest.TagAlreadyExistsError{
+				Tag:    reference,
+				Digest: digest.Digest(existingVersion.ManifestBlobDigest),
+			}
+		} else if err := db.DeleteArtifactVersion(
+			ctx, existingVersion.ArtifactID, existingVersion.Name, auth.CurrentUserID(),
+		); err != nil {
 			return err
 		}
 
@@ -254,6 +281,43 @@ func (h *handler) Put(
 				return err
 			}
 		}
-		return nil
+
+		// A manifest is first stored by its digest and only then by its tag, so its subject and platforms are only
+		// stored for the digest version.
+		if reference == manifestData.Digest.String() {
+			if platforms, err := types.ParseManifestPlatforms(manifestData.ContentType, manifestData.Data); err != nil {
+				return fmt.Errorf("could not parse index: %w", err)
+			} else if err := db.CreateArtifactVersionPlatforms(ctx, version.ID, platforms); err != nil {
+				return err
+			}
+			if subject := manifestSubject(manifestData.Data); subject != nil {
+				if err := db.SetArtifactVersionSubject(ctx, version.ID, types.Digest(*subject)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if reference != manifestData.Digest.String() {
+			if webhooksQueued, err = notification.EnqueueArtifactPushWebhooks(
+				ctx,
+				*auth.CurrentOrgID(),
+				name.OrgName,
+				name.ArtifactName,
+				reference,
+				types.Digest(manifestData.Digest),
+				auth.CurrentUserID(),
+				auth.CurrentUserEmail(),
+			); err != nil {
+				return err
+			}
+		}
+
+		// Blobs are checked individually at upload time, but only content referenced by a manifest counts towards the
+		// quota. Checking again here makes sure that the quota also holds for manifests with many layers.
+		return db.EnsureOrganizationStorageQuota(ctx, *auth.CurrentOrgID(), 0)
 	})
+	if err == nil && webhooksQueued {
+		notification.DeliverArtifactPushWebhooksAsync(ctx)
+	}
+	return err
 }
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/auth"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/db/dbtest"
+	"github.com/distr-sh/distr/internal/registry/manifest"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"go.uber.org/zap"
+)
+
+// testAuthInfo authenticates a user of an organization without a token.
+type testAuthInfo struct {
+	user          *types.UserAccount
+	org           *types.Organization
+	customerOrgID *uuid.UUID
+}
+
+func (i *testAuthInfo) CurrentUserID() uuid.UUID         { return i.user.ID }
+func (i *testAuthInfo) CurrentUserEmail() string         { return i.user.Email }
+func (i *testAuthInfo) CurrentUserRole() *types.UserRole { return util.PtrTo(types.UserRoleAdmin) }
+func (i *testAuthInfo) CurrentOrgID() *uuid.UUID         { return &i.org.ID }
+func (i *testAuthInfo) CurrentCustomerOrgID() *uuid.UUID { return i.customerOrgID }
+func (i *testAuthInfo) CurrentUserEmailVerified() bool   { return true }
+func (i *testAuthInfo) IsSuperAdmin() bool               { return false }
+func (i *testAuthInfo) Token() any                       { return nil }
+func (i *testAuthInfo) CurrentOrg() *types.Organization  { return i.org }
+
+func testManifest(content string) manifest.Manifest {
+	return testManifestData([]byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"content":"` + content + `"}`))
+}
+
+func testManifestData(data []byte) manifest.Manifest {
+	return manifest.Manifest{
+		BlobWithData: manifest.BlobWithData{
+			Blob: manifest.Blob{Digest: digest.FromBytes(data), Size: int64(len(data))},
+			Data: data,
+		},
+		ContentType: "application/vnd.oci.image.manifest.v1+json",
+	}
+}
+
+// TestPutExistingTag checks that pushing to an existing immutable tag fails with the digest the tag references.
+func TestPutExistingTag(t *testing.T) {
+	g := NewWithT(t)
+	ctx := internalctx.WithLogger(dbtest.TxContext(t, nil), zap.NewNop())
+
+	org := dbtest.CreateOrganization(t, ctx)
+	user := dbtest.CreateUserAccount(t, ctx)
+	ctx = auth.ArtifactsAuthentication.NewContext(ctx, &testAuthInfo{user: &user, org: &org})
+
+	h := NewManifestHandler()
+	repo := *org.Slug + "/app"
+	put := func(reference string, mf manifest.Manifest) error {
+		return h.Put(ctx, repo, reference, mf, nil)
+	}
+
+	original := testManifest("original")
+	g.Expect(put(original.Digest.String(), original)).To(Succeed())
+	g.Expect(put("1.0.0", original)).To(Succeed())
+	version, err := db.GetArtifactVersion(ctx, *org.Slug, "app", "1.0.0")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	changed := testManifest("changed")
+	g.Expect(put(changed.Digest.String(), changed)).To(Succeed())
+	err = put("1.0.0", changed)
+	g.Expect(err).To(MatchError(manifest.ErrTagAlreadyExists))
+	var tagErr *manifest.TagAlreadyExistsError
+	g.Expect(errors.As(err, &tagErr)).To(BeTrue())
+	g.Expect(tagErr.Tag).To(Equal("1.0.0"))
+	g.Expect(tagErr.Digest).To(Equal(original.Digest))
+	unchanged, err := db.GetArtifactVersion(ctx, *org.Slug, "app", "1.0.0")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(unchanged.ID).To(Equal(version.ID))
+}
+
+// TestListReferrers checks that the referrers of a manifest are found without creating any tags, and that customers
+// only see referrers covered by their licenses.
+func TestListReferrers(t *testing.T) {
+	g := NewWithT(t)
+	ctx := internalctx.WithLogger(dbtest.TxContext(t, nil), zap.NewNop())
+
+	org := dbtest.CreateOrganization(t, ctx, types.FeatureLicensing)
+	user := dbtest.CreateUserAccount(t, ctx)
+	vendorCtx := auth.ArtifactsAuthentication.NewContext(ctx, &testAuthInfo{user: &user, org: &org})
+
+	h := NewManifestHandler()
+	repo := *org.Slug + "/app"
+	push := func(mf manifest.Manifest, tag string) {
+		g.Expect(h.Put(vendorCtx, repo, mf.Digest.String(), mf, nil)).To(Succeed())
+		if tag != "" {
+			g.Expect(h.Put(vendorCtx, repo, tag, mf, nil)).To(Succeed())
+		}
+	}
+
+	image := testManifest("image")
+	push(image, "1.0.0")
+	signature := testManifestData([]byte(`{"schemaVersion":2,` +
+		`"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"artifactType":"application/vnd.dev.cosign.artifact.sig.v1+json",` +
+		`"subject":{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + image.Digest.String() +
+		`","size":` + fmt.Sprint(image.Size) + `}}`))
+	push(signature, "")
+	push(testManifest("unrelated"), "2.0.0")
+
+	g.Expect(h.ListTags(vendorCtx, repo, 0, "")).To(Equal([]string{"1.0.0", "2.0.0"}))
+
+	referrers, err := h.(manifest.ReferrersHandler).ListReferrers(vendorCtx, repo, image.Digest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(referrers).To(HaveLen(1))
+	g.Expect(referrers[0].Digest).To(Equal(signature.Digest))
+	g.Expect(referrers[0].Size).To(Equal(signature.Size))
+	g.Expect(referrers[0].ArtifactType).To(Equal("application/vnd.dev.cosign.artifact.sig.v1+json"))
+
+	g.Expect(h.(manifest.ReferrersHandler).ListReferrers(vendorCtx, repo, signature.Digest)).To(BeEmpty())
+	_, err = h.(manifest.ReferrersHandler).ListReferrers(vendorCtx, *org.Slug+"/unknown", image.Digest)
+	g.Expect(err).To(MatchError(manifest.ErrNameUnknown))
+
+	artifact, err := db.GetArtifactByName(ctx, *org.Slug, "app")
+	g.Expect(err).NotTo(HaveOccurred())
+	tagged, err := db.GetArtifactVersion(ctx, *org.Slug, "app", "1.0.0")
+	g.Expect(err).NotTo(HaveOccurred())
+	customerReferrers := func(allVersions bool) []string {
+		customerOrg := types.CustomerOrganization{OrganizationID: org.ID, Name: fmt.Sprintf("Customer %v", allVersions)}
+		g.Expect(db.CreateCustomerOrganization(ctx, &customerOrg)).To(Succeed())
+		license := types.ArtifactLicenseBase{
+			Name:                   fmt.Sprintf("License %v", allVersions),
+			OrganizationID:         org.ID,
+			CustomerOrganizationID: &customerOrg.ID,
+		}
+		g.Expect(db.CreateArtifactLicense(ctx, &license)).To(Succeed())
+		var versionID *uuid.UUID
+		if !allVersions {
+			versionID = &tagged.ID
+		}
+		g.Expect(db.AddArtifactToArtifactLicense(ctx, license.ID, artifact.ID, versionID)).To(Succeed())
+
+		customerCtx := auth.ArtifactsAuthentication.NewContext(ctx,
+			&testAuthInfo{user: &user, org: &org, customerOrgID: &customerOrg.ID})
+		referrers, err := h.(manifest.ReferrersHandler).ListReferrers(customerCtx, repo, image.Digest)
+		g.Expect(err).NotTo(HaveOccurred())
+		digests := make([]string, len(referrers))
+		for i, referrer := range referrers {
+			digests[i] = referrer.Digest.String()
+		}
+		return digests
+	}
+	g.Expect(customerReferrers(true)).To(Equal([]string{signature.Digest.String()}))
+	g.Expect(customerReferrers(false)).To(BeEmpty())
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/distr-sh/distr/internal/apierrors"
+	"github.com/distr-sh/distr/internal/auth"
+	"github.com/distr-sh/distr/internal/db"
+	"github.com/distr-sh/distr/internal/registry/manifest"
+	"github.com/distr-sh/distr/internal/registry/name"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ListReferrers implements manifest.ReferrersHandler. Customers only see referrers that are covered by their licenses,
+// like for ListDigests.
+func (h *handler) ListReferrers(
+	ctx context.Context,
+	nameStr string,
+	subject digest.Digest,
+) ([]imgspecv1.Descriptor, error) {
+	name, err := name.Parse(nameStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", manifest.ErrNameUnknown, err)
+	}
+	auth := auth.ArtifactsAuthentication.Require(ctx)
+	var licenseCustomerOrgID *uuid.UUID
+	if auth.CurrentOrg().HasFeature(types.FeatureLicensing) && auth.CurrentCustomerOrgID() != nil {
+		licenseCustomerOrgID = auth.CurrentCustomerOrgID()
+	}
+	artifact, err := db.GetArtifactByName(ctx, name.OrgName, name.ArtifactName)
+	if err != nil {
+		if errors.Is(err, apierrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", manifest.ErrNameUnknown, err)
+		}
+		return nil, err
+	}
+	referrers, err := db.GetReferrersForDigest(ctx, artifact.ID, licenseCustomerOrgID, types.Digest(subject))
+	if err != nil {
+		return nil, err
+	}
+	descriptors := make([]imgspecv1.Descriptor, len(referrers))
+	for i, referrer := range referrers {
+		descriptors[i] = referrerDescriptor(referrer)
+	}
+	return descriptors, nil
+}
+
+// referrerDescriptor returns the descriptor of the given version in a referrers index. As required by the OCI
+// distribution spec, the artifact type falls back to the config media type of the manifest.
+func referrerDescriptor(av types.ArtifactVersion) imgspecv1.Descriptor {
+	var m struct {
+		ArtifactType string `json:"artifactType"`
+		Config       struct {
+			MediaType string `json:"mediaType"`
+		} `json:"config"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	_ = json.Unmarshal(av.ManifestData, &m)
+	if m.ArtifactType == "" {
+		m.ArtifactType = m.Config.MediaType
+	}
+	return imgspecv1.Descriptor{
+		MediaType:    av.ManifestContentType,
+		Digest:       digest.Digest(av.ManifestBlobDigest),
+		Size:         av.ManifestBlobSize,
+		ArtifactType: m.ArtifactType,
+		Annotations:  m.Annotations,
+	}
+}
+
+// manifestSubject returns the digest of the subject of the given manifest, or nil if it has none.
+func manifestSubject(data []byte) *digest.Digest {
+	var m struct {
+		Subject *imgspecv1.Descriptor `json:"subject"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil || m.Subject == nil {
+		return nil
+	}
+	return &m.Subject.Digest
+}
@@ -1,9 +1,32 @@
 package manifest
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+)
 
 var (
 	ErrNameUnknown      = errors.New("unknown name")
 	ErrManifestUnknown  = errors.New("unknown manifest")
 	ErrTagAlreadyExists = errors.New("tag already exists")
+	// ErrArtifactNotCreated is returned if a manifest is pushed to an artifact that does not exist yet, but the
+	// organization requires artifacts to be created before they can be pushed. It matches ErrNameUnknown.
+	ErrArtifactNotCreated = fmt.Errorf("%w: artifact must be created before it can be pushed", ErrNameUnknown)
 )
+
+// TagAlreadyExistsError is returned if a tag cannot be overwritten because it already references different content.
+// It matches ErrTagAlreadyExists and carries the digest of the manifest the tag currently references.
+type TagAlreadyExistsError struct {
+	Tag    string
+	Digest digest.Digest
+}
+
+func (err *TagAlreadyExistsError) Error() string {
+	return fmt.Sprintf("%v: tag %v already exists with different content (%v)", ErrTagAlreadyExists, err.Tag, err.Digest)
+}
+
+func (err *TagAlreadyExistsError) Unwrap() error {
+	return ErrTagAlreadyExists
+}
@@ -5,6 +5,7 @@ import (
 	"maps"
 	"slices"
 	"sort"
+	"sync"
 
 	"github.com/distr-sh/distr/internal/registry/manifest"
 	"github.com/opencontainers/go-digest"
@@ -12,6 +13,7 @@ import (
 
 type handler struct {
 	manifests map[string]map[string]manifest.Manifest
+	lock      sync.RWMutex
 }
 
 func NewManifestHandler() manifest.ManifestHandler {
@@ -22,8 +24,13 @@ func NewManifestHandler() manifest.ManifestHandler {
 
 // Delete implements manifest.ManifestHandler.
 func (h *handler) Delete(ctx context.Context, name string, reference string) error {
-	if _, err := h.Get(ctx, name, reference); err != nil {
-		return err
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if references, ok := h.manifests[name]; !ok {
+		return manifest.ErrNameUnknown
+	} else if _, ok := references[reference]; !ok {
+		return manifest.ErrManifestUnknown
 	}
 	delete(h.manifests[name], reference)
 	return nil
@@ -31,6 +38,9 @@ func (h *handler) Delete(ctx context.Context, name string, reference string) err
 
 // Get implements manifest.ManifestHandler.
 func (h *handler) Get(ctx context.Context, name string, reference string) (*manifest.Manifest, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
 	if references, ok := h.manifests[name]; !ok {
 		return nil, manifest.ErrNameUnknown
 	} else if m, ok := references[reference]; !ok {
@@ -41,8 +51,21 @@ func (h *handler) Get(ctx context.Context, name string, reference string) (*mani
 }
 
 // List implements manifest.ManifestHandler.
-func (h *handler) List(ctx context.Context, n int) ([]string, error) {
-	names := slices.Collect(maps.Keys(h.manifests))
+func (h *handler) List(ctx context.Context, n int, includeEmpty bool) ([]string, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	names := slices.Sorted(maps.Keys(h.manifests))
+	if !includeEmpty {
+		names = slices.DeleteFunc(names, func(name string) bool {
+			for reference := range h.manifests[name] {
+				if _, err := digest.Parse(reference); err != nil {
+					return false
+				}
+			}
+			return true
+		})
+	}
 	if 0 < n && n < len(names) {
 		names = names[:n]
 	}
@@ -51,6 +74,9 @@ func (h *handler) List(ctx context.Context, n int) ([]string, error) {
 
 // ListTags implements manifest.ManifestHandler.
 func (h *handler) ListTags(ctx context.Context, name string, n int, last string) ([]string, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
 	referencesMap, ok := h.manifests[name]
 	if !ok {
 		return nil, manifest.ErrNameUnknown
@@ -67,12 +93,11 @@ func (h *handler) ListTags(ctx context.Context, name string, n int, last string)
 	sort.Strings(references)
 
 	if last != "" {
-		for i, reference := range references {
-			if reference > last {
-				references = references[i:]
-				break
-			}
+		i, found := slices.BinarySearch(references, last)
+		if found {
+			i++
 		}
+		references = references[i:]
 	}
 
 	if 0 < n && n < len(references) {
@@ -84,6 +109,9 @@ func (h *handler) ListTags(ctx context.Context, name string, n int, last string)
 
 // ListDigests implements manifest.ManifestHandler.
 func (h *handler) ListDigests(ctx context.Context, name string) ([]digest.Digest, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
 	references, ok := h.manifests[name]
 	if !ok {
 		return nil, manifest.ErrNameUnknown
@@ -107,6 +135,9 @@ func (h *handler) Put(
 	m manifest.Manifest,
 	_ []manifest.Blob,
 ) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
 	if _, ok := h.manifests[name]; !ok {
 		h.manifests[name] = make(map[string]manifest.Manifest)
 	}
@@ -4,10 +4,13 @@ import (
 	"context"
 
 	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type ManifestHandler interface {
-	List(ctx context.Context, n int) ([]string, error)
+	// List returns the names of up to n repositories. Repositories without any tag are only included if includeEmpty
+	// is true, because they cannot be pulled by tag.
+	List(ctx context.Context, n int, includeEmpty bool) ([]string, error)
 	// ListTags
 	//
 	// Spec for implementation:
@@ -23,3 +26,11 @@ type ManifestHandler interface {
 	Put(ctx context.Context, name string, reference string, manifest Manifest, blobs []Blob) error
 	Delete(ctx context.Context, name string, reference string) error
 }
+
+// ReferrersHandler is implemented by manifest handlers that can look up the referrers of a manifest directly instead of
+// reading every manifest of the repository.
+type ReferrersHandler interface {
+	// ListReferrers returns the descriptors of all manifests of the repository that are visible for the current user
+	// and have the manifest with the given digest as subject.
+	ListReferrers(ctx context.Context, name string, subject digest.Digest) ([]imgspecv1.Descriptor, error)
+}
@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	imanifest "github.com/distr-sh/distr/internal/registry/manifest"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestAcceptsMediaType(t *testing.T) {
+	const (
+		ociManifest = "application/vnd.oci.image.manifest.v1+json"
+		ociIndex    = "application/vnd.oci.image.index.v1+json"
+	)
+	tests := []struct {
+		name      string
+		accept    []string
+		mediaType string
+		expected  bool
+	}{
+		{"no accept header", nil, ociManifest, true},
+		{"empty accept header", []string{""}, ociManifest, true},
+		{"no media type", []string{ociIndex}, "", true},
+		{"exact match", []string{ociManifest}, ociManifest, true},
+		{"no match", []string{ociIndex}, ociManifest, false},
+		{"comma separated", []string{ociIndex + ", " + ociManifest}, ociManifest, true},
+		{"multiple values", []string{ociIndex, ociManifest}, ociManifest, true},
+		{"wildcard", []string{"*/*"}, ociManifest, true},
+		{"type wildcard", []string{"application/*"}, ociManifest, true},
+		{"other type wildcard", []string{"text/*"}, ociManifest, false},
+		{"media type parameters", []string{ociManifest}, ociManifest + "; charset=utf-8", true},
+		{"accept parameters", []string{ociManifest + ";q=0.5"}, ociManifest, true},
+		{"rejected with q=0", []string{ociManifest + ";q=0"}, ociManifest, false},
+		{"invalid range is skipped", []string{"/, " + ociManifest}, ociManifest, true},
+		{"only invalid range", []string{"/"}, ociManifest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(acceptsMediaType(tt.accept, tt.mediaType)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestManifestPutErrorTagAlreadyExists(t *testing.T) {
+	g := NewWithT(t)
+	d := digest.FromString("existing")
+
+	regErr := manifestPutError(fmt.Errorf("put failed: %w", &imanifest.TagAlreadyExistsError{Tag: "1.0.0", Digest: d}))
+	g.Expect(regErr.Status).To(Equal(http.StatusConflict))
+	g.Expect(regErr.Code).To(Equal("UNSUPPORTED"))
+	g.Expect(regErr.Message).To(ContainSubstring(d.String()))
+	g.Expect(regErr.Header.Get("Docker-Content-Digest")).To(Equal(d.String()))
+
+	regErr = manifestPutError(imanifest.ErrTagAlreadyExists)
+	g.Expect(regErr).To(BeIdenticalTo(regErrTagAlreadyExists))
+	g.Expect(regErr.Header).To(BeEmpty())
+}
+
+func TestCheckTargetDigest(t *testing.T) {
+	data := []byte(`{"schemaVersion":2}`)
+	mf := imanifest.Manifest{
+		BlobWithData: imanifest.BlobWithData{
+			Blob: imanifest.Blob{Digest: digest.FromBytes(data), Size: int64(len(data))},
+			Data: data,
+		},
+	}
+	tests := []struct {
+		name     string
+		target   string
+		expected *regError
+	}{
+		{"tag", "1.0.0", nil},
+		{"sha256", digest.SHA256.FromBytes(data).String(), nil},
+		{"sha512", digest.SHA512.FromBytes(data).String(), nil},
+		{"sha256 mismatch", digest.SHA256.FromString("other").String(), regErrDigestMismatch},
+		{"sha512 mismatch", digest.SHA512.FromString("other").String(), regErrDigestMismatch},
+		{"invalid digest", "sha256:invalid", regErrDigestInvalid},
+		{"unsupported algorithm", "md5:d41d8cd98f00b204e9800998ecf8427e", regErrDigestInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewWithT(t).Expect(checkTargetDigest(tt.target, mf)).To(BeIdenticalTo(tt.expected))
+		})
+	}
+}
@@ -33,6 +33,8 @@ import (
 
 	"github.com/distr-sh/distr/internal/auth"
 	"github.com/distr-sh/distr/internal/authn/authinfo"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/mail"
 	"github.com/distr-sh/distr/internal/middleware"
 	"github.com/distr-sh/distr/internal/registry/audit"
@@ -69,6 +71,11 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 	}
 
+	if env.ReadOnlyMode() && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp.Header().Set("Retry-After", middleware.ReadOnlyModeRetryAfter)
+		return regErrReadOnly
+	}
+
 	if isBlob(req) {
 		return r.blobs.handle(resp, req)
 	}
@@ -108,11 +115,25 @@ func (r *registry) root(resp http.ResponseWriter, req *http.Request) {
 	r.log.Infof("%s %s", req.Method, req.URL)
 }
 
+// NewDisabled returns a handler that responds to all requests with a registry error explaining that the registry is
+// not enabled on this instance. It is used in place of the registry, so that clients get a meaningful error.
+func NewDisabled() http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Header().Set("Content-Type", "application/json")
+		resp.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		_ = regErrRegistryDisabled.Write(resp)
+	})
+}
+
 // New returns a handler which implements the docker registry protocol.
 // It should be registered at the site root.
 func New(opts ...Option) http.Handler {
 	reg := &registry{
-		manifests: manifests{},
+		manifests: manifests{
+			defaultPageSize:  defaultPageSize,
+			maxPageSize:      defaultPageSize,
+			maxManifestBytes: defaultMaxManifestBytes,
+		},
 	}
 	for _, o := range opts {
 		o(reg)
@@ -137,15 +158,22 @@ func NewDefault(
 		WithBlobHandler(s3.NewBlobHandler(ctx)),
 		WithManifestHandler(db.NewManifestHandler()),
 		WithAuthorizer(authz.NewAuthorizer()),
-		WithAuditor(audit.NewAuditor()),
+		WithAuditor(audit.NewRetryingAuditor(
+			internalctx.WithLogger(internalctx.WithDb(ctx, pool), logger),
+			env.RegistryAuditRetryQueueSize(),
+			env.RegistryAuditRetryInterval(),
+		)),
+		WithPageSize(env.RegistryDefaultPageSize(), env.RegistryMaxPageSize()),
+		WithMaxManifestSize(int64(env.RegistryMaxManifestBytes())),
 		WithMiddlewares(
 			chimiddleware.Recoverer,
 			chimiddleware.RequestID,
-			chimiddleware.RealIP,
+			middleware.RealIP(env.TrustedProxyCIDRs(), env.TrustedProxyHeaders()),
 			middleware.OTEL(tracer),
 			middleware.Sentry,
 			middleware.LoggerCtxMiddleware(logger),
 			middleware.LoggingMiddleware,
+			CORS(env.RegistryCORSAllowedOrigins()),
 			middleware.ContextInjectorMiddleware(pool, mailer, nil),
 			auth.ArtifactsAuthentication.Middleware,
 			auth.ArtifactsAuthentication.ValidatorMiddleware(func(value authinfo.AuthInfoWithOrganization) error {
@@ -158,6 +186,13 @@ func NewDefault(
 	)
 }
 
+const (
+	// defaultPageSize is the default and maximum number of entries returned by the tags and catalog endpoints.
+	defaultPageSize = 10000
+	// defaultMaxManifestBytes is the maximum size of a pushed manifest.
+	defaultMaxManifestBytes = 4 << 20
+)
+
 // Option describes the available options
 // for creating the registry.
 type Option func(r *registry)
@@ -171,6 +206,23 @@ func WithLogger(l *zap.Logger) Option {
 	}
 }
 
+// WithPageSize overrides the number of entries returned by the tags and catalog endpoints if the client does not
+// request a specific number, as well as the maximum number a client may request. Larger requests are clamped.
+func WithPageSize(defaultSize, maxSize int) Option {
+	return func(r *registry) {
+		r.manifests.defaultPageSize = defaultSize
+		r.manifests.maxPageSize = maxSize
+	}
+}
+
+// WithMaxManifestSize overrides the maximum size in bytes of a pushed manifest. Larger manifests are rejected before
+// they are parsed.
+func WithMaxManifestSize(maxBytes int64) Option {
+	return func(r *registry) {
+		r.manifests.maxManifestBytes = maxBytes
+	}
+}
+
 // WithReferrersSupport enables the referrers API endpoint (OCI 1.1+)
 func WithReferrersSupport(enabled bool) Option {
 	return func(r *registry) {
@@ -0,0 +1,506 @@
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/distr-sh/distr/internal/auth"
+	"github.com/distr-sh/distr/internal/authn/authinfo"
+	internalctx "github.com/distr-sh/distr/internal/context"
+	"github.com/distr-sh/distr/internal/registry"
+	"github.com/distr-sh/distr/internal/registry/authz"
+	blobinmemory "github.com/distr-sh/distr/internal/registry/blob/inmemory"
+	imanifest "github.com/distr-sh/distr/internal/registry/manifest"
+	manifestinmemory "github.com/distr-sh/distr/internal/registry/manifest/inmemory"
+	"github.com/distr-sh/distr/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/zap"
+)
+
+type allowAllAuthorizer struct{}
+
+func (allowAllAuthorizer) Authorize(context.Context, string, authz.Action) error { return nil }
+
+func (allowAllAuthorizer) AuthorizeReference(context.Context, string, string, authz.Action) error {
+	return nil
+}
+
+func (allowAllAuthorizer) AuthorizeBlob(context.Context, digest.Digest, authz.Action) error {
+	return nil
+}
+
+type noopAuditor struct{}
+
+func (noopAuditor) AuditPull(context.Context, string, string) error            { return nil }
+func (noopAuditor) AuditPullWithinLimit(context.Context, string, string) error { return nil }
+
+// noopTx is a transaction that does nothing. The in-memory handlers do not use the database, but the registry runs
+// manifest pushes in a transaction.
+type noopTx struct{ pgx.Tx }
+
+func (tx noopTx) Begin(context.Context) (pgx.Tx, error) { return tx, nil }
+func (noopTx) Commit(context.Context) error             { return nil }
+func (noopTx) Rollback(context.Context) error           { return nil }
+
+// anonymousAuthInfo authenticates requests without an organization, so that no organization quotas are checked.
+type anonymousAuthInfo struct{ authinfo.AuthInfo }
+
+func (anonymousAuthInfo) CurrentOrgID() *uuid.UUID         { return nil }
+func (anonymousAuthInfo) CurrentOrg() *types.Organization  { return nil }
+func (anonymousAuthInfo) CurrentCustomerOrgID() *uuid.UUID { return nil }
+func (anonymousAuthInfo) CurrentUserRole() *types.UserRole { return nil }
+func (anonymousAuthInfo) CurrentUserID() uuid.UUID         { return uuid.Nil }
+func (anonymousAuthInfo) IsSuperAdmin() bool               { return false }
+func (anonymousAuthInfo) CurrentUserEmailVerified() bool   { return true }
+func (anonymousAuthInfo) CurrentUserEmail() string         { return "" }
+func (anonymousAuthInfo) Token() any                       { return nil }
+
+// immutableTagsHandler rejects pushes to an existing tag, like the database manifest handler does for organizations
+// without mutable artifact versions.
+type immutableTagsHandler struct{ imanifest.ManifestHandler }
+
+func (h immutableTagsHandler) Put(
+	ctx context.Context,
+	name, reference string,
+	mf imanifest.Manifest,
+	blobs []imanifest.Blob,
+) error {
+	if existing, err := h.Get(ctx, name, reference); err == nil {
+		return &imanifest.TagAlreadyExistsError{Tag: reference, Digest: existing.Digest}
+	}
+	return h.ManifestHandler.Put(ctx, name, reference, mf, blobs)
+}
+
+// listedReferrersHandler looks up referrers in a fixed map instead of reading every manifest.
+type listedReferrersHandler struct {
+	imanifest.ManifestHandler
+	referrers map[digest.Digest][]imgspecv1.Descriptor
+}
+
+func (h listedReferrersHandler) ListReferrers(
+	ctx context.Context,
+	name string,
+	subject digest.Digest,
+) ([]imgspecv1.Descriptor, error) {
+	if _, err := h.ListDigests(ctx, name); err != nil {
+		return nil, err
+	}
+	return h.referrers[subject], nil
+}
+
+// newTestRegistry starts a registry with in-memory blob and manifest handlers that allows every request.
+func newTestRegistry(t *testing.T, opts ...registry.Option) *httptest.Server {
+	logger := zap.NewNop()
+	opts = append([]registry.Option{
+		registry.WithLogger(logger),
+		registry.WithBlobHandler(blobinmemory.NewBlobHandler()),
+		registry.WithManifestHandler(manifestinmemory.NewManifestHandler()),
+		registry.WithAuthorizer(allowAllAuthorizer{}),
+		registry.WithAuditor(noopAuditor{}),
+		registry.WithMiddlewares(func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := internalctx.WithDb(internalctx.WithLogger(r.Context(), logger), noopTx{})
+				ctx = auth.ArtifactsAuthentication.NewContext(ctx, anonymousAuthInfo{})
+				h.ServeHTTP(w, r.WithContext(ctx))
+			})
+		}),
+	}, opts...)
+	server := httptest.NewServer(registry.New(opts...))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// pushTestManifest pushes an image manifest with the given tag. The config blob is not uploaded, because pushing a
+// manifest does not require its blobs to exist.
+func pushTestManifest(g Gomega, server *httptest.Server, repo, tag string) digest.Digest {
+	resp, d := putTestManifest(g, server, repo, tag, tag)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	return d
+}
+
+// putTestManifest puts an image manifest whose config depends on content to the given reference and returns the
+// response and the digest of the manifest.
+func putTestManifest(
+	g Gomega,
+	server *httptest.Server,
+	repo, reference, content string,
+) (*http.Response, digest.Digest) {
+	config := []byte(`{"content":"` + content + `"}`)
+	data, err := json.Marshal(imgspecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Config: imgspecv1.Descriptor{
+			MediaType: imgspecv1.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+		Layers: []imgspecv1.Descriptor{},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/v2/"+repo+"/manifests/"+reference, bytes.NewReader(data))
+	g.Expect(err).NotTo(HaveOccurred())
+	req.Header.Set("Content-Type", imgspecv1.MediaTypeImageManifest)
+	resp, err := http.DefaultClient.Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	_ = resp.Body.Close()
+	return resp, digest.FromBytes(data)
+}
+
+// doRequest sends a request with the given body to the test registry and returns the response with its body read.
+func doRequest(
+	g Gomega,
+	server *httptest.Server,
+	method, path string,
+	header http.Header,
+	body []byte,
+) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(body))
+	g.Expect(err).NotTo(HaveOccurred())
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	g.Expect(err).NotTo(HaveOccurred())
+	return resp, data
+}
+
+func getJSON[T any](g Gomega, url string) (T, *http.Response) {
+	var result T
+	resp, err := http.Get(url)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		g.Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+	}
+	return result, resp
+}
+
+func TestTagsPageSize(t *testing.T) {
+	server := newTestRegistry(t, registry.WithPageSize(2, 3))
+	for _, tag := range []string{"a", "b", "c", "d"} {
+		pushTestManifest(NewWithT(t), server, "org/app", tag)
+	}
+
+	type tagList struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		tags   []string
+	}{
+		{"default", "", http.StatusOK, []string{"a", "b"}},
+		{"requested", "?n=1", http.StatusOK, []string{"a"}},
+		{"clamped", "?n=1000000", http.StatusOK, []string{"a", "b", "c"}},
+		{"zero", "?n=0", http.StatusOK, []string{}},
+		{"negative", "?n=-1", http.StatusBadRequest, nil},
+		{"invalid", "?n=all", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			list, resp := getJSON[tagList](g, server.URL+"/v2/org/app/tags/list"+tt.query)
+			g.Expect(resp.StatusCode).To(Equal(tt.status))
+			if tt.status == http.StatusOK {
+				g.Expect(list.Name).To(Equal("org/app"))
+				g.Expect(list.Tags).To(Equal(tt.tags))
+			}
+		})
+	}
+}
+
+func TestTagsPagination(t *testing.T) {
+	g := NewWithT(t)
+	server := newTestRegistry(t, registry.WithPageSize(2, 3))
+	for _, tag := range []string{"a", "b", "c", "d", "e"} {
+		pushTestManifest(g, server, "org/app", tag)
+	}
+
+	type tagList struct {
+		Tags []string `json:"tags"`
+	}
+	var pages [][]string
+	var links []string
+	next := "/v2/org/app/tags/list"
+	for next != "" {
+		list, resp := getJSON[tagList](g, server.URL+next)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		pages = append(pages, list.Tags)
+		link := resp.Header.Get("Link")
+		links = append(links, link)
+		next = ""
+		if link != "" {
+			g.Expect(link).To(HavePrefix("<"))
+			g.Expect(link).To(HaveSuffix(`>; rel="next"`))
+			next = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		}
+	}
+	g.Expect(pages).To(Equal([][]string{{"a", "b"}, {"c", "d"}, {"e"}}))
+	g.Expect(links).To(Equal([]string{
+		`</v2/org/app/tags/list?last=b&n=2>; rel="next"`,
+		`</v2/org/app/tags/list?last=d&n=2>; rel="next"`,
+		"",
+	}))
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		link  string
+	}{
+		{"clamped", "?n=1000000", `</v2/org/app/tags/list?last=c&n=3>; rel="next"`},
+		{"exactly the last page", "?n=2&last=c", ""},
+		{"zero", "?n=0", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, resp := getJSON[tagList](g, server.URL+"/v2/org/app/tags/list"+tt.query)
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Get("Link")).To(Equal(tt.link))
+		})
+	}
+}
+
+func TestCatalogPageSize(t *testing.T) {
+	server := newTestRegistry(t, registry.WithPageSize(2, 3))
+	for _, repo := range []string{"org/a", "org/b", "org/c", "org/d"} {
+		pushTestManifest(NewWithT(t), server, repo, "latest")
+	}
+
+	type catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		repos  []string
+	}{
+		{"default", "", http.StatusOK, []string{"org/a", "org/b"}},
+		{"clamped", "?n=1000000", http.StatusOK, []string{"org/a", "org/b", "org/c"}},
+		{"zero", "?n=0", http.StatusOK, []string{}},
+		{"negative", "?n=-1", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			list, resp := getJSON[catalog](g, server.URL+"/v2/_catalog"+tt.query)
+			g.Expect(resp.StatusCode).To(Equal(tt.status))
+			if tt.status == http.StatusOK {
+				g.Expect(list.Repositories).To(Equal(tt.repos))
+			}
+		})
+	}
+}
+
+func TestPutExistingImmutableTag(t *testing.T) {
+	server := newTestRegistry(t,
+		registry.WithManifestHandler(immutableTagsHandler{manifestinmemory.NewManifestHandler()}))
+	g := NewWithT(t)
+	original := pushTestManifest(g, server, "org/app", "1.0.0")
+
+	resp, _ := putTestManifest(g, server, "org/app", "1.0.0", "changed")
+	g.Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+	g.Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(original.String()))
+
+	req, err := http.NewRequest(http.MethodHead, server.URL+"/v2/org/app/manifests/1.0.0", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	req.Header.Set("Accept", imgspecv1.MediaTypeImageManifest)
+	resp, err = http.DefaultClient.Do(req)
+	g.Expect(err).NotTo(HaveOccurred())
+	_ = resp.Body.Close()
+	g.Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(original.String()))
+}
+
+// TestPushAndPull pushes an image with a monolithic and a chunked blob upload and pulls it again.
+func TestPushAndPull(t *testing.T) {
+	g := NewWithT(t)
+	server := newTestRegistry(t)
+	const repo = "org/app"
+
+	config := []byte(`{}`)
+	configDigest := digest.FromBytes(config)
+	resp, _ := doRequest(g, server, http.MethodPost, "/v2/"+repo+"/blobs/uploads/?digest="+configDigest.String(), nil,
+		config)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	g.Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(configDigest.String()))
+
+	// the layer is uploaded in two chunks, like a client using a chunked upload session would
+	layer := []byte("hello distr")
+	layerDigest := digest.FromBytes(layer)
+	resp, _ = doRequest(g, server, http.MethodPost, "/v2/"+repo+"/blobs/uploads/", nil, nil)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	location := resp.Header.Get("Location")
+	g.Expect(location).NotTo(BeEmpty())
+	resp, _ = doRequest(g, server, http.MethodPatch, location, http.Header{"Content-Range": {"0-4"}}, layer[:5])
+	g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	g.Expect(resp.Header.Get("Range")).To(Equal("0-4"))
+	resp, _ = doRequest(g, server, http.MethodPatch, location, http.Header{"Content-Range": {"3-8"}}, layer[5:])
+	g.Expect(resp.StatusCode).To(Equal(http.StatusRequestedRangeNotSatisfiable))
+	resp, _ = doRequest(g, server, http.MethodPatch, location, http.Header{"Content-Range": {"5-10"}}, layer[5:])
+	g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	g.Expect(resp.Header.Get("Range")).To(Equal("0-10"))
+	resp, _ = doRequest(g, server, http.MethodPut, location+"?digest="+layerDigest.String(), nil, nil)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	resp, _ = doRequest(g, server, http.MethodHead, "/v2/"+repo+"/blobs/"+layerDigest.String(), nil, nil)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	g.Expect(resp.ContentLength).To(BeEquivalentTo(len(layer)))
+
+	manifestData, err := json.Marshal(imgspecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Config: imgspecv1.Descriptor{
+			MediaType: imgspecv1.MediaTypeImageConfig,
+			Digest:    configDigest,
+			Size:      int64(len(config)),
+		},
+		Layers: []imgspecv1.Descriptor{
+			{MediaType: imgspecv1.MediaTypeImageLayer, Digest: layerDigest, Size: int64(len(layer))},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	manifestDigest := digest.FromBytes(manifestData)
+	resp, _ = doRequest(g, server, http.MethodPut, "/v2/"+repo+"/manifests/v1",
+		http.Header{"Content-Type": {imgspecv1.MediaTypeImageManifest}}, manifestData)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	g.Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(manifestDigest.String()))
+
+	type catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	repos, _ := getJSON[catalog](g, server.URL+"/v2/_catalog")
+	g.Expect(repos.Repositories).To(Equal([]string{repo}))
+	type tagList struct {
+		Tags []string `json:"tags"`
+	}
+	tags, _ := getJSON[tagList](g, server.URL+"/v2/"+repo+"/tags/list")
+	g.Expect(tags.Tags).To(Equal([]string{"v1"}))
+
+	for _, reference := range []string{"v1", manifestDigest.String()} {
+		resp, data := doRequest(g, server, http.MethodGet, "/v2/"+repo+"/manifests/"+reference,
+			http.Header{"Accept": {imgspecv1.MediaTypeImageManifest}}, nil)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		g.Expect(resp.Header.Get("Content-Type")).To(Equal(imgspecv1.MediaTypeImageManifest))
+		g.Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(manifestDigest.String()))
+		g.Expect(data).To(Equal(manifestData))
+	}
+
+	var pulledManifest imgspecv1.Manifest
+	g.Expect(json.Unmarshal(manifestData, &pulledManifest)).To(Succeed())
+	for _, descriptor := range append(pulledManifest.Layers, pulledManifest.Config) {
+		resp, data := doRequest(g, server, http.MethodGet, "/v2/"+repo+"/blobs/"+descriptor.Digest.String(), nil, nil)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		g.Expect(digest.FromBytes(data)).To(Equal(descriptor.Digest))
+	}
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"unknown repository", "/v2/org/unknown/manifests/v1", http.StatusNotFound},
+		{"unknown tag", "/v2/" + repo + "/manifests/v2", http.StatusNotFound},
+		{"unknown blob", "/v2/" + repo + "/blobs/" + digest.FromString("unknown").String(), http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			resp, _ := doRequest(g, server, http.MethodGet, tt.path, nil, nil)
+			g.Expect(resp.StatusCode).To(Equal(tt.status))
+		})
+	}
+}
+
+func TestPushManifestBySHA512Digest(t *testing.T) {
+	g := NewWithT(t)
+	server := newTestRegistry(t)
+	data := []byte(`{"schemaVersion":2,"mediaType":"` + imgspecv1.MediaTypeImageManifest + `","config":{"mediaType":"` +
+		imgspecv1.MediaTypeImageConfig + `","digest":"` + digest.FromString("{}").String() + `","size":2},"layers":[]}`)
+	header := http.Header{"Content-Type": {imgspecv1.MediaTypeImageManifest}}
+
+	resp, _ := doRequest(g, server, http.MethodPut, "/v2/org/app/manifests/"+digest.SHA512.FromBytes(data).String(),
+		header, data)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	resp, _ = doRequest(g, server, http.MethodPut, "/v2/org/app/manifests/"+digest.SHA512.FromString("other").String(),
+		header, data)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+}
+
+func TestReferrers(t *testing.T) {
+	g := NewWithT(t)
+	manifests := manifestinmemory.NewManifestHandler()
+	server := newTestRegistry(t, registry.WithReferrersSupport(true), registry.WithManifestHandler(manifests))
+
+	image := pushTestManifest(g, server, "org/app", "1.0.0")
+	pushTestManifest(g, server, "org/app", "2.0.0")
+	const artifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	data, err := json.Marshal(imgspecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Config:    imgspecv1.Descriptor{MediaType: artifactType, Digest: digest.FromString("{}"), Size: 2},
+		Layers:    []imgspecv1.Descriptor{},
+		Subject:   &imgspecv1.Descriptor{MediaType: imgspecv1.MediaTypeImageManifest, Digest: image, Size: 1},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	referrer := digest.FromBytes(data)
+	resp, _ := doRequest(g, server, http.MethodPut, "/v2/org/app/manifests/"+referrer.String(),
+		http.Header{"Content-Type": {imgspecv1.MediaTypeImageManifest}}, data)
+	g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+	expected := imgspecv1.Descriptor{
+		MediaType:    imgspecv1.MediaTypeImageManifest,
+		Digest:       referrer,
+		Size:         int64(len(data)),
+		ArtifactType: artifactType,
+	}
+
+	// the manifests are shared, so the referrers handler sees the same repository
+	listedServer := newTestRegistry(t,
+		registry.WithReferrersSupport(true),
+		registry.WithManifestHandler(listedReferrersHandler{
+			ManifestHandler: manifests,
+			referrers:       map[digest.Digest][]imgspecv1.Descriptor{image: {expected}},
+		}))
+
+	for name, server := range map[string]*httptest.Server{
+		"reading all manifests": server,
+		"referrers handler":     listedServer,
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			index, resp := getJSON[imgspecv1.Index](g, server.URL+"/v2/org/app/referrers/"+image.String())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(resp.Header.Get("Content-Type")).To(Equal(imgspecv1.MediaTypeImageIndex))
+			g.Expect(index.MediaType).To(Equal(imgspecv1.MediaTypeImageIndex))
+			g.Expect(index.Manifests).To(Equal([]imgspecv1.Descriptor{expected}))
+
+			index, resp = getJSON[imgspecv1.Index](g, server.URL+"/v2/org/app/referrers/"+referrer.String())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			g.Expect(index.Manifests).To(BeEmpty())
+
+			_, resp = getJSON[imgspecv1.Index](g, server.URL+"/v2/org/unknown/referrers/"+image.String())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			_, resp = getJSON[imgspecv1.Index](g, server.URL+"/v2/org/app/referrers/1.0.0")
+			g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			tags, _ := getJSON[struct {
+				Tags []string `json:"tags"`
+			}](g, server.URL+"/v2/org/app/tags/list")
+			g.Expect(tags.Tags).To(Equal([]string{"1.0.0", "2.0.0"}))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"path"
 	"text/template"
 
 	"github.com/distr-sh/distr/internal/util"
@@ -20,10 +21,12 @@ func Get(name string) ([]byte, error) {
 	return fs.ReadFile(fsys, name)
 }
 
+// GetTemplate returns the parsed template with the given name. Executing it fails if the data does not contain a key
+// that the template uses, instead of rendering "<no value>".
 func GetTemplate(name string) (*template.Template, error) {
 	if tmpl, ok := templates[name]; ok {
 		return tmpl, nil
-	} else if tmpl, err := template.ParseFS(fsys, name); err != nil {
+	} else if tmpl, err := template.New(path.Base(name)).Option("missingkey=error").ParseFS(fsys, name); err != nil {
 		return nil, fmt.Errorf("failed to parse template %v: %w", name, err)
 	} else {
 		templates[name] = tmpl
@@ -1,6 +1,8 @@
 package routing
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -9,9 +11,12 @@ import (
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/distr-sh/distr/internal/frontend"
 	"github.com/distr-sh/distr/internal/handlers"
+	"github.com/distr-sh/distr/internal/jobs"
 	"github.com/distr-sh/distr/internal/mail"
 	"github.com/distr-sh/distr/internal/middleware"
 	"github.com/distr-sh/distr/internal/oidc"
+	"github.com/distr-sh/distr/internal/registry"
+	"github.com/distr-sh/distr/internal/registry/blob/s3"
 	"github.com/distr-sh/distr/internal/tracers"
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -57,7 +62,12 @@ Read more about Distr and our use cases at https://distr.sh/docs/
 `
 
 func NewRouter(
-	logger *zap.Logger, db *pgxpool.Pool, mailer mail.Mailer, tracers *tracers.Tracers, oidcer *oidc.OIDCer,
+	logger *zap.Logger,
+	db *pgxpool.Pool,
+	mailer mail.Mailer,
+	tracers *tracers.Tracers,
+	oidcer *oidc.OIDCer,
+	scheduler *jobs.Scheduler,
 ) http.Handler {
 	baseRouter := chi.NewRouter()
 	baseRouter.Use(
@@ -87,12 +97,18 @@ func NewRouter(
 			Layout:      "responsive",
 		}),
 	)
-	openapiRouter.Route("/api", ApiRouter(logger, db, mailer, tracers, oidcer))
+	openapiRouter.Route("/api", ApiRouter(logger, db, mailer, tracers, oidcer, scheduler))
 
 	baseRouter.Mount("/internal", InternalRouter())
 	baseRouter.Mount("/status", StatusRouter())
 	baseRouter.Mount("/ready", ReadyRouter(db))
+	baseRouter.Mount("/healthz", StatusRouter())
+	baseRouter.Mount("/readyz", ReadinessRouter(logger, db))
 	baseRouter.Mount("/.well-known", WellKnownRouter())
+	if !env.RegistryEnabled() {
+		// without this, registry clients would receive the frontend instead of an error they can display
+		baseRouter.Mount("/v2", registry.NewDisabled())
+	}
 	baseRouter.Mount("/", FrontendRouter())
 
 	return baseRouter
@@ -104,11 +120,12 @@ func ApiRouter(
 	mailer mail.Mailer,
 	tracers *tracers.Tracers,
 	oidcer *oidc.OIDCer,
+	scheduler *jobs.Scheduler,
 ) func(r chiopenapi.Router) {
 	return func(r chiopenapi.Router) {
 		r.Use(
 			chimiddleware.RequestID,
-			chimiddleware.RealIP,
+			middleware.RealIP(env.TrustedProxyCIDRs(), env.TrustedProxyHeaders()),
 			middleware.Sentry,
 			middleware.LoggerCtxMiddleware(logger),
 			middleware.LoggingMiddleware,
@@ -124,7 +141,8 @@ func ApiRouter(
 				// public routes go here
 				r.Group(func(r chiopenapi.Router) {
 					r.Route("/auth", handlers.AuthRouter)
-					r.Route("/webhook", handlers.WebhookRouter)
+					r.Route("/version", handlers.VersionRouter)
+					r.With(middleware.ReadOnlyMode).Route("/webhook", handlers.WebhookRouter)
 				})
 
 				// authenticated routes go here
@@ -137,6 +155,7 @@ func ApiRouter(
 					r.Use(
 						middleware.SentryUser,
 						auth.Authentication.Middleware,
+						middleware.ReadOnlyMode,
 						httprate.Limit(30, 1*time.Second, httprate.WithKeyFuncs(middleware.RateLimitUserIDKey)),
 						httprate.Limit(60, 1*time.Minute, httprate.WithKeyFuncs(middleware.RateLimitUserIDKey)),
 						httprate.Limit(2000, 1*time.Hour, httprate.WithKeyFuncs(middleware.RateLimitUserIDKey)),
@@ -145,11 +164,13 @@ func ApiRouter(
 						// such that agents cant access anything here (they also can't now, because their tokens will not
 						// pass the Authentication chain (DbAuthenticator can't find the user -> 401)
 					)
+					r.Route("/admin", handlers.AdminRouter(scheduler))
 					r.Route("/agent-versions", handlers.AgentVersionsRouter)
 					r.Route("/application-licenses", handlers.ApplicationLicensesRouter)
 					r.Route("/applications", handlers.ApplicationsRouter)
 					r.Route("/artifact-licenses", handlers.ArtifactLicensesRouter)
 					r.Route("/artifact-pulls", handlers.ArtifactPullsRouter)
+					r.Route("/artifact-push-webhooks", handlers.ArtifactPushWebhooksRouter)
 					r.Route("/artifacts", handlers.ArtifactsRouter)
 					r.Route("/billing", handlers.BillingRouter)
 					r.Route("/context", handlers.ContextRouter)
@@ -161,6 +182,7 @@ func ApiRouter(
 					r.Route("/deployments", handlers.DeploymentsRouter)
 					r.Route("/files", handlers.FileRouter)
 					r.Route("/notification-records", handlers.NotificationRecordsRouter)
+					r.Route("/notifications", handlers.NotificationsRouter)
 					r.Route("/organization", handlers.OrganizationRouter)
 					r.Route("/organizations", handlers.OrganizationsRouter)
 					r.Route("/secrets", handlers.SecretsRouter)
@@ -214,6 +236,49 @@ func ReadyRouter(db *pgxpool.Pool) http.Handler {
 	return router
 }
 
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// ReadinessRouter checks that all downstream dependencies are reachable.
+// It responds with 503 and lists the failed dependencies if any check fails.
+func ReadinessRouter(logger *zap.Logger, db *pgxpool.Pool) http.Handler {
+	checks := []readinessCheck{{
+		name: "database",
+		check: func(ctx context.Context) error {
+			var result int
+			return db.QueryRow(ctx, "SELECT 1").Scan(&result)
+		},
+	}}
+	if env.RegistryEnabled() {
+		checks = append(checks, readinessCheck{name: "s3", check: s3.NewBucketCheck(context.Background())})
+	}
+
+	router := chi.NewRouter()
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		failed := []string{}
+		for _, c := range checks {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			if err := c.check(ctx); err != nil {
+				logger.Warn("readiness check failed", zap.String("dependency", c.name), zap.Error(err))
+				failed = append(failed, c.name)
+			}
+			cancel()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(failed) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(struct {
+			Ready  bool     `json:"ready"`
+			Failed []string `json:"failed"`
+		}{Ready: len(failed) == 0, Failed: failed})
+	})
+	return router
+}
+
 func FrontendRouter() http.Handler {
 	router := chi.NewRouter()
 	router.Use(
@@ -225,14 +290,11 @@ func FrontendRouter() http.Handler {
 	return router
 }
 
+// WellKnownRouter is mounted independently of the request host, so its documents are also served on the custom
+// domains of all organizations.
 func WellKnownRouter() http.Handler {
 	router := chi.NewRouter()
-	if env.WellKnownMicrosoftIdentityAssociation() != nil {
-		router.Get("/microsoft-identity-association.json", func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write(env.WellKnownMicrosoftIdentityAssociation())
-		})
-	}
-
+	router.Get("/microsoft-identity-association.json",
+		handlers.WellKnownMicrosoftIdentityAssociationHandler(env.WellKnownMicrosoftIdentityAssociation()))
 	return router
 }
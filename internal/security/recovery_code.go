@@ -10,6 +10,9 @@ import (
 const (
 	recoveryCodeLength = 10
 	recoveryCodeCount  = 10
+
+	// RecoveryCodesLowThreshold is the number of unused recovery codes below which users are asked to regenerate them.
+	RecoveryCodesLowThreshold = 3
 )
 
 func GenerateRecoveryCodes() ([]string, error) {
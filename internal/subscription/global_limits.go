@@ -2,6 +2,7 @@ package subscription
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/distr-sh/distr/api"
 	"github.com/distr-sh/distr/internal/types"
@@ -29,6 +30,11 @@ const (
 	MaxLogExportRowsEnterprise Limit = 1_000_000
 )
 
+const (
+	MinMetricsEntriesMaxAgePro        = 24 * time.Hour
+	MinMetricsEntriesMaxAgeEnterprise = 7 * 24 * time.Hour
+)
+
 func (l Limit) IsReached(other int64) bool {
 	return l != Unlimited && int64(l) <= other
 }
@@ -105,6 +111,27 @@ func GetLogExportRowsLimit(st types.SubscriptionType) Limit {
 	}
 }
 
+// GetMetricsEntriesMaxAge returns the age after which deployment target metrics of an organization with the given
+// subscription type are deleted. The configured max age applies to all organizations, but pro organizations keep
+// their metrics at least for MinMetricsEntriesMaxAgePro and enterprise organizations for
+// MinMetricsEntriesMaxAgeEnterprise.
+func GetMetricsEntriesMaxAge(st types.SubscriptionType, configured time.Duration) time.Duration {
+	switch st {
+	case types.SubscriptionTypeCommunity:
+		return configured
+	case types.SubscriptionTypeTrial:
+		return max(configured, MinMetricsEntriesMaxAgePro)
+	case types.SubscriptionTypeStarter:
+		return configured
+	case types.SubscriptionTypePro:
+		return max(configured, MinMetricsEntriesMaxAgePro)
+	case types.SubscriptionTypeEnterprise:
+		return max(configured, MinMetricsEntriesMaxAgeEnterprise)
+	default:
+		panic(fmt.Sprintf("invalid subscription type: %v", st))
+	}
+}
+
 func GetSubscriptionLimits(st types.SubscriptionType) api.SubscriptionLimits {
 	return api.SubscriptionLimits{
 		MaxCustomerOrganizations:        int64(GetCustomersPerOrganizationLimit(st)),
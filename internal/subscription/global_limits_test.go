@@ -0,0 +1,37 @@
+package subscription_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/subscription"
+	"github.com/distr-sh/distr/internal/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestGetMetricsEntriesMaxAge(t *testing.T) {
+	for _, tc := range []struct {
+		subscriptionType types.SubscriptionType
+		configured       time.Duration
+		expected         time.Duration
+	}{
+		{types.SubscriptionTypeCommunity, time.Hour, time.Hour},
+		{types.SubscriptionTypeStarter, time.Hour, time.Hour},
+		{types.SubscriptionTypeTrial, time.Hour, subscription.MinMetricsEntriesMaxAgePro},
+		{types.SubscriptionTypePro, time.Hour, subscription.MinMetricsEntriesMaxAgePro},
+		{types.SubscriptionTypePro, 48 * time.Hour, 48 * time.Hour},
+		{types.SubscriptionTypeEnterprise, time.Hour, subscription.MinMetricsEntriesMaxAgeEnterprise},
+		{types.SubscriptionTypeEnterprise, 30 * 24 * time.Hour, 30 * 24 * time.Hour},
+	} {
+		t.Run(fmt.Sprintf("%v %v", tc.subscriptionType, tc.configured), func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(subscription.GetMetricsEntriesMaxAge(tc.subscriptionType, tc.configured)).To(Equal(tc.expected))
+		})
+	}
+
+	t.Run("invalid subscription type", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(func() { subscription.GetMetricsEntriesMaxAge("invalid", time.Hour) }).To(Panic())
+	})
+}
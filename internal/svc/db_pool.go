@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/distr-sh/distr/internal/db"
 	"github.com/distr-sh/distr/internal/env"
 	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5"
@@ -38,29 +39,7 @@ func (reg *Registry) createDBPool(ctx context.Context) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, err
 	}
-	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		typeNames := []string{
-			"DEPLOYMENT_TYPE",
-			"USER_ROLE",
-			"HELM_CHART_TYPE",
-			"DEPLOYMENT_STATUS_TYPE",
-			"FEATURE",
-			"_FEATURE",
-			"TUTORIAL",
-			"SUBSCRIPTIONTYPE",
-			"_SUBSCRIPTIONTYPE",
-			"CUSTOMER_ORGANIZATION_FEATURE",
-			"_CUSTOMER_ORGANIZATION_FEATURE",
-		}
-		for _, typeName := range typeNames {
-			if pgType, err := conn.LoadType(ctx, typeName); err != nil {
-				return err
-			} else {
-				conn.TypeMap().RegisterType(pgType)
-			}
-		}
-		return nil
-	}
+	config.AfterConnect = db.RegisterTypes
 	if maxConns := env.DatabaseMaxConns(); maxConns != nil {
 		config.MaxConns = int32(*maxConns)
 	}
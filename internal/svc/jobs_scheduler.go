@@ -17,83 +17,11 @@ func (r *Registry) createJobsScheduler() (*jobs.Scheduler, error) {
 		return nil, err
 	}
 
-	if cron := env.CleanupDeploymenRevisionStatusCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob(
-				"DeploymentRevisionStatusCleanup",
-				cleanup.RunDeploymentRevisionStatusCleanup,
-				env.CleanupDeploymenRevisionStatusTimeout(),
-			),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if cron := env.CleanupDeploymenTargetStatusCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob(
-				"DeploymentTargetStatusCleanup",
-				cleanup.RunDeploymentTargetStatusCleanup,
-				env.CleanupDeploymenTargetStatusTimeout(),
-			),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if cron := env.CleanupDeploymentTargetMetricsCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob(
-				"DeploymentTargetMetricsCleanup",
-				cleanup.RunDeploymentTargetMetricsCleanup,
-				env.CleanupDeploymentTargetMetricsTimeout(),
-			),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if cron := env.CleanupDeploymentTargetLogRecordCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob(
-				"DeploymentTargetLogRecordCleanup",
-				cleanup.RunDeploymentTargetLogRecordCleanup,
-				env.CleanupDeploymentTargetLogRecordTimeout(),
-			),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if cron := env.CleanupDeploymentLogRecordCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob(
-				"DeploymentLogRecordCleanup",
-				cleanup.RunDeploymentLogRecordCleanup,
-				env.CleanupDeploymentLogRecordTimeout(),
-			),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if cron := env.CleanupOIDCStateCron(); cron != nil {
-		err = scheduler.RegisterCronJob(
-			*cron,
-			jobs.NewJob("OIDCStateCleanup", cleanup.RunOIDCStateCleanup, env.CleanupOIDCStateCronTimeout()),
-		)
-		if err != nil {
-			return nil, err
+	for _, job := range cleanup.Jobs() {
+		if job.Cron != nil {
+			if err := scheduler.RegisterCronJob(*job.Cron, jobs.NewJob(job.Name, job.Run, job.Timeout)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -111,5 +39,25 @@ func (r *Registry) createJobsScheduler() (*jobs.Scheduler, error) {
 		}
 	}
 
+	err = scheduler.RegisterCronJob(
+		env.AlertDigestCron(),
+		jobs.NewJob("AlertDigest", notification.RunAlertDigests, env.AlertDigestTimeout()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = scheduler.RegisterCronJob(
+		env.ArtifactPushWebhookCron(),
+		jobs.NewJob(
+			"ArtifactPushWebhookDelivery",
+			notification.RunArtifactPushWebhookDeliveries,
+			env.ArtifactPushWebhookTimeout(),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return scheduler, nil
 }
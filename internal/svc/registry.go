@@ -132,6 +132,7 @@ func (r *Registry) GetRouter() http.Handler {
 		r.GetMailer(),
 		r.GetTracers(),
 		r.GetOIDCer(),
+		r.GetJobsScheduler(),
 	)
 }
 
@@ -52,16 +52,38 @@ func (reg *Registry) createTracer(ctx context.Context) (*tracers.Tracers, error)
 		)...)
 	}
 
-	if cfg := env.OtelRegistrySampler(); cfg != nil {
-		tracers.RegistryProvider = trace.NewTracerProvider(append(
-			tpopts,
-			trace.WithSampler(samplerFromConfig(cfg)),
-		)...)
+	if sampler := registrySampler(); sampler != nil {
+		tracers.RegistryProvider = trace.NewTracerProvider(append(tpopts, trace.WithSampler(sampler))...)
 	}
 
 	return &tracers, nil
 }
 
+// registrySampler returns the sampler for registry requests or nil if the default sampler should be used.
+// If pull or push samplers are configured, requests without a specific sampler use the general registry sampler or,
+// if that is not configured either, a parent based sampler that samples all root spans.
+func registrySampler() trace.Sampler {
+	var sampler trace.Sampler
+	if cfg := env.OtelRegistrySampler(); cfg != nil {
+		sampler = samplerFromConfig(cfg)
+	}
+	pullCfg, pushCfg := env.OtelRegistryPullSampler(), env.OtelRegistryPushSampler()
+	if pullCfg == nil && pushCfg == nil {
+		return sampler
+	}
+	if sampler == nil {
+		sampler = trace.ParentBased(trace.AlwaysSample())
+	}
+	pullSampler, pushSampler := sampler, sampler
+	if pullCfg != nil {
+		pullSampler = samplerFromConfig(pullCfg)
+	}
+	if pushCfg != nil {
+		pushSampler = samplerFromConfig(pushCfg)
+	}
+	return tracers.NewMethodSampler(pullSampler, pushSampler)
+}
+
 func samplerFromConfig(cfg *env.SamplerConfig) trace.Sampler {
 	switch cfg.Sampler {
 	case env.SamplerAlwaysOn:
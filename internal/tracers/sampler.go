@@ -0,0 +1,48 @@
+package tracers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NewMethodSampler returns a sampler for HTTP server spans that delegates to readSampler for requests with a safe
+// method (GET, HEAD and OPTIONS) and to writeSampler for all other requests. This allows, for example, to sample
+// frequent registry pulls at a low rate while still recording every push.
+//
+// The method is taken from the "http.request.method" attribute or, if absent, the first word of the span name.
+func NewMethodSampler(readSampler, writeSampler trace.Sampler) trace.Sampler {
+	return &methodSampler{read: readSampler, write: writeSampler}
+}
+
+type methodSampler struct {
+	read  trace.Sampler
+	write trace.Sampler
+}
+
+// ShouldSample implements trace.Sampler.
+func (s *methodSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	switch requestMethod(p) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return s.read.ShouldSample(p)
+	default:
+		return s.write.ShouldSample(p)
+	}
+}
+
+// Description implements trace.Sampler.
+func (s *methodSampler) Description() string {
+	return fmt.Sprintf("MethodSampler{read:%v,write:%v}", s.read.Description(), s.write.Description())
+}
+
+func requestMethod(p trace.SamplingParameters) string {
+	for _, attr := range p.Attributes {
+		if attr.Key == "http.request.method" || attr.Key == "http.method" {
+			return attr.Value.AsString()
+		}
+	}
+	method, _, _ := strings.Cut(p.Name, " ")
+	return method
+}
@@ -0,0 +1,30 @@
+package tracers_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/tracers"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestMethodSampler(t *testing.T) {
+	g := NewWithT(t)
+	sampler := tracers.NewMethodSampler(trace.NeverSample(), trace.AlwaysSample())
+
+	g.Expect(sampler.ShouldSample(trace.SamplingParameters{Name: "GET"}).Decision).
+		To(Equal(trace.Drop))
+	g.Expect(sampler.ShouldSample(trace.SamplingParameters{Name: "HEAD /v2/"}).Decision).
+		To(Equal(trace.Drop))
+	g.Expect(sampler.ShouldSample(trace.SamplingParameters{Name: "PUT"}).Decision).
+		To(Equal(trace.RecordAndSample))
+	g.Expect(sampler.ShouldSample(trace.SamplingParameters{
+		Name:       "request",
+		Attributes: []attribute.KeyValue{attribute.String("http.request.method", "GET")},
+	}).Decision).To(Equal(trace.Drop))
+	g.Expect(sampler.ShouldSample(trace.SamplingParameters{
+		Name:       "GET",
+		Attributes: []attribute.KeyValue{attribute.String("http.request.method", "PATCH")},
+	}).Decision).To(Equal(trace.RecordAndSample))
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+	"net/netip"
 	"time"
 
 	"github.com/distr-sh/distr/internal/authkey"
@@ -16,12 +18,50 @@ type AccessToken struct {
 	Key            authkey.Key `db:"key"`
 	UserAccountID  uuid.UUID   `db:"user_account_id"`
 	OrganizationID uuid.UUID   `db:"organization_id"`
+	AllowedCIDRs   []string    `db:"allowed_cidrs"`
 }
 
 func (tok AccessToken) HasExpired() bool {
 	return tok.ExpiresAt == nil || tok.ExpiresAt.After(time.Now())
 }
 
+// AllowsIPAddress reports whether the token may be used from the given address.
+// The address may optionally contain a port, as in [net/http.Request.RemoteAddr].
+// A token without allowed CIDRs may be used from any address.
+func (tok AccessToken) AllowsIPAddress(address string) bool {
+	if len(tok.AllowedCIDRs) == 0 {
+		return true
+	}
+	addr, err := parseIPAddress(address)
+	if err != nil {
+		return false
+	}
+	for _, cidr := range tok.AllowedCIDRs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIPAddress(address string) (netip.Addr, error) {
+	if addrPort, err := netip.ParseAddrPort(address); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(address)
+	return addr.Unmap(), err
+}
+
+// ValidateCIDRs returns an error if any of the given strings is not a valid IPv4 or IPv6 CIDR.
+func ValidateCIDRs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+	}
+	return nil
+}
+
 type AccessTokenWithUserAccount struct {
 	AccessToken
 	UserAccount            UserAccount `db:"user_account"`
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestAccessTokenAllowsIPAddressUnrestricted(t *testing.T) {
+	g := NewWithT(t)
+	tok := AccessToken{}
+	g.Expect(tok.AllowsIPAddress("203.0.113.7:1234")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("not an address")).To(BeTrue())
+}
+
+func TestAccessTokenAllowsIPAddressIPv4(t *testing.T) {
+	g := NewWithT(t)
+	tok := AccessToken{AllowedCIDRs: []string{"203.0.113.0/24", "198.51.100.10/32"}}
+	g.Expect(tok.AllowsIPAddress("203.0.113.7")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("203.0.113.7:54321")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("198.51.100.10")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("198.51.100.11")).To(BeFalse())
+	g.Expect(tok.AllowsIPAddress("192.0.2.1:80")).To(BeFalse())
+	g.Expect(tok.AllowsIPAddress("::ffff:203.0.113.7")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("")).To(BeFalse())
+}
+
+func TestAccessTokenAllowsIPAddressIPv6(t *testing.T) {
+	g := NewWithT(t)
+	tok := AccessToken{AllowedCIDRs: []string{"2001:db8::/32"}}
+	g.Expect(tok.AllowsIPAddress("2001:db8::1")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("[2001:db8:1::1]:443")).To(BeTrue())
+	g.Expect(tok.AllowsIPAddress("2001:db9::1")).To(BeFalse())
+	g.Expect(tok.AllowsIPAddress("203.0.113.7")).To(BeFalse())
+}
+
+func TestValidateCIDRs(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(ValidateCIDRs(nil)).To(Succeed())
+	g.Expect(ValidateCIDRs([]string{"10.0.0.0/8", "2001:db8::/32", "192.0.2.1/32"})).To(Succeed())
+	g.Expect(ValidateCIDRs([]string{"10.0.0.0"})).NotTo(Succeed())
+	g.Expect(ValidateCIDRs([]string{"10.0.0.0/33"})).NotTo(Succeed())
+	g.Expect(ValidateCIDRs([]string{"2001:db8::/129"})).NotTo(Succeed())
+	g.Expect(ValidateCIDRs([]string{"10.0.0.0/8", "not-a-cidr"})).NotTo(Succeed())
+}
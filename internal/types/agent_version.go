@@ -18,6 +18,27 @@ type AgentVersion struct {
 	ComposeFileRevision  string    `db:"compose_file_revision" json:"-"`
 }
 
+// CheckMinVersion returns an error if the version is older than minVersion. Snapshot builds, versions that are not
+// valid semantic versions and a nil minVersion are always accepted.
+func (av AgentVersion) CheckMinVersion(minVersion *semver.Version) error {
+	return CheckAgentVersionName(av.Name, minVersion)
+}
+
+// CheckAgentVersionName is like [AgentVersion.CheckMinVersion] for a version that is only known by its name.
+func CheckAgentVersionName(name string, minVersion *semver.Version) error {
+	if minVersion == nil || name == "snapshot" {
+		return nil
+	}
+	sv, err := semver.NewVersion(name)
+	if err != nil {
+		return nil
+	}
+	if sv.LessThan(minVersion) {
+		return fmt.Errorf("agent version %v is outdated, please update to at least %v", sv, minVersion)
+	}
+	return nil
+}
+
 func (av AgentVersion) CheckMultiDeploymentSupported() error {
 	if av.Name == "snapshot" {
 		return nil
@@ -1,11 +1,58 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/distr-sh/distr/internal/validation"
 	"github.com/google/uuid"
 )
 
+const quietHoursLayout = "15:04"
+
+var ErrInvalidAlertTransition = fmt.Errorf("invalid alert transition")
+
+// AlertTransition is a kind of deployment status change that an alert configuration can notify about.
+type AlertTransition string
+
+const (
+	AlertTransitionError          AlertTransition = "error"
+	AlertTransitionErrorRecovered AlertTransition = "error_recovered"
+	AlertTransitionStale          AlertTransition = "stale"
+	AlertTransitionStaleRecovered AlertTransition = "stale_recovered"
+)
+
+func AllAlertTransitions() []AlertTransition {
+	return []AlertTransition{
+		AlertTransitionError,
+		AlertTransitionErrorRecovered,
+		AlertTransitionStale,
+		AlertTransitionStaleRecovered,
+	}
+}
+
+func ParseAlertTransition(value string) (AlertTransition, error) {
+	if t := AlertTransition(value); slices.Contains(AllAlertTransitions(), t) {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: %v", ErrInvalidAlertTransition, value)
+}
+
+func (ref *AlertTransition) UnmarshalJSON(data []byte) error {
+	var transitionStr string
+	if err := json.Unmarshal(data, &transitionStr); err != nil {
+		return err
+	} else if transition, err := ParseAlertTransition(transitionStr); err != nil {
+		return err
+	} else {
+		*ref = transition
+		return nil
+	}
+}
+
 type AlertConfiguration struct {
 	ID                     uuid.UUID   `db:"id" json:"id"`
 	CreatedAt              time.Time   `db:"created_at" json:"createdAt"`
@@ -16,9 +63,101 @@ type AlertConfiguration struct {
 	DeploymentTargetIDs    []uuid.UUID `db:"deployment_target_ids" json:"deploymentTargetIds"`
 	UserAccountIDs         []uuid.UUID `db:"user_account_ids" json:"userAccountIds"`
 
+	// Transitions are the status changes that trigger a notification. Creating or updating a configuration without
+	// transitions enables all of them.
+	Transitions []AlertTransition `db:"transitions" json:"transitions"`
+
+	// QuietHoursStart and QuietHoursEnd define a daily window in QuietHoursTimezone (all in "15:04" format or all nil)
+	// during which notifications are suppressed. The window may span midnight. Error notifications are still sent
+	// during quiet hours if QuietHoursAllowErrors is set, which is the default if it is omitted, like in the database.
+	QuietHoursStart       *string `db:"quiet_hours_start" json:"quietHoursStart,omitempty"`
+	QuietHoursEnd         *string `db:"quiet_hours_end" json:"quietHoursEnd,omitempty"`
+	QuietHoursTimezone    *string `db:"quiet_hours_timezone" json:"quietHoursTimezone,omitempty"`
+	QuietHoursAllowErrors bool    `db:"quiet_hours_allow_errors" json:"quietHoursAllowErrors"`
+
+	// Digest configurations do not notify about each status change immediately. Instead, all notifications since the
+	// last digest are sent periodically in a single summary.
+	Digest bool `db:"digest" json:"digest"`
+
 	// UserAccounts is only populated from the database. It is never used by insert or update operations.
 	UserAccounts []UserAccount `db:"user_accounts" json:"userAccounts"`
 
 	// DeploymentTargets is only populated from the database. It is never used by insert or update operations.
 	DeploymentTargets []DeploymentTarget `db:"deployment_targets" json:"deploymentTargets"`
 }
+
+// UnmarshalJSON decodes the configuration like the default decoder, except that QuietHoursAllowErrors defaults to true
+// if it is omitted, so that configurations created through the API behave like those that only exist in the database.
+func (c *AlertConfiguration) UnmarshalJSON(data []byte) error {
+	type alertConfiguration AlertConfiguration
+	result := alertConfiguration{QuietHoursAllowErrors: true}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*c = AlertConfiguration(result)
+	return nil
+}
+
+// NotifiesOn reports whether the configuration should send notifications for the given transition.
+func (c *AlertConfiguration) NotifiesOn(transition AlertTransition) bool {
+	return slices.Contains(c.Transitions, transition)
+}
+
+// InQuietHours reports whether t lies within the quiet hours of the configuration.
+func (c *AlertConfiguration) InQuietHours(t time.Time) bool {
+	if c.QuietHoursStart == nil || c.QuietHoursEnd == nil || c.QuietHoursTimezone == nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(*c.QuietHoursTimezone)
+	if err != nil {
+		return false
+	}
+	start, err := parseQuietHoursTime(*c.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseQuietHoursTime(*c.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	if startMinute <= endMinute {
+		return startMinute <= minute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+func (c *AlertConfiguration) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return validation.NewValidationFailedError("name must not be empty")
+	}
+
+	if c.QuietHoursStart != nil || c.QuietHoursEnd != nil || c.QuietHoursTimezone != nil {
+		if c.QuietHoursStart == nil || c.QuietHoursEnd == nil || c.QuietHoursTimezone == nil {
+			return validation.NewValidationFailedError("quiet hours start, end and timezone must be set together")
+		} else if _, err := parseQuietHoursTime(*c.QuietHoursStart); err != nil {
+			return validation.NewValidationFailedError("quiet hours start must be in HH:MM format")
+		} else if _, err := parseQuietHoursTime(*c.QuietHoursEnd); err != nil {
+			return validation.NewValidationFailedError("quiet hours end must be in HH:MM format")
+		} else if *c.QuietHoursStart == *c.QuietHoursEnd {
+			return validation.NewValidationFailedError("quiet hours start and end must not be equal")
+		} else if _, err := time.LoadLocation(*c.QuietHoursTimezone); err != nil || *c.QuietHoursTimezone == "" {
+			return validation.NewValidationFailedError("quiet hours timezone is invalid")
+		}
+	}
+
+	return nil
+}
+
+func parseQuietHoursTime(value string) (time.Time, error) {
+	t, err := time.Parse(quietHoursLayout, value)
+	if err == nil && t.Format(quietHoursLayout) != value {
+		err = fmt.Errorf("time %q is not in %v format", value, quietHoursLayout)
+	}
+	return t, err
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestAlertConfigurationUnmarshalJSONQuietHoursAllowErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	var config AlertConfiguration
+	g.Expect(json.Unmarshal([]byte(`{"name":"test","transitions":["error"]}`), &config)).To(Succeed())
+	g.Expect(config.Name).To(Equal("test"))
+	g.Expect(config.Transitions).To(Equal([]AlertTransition{AlertTransitionError}))
+	g.Expect(config.QuietHoursAllowErrors).To(BeTrue())
+
+	config = AlertConfiguration{}
+	g.Expect(json.Unmarshal([]byte(`{"name":"test","quietHoursAllowErrors":false}`), &config)).To(Succeed())
+	g.Expect(config.QuietHoursAllowErrors).To(BeFalse())
+
+	g.Expect(json.Unmarshal([]byte(`{"transitions":["unknown"]}`), &config)).To(MatchError(ErrInvalidAlertTransition))
+}
+
+func TestAlertConfigurationInQuietHours(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	quietHours := func(start, end string) AlertConfiguration {
+		return AlertConfiguration{
+			QuietHoursStart:    util.PtrTo(start),
+			QuietHoursEnd:      util.PtrTo(end),
+			QuietHoursTimezone: util.PtrTo("Europe/Vienna"),
+		}
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.January, 15, hour, minute, 0, 0, vienna)
+	}
+
+	tests := []struct {
+		name     string
+		config   AlertConfiguration
+		t        time.Time
+		expected bool
+	}{
+		{"not configured", AlertConfiguration{}, at(3, 0), false},
+		{"within", quietHours("09:00", "17:00"), at(12, 0), true},
+		{"at start", quietHours("09:00", "17:00"), at(9, 0), true},
+		{"at end", quietHours("09:00", "17:00"), at(17, 0), false},
+		{"before", quietHours("09:00", "17:00"), at(8, 59), false},
+		{"over midnight late", quietHours("22:00", "06:00"), at(23, 30), true},
+		{"over midnight early", quietHours("22:00", "06:00"), at(3, 0), true},
+		{"over midnight outside", quietHours("22:00", "06:00"), at(12, 0), false},
+		// 02:00 UTC is 03:00 in Vienna in winter
+		{"other time zone", quietHours("02:30", "04:00"), time.Date(2026, time.January, 15, 2, 0, 0, 0, time.UTC), true},
+		{"invalid time zone", AlertConfiguration{
+			QuietHoursStart:    util.PtrTo("00:00"),
+			QuietHoursEnd:      util.PtrTo("23:59"),
+			QuietHoursTimezone: util.PtrTo("Invalid/Zone"),
+		}, at(12, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tt.config.InQuietHours(tt.t)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package types
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/containers/image/v5/manifest"
+	"github.com/distr-sh/distr/internal/validation"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +19,56 @@ const (
 	ManifestTypeHelmChart      ManifestType = "helm-chart"
 )
 
+// InferManifestType determines the kind of artifact described by a manifest with the given content type and data.
+// Docker manifests are always container images, OCI manifests are Helm charts if their config or one of their layers
+// has a Helm media type. Everything else, including all image indexes and manifests that can not be parsed, is
+// generic.
+func InferManifestType(contentType string, data []byte) ManifestType {
+	if strings.HasPrefix(contentType, "application/vnd.docker") {
+		return ManifestTypeContainerImage
+	} else if manifest.MIMETypeIsMultiImage(contentType) || len(data) == 0 {
+		return ManifestTypeGeneric
+	} else if parsedManifest, err := manifest.FromBlob(data, contentType); err != nil {
+		return ManifestTypeGeneric
+	} else if strings.HasPrefix(parsedManifest.ConfigInfo().MediaType, "application/vnd.cncf.helm") ||
+		slices.ContainsFunc(parsedManifest.LayerInfos(), func(layer manifest.LayerInfo) bool {
+			return strings.HasPrefix(layer.MediaType, "application/vnd.cncf.helm")
+		}) {
+		return ManifestTypeHelmChart
+	} else {
+		return ManifestTypeGeneric
+	}
+}
+
+// ParseManifestPlatforms returns the platforms of all manifests referenced by an image index with the given content
+// type and data. Manifests without a platform are skipped. For all other content types, the result is empty.
+func ParseManifestPlatforms(contentType string, data []byte) ([]ArtifactVersionPlatform, error) {
+	if !manifest.MIMETypeIsMultiImage(contentType) {
+		return nil, nil
+	}
+	list, err := manifest.ListFromBlob(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	var result []ArtifactVersionPlatform
+	for _, d := range list.Instances() {
+		if instance, err := list.Instance(d); err != nil {
+			return nil, err
+		} else if platform := instance.ReadOnly.Platform; platform != nil && platform.OS != "" {
+			p := ArtifactVersionPlatform{
+				Digest:       Digest(d),
+				OS:           platform.OS,
+				Architecture: platform.Architecture,
+				Variant:      platform.Variant,
+			}
+			if !slices.Contains(result, p) {
+				result = append(result, p)
+			}
+		}
+	}
+	return result, nil
+}
+
 type Artifact struct {
 	ID             uuid.UUID  `db:"id" json:"id"`
 	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
@@ -22,6 +77,33 @@ type Artifact struct {
 	ImageID        *uuid.UUID `db:"image_id" json:"-"`
 }
 
+type DeletedArtifact struct {
+	Artifact
+	DeletedAt time.Time `db:"deleted_at" json:"deletedAt"`
+}
+
+// ArtifactDeletion records the deletion of a tag or, if TagName is nil, of the whole artifact.
+// ArtifactID is nil once the artifact has been purged, but the record and the artifact name are kept.
+type ArtifactDeletion struct {
+	ID                        uuid.UUID  `db:"id" json:"id"`
+	CreatedAt                 time.Time  `db:"created_at" json:"createdAt"`
+	ArtifactID                *uuid.UUID `db:"artifact_id" json:"artifactId,omitempty"`
+	ArtifactName              string     `db:"artifact_name" json:"artifactName"`
+	TagName                   *string    `db:"tag_name" json:"tagName,omitempty"`
+	ManifestBlobDigest        *Digest    `db:"manifest_blob_digest" json:"digest,omitempty"`
+	DeletedByUserAccountID    *uuid.UUID `db:"deleted_by_useraccount_id" json:"deletedByUserAccountId,omitempty"`
+	DeletedByUserAccountEmail *string    `db:"deleted_by_useraccount_email" json:"deletedByUserAccountEmail,omitempty"`
+}
+
+// ArtifactPullStats summarizes the pulls of an artifact within a period. DistinctPullers counts each customer
+// organization and each vendor user pulling without a customer organization once.
+type ArtifactPullStats struct {
+	ArtifactID      uuid.UUID `db:"artifact_id" json:"artifactId"`
+	ArtifactName    string    `db:"artifact_name" json:"artifactName"`
+	PullsTotal      int       `db:"pulls_total" json:"pullsTotal"`
+	DistinctPullers int       `db:"distinct_pullers" json:"distinctPullers"`
+}
+
 type DownloadMetrics struct {
 	DownloadsTotal                         int         `db:"downloads_total" json:"downloadsTotal"`
 	DownloadedByUsersCount                 int         `db:"downloaded_by_users_count" json:"downloadedByUsersCount"`
@@ -37,18 +119,26 @@ type ArtifactVersionTag struct {
 	Downloads DownloadMetrics `json:"downloads"`
 }
 
+// ArtifactVersionPlatform is the platform of a manifest referenced by an image index.
+type ArtifactVersionPlatform struct {
+	Digest       Digest `db:"manifest_blob_digest" json:"digest"`
+	OS           string `db:"os" json:"os"`
+	Architecture string `db:"architecture" json:"architecture"`
+	Variant      string `db:"variant" json:"variant,omitempty"`
+}
+
 type TaggedArtifactVersion struct {
 	ID                  uuid.UUID            `db:"id" json:"id"`
 	CreatedAt           time.Time            `db:"created_at" json:"createdAt"`
 	Digest              string               `db:"manifest_blob_digest" json:"digest"`
 	ManifestContentType string               `db:"manifest_content_type" json:"manifestContentType"`
-	ManifestData        []byte               `db:"manifest_data" json:"-"`
 	Tags                []ArtifactVersionTag `db:"tags" json:"tags"`
 	Size                int64                `db:"size" json:"size"`
 
 	DownloadMetrics
 
-	InferredType ManifestType `db:"-" json:"inferredType"`
+	InferredType ManifestType              `db:"inferred_type" json:"inferredType"`
+	Platforms    []ArtifactVersionPlatform `db:"platforms" json:"platforms,omitempty"`
 }
 
 type ArtifactWithDownloads struct {
@@ -61,3 +151,90 @@ type ArtifactWithTaggedVersion struct {
 	ArtifactWithDownloads
 	Versions []TaggedArtifactVersion `db:"versions" json:"versions,omitempty"`
 }
+
+type ArtifactSortBy string
+
+const (
+	ArtifactSortByName      ArtifactSortBy = "name"
+	ArtifactSortByCreated   ArtifactSortBy = "created"
+	ArtifactSortByDownloads ArtifactSortBy = "downloads"
+)
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "asc"
+	SortDirectionDesc SortDirection = "desc"
+)
+
+// ArtifactListFilter restricts and orders the result of an artifact list query.
+// The zero value returns all artifacts ordered by their latest version date.
+type ArtifactListFilter struct {
+	NameQuery string
+	// Type restricts the result to artifacts with at least one version of this type.
+	Type ManifestType
+	// TaggedOnly restricts the result to artifacts with at least one tag.
+	TaggedOnly bool
+	SortBy     ArtifactSortBy
+	SortDir    SortDirection
+	Limit      int
+	Offset     int
+	// AccessUserID and AccessUserRole restrict the result to artifacts that the vendor user with this ID and role may
+	// read according to the access rules of the artifact. They are set with RestrictToReadableBy.
+	AccessUserID   *uuid.UUID
+	AccessUserRole *UserRole
+}
+
+// RestrictToReadableBy restricts the result to artifacts that the vendor user with the given ID and role may read,
+// see ArtifactAccessAllowed. Admins bypass the access rules, so the filter is not changed for them.
+func (f *ArtifactListFilter) RestrictToReadableBy(userID uuid.UUID, role UserRole) {
+	if role != UserRoleAdmin {
+		f.AccessUserID = &userID
+		f.AccessUserRole = &role
+	}
+}
+
+func (f ArtifactListFilter) Validate() error {
+	switch f.SortBy {
+	case "", ArtifactSortByName, ArtifactSortByCreated, ArtifactSortByDownloads:
+	default:
+		return validation.NewValidationFailedError(fmt.Sprintf("invalid sort field: %v", f.SortBy))
+	}
+	switch f.SortDir {
+	case "", SortDirectionAsc, SortDirectionDesc:
+	default:
+		return validation.NewValidationFailedError(fmt.Sprintf("invalid sort direction: %v", f.SortDir))
+	}
+	switch f.Type {
+	case "", ManifestTypeGeneric, ManifestTypeContainerImage, ManifestTypeHelmChart:
+	default:
+		return validation.NewValidationFailedError(fmt.Sprintf("invalid type: %v", f.Type))
+	}
+	if f.Limit < 0 {
+		return validation.NewValidationFailedError("limit must not be negative")
+	}
+	if f.Offset < 0 {
+		return validation.NewValidationFailedError("offset must not be negative")
+	}
+	return nil
+}
+
+// CatalogExportVersion is a single manifest of an artifact including everything that is needed to re-create it in
+// another registry.
+type CatalogExportVersion struct {
+	ArtifactID          uuid.UUID           `db:"artifact_id"`
+	ArtifactName        string              `db:"artifact_name"`
+	ArtifactCreatedAt   time.Time           `db:"artifact_created_at"`
+	CreatedAt           time.Time           `db:"created_at"`
+	ManifestBlobDigest  Digest              `db:"manifest_blob_digest"`
+	ManifestBlobSize    int64               `db:"manifest_blob_size"`
+	ManifestContentType string              `db:"manifest_content_type"`
+	ManifestData        []byte              `db:"manifest_data"`
+	Tags                []string            `db:"tags"`
+	Blobs               []CatalogExportBlob `db:"blobs"`
+}
+
+type CatalogExportBlob struct {
+	Digest Digest `db:"digest"`
+	Size   int64  `db:"size"`
+}
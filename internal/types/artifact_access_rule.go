@@ -0,0 +1,55 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ArtifactPermission string
+
+const (
+	ArtifactPermissionRead  ArtifactPermission = "read"
+	ArtifactPermissionWrite ArtifactPermission = "write"
+)
+
+// Grants reports whether p includes other. Write permission implies read permission.
+func (p ArtifactPermission) Grants(other ArtifactPermission) bool {
+	return p == other || p == ArtifactPermissionWrite && other == ArtifactPermissionRead
+}
+
+// ArtifactAccessRule grants a vendor user, or all vendor users with a given role, access to a single artifact.
+// Exactly one of UserAccountID and UserRole is set.
+type ArtifactAccessRule struct {
+	ID            uuid.UUID          `db:"id" json:"id"`
+	CreatedAt     time.Time          `db:"created_at" json:"createdAt"`
+	ArtifactID    uuid.UUID          `db:"artifact_id" json:"artifactId"`
+	UserAccountID *uuid.UUID         `db:"user_account_id" json:"userAccountId,omitempty"`
+	UserRole      *UserRole          `db:"user_role" json:"userRole,omitempty"`
+	Permission    ArtifactPermission `db:"permission" json:"permission"`
+}
+
+func (rule ArtifactAccessRule) appliesTo(userID uuid.UUID, role UserRole) bool {
+	return rule.UserAccountID != nil && *rule.UserAccountID == userID ||
+		rule.UserRole != nil && *rule.UserRole == role
+}
+
+// ArtifactAccessAllowed reports whether a vendor user with the given role may access an artifact with the given
+// access rules. Artifacts without any rules are accessible to all vendor users, and admins bypass the rules entirely.
+// Rules can only restrict access further, so the organization role must still permit the requested action.
+func ArtifactAccessAllowed(
+	rules []ArtifactAccessRule,
+	userID uuid.UUID,
+	role UserRole,
+	permission ArtifactPermission,
+) bool {
+	if role == UserRoleAdmin || len(rules) == 0 {
+		return true
+	}
+	for _, rule := range rules {
+		if rule.appliesTo(userID, role) && rule.Permission.Grants(permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/util"
+	"github.com/google/uuid"
+	. "github.com/onsi/gomega"
+)
+
+func TestArtifactAccessAllowedWithoutRules(t *testing.T) {
+	g := NewWithT(t)
+	userID := uuid.New()
+	g.Expect(ArtifactAccessAllowed(nil, userID, UserRoleReadOnly, ArtifactPermissionRead)).To(BeTrue())
+	g.Expect(ArtifactAccessAllowed(nil, userID, UserRoleReadWrite, ArtifactPermissionWrite)).To(BeTrue())
+}
+
+func TestArtifactAccessAllowedAdminBypass(t *testing.T) {
+	g := NewWithT(t)
+	rules := []ArtifactAccessRule{{UserAccountID: util.PtrTo(uuid.New()), Permission: ArtifactPermissionRead}}
+	g.Expect(ArtifactAccessAllowed(rules, uuid.New(), UserRoleAdmin, ArtifactPermissionWrite)).To(BeTrue())
+}
+
+func TestArtifactAccessAllowedByUser(t *testing.T) {
+	g := NewWithT(t)
+	userID := uuid.New()
+	rules := []ArtifactAccessRule{{UserAccountID: &userID, Permission: ArtifactPermissionRead}}
+	g.Expect(ArtifactAccessAllowed(rules, userID, UserRoleReadWrite, ArtifactPermissionRead)).To(BeTrue())
+	g.Expect(ArtifactAccessAllowed(rules, userID, UserRoleReadWrite, ArtifactPermissionWrite)).To(BeFalse())
+	g.Expect(ArtifactAccessAllowed(rules, uuid.New(), UserRoleReadWrite, ArtifactPermissionRead)).To(BeFalse())
+}
+
+func TestArtifactAccessAllowedByRole(t *testing.T) {
+	g := NewWithT(t)
+	rules := []ArtifactAccessRule{{UserRole: util.PtrTo(UserRoleReadWrite), Permission: ArtifactPermissionWrite}}
+	g.Expect(ArtifactAccessAllowed(rules, uuid.New(), UserRoleReadWrite, ArtifactPermissionWrite)).To(BeTrue())
+	g.Expect(ArtifactAccessAllowed(rules, uuid.New(), UserRoleReadWrite, ArtifactPermissionRead)).To(BeTrue())
+	g.Expect(ArtifactAccessAllowed(rules, uuid.New(), UserRoleReadOnly, ArtifactPermissionRead)).To(BeFalse())
+}
+
+func TestArtifactListFilterRestrictToReadableBy(t *testing.T) {
+	g := NewWithT(t)
+	userID := uuid.New()
+
+	var filter ArtifactListFilter
+	filter.RestrictToReadableBy(userID, UserRoleReadOnly)
+	g.Expect(filter.AccessUserID).To(HaveValue(Equal(userID)))
+	g.Expect(filter.AccessUserRole).To(HaveValue(Equal(UserRoleReadOnly)))
+
+	filter = ArtifactListFilter{}
+	filter.RestrictToReadableBy(userID, UserRoleAdmin)
+	g.Expect(filter.AccessUserID).To(BeNil())
+	g.Expect(filter.AccessUserRole).To(BeNil())
+}
@@ -1,11 +1,24 @@
 package types
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/distr-sh/distr/internal/validation"
 	"github.com/google/uuid"
 )
 
+// PullLimitPeriod is the calendar period in which the pulls of a license are counted. The values are valid units for
+// the date_trunc function of PostgreSQL.
+type PullLimitPeriod string
+
+const (
+	PullLimitPeriodDay   PullLimitPeriod = "day"
+	PullLimitPeriodWeek  PullLimitPeriod = "week"
+	PullLimitPeriodMonth PullLimitPeriod = "month"
+	PullLimitPeriodYear  PullLimitPeriod = "year"
+)
+
 type ArtifactLicenseBase struct {
 	ID                     uuid.UUID  `db:"id" json:"id"`
 	CreatedAt              time.Time  `db:"created_at" json:"createdAt"`
@@ -13,6 +26,26 @@ type ArtifactLicenseBase struct {
 	ExpiresAt              *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
 	OrganizationID         uuid.UUID  `db:"organization_id" json:"-"`
 	CustomerOrganizationID *uuid.UUID `db:"customer_organization_id" json:"customerOrganizationId,omitempty"`
+	// MaxPulls limits the number of pulls of the licensed artifacts by the customer organization per PullLimitPeriod.
+	// If it is nil, pulls are not limited.
+	MaxPulls        *int             `db:"max_pulls" json:"maxPulls,omitempty"`
+	PullLimitPeriod *PullLimitPeriod `db:"pull_limit_period" json:"pullLimitPeriod,omitempty"`
+}
+
+func (l ArtifactLicenseBase) Validate() error {
+	if l.MaxPulls == nil && l.PullLimitPeriod == nil {
+		return nil
+	} else if l.MaxPulls == nil || l.PullLimitPeriod == nil {
+		return validation.NewValidationFailedError("max pulls and pull limit period must be set together")
+	} else if *l.MaxPulls <= 0 {
+		return validation.NewValidationFailedError("max pulls must be positive")
+	}
+	switch *l.PullLimitPeriod {
+	case PullLimitPeriodDay, PullLimitPeriodWeek, PullLimitPeriodMonth, PullLimitPeriodYear:
+		return nil
+	default:
+		return validation.NewValidationFailedError(fmt.Sprintf("invalid pull limit period: %v", *l.PullLimitPeriod))
+	}
 }
 
 type ArtifactLicenseSelection struct {
@@ -24,3 +57,12 @@ type ArtifactLicense struct {
 	ArtifactLicenseBase
 	Artifacts []ArtifactLicenseSelection `db:"artifacts" json:"artifacts,omitempty"`
 }
+
+// ArtifactCustomerAccess is a customer organization that can pull an artifact.
+type ArtifactCustomerAccess struct {
+	CustomerOrganization
+	// AllVersions is true if the customer organization can pull every version of the artifact. Otherwise, it can only
+	// pull the versions in VersionIDs and the manifests they reference.
+	AllVersions bool        `db:"all_versions" json:"allVersions"`
+	VersionIDs  []uuid.UUID `db:"version_ids" json:"versionIds,omitempty"`
+}
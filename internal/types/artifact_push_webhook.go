@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/distr-sh/distr/internal/validation"
+	"github.com/google/uuid"
+)
+
+// ArtifactPushWebhook is called whenever a tag of an artifact of its organization is pushed.
+type ArtifactPushWebhook struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"-"`
+	URL            string    `db:"url" json:"url"`
+	// Secret is used to sign the payload of every request. It is never included in responses and only replaced by an
+	// update if it is not empty.
+	Secret string `db:"secret" json:"secret,omitempty"`
+	// ArtifactNameFilter is a pattern in the syntax of path.Match, e.g. "apps/*". If it is nil, the webhook is called for
+	// all artifacts.
+	ArtifactNameFilter *string `db:"artifact_name_filter" json:"artifactNameFilter,omitempty"`
+}
+
+func (w ArtifactPushWebhook) Validate() error {
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return validation.NewValidationFailedError("url is invalid")
+	} else if w.ArtifactNameFilter != nil {
+		if _, err := path.Match(*w.ArtifactNameFilter, ""); err != nil {
+			return validation.NewValidationFailedError("artifact name filter is invalid")
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the webhook must be called for a push of the artifact with the given name.
+func (w ArtifactPushWebhook) Matches(artifactName string) bool {
+	if w.ArtifactNameFilter == nil {
+		return true
+	}
+	matched, _ := path.Match(*w.ArtifactNameFilter, artifactName)
+	return matched
+}
+
+// ArtifactPushWebhookDelivery records the delivery of a single push event to a webhook. NextAttemptAt is nil once the
+// event has been delivered or all attempts have failed.
+type ArtifactPushWebhookDelivery struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	WebhookID      uuid.UUID       `db:"webhook_id" json:"webhookId"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  *time.Time      `db:"next_attempt_at" json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `db:"last_attempt_at" json:"lastAttemptAt,omitempty"`
+	LastStatusCode *int            `db:"last_status_code" json:"lastStatusCode,omitempty"`
+	LastError      *string         `db:"last_error" json:"lastError,omitempty"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"deliveredAt,omitempty"`
+}
+
+// ArtifactPushWebhookDeliveryWithTarget is a pending delivery together with the URL and secret of its webhook.
+type ArtifactPushWebhookDeliveryWithTarget struct {
+	ArtifactPushWebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// ArtifactPushEvent is the payload sent to artifact push webhooks.
+type ArtifactPushEvent struct {
+	Event          string    `json:"event"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Artifact       string    `json:"artifact"`
+	Tag            string    `json:"tag"`
+	Digest         Digest    `json:"digest"`
+	PushedBy       struct {
+		ID    uuid.UUID `json:"id"`
+		Email string    `json:"email"`
+	} `json:"pushedBy"`
+	Timestamp time.Time `json:"timestamp"`
+}
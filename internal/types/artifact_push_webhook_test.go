@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestArtifactPushWebhookMatches(t *testing.T) {
+	tests := []struct {
+		filter   *string
+		name     string
+		expected bool
+	}{
+		{nil, "apps/backend", true},
+		{util.PtrTo("apps/backend"), "apps/backend", true},
+		{util.PtrTo("apps/backend"), "apps/frontend", false},
+		{util.PtrTo("apps/*"), "apps/backend", true},
+		{util.PtrTo("apps/*"), "apps/backend/debug", false},
+		{util.PtrTo("apps/*"), "charts/backend", false},
+		{util.PtrTo("*/backend"), "apps/backend", true},
+		{util.PtrTo("apps/[bf]*"), "apps/frontend", true},
+		{util.PtrTo("apps/[bf]*"), "apps/worker", false},
+		{util.PtrTo("apps/["), "apps/backend", false},
+	}
+	for _, tt := range tests {
+		filter := "<nil>"
+		if tt.filter != nil {
+			filter = *tt.filter
+		}
+		t.Run(filter+" "+tt.name, func(t *testing.T) {
+			NewWithT(t).Expect(ArtifactPushWebhook{ArtifactNameFilter: tt.filter}.Matches(tt.name)).To(Equal(tt.expected))
+		})
+	}
+}
@@ -18,4 +18,15 @@ type ArtifactVersion struct {
 	ManifestContentType    string     `db:"manifest_content_type" json:"manifestContentType"`
 	ManifestData           []byte     `db:"manifest_data" json:"-"`
 	ArtifactID             uuid.UUID  `db:"artifact_id" json:"artifactId"`
+	// InferredType is computed from the manifest once when the version is pushed, see InferManifestType.
+	InferredType ManifestType `db:"inferred_type" json:"-"`
+}
+
+// BasicArtifactVersion contains only the identifying fields of an artifact version. It is used where the size, tags
+// and download metrics of a version are not needed.
+type BasicArtifactVersion struct {
+	ID                  uuid.UUID `db:"id"`
+	Name                string    `db:"name"`
+	ManifestBlobDigest  Digest    `db:"manifest_blob_digest"`
+	ManifestContentType string    `db:"manifest_content_type"`
 }
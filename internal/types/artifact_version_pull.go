@@ -1,8 +1,13 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ArtifactVersionPull struct {
+	ID                   uuid.UUID             `json:"id"`
 	CreatedAt            time.Time             `json:"createdAt"`
 	RemoteAddress        *string               `json:"remoteAddress,omitempty"`
 	UserAccount          *UserAccount          `json:"userAccount,omitempty"`
@@ -10,3 +15,18 @@ type ArtifactVersionPull struct {
 	Artifact             Artifact              `json:"artifact"`
 	ArtifactVersion      ArtifactVersion       `json:"artifactVersion"`
 }
+
+// CustomerOrganizationPullStats summarizes the pulls of a customer organization within a period.
+type CustomerOrganizationPullStats struct {
+	CustomerOrganizationID   uuid.UUID `db:"customer_organization_id" json:"customerOrganizationId"`
+	CustomerOrganizationName string    `db:"customer_organization_name" json:"customerOrganizationName"`
+	PullsTotal               int       `db:"pulls_total" json:"pullsTotal"`
+	DistinctArtifacts        int       `db:"distinct_artifacts" json:"distinctArtifacts"`
+	LastPulledAt             time.Time `db:"last_pulled_at" json:"lastPulledAt"`
+}
+
+// PullCountBucket is the number of pulls within a time bucket starting at Start.
+type PullCountBucket struct {
+	Start      time.Time `db:"start" json:"start"`
+	PullsTotal int       `db:"pulls_total" json:"pullsTotal"`
+}
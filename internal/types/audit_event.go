@@ -0,0 +1,22 @@
+package types
+
+import "fmt"
+
+// AuditEventType identifies a kind of audit record whose retention is managed by the audit event cleanup.
+type AuditEventType string
+
+const (
+	AuditEventTypeArtifactDeletion    AuditEventType = "artifact_deletion"
+	AuditEventTypeArtifactTagDeletion AuditEventType = "artifact_tag_deletion"
+)
+
+var AuditEventTypes = []AuditEventType{AuditEventTypeArtifactDeletion, AuditEventTypeArtifactTagDeletion}
+
+func ParseAuditEventType(value string) (AuditEventType, error) {
+	for _, t := range AuditEventTypes {
+		if string(t) == value {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid AuditEventType: %v", value)
+}
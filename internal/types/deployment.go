@@ -1,8 +1,10 @@
 package types
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/distr-sh/distr/internal/validation"
 	"github.com/google/uuid"
 )
 
@@ -42,3 +44,50 @@ func (d *DeploymentWithLatestRevision) GetValuesYAML() []byte {
 func (d *DeploymentWithLatestRevision) GetEnvFileData() []byte {
 	return d.EnvFileData
 }
+
+// DeploymentOverview is a deployment together with its deployment target and the latest status reported for any of
+// its revisions. It is used for the organization-wide deployment list.
+type DeploymentOverview struct {
+	Deployment
+	DeploymentTargetName   string                    `db:"deployment_target_name" json:"deploymentTargetName"`
+	CustomerOrganizationID *uuid.UUID                `db:"customer_organization_id" json:"customerOrganizationId,omitempty"` //nolint:lll
+	ApplicationName        string                    `db:"application_name" json:"applicationName"`
+	ApplicationVersionName string                    `db:"application_version_name" json:"applicationVersionName"`
+	LatestStatus           *DeploymentRevisionStatus `db:"latest_status" json:"latestStatus,omitempty"`
+	// LastSeenAt is the time of the latest heartbeat sent by the agent of the deployment target
+	LastSeenAt *time.Time `db:"last_seen_at" json:"lastSeenAt,omitempty"`
+	// Stale is true if the latest status is outdated. Together with AgentOnline, this distinguishes deployments that
+	// are failing from deployments whose agent has stopped reporting.
+	Stale bool `db:"-" json:"stale"`
+	// AgentOnline is true if the agent of the deployment target has sent a heartbeat recently
+	AgentOnline bool `db:"-" json:"agentOnline"`
+}
+
+func (d *DeploymentOverview) IsAgentOnline() bool {
+	return isAgentOnline(d.LastSeenAt)
+}
+
+// DeploymentListFilter restricts the result of the organization-wide deployment list.
+type DeploymentListFilter struct {
+	// StatusType restricts the result to deployments whose latest status has this type.
+	StatusType         DeploymentStatusType
+	DeploymentTargetID *uuid.UUID
+	Limit              int
+	Offset             int
+}
+
+func (f DeploymentListFilter) Validate() error {
+	switch f.StatusType {
+	case "", DeploymentStatusTypeHealthy, DeploymentStatusTypeRunning, DeploymentStatusTypeProgressing,
+		DeploymentStatusTypeError:
+	default:
+		return validation.NewValidationFailedError(fmt.Sprintf("invalid status: %v", f.StatusType))
+	}
+	if f.Limit < 1 {
+		return validation.NewValidationFailedError("limit must be positive")
+	}
+	if f.Offset < 0 {
+		return validation.NewValidationFailedError("offset must not be negative")
+	}
+	return nil
+}
@@ -14,8 +14,8 @@ type DeploymentRevisionStatus struct {
 	Message              string               `db:"message" json:"message"`
 }
 
-const deploymentRevisionStatusStaleDuration = 1 * time.Minute
-
-func (s *DeploymentRevisionStatus) IsStale() bool {
-	return s != nil && time.Since(s.CreatedAt) > deploymentRevisionStatusStaleDuration
+// IsStale returns true if the status is older than staleDuration, which means that the agent has not reported a status
+// for this deployment for some time. See env.DeploymentStatusStaleDuration.
+func (s *DeploymentRevisionStatus) IsStale(staleDuration time.Duration) bool {
+	return s != nil && time.Since(s.CreatedAt) > staleDuration
 }
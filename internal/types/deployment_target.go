@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/distr-sh/distr/internal/validation"
@@ -24,6 +26,25 @@ type DeploymentTarget struct {
 	ReportedAgentVersionID *uuid.UUID                 `db:"reported_agent_version_id" json:"reportedAgentVersionId,omitempty"` //nolint:lll
 	MetricsEnabled         bool                       `db:"metrics_enabled" json:"metricsEnabled"`
 	Resources              *DeploymentTargetResources `db:"resources" json:"resources,omitempty"`
+	// LogRecordEntriesMaxCount overrides the instance-wide LOG_RECORD_ENTRIES_MAX_COUNT for this deployment target
+	LogRecordEntriesMaxCount *int `db:"log_record_entries_max_count" json:"logRecordEntriesMaxCount,omitempty"`
+	// AccessKeyRotatedAt is the time the secret was last rotated. Agent tokens issued before are no longer accepted.
+	AccessKeyRotatedAt *time.Time `db:"access_key_rotated_at" json:"accessKeyRotatedAt,omitempty"`
+}
+
+// AcceptsAgentTokenIssuedAt returns false if the agent token was issued before the secret was rotated. JWTs only have
+// a precision of one second, so tokens issued in the same second as the rotation are accepted.
+func (dt *DeploymentTarget) AcceptsAgentTokenIssuedAt(issuedAt time.Time) bool {
+	return dt.AccessKeyRotatedAt == nil || !issuedAt.Before(dt.AccessKeyRotatedAt.Truncate(time.Second))
+}
+
+// GetEffectiveLogRecordEntriesMaxCount returns the override of this deployment target if one is set and the
+// instance-wide default otherwise. A nil result means that the log records are not limited.
+func (dt *DeploymentTarget) GetEffectiveLogRecordEntriesMaxCount(instanceDefault *int) *int {
+	if dt.LogRecordEntriesMaxCount != nil {
+		return dt.LogRecordEntriesMaxCount
+	}
+	return instanceDefault
 }
 
 type DeploymentTargetResources struct {
@@ -33,7 +54,41 @@ type DeploymentTargetResources struct {
 	MemoryLimit   string `json:"memoryLimit"`
 }
 
+// dockerMemoryPattern matches the memory sizes accepted by Docker Compose, e.g. "512m" or "1.5GB".
+var dockerMemoryPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)? ?[kKmMgGtT]?[iI]?[bB]?$`)
+
+// validateDocker checks that the resources can be used in a Docker Compose file. For Docker, CPUs are given as a number
+// of cores (e.g. "0.5") and memory as a size with an optional unit (e.g. "512m"). Empty values are not set.
+func (r *DeploymentTargetResources) validateDocker() error {
+	for _, cpu := range []struct{ name, value string }{
+		{"CPU request", r.CPURequest},
+		{"CPU limit", r.CPULimit},
+	} {
+		if cpu.value == "" {
+			continue
+		} else if cpus, err := strconv.ParseFloat(cpu.value, 64); err != nil || cpus <= 0 {
+			return validation.NewValidationFailedError(
+				fmt.Sprintf("invalid %v %q: must be a positive number of CPUs, e.g. \"0.5\"", cpu.name, cpu.value),
+			)
+		}
+	}
+	for _, memory := range []struct{ name, value string }{
+		{"memory request", r.MemoryRequest},
+		{"memory limit", r.MemoryLimit},
+	} {
+		if memory.value != "" && !dockerMemoryPattern.MatchString(memory.value) {
+			return validation.NewValidationFailedError(
+				fmt.Sprintf("invalid %v %q: must be a size like \"512m\" or \"1g\"", memory.name, memory.value),
+			)
+		}
+	}
+	return nil
+}
+
 func (dt *DeploymentTarget) Validate() error {
+	if dt.LogRecordEntriesMaxCount != nil && *dt.LogRecordEntriesMaxCount < 0 {
+		return validation.NewValidationFailedError("log record entries max count must not be negative")
+	}
 	switch dt.Type {
 	case DeploymentTypeKubernetes:
 		if dt.Namespace == nil || *dt.Namespace == "" {
@@ -60,7 +115,9 @@ func (dt *DeploymentTarget) Validate() error {
 		}
 	case DeploymentTypeDocker:
 		if dt.Resources != nil {
-			return validation.NewValidationFailedError("DeploymentTarget with type \"docker\" must not have resources")
+			if err := dt.Resources.validateDocker(); err != nil {
+				return err
+			}
 		}
 	default:
 		return validation.NewValidationFailedError("invalid deployment target type")
@@ -74,4 +131,26 @@ type DeploymentTargetFull struct {
 	CurrentStatus        *DeploymentTargetStatus        `db:"current_status" json:"currentStatus,omitempty"`
 	Deployments          []DeploymentWithLatestRevision `db:"-" json:"deployments"`
 	AgentVersion         AgentVersion                   `db:"agent_version" json:"agentVersion"`
+	// EffectiveLogRecordEntriesMaxCount is the log record limit that is actually enforced for this deployment target
+	EffectiveLogRecordEntriesMaxCount *int `db:"-" json:"effectiveLogRecordEntriesMaxCount,omitempty"`
+	// LastSeenAt is the time of the latest heartbeat sent by the agent
+	LastSeenAt *time.Time `db:"last_seen_at" json:"lastSeenAt,omitempty"`
+	// Online is true if the agent has sent a heartbeat recently
+	Online bool `db:"-" json:"online"`
+	// ReportedAgentVersion is the version of the agent that last connected
+	ReportedAgentVersion *AgentVersion `db:"reported_agent_version" json:"-"`
+	// AgentVersionWarning is set if the reported agent version is older than the minimum supported version
+	AgentVersionWarning *string `db:"-" json:"agentVersionWarning,omitempty"`
+}
+
+// DeploymentTargetHeartbeatInterval is the interval in which agents send heartbeats. A deployment target is considered
+// offline if no heartbeat was received for a few intervals.
+const DeploymentTargetHeartbeatInterval = 30 * time.Second
+
+func (dt *DeploymentTargetFull) IsOnline() bool {
+	return isAgentOnline(dt.LastSeenAt)
+}
+
+func isAgentOnline(lastSeenAt *time.Time) bool {
+	return lastSeenAt != nil && time.Since(*lastSeenAt) < 3*DeploymentTargetHeartbeatInterval
 }
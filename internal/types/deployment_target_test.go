@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDeploymentTargetValidateDockerResources(t *testing.T) {
+	g := NewWithT(t)
+
+	dt := DeploymentTarget{Name: "docker", Type: DeploymentTypeDocker}
+	g.Expect(dt.Validate()).To(Succeed())
+
+	dt.Resources = &DeploymentTargetResources{CPULimit: "0.5", MemoryLimit: "512m"}
+	g.Expect(dt.Validate()).To(Succeed())
+
+	dt.Resources = &DeploymentTargetResources{CPURequest: "1", MemoryRequest: "1.5GB", CPULimit: "2", MemoryLimit: "2g"}
+	g.Expect(dt.Validate()).To(Succeed())
+
+	dt.Resources = &DeploymentTargetResources{CPULimit: "500m"}
+	g.Expect(dt.Validate()).NotTo(Succeed())
+
+	dt.Resources = &DeploymentTargetResources{CPULimit: "0"}
+	g.Expect(dt.Validate()).NotTo(Succeed())
+
+	dt.Resources = &DeploymentTargetResources{MemoryLimit: "512 apples"}
+	g.Expect(dt.Validate()).NotTo(Succeed())
+}
+
+func TestDeploymentTargetAcceptsAgentTokenIssuedAt(t *testing.T) {
+	g := NewWithT(t)
+
+	dt := DeploymentTarget{}
+	g.Expect(dt.AcceptsAgentTokenIssuedAt(time.Time{})).To(BeTrue())
+
+	rotatedAt := time.Date(2026, 1, 1, 12, 0, 0, 700_000_000, time.UTC)
+	dt.AccessKeyRotatedAt = &rotatedAt
+	g.Expect(dt.AcceptsAgentTokenIssuedAt(rotatedAt.Add(-time.Second))).To(BeFalse())
+	g.Expect(dt.AcceptsAgentTokenIssuedAt(rotatedAt.Add(-time.Hour))).To(BeFalse())
+	// the issued at claim of a token has no fractional seconds
+	g.Expect(dt.AcceptsAgentTokenIssuedAt(rotatedAt.Truncate(time.Second))).To(BeTrue())
+	g.Expect(dt.AcceptsAgentTokenIssuedAt(rotatedAt.Add(time.Minute))).To(BeTrue())
+}
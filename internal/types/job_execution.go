@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobExecution is the recorded outcome of a single run of a scheduled job on any instance.
+type JobExecution struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	JobName    string    `db:"job_name" json:"jobName"`
+	StartedAt  time.Time `db:"started_at" json:"startedAt"`
+	FinishedAt time.Time `db:"finished_at" json:"finishedAt"`
+	// Error is the error message if the job failed.
+	Error *string `db:"error" json:"error,omitempty"`
+	// RowsAffected is nil if the job did not report the number of affected rows.
+	RowsAffected *int64 `db:"rows_affected" json:"rowsAffected,omitempty"`
+}
@@ -16,6 +16,8 @@ type NotificationRecord struct {
 	PreviousDeploymentRevisionStatusID *uuid.UUID `db:"previous_deployment_revision_status_id"`
 	CurrentDeploymentRevisionStatusID  *uuid.UUID `db:"current_deployment_revision_status_id"`
 	Message                            string     `db:"message" json:"message"`
+	// DeliveredAt is nil while the record is waiting to be sent as part of a digest
+	DeliveredAt *time.Time `db:"delivered_at"`
 }
 
 type NotificationRecordWithCurrentStatus struct {
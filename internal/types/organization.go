@@ -18,6 +18,8 @@ type Organization struct {
 	Features                            []Feature          `db:"features" json:"features"`
 	AppDomain                           *string            `db:"app_domain" json:"appDomain"`
 	RegistryDomain                      *string            `db:"registry_domain" json:"registryDomain"`
+	AppDomainVerifiedAt                 *time.Time         `db:"app_domain_verified_at" json:"-"`
+	RegistryDomainVerifiedAt            *time.Time         `db:"registry_domain_verified_at" json:"-"`
 	EmailFromAddress                    *string            `db:"email_from_address" json:"emailFromAddress"`
 	SubscriptionType                    SubscriptionType   `db:"subscription_type" json:"subscriptionType"`
 	SubscriptionPeriod                  SubscriptionPeriod `db:"subscription_period" json:"subscriptionPeriod"`
@@ -29,6 +31,9 @@ type Organization struct {
 	PreConnectScript                    *string            `db:"pre_connect_script" json:"preConnectScript"`
 	PostConnectScript                   *string            `db:"post_connect_script" json:"postConnectScript"`
 	ConnectScriptIsSudo                 bool               `db:"connect_script_is_sudo" json:"connectScriptIsSudo"`
+	QuotaExceededWebhookURL             *string            `db:"quota_exceeded_webhook_url" json:"quotaExceededWebhookUrl"`
+	QuotaExceededEmailEnabled           bool               `db:"quota_exceeded_email_enabled" json:"quotaExceededEmailEnabled"`  //nolint:lll
+	RequireArtifactPrecreation          bool               `db:"require_artifact_precreation" json:"requireArtifactPrecreation"` //nolint:lll
 }
 
 func (org *Organization) HasFeature(feature Feature) bool {
@@ -100,3 +105,11 @@ func (b *OrganizationBranding) LogoDataUrl() *string {
 		return nil
 	}
 }
+
+type OrganizationDomains struct {
+	AppDomain                *string    `db:"app_domain"`
+	RegistryDomain           *string    `db:"registry_domain"`
+	VerificationToken        *string    `db:"domain_verification_token"`
+	AppDomainVerifiedAt      *time.Time `db:"app_domain_verified_at"`
+	RegistryDomainVerifiedAt *time.Time `db:"registry_domain_verified_at"`
+}
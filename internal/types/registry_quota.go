@@ -0,0 +1,21 @@
+package types
+
+// RegistryQuota describes the registry usage of an organization. A nil limit means that there is no limit.
+type RegistryQuota struct {
+	TagCount          int64  `db:"tag_count" json:"tagCount"`
+	TagLimit          *int64 `db:"tag_limit" json:"tagLimit"`
+	StorageUsedBytes  int64  `db:"storage_used_bytes" json:"storageUsedBytes"`
+	StorageLimitBytes *int64 `db:"storage_limit_bytes" json:"storageLimitBytes"`
+}
+
+// StorageAvailable reports whether additional bytes can be stored without exceeding the storage limit.
+func (q RegistryQuota) StorageAvailable(additional int64) bool {
+	return q.StorageLimitBytes == nil || q.StorageUsedBytes+additional <= *q.StorageLimitBytes
+}
+
+type RegistryQuotaKind string
+
+const (
+	RegistryQuotaKindTags    RegistryQuotaKind = "tags"
+	RegistryQuotaKindStorage RegistryQuotaKind = "storage"
+)
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegistryQuotaStorageAvailable(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(RegistryQuota{StorageUsedBytes: 100}.StorageAvailable(1 << 40)).To(BeTrue())
+
+	quota := RegistryQuota{StorageUsedBytes: 100, StorageLimitBytes: util.PtrTo[int64](150)}
+	g.Expect(quota.StorageAvailable(0)).To(BeTrue())
+	g.Expect(quota.StorageAvailable(50)).To(BeTrue())
+	g.Expect(quota.StorageAvailable(51)).To(BeFalse())
+
+	quota = RegistryQuota{StorageUsedBytes: 200, StorageLimitBytes: util.PtrTo[int64](150)}
+	g.Expect(quota.StorageAvailable(0)).To(BeFalse())
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+)
+
+const (
+	truncatedIPv4Bits = 24
+	truncatedIPv6Bits = 48
+	hashedIPPrefix    = "anon-"
+)
+
+// NormalizeIPAddress returns the canonical form of the IP address contained in address, which may optionally include
+// a port, as in [net/http.Request.RemoteAddr]. IPv4-mapped IPv6 addresses are converted to IPv4 and IPv6 zones are
+// removed, so that every address has exactly one representation. If address does not contain a valid IP address, it
+// is returned unchanged.
+func NormalizeIPAddress(address string) string {
+	var addr netip.Addr
+	if addrPort, err := netip.ParseAddrPort(address); err == nil {
+		addr = addrPort.Addr()
+	} else if parsed, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")); err == nil {
+		addr = parsed
+	} else {
+		return address
+	}
+	return addr.Unmap().WithZone("").String()
+}
+
+// TruncateIPAddress zeroes the host part of the given address, keeping the first 24 bits of IPv4 and the first 48
+// bits of IPv6 addresses. This keeps enough information for coarse analytics like the network of the client. The
+// address must be normalized, see [NormalizeIPAddress]. If address is not a valid IP address, an empty string is
+// returned, so that no unknown data is kept.
+func TruncateIPAddress(address string) string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return ""
+	}
+	bits := truncatedIPv6Bits
+	if addr.Is4() {
+		bits = truncatedIPv4Bits
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.Addr().String()
+}
+
+// HashIPAddress returns a pseudonym for the given address that is derived from it using the given key. The same
+// address and key always result in the same pseudonym, so distinct clients can still be counted, but the address
+// cannot be recovered without the key.
+func HashIPAddress(address string, key []byte) string {
+	if address == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(address))
+	return hashedIPPrefix + hex.EncodeToString(mac.Sum(nil)[:8])
+}
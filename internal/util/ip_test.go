@@ -0,0 +1,51 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeIPAddress(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "192.0.2.1", expected: "192.0.2.1"},
+		{input: "192.0.2.1:1234", expected: "192.0.2.1"},
+		{input: "2001:db8::1", expected: "2001:db8::1"},
+		{input: "2001:DB8:0:0:0:0:0:1", expected: "2001:db8::1"},
+		{input: "[2001:db8::1]", expected: "2001:db8::1"},
+		{input: "[2001:db8:0::1]:1234", expected: "2001:db8::1"},
+		{input: "[fe80::1%eth0]:1234", expected: "fe80::1"},
+		{input: "::ffff:192.0.2.1", expected: "192.0.2.1"},
+		{input: "[::ffff:192.0.2.1]:1234", expected: "192.0.2.1"},
+		{input: "", expected: ""},
+		{input: "not an address", expected: "not an address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(util.NormalizeIPAddress(tt.input)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestTruncateIPAddress(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(util.TruncateIPAddress("192.0.2.123")).To(Equal("192.0.2.0"))
+	g.Expect(util.TruncateIPAddress("2001:db8:1234:5678::1")).To(Equal("2001:db8:1234::"))
+	g.Expect(util.TruncateIPAddress("not an address")).To(BeEmpty())
+}
+
+func TestHashIPAddress(t *testing.T) {
+	g := NewWithT(t)
+	hash := util.HashIPAddress("192.0.2.1", []byte("key"))
+	g.Expect(hash).To(HavePrefix("anon-"))
+	g.Expect(hash).NotTo(ContainSubstring("192.0.2.1"))
+	g.Expect(util.HashIPAddress("192.0.2.1", []byte("key"))).To(Equal(hash))
+	g.Expect(util.HashIPAddress("192.0.2.2", []byte("key"))).NotTo(Equal(hash))
+	g.Expect(util.HashIPAddress("192.0.2.1", []byte("other"))).NotTo(Equal(hash))
+	g.Expect(util.HashIPAddress("", []byte("key"))).To(BeEmpty())
+}
@@ -16,3 +16,12 @@ func PtrCopy[T any](ptr *T) *T {
 func PtrEq[T comparable](a, b *T) bool {
 	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
 }
+
+// PtrDerefOrZero returns the value ptr points to or the zero value of T if ptr is nil
+func PtrDerefOrZero[T any](ptr *T) T {
+	if ptr == nil {
+		var zero T
+		return zero
+	}
+	return *ptr
+}
@@ -0,0 +1,20 @@
+package util
+
+const truncationMarker = "…"
+
+// Truncate shortens s to at most maxLength runes, replacing the end with an ellipsis if anything was cut off.
+// A maxLength of 0 or less disables truncation.
+func Truncate(s string, maxLength int) (string, bool) {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s, false
+	}
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s, false
+	}
+	markerLength := len([]rune(truncationMarker))
+	if maxLength <= markerLength {
+		return string(runes[:maxLength]), true
+	}
+	return string(runes[:maxLength-markerLength]) + truncationMarker, true
+}
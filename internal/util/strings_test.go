@@ -0,0 +1,32 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/distr-sh/distr/internal/util"
+	. "github.com/onsi/gomega"
+)
+
+func TestTruncate(t *testing.T) {
+	g := NewWithT(t)
+
+	s, truncated := util.Truncate("hello world", 0)
+	g.Expect(s).To(Equal("hello world"))
+	g.Expect(truncated).To(BeFalse())
+
+	s, truncated = util.Truncate("hello world", 11)
+	g.Expect(s).To(Equal("hello world"))
+	g.Expect(truncated).To(BeFalse())
+
+	s, truncated = util.Truncate("hello world", 6)
+	g.Expect(s).To(Equal("hello…"))
+	g.Expect(truncated).To(BeTrue())
+
+	s, truncated = util.Truncate("äöüäöü", 6)
+	g.Expect(s).To(Equal("äöüäöü"))
+	g.Expect(truncated).To(BeFalse())
+
+	s, truncated = util.Truncate("äöüäöü", 4)
+	g.Expect(s).To(Equal("äöü…"))
+	g.Expect(truncated).To(BeTrue())
+}
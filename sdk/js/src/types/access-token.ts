@@ -4,6 +4,7 @@ export interface AccessToken extends BaseModel {
   expiresAt?: string;
   lastUsedAt?: string;
   label?: string;
+  allowedCidrs?: string[];
 }
 
 export interface AccessTokenWithKey extends AccessToken {
@@ -13,4 +14,5 @@ export interface AccessTokenWithKey extends AccessToken {
 export interface CreateAccessTokenRequest {
   label?: string;
   expiresAt?: Date;
+  allowedCidrs?: string[];
 }
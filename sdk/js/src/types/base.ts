@@ -11,7 +11,9 @@ export interface TokenResponse {
   token: string;
 }
 
-export type LoginResponse = ({requiresMfa: false} & TokenResponse) | {requiresMfa: true};
+export type LoginResponse =
+  | ({requiresMfa: false; recoveryCodesLow?: boolean} & TokenResponse)
+  | {requiresMfa: true};
 
 export interface DeploymentTargetAccessResponse {
   connectUrl: string;
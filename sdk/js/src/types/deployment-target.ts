@@ -13,8 +13,13 @@ export interface DeploymentTarget extends BaseModel, Named {
   deployments: DeploymentWithLatestRevision[];
   agentVersion?: AgentVersion;
   reportedAgentVersionId?: string;
+  agentVersionWarning?: string;
   metricsEnabled: boolean;
   resources?: DeploymentTargetResources;
+  logRecordEntriesMaxCount?: number;
+  effectiveLogRecordEntriesMaxCount?: number;
+  lastSeenAt?: string;
+  online?: boolean;
 }
 
 export interface DeploymentTargetResources {